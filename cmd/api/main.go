@@ -0,0 +1,88 @@
+// Command api runs the scanner as a headless REST API server
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/artnikel/nuclei/internal/api"
+	"github.com/artnikel/nuclei/internal/config"
+	"github.com/artnikel/nuclei/internal/logging"
+	"github.com/artnikel/nuclei/internal/metrics"
+	"github.com/artnikel/nuclei/internal/tracing"
+)
+
+func main() {
+	configPath := flag.String("config", "config.yaml", "path to the config file")
+	addr := flag.String("addr", "", "address to listen on (default api.addr of the config file, "+api.DefaultAddr+" if unset)")
+	token := flag.String("token", "", "bearer token clients must send (default api.token of the config file, random if unset)")
+	flag.Parse()
+
+	cfg, err := config.LoadConfig(*configPath)
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+
+	logger, err := logging.NewLogger(cfg.Logging.Path, cfg.Logging.Format)
+	if err != nil {
+		log.Fatalf("failed to init logger: %v", err)
+	}
+
+	if cfg.App.MetricsAddr != "" {
+		go func() {
+			if err := metrics.Serve(cfg.App.MetricsAddr); err != nil {
+				logger.Error("Metrics server failed", slog.Any("error", err))
+			}
+		}()
+	}
+
+	shutdownTracing, err := tracing.Init(context.Background(), cfg.Tracing.Endpoint)
+	if err != nil {
+		logger.Error("Failed to init tracing", slog.Any("error", err))
+	} else {
+		defer shutdownTracing(context.Background())
+	}
+
+	apiCfg := cfg.API
+	if *addr != "" {
+		apiCfg.Addr = *addr
+	}
+	if apiCfg.Addr == "" {
+		apiCfg.Addr = api.DefaultAddr
+	}
+	if *token != "" {
+		apiCfg.Token = *token
+	}
+	if apiCfg.Token == "" {
+		apiCfg.Token, err = randomToken()
+		if err != nil {
+			log.Fatalf("failed to generate API token: %v", err)
+		}
+		fmt.Fprintf(os.Stderr, "Generated API token: %s\n", apiCfg.Token)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	logger.Info("API server listening", slog.String("addr", apiCfg.Addr))
+	if err := api.NewServer(apiCfg, logger).ListenAndServe(ctx, apiCfg.Addr); err != nil {
+		logger.Fatal("API server failed", slog.Any("error", err))
+	}
+}
+
+// randomToken returns a random hex token for servers started without one
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
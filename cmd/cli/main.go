@@ -0,0 +1,504 @@
+//go:build cli
+
+// Command cli runs template scans from the terminal without any GUI dependencies
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/artnikel/nuclei/internal/baseline"
+	"github.com/artnikel/nuclei/internal/config"
+	"github.com/artnikel/nuclei/internal/constants"
+	"github.com/artnikel/nuclei/internal/dedup"
+	"github.com/artnikel/nuclei/internal/logging"
+	"github.com/artnikel/nuclei/internal/notifier"
+	"github.com/artnikel/nuclei/internal/output"
+	"github.com/artnikel/nuclei/internal/scanner"
+	"github.com/artnikel/nuclei/internal/templates"
+	"github.com/artnikel/nuclei/internal/tracing"
+)
+
+// options holds the parsed command line flags
+type options struct {
+	targets   string
+	templates string
+	output    string
+	format    string
+	threads   int
+	timeout   time.Duration
+	proxy     string
+	severity  string
+	tags      string
+	headless  bool
+	logDir    string
+	logFormat string
+	noColor   bool
+
+	strictSchema bool
+	configPath   string
+	baseline     string
+	noRescan     time.Duration
+	noUpdate     bool
+	clearCache   bool
+	verifySigs   bool
+	minCVSS      float64
+	maxRedirects int
+
+	elasticsearch output.ElasticsearchConfig
+
+	// outputTemplate and outputTemplateFile format findings with a text/template instead of format
+	outputTemplate     string
+	outputTemplateFile string
+
+	// jira creates Jira issues for high and critical findings with the jira settings of the config file
+	jira bool
+	// githubIssues creates GitHub issues for findings with the github settings of the config file
+	githubIssues bool
+
+	// uploadSARIF uploads the findings to GitHub code scanning once the scan completes
+	uploadSARIF bool
+	sarifUpload sarifUploadOptions
+}
+
+// sarifUploadOptions are the repository and commit the SARIF upload is made for
+type sarifUploadOptions struct {
+	owner     string
+	repo      string
+	commitSHA string
+	ref       string
+	token     string
+}
+
+func main() {
+	opts := parseFlags()
+
+	logger, err := logging.NewLogger(opts.logDir, opts.logFormat)
+	if err != nil {
+		log.Fatalf("failed to init logger: %v", err)
+	}
+
+	shutdownTracing, err := tracing.Init(context.Background(), tracingEndpoint(opts.configPath, logger))
+	if err != nil {
+		logger.Error("Failed to init tracing", slog.Any("error", err))
+	} else {
+		defer shutdownTracing(context.Background())
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := run(ctx, opts, logger); err != nil {
+		log.Fatalf("scan failed: %v", err)
+	}
+}
+
+// tracingEndpoint returns the tracing endpoint of the config file, empty if the file doesn't exist
+func tracingEndpoint(configPath string, logger *logging.Logger) string {
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			logger.Warn("Failed to load config, tracing endpoint not set", slog.Any("error", err))
+		}
+		return ""
+	}
+	return cfg.Tracing.Endpoint
+}
+
+// parseFlags reads the command line flags into options
+func parseFlags() *options {
+	opts := &options{}
+	flag.StringVar(&opts.targets, "targets", "", "file with targets, one per line (- for stdin)")
+	flag.StringVar(&opts.templates, "templates", "", "directory with templates")
+	flag.StringVar(&opts.output, "output", "", "file to write findings to (default stdout)")
+	flag.StringVar(&opts.format, "format", output.FormatText, "output format: text, json, jsonl, csv, html, junit, sarif")
+	flag.StringVar(&opts.format, "output-format", output.FormatText, "alias for -format")
+	flag.StringVar(&opts.output, "output-file", "", "alias for -output")
+	flag.StringVar(&opts.outputTemplate, "output-template", "", "Go text/template formatting each finding, overrides -format (e.g. '{{.TemplateID}} {{.Target}} {{.Severity}}')")
+	flag.StringVar(&opts.outputTemplateFile, "output-template-file", "", "file with the Go text/template formatting each finding, overrides -format")
+	flag.IntVar(&opts.threads, "threads", 10, "number of targets scanned in parallel")
+	flag.DurationVar(&opts.timeout, "timeout", time.Minute, "timeout for scanning a single target")
+	flag.StringVar(&opts.proxy, "proxy", "", "HTTP proxy URL")
+	flag.StringVar(&opts.severity, "severity", "", "comma-separated severities to run (e.g. critical,high)")
+	flag.StringVar(&opts.tags, "tags", "", "comma-separated tags to run (e.g. cve,rce)")
+	flag.BoolVar(&opts.headless, "headless", false, "enable headless browser requests")
+	flag.StringVar(&opts.logDir, "log-dir", "logs", "directory for log files")
+	flag.StringVar(&opts.logFormat, "log-format", logging.FormatText, "log format: text or json")
+	flag.BoolVar(&opts.noColor, "no-color", false, "disable colors of the findings printed to stderr")
+	flag.StringVar(&opts.baseline, "baseline", "", "baseline file, only findings missing from it are reported (created if missing)")
+	flag.DurationVar(&opts.noRescan, "no-rescan-within", 0, "skip target and template pairs already checked within this duration (e.g. 24h)")
+	flag.StringVar(&opts.configPath, "config", "config.yaml", "config file with template update settings (optional)")
+	flag.BoolVar(&opts.noUpdate, "no-update", false, "skip the template update check on startup")
+	flag.BoolVar(&opts.clearCache, "clear-template-cache", false, "remove cached parsed templates before loading them")
+	flag.BoolVar(&opts.verifySigs, "verify-signatures", false, "skip templates without a valid signature made with the key from the config file")
+	flag.Float64Var(&opts.minCVSS, "min-cvss", 0, "skip templates with a CVSS score below this value (e.g. 7.0), 0 disables the filter")
+	flag.IntVar(&opts.maxRedirects, "max-redirects", templates.DefaultMaxHTTPRedirects, "maximum number of redirects followed per request, 0 disables following")
+	flag.StringVar(&opts.elasticsearch.URL, "es-url", "", "Elasticsearch or OpenSearch URL findings are also indexed into (e.g. http://localhost:9200)")
+	flag.StringVar(&opts.elasticsearch.Index, "es-index", "nuclei-findings", "Elasticsearch index of the findings")
+	flag.StringVar(&opts.elasticsearch.Username, "es-username", "", "Elasticsearch basic auth username")
+	flag.StringVar(&opts.elasticsearch.Password, "es-password", "", "Elasticsearch basic auth password")
+	flag.BoolVar(&opts.jira, "jira", false, "create Jira issues for high and critical findings in the project from the config file")
+	flag.BoolVar(&opts.githubIssues, "github-issues", false, "create GitHub issues for findings in the repository from the config file")
+	flag.BoolVar(&opts.uploadSARIF, "upload-sarif", false, "upload the findings as SARIF to GitHub code scanning once the scan completes")
+	flag.StringVar(&opts.sarifUpload.owner, "sarif-owner", "", "owner of the repository the SARIF is uploaded to")
+	flag.StringVar(&opts.sarifUpload.repo, "sarif-repo", "", "repository the SARIF is uploaded to")
+	flag.StringVar(&opts.sarifUpload.commitSHA, "sarif-commit", "", "commit the SARIF is uploaded for (default git rev-parse HEAD)")
+	flag.StringVar(&opts.sarifUpload.ref, "sarif-ref", "", "ref the SARIF is uploaded for (e.g. refs/heads/main)")
+	flag.StringVar(&opts.sarifUpload.token, "sarif-token", "", "GitHub token with the security_events scope (default $GITHUB_TOKEN)")
+	flag.BoolVar(&opts.strictSchema, "strict-schema", false, "fail on templates violating the template schema instead of skipping them")
+	flag.Parse()
+
+	if opts.targets == "" || opts.templates == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	return opts
+}
+
+// run loads targets and templates, scans every target and writes the findings
+func run(ctx context.Context, opts *options, logger *logging.Logger) error {
+	targets, err := loadTargets(opts.targets)
+	if err != nil {
+		return fmt.Errorf("failed to read targets: %w", err)
+	}
+
+	if !opts.noUpdate {
+		updateTemplates(ctx, opts, logger)
+	}
+
+	advanced := templates.DefaultAdvancedSettings()
+	advanced.Proxy = opts.proxy
+	advanced.DisableHeadless = !opts.headless
+	advanced.MaxHTTPRedirects = opts.maxRedirects
+	advanced.MaxIdleConnsPerHost = templates.DefaultMaxIdleConnsPerHost(opts.threads)
+	if opts.noRescan > 0 {
+		cache, err := dedup.NewTargetCache(constants.TargetCacheFile)
+		if err != nil {
+			return fmt.Errorf("failed to open target cache: %w", err)
+		}
+		defer cache.Close()
+		advanced.TargetCache = cache
+		advanced.RescanInterval = opts.noRescan
+	}
+	if advanced.EnableDeduplication {
+		targets = scanner.DeduplicateTargets(targets)
+	}
+	if opts.verifySigs {
+		cfg, err := config.LoadConfig(opts.configPath)
+		if err != nil {
+			return fmt.Errorf("failed to load template signing key: %w", err)
+		}
+		advanced.VerifySignatures = true
+		advanced.TemplateSigningKey = cfg.Security.TemplateSigningKey
+	}
+
+	if opts.clearCache {
+		if err := templates.ClearTemplateCache(advanced.CacheDir); err != nil {
+			return fmt.Errorf("failed to clear template cache: %w", err)
+		}
+	}
+
+	_, index, err := templates.LoadTemplates(opts.templates, splitList(opts.tags), opts.strictSchema, advanced, func(err error) {
+		logger.Warn("Skipping invalid template", slog.Any("error", err))
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to load templates: %w", err)
+	}
+	tmpls := filterByCVSS(index.BySeverities(splitList(opts.severity)), opts.minCVSS)
+
+	out := io.Writer(os.Stdout)
+	if opts.output != "" {
+		f, err := os.Create(opts.output)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer f.Close()
+		out = f
+	}
+	writer, err := newOutputWriter(opts, out)
+	if err != nil {
+		return err
+	}
+	if opts.output != "" {
+		// findings go to a file, print them live on stderr too
+		terminal := output.NewTerminalWriter(os.Stderr, !opts.noColor && output.IsTerminal(os.Stderr))
+		writer = output.NewMultiWriter(writer, terminal)
+	}
+	if opts.elasticsearch.URL != "" {
+		writer = output.NewMultiWriter(writer, output.NewElasticsearchWriter(opts.elasticsearch, logger))
+	}
+	if opts.jira || opts.githubIssues {
+		cfg, err := config.LoadConfig(opts.configPath)
+		if err != nil {
+			return fmt.Errorf("failed to load issue tracker settings: %w", err)
+		}
+		if opts.jira {
+			if cfg.Jira.URL == "" || cfg.Jira.Project == "" {
+				return fmt.Errorf("jira url and project must be set in %s", opts.configPath)
+			}
+			writer = output.NewMultiWriter(writer, notifier.NewJiraNotifier(cfg.Jira, logger))
+		}
+		if opts.githubIssues {
+			if cfg.GitHub.Token == "" || cfg.GitHub.Owner == "" || cfg.GitHub.Repo == "" {
+				return fmt.Errorf("github token, owner and repo must be set in %s", opts.configPath)
+			}
+			writer = output.NewMultiWriter(writer, notifier.NewGitHubIssueNotifier(cfg.GitHub, logger))
+		}
+	}
+	var sarif *bytes.Buffer
+	if opts.uploadSARIF {
+		if err := resolveSARIFUpload(ctx, &opts.sarifUpload); err != nil {
+			return err
+		}
+		sarif = &bytes.Buffer{}
+		writer = output.NewMultiWriter(writer, output.NewSARIFWriter(sarif))
+	}
+	if opts.baseline != "" {
+		writer, err = newBaselineWriter(opts.baseline, writer)
+		if err != nil {
+			return fmt.Errorf("failed to load baseline: %w", err)
+		}
+	}
+
+	if pw, ok := writer.(output.PassWriter); ok {
+		advanced.OnPass = func(target, templateID string, duration time.Duration) {
+			if err := pw.WritePass(target, templateID, duration); err != nil {
+				logger.Warn("Failed to write template pass", slog.String("template_id", templateID), slog.Any("error", err))
+			}
+		}
+	}
+
+	total := len(targets)
+	var processed atomic.Int64
+
+	targetsCh := make(chan string)
+	go func() {
+		defer close(targetsCh)
+		for _, t := range targets {
+			select {
+			case <-ctx.Done():
+				return
+			case targetsCh <- t:
+			}
+		}
+	}()
+
+	processFn := func(ctx context.Context, target string) error {
+		defer func() {
+			fmt.Fprintf(os.Stderr, "\rProcessed %d/%d targets", processed.Add(1), total)
+		}()
+		return scanTarget(ctx, target, tmpls, opts, advanced, writer, logger)
+	}
+
+	<-scanner.StartWorkers(ctx, targetsCh, opts.threads, advanced.Paused, processFn, logger)
+	scanner.ShutdownWorkerPools()
+	fmt.Fprintln(os.Stderr)
+
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	if sarif != nil {
+		u := opts.sarifUpload
+		uploader := output.NewGitHubSARIFUploader(u.token, u.owner, u.repo)
+		if err := uploader.Upload(ctx, sarif.Bytes(), u.commitSHA, u.ref); err != nil {
+			return fmt.Errorf("failed to upload SARIF: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Uploaded SARIF to %s/%s for %s\n", u.owner, u.repo, u.commitSHA)
+	}
+	return nil
+}
+
+// resolveSARIFUpload fills the commit from the git repository and the token from $GITHUB_TOKEN when they're
+// not set, then checks every upload option is known
+func resolveSARIFUpload(ctx context.Context, u *sarifUploadOptions) error {
+	if u.token == "" {
+		u.token = os.Getenv("GITHUB_TOKEN")
+	}
+	if u.commitSHA == "" {
+		sha, err := output.DetectCommitSHA(ctx)
+		if err != nil {
+			return fmt.Errorf("-sarif-commit is required outside a git repository: %w", err)
+		}
+		u.commitSHA = sha
+	}
+	if u.owner == "" || u.repo == "" || u.ref == "" || u.token == "" {
+		return errors.New("-upload-sarif requires -sarif-owner, -sarif-repo, -sarif-ref and a token")
+	}
+	return nil
+}
+
+// newOutputWriter returns the writer of the output template if one is set, of the output format otherwise
+func newOutputWriter(opts *options, out io.Writer) (output.Writer, error) {
+	text := opts.outputTemplate
+	if opts.outputTemplateFile != "" {
+		if text != "" {
+			return nil, errors.New("-output-template and -output-template-file are mutually exclusive")
+		}
+		data, err := os.ReadFile(opts.outputTemplateFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read output template: %w", err)
+		}
+		text = string(data)
+	}
+	if text == "" {
+		return output.NewWriter(opts.format, out)
+	}
+	return output.NewTemplateWriter(out, text)
+}
+
+// scanTarget runs the templates against one target with the shared scan engine and writes the matches
+func scanTarget(
+	ctx context.Context,
+	target string,
+	tmpls []*templates.Template,
+	opts *options,
+	advanced *templates.AdvancedSettingsChecker,
+	writer output.Writer,
+	logger *logging.Logger,
+) error {
+	ctx, cancel := context.WithTimeout(ctx, opts.timeout)
+	defer cancel()
+
+	findings, err := templates.ScanTemplates(ctx, target, tmpls, nil, opts.timeout, advanced, logger, func(int, int) {})
+	if err != nil {
+		logger.Info("Failed to scan target", slog.String("target", target), slog.Any("error", err))
+		return err
+	}
+	for _, finding := range findings {
+		if err := writer.Write(finding); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateTemplates syncs the templates directory with the update URL from the config file, if one is configured
+func updateTemplates(ctx context.Context, opts *options, logger *logging.Logger) {
+	cfg, err := config.LoadConfig(opts.configPath)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			logger.Warn("Failed to load config, skipping template update", slog.Any("error", err))
+		}
+		return
+	}
+	if cfg.Templates.UpdateURL == "" {
+		return
+	}
+
+	result, err := templates.NewUpdater(cfg.Templates.UpdateURL, opts.templates, cfg.Templates.HMACKey).CheckForUpdates(ctx)
+	if err != nil {
+		logger.Warn("Template update failed", slog.Any("error", err))
+		fmt.Fprintf(os.Stderr, "warning: template update failed: %v\n", err)
+		return
+	}
+	logger.Info("Templates updated", slog.Int("changed", len(result.Changed)))
+}
+
+// baselineWriter forwards only findings missing from the baseline and reports resolved ones on Close.
+// When the baseline file does not exist yet it is created from the scan findings
+type baselineWriter struct {
+	mu       sync.Mutex
+	next     output.Writer
+	path     string
+	baseline *baseline.Baseline
+	current  []templates.Finding
+}
+
+// newBaselineWriter loads the baseline at path and wraps next
+func newBaselineWriter(path string, next output.Writer) (*baselineWriter, error) {
+	b, err := baseline.Load(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	return &baselineWriter{next: next, path: path, baseline: b}, nil
+}
+
+// Write records the finding and forwards it if it is new
+func (b *baselineWriter) Write(f *templates.Finding) error {
+	b.mu.Lock()
+	b.current = append(b.current, *f)
+	b.mu.Unlock()
+	if b.baseline != nil && b.baseline.Contains(*f) {
+		return nil
+	}
+	return b.next.Write(f)
+}
+
+// WritePass forwards the template execution without a match if the wrapped writer reports them
+func (b *baselineWriter) WritePass(target, templateID string, duration time.Duration) error {
+	if pw, ok := b.next.(output.PassWriter); ok {
+		return pw.WritePass(target, templateID, duration)
+	}
+	return nil
+}
+
+// Close reports resolved findings or saves the new baseline, then closes the wrapped writer
+func (b *baselineWriter) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.baseline == nil {
+		if err := baseline.NewBaseline(b.current).Save(b.path); err != nil {
+			return fmt.Errorf("failed to save baseline: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Baseline with %d findings saved to %s\n", len(b.current), b.path)
+	} else {
+		newFindings, resolved := baseline.Compare(b.current, b.baseline)
+		fmt.Fprintf(os.Stderr, "Baseline comparison: %d new, %d resolved\n", len(newFindings), len(resolved))
+		for _, f := range resolved {
+			fmt.Fprintf(os.Stderr, "resolved: [%s] [%s] %s\n", f.Severity, f.TemplateID, f.Target)
+		}
+	}
+	return b.next.Close()
+}
+
+// loadTargets reads targets from the file at path or from stdin when path is "-"
+func loadTargets(path string) ([]string, error) {
+	if path == "-" {
+		return scanner.ReadTargets(os.Stdin)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return scanner.ReadTargets(f)
+}
+
+// splitList splits a comma-separated flag value, dropping empty items
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// filterByCVSS keeps the templates with a CVSS score of at least minScore, a zero minScore keeps all of them
+func filterByCVSS(tmpls []*templates.Template, minScore float64) []*templates.Template {
+	if minScore <= 0 {
+		return tmpls
+	}
+	var res []*templates.Template
+	for _, tmpl := range tmpls {
+		if tmpl.Info.CVSSScore >= minScore {
+			res = append(res, tmpl)
+		}
+	}
+	return res
+}
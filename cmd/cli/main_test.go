@@ -0,0 +1,195 @@
+//go:build cli
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/artnikel/nuclei/internal/logging"
+	"github.com/artnikel/nuclei/internal/output"
+	"github.com/artnikel/nuclei/internal/templates"
+)
+
+const fixtureTemplates = `id: exposed-env
+info:
+  name: Exposed env file
+  author: test
+  severity: high
+http:
+  - method: GET
+    path:
+      - "{{BaseURL}}/.env"
+    matchers-condition: and
+    matchers:
+      - type: status
+        status: [200]
+      - type: word
+        words: ["DB_PASSWORD="]
+`
+
+const fixtureMissing = `id: exposed-git
+info:
+  name: Exposed git config
+  author: test
+  severity: medium
+http:
+  - method: GET
+    path:
+      - "{{BaseURL}}/.git/config"
+    matchers:
+      - type: status
+        status: [200]
+`
+
+// newFixture starts the mock target and writes the fixture templates and the targets file
+func newFixture(t *testing.T) (target *httptest.Server, templatesDir, targetsFile string) {
+	t.Helper()
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	target = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/.env" {
+			io.WriteString(w, "DB_PASSWORD=secret\n")
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	t.Cleanup(target.Close)
+
+	templatesDir = t.TempDir()
+	for name, content := range map[string]string{"env.yaml": fixtureTemplates, "git.yaml": fixtureMissing} {
+		if err := os.WriteFile(filepath.Join(templatesDir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	targetsFile = filepath.Join(t.TempDir(), "targets.txt")
+	if err := os.WriteFile(targetsFile, []byte(target.URL+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return target, templatesDir, targetsFile
+}
+
+func testOptions(targets, templatesDir, out string) *options {
+	return &options{
+		targets:      targets,
+		templates:    templatesDir,
+		output:       out,
+		format:       output.FormatJSON,
+		threads:      2,
+		timeout:      10 * time.Second,
+		noUpdate:     true,
+		noColor:      true,
+		configPath:   filepath.Join(filepath.Dir(out), "missing-config.yaml"),
+		maxRedirects: templates.DefaultMaxHTTPRedirects,
+	}
+}
+
+func testLogger() *logging.Logger {
+	return &logging.Logger{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+}
+
+func readFindings(t *testing.T, path string) []templates.Finding {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var findings []templates.Finding
+	if err := json.Unmarshal(data, &findings); err != nil {
+		t.Fatalf("output is not a JSON array of findings: %v\n%s", err, data)
+	}
+	return findings
+}
+
+func TestCLIEndToEnd(t *testing.T) {
+	target, templatesDir, targetsFile := newFixture(t)
+	out := filepath.Join(t.TempDir(), "findings.json")
+
+	if err := run(context.Background(), testOptions(targetsFile, templatesDir, out), testLogger()); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	findings := readFindings(t, out)
+	if len(findings) != 1 {
+		t.Fatalf("got %d findings, want 1: %+v", len(findings), findings)
+	}
+	f := findings[0]
+	if f.TemplateID != "exposed-env" || f.Severity != "high" || f.Target != target.URL {
+		t.Errorf("finding = %+v", f)
+	}
+}
+
+func TestCLIEndToEndSeverityFilter(t *testing.T) {
+	_, templatesDir, targetsFile := newFixture(t)
+	out := filepath.Join(t.TempDir(), "findings.json")
+	opts := testOptions(targetsFile, templatesDir, out)
+	opts.severity = "medium"
+
+	if err := run(context.Background(), opts, testLogger()); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if findings := readFindings(t, out); len(findings) != 0 {
+		t.Fatalf("severity filter kept findings: %+v", findings)
+	}
+}
+
+func TestCLIEndToEndStdinTargets(t *testing.T) {
+	target, templatesDir, _ := newFixture(t)
+	out := filepath.Join(t.TempDir(), "findings.json")
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdin := os.Stdin
+	os.Stdin = r
+	t.Cleanup(func() { os.Stdin = stdin })
+	io.WriteString(w, target.URL+"\n")
+	w.Close()
+
+	if err := run(context.Background(), testOptions("-", templatesDir, out), testLogger()); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if findings := readFindings(t, out); len(findings) != 1 {
+		t.Fatalf("got %d findings from stdin targets, want 1", len(findings))
+	}
+}
+
+func TestCLIEndToEndMinCVSS(t *testing.T) {
+	_, templatesDir, targetsFile := newFixture(t)
+	scored := strings.Replace(fixtureTemplates, "  severity: high\n", "  severity: high\n  cvss-score: 6.5\n", 1)
+	if err := os.WriteFile(filepath.Join(templatesDir, "env.yaml"), []byte(scored), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		minCVSS float64
+		want    int
+	}{
+		{minCVSS: 0, want: 1},
+		{minCVSS: 6.5, want: 1},
+		{minCVSS: 7.0, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.minCVSS), func(t *testing.T) {
+			out := filepath.Join(t.TempDir(), "findings.json")
+			opts := testOptions(targetsFile, templatesDir, out)
+			opts.minCVSS = tt.minCVSS
+			if err := run(context.Background(), opts, testLogger()); err != nil {
+				t.Fatalf("run: %v", err)
+			}
+			if findings := readFindings(t, out); len(findings) != tt.want {
+				t.Errorf("--min-cvss %v: got %d findings, want %d", tt.minCVSS, len(findings), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+// Command diff compares the findings of two scans saved with the json or jsonl output format
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/artnikel/nuclei/internal/baseline"
+	"github.com/artnikel/nuclei/internal/output"
+	"github.com/artnikel/nuclei/internal/report"
+	"github.com/artnikel/nuclei/internal/templates"
+)
+
+// ANSI colors of the terminal diff
+const (
+	colorReset = "\033[0m"
+	colorRed   = "\033[31m"
+	colorGreen = "\033[32m"
+)
+
+func main() {
+	before := flag.String("before", "", "findings of the earlier scan (json or jsonl)")
+	after := flag.String("after", "", "findings of the later scan (json or jsonl)")
+	format := flag.String("format", "text", "output format: text or html")
+	outPath := flag.String("output", "", "file to write the comparison to (default stdout)")
+	noColor := flag.Bool("no-color", false, "disable colors of the text comparison")
+	flag.Parse()
+
+	if *before == "" || *after == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	beforeFindings, err := loadFindings(*before)
+	if err != nil {
+		log.Fatalf("failed to load %s: %v", *before, err)
+	}
+	afterFindings, err := loadFindings(*after)
+	if err != nil {
+		log.Fatalf("failed to load %s: %v", *after, err)
+	}
+	newFindings, resolved, unchanged := baseline.Diff(beforeFindings, afterFindings)
+
+	out := os.Stdout
+	if *outPath != "" {
+		f, err := os.Create(*outPath)
+		if err != nil {
+			log.Fatalf("failed to create output file: %v", err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	switch *format {
+	case output.FormatText:
+		color := !*noColor && output.IsTerminal(out)
+		err = writeTextDiff(out, color, newFindings, resolved, unchanged)
+	case output.FormatHTML:
+		var reporter *report.DiffReporter
+		reporter, err = report.NewDiffReporter()
+		if err == nil {
+			err = reporter.Render(out, *before, *after, newFindings, resolved, unchanged)
+		}
+	default:
+		err = fmt.Errorf("unsupported format: %s", *format)
+	}
+	if err != nil {
+		log.Fatalf("failed to write comparison: %v", err)
+	}
+}
+
+// loadFindings reads the findings of a JSON array or of JSON lines
+func loadFindings(path string) ([]templates.Finding, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimSpace(data)
+	var findings []templates.Finding
+	if bytes.HasPrefix(data, []byte("[")) {
+		err := json.Unmarshal(data, &findings)
+		return findings, err
+	}
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(nil, 10<<20)
+	for sc.Scan() {
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var f templates.Finding
+		if err := json.Unmarshal(line, &f); err != nil {
+			return nil, err
+		}
+		findings = append(findings, f)
+	}
+	return findings, sc.Err()
+}
+
+// writeTextDiff prints new findings with +, resolved ones with - and unchanged ones indented, then the counts
+func writeTextDiff(w io.Writer, color bool, newFindings, resolved, unchanged []templates.Finding) error {
+	printLine := func(prefix, ansi string, f templates.Finding) error {
+		line := fmt.Sprintf("%s [%s] [%s] %s", prefix, f.Severity, f.TemplateID, f.MatchedURL)
+		if color && ansi != "" {
+			line = ansi + line + colorReset
+		}
+		_, err := fmt.Fprintln(w, line)
+		return err
+	}
+	for _, f := range newFindings {
+		if err := printLine("+", colorGreen, f); err != nil {
+			return err
+		}
+	}
+	for _, f := range resolved {
+		if err := printLine("-", colorRed, f); err != nil {
+			return err
+		}
+	}
+	for _, f := range unchanged {
+		if err := printLine(" ", "", f); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "%d new, %d resolved, %d unchanged\n", len(newFindings), len(resolved), len(unchanged))
+	return err
+}
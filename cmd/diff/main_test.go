@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/artnikel/nuclei/internal/baseline"
+	"github.com/artnikel/nuclei/internal/report"
+)
+
+func TestDiffFixtures(t *testing.T) {
+	before, err := loadFindings(filepath.Join("testdata", "before.json"))
+	if err != nil {
+		t.Fatalf("load before: %v", err)
+	}
+	after, err := loadFindings(filepath.Join("testdata", "after.jsonl"))
+	if err != nil {
+		t.Fatalf("load after: %v", err)
+	}
+	if len(before) != 4 || len(after) != 4 {
+		t.Fatalf("loaded %d and %d findings, want 4 and 4", len(before), len(after))
+	}
+
+	newFindings, resolved, unchanged := baseline.Diff(before, after)
+	if len(newFindings) != 2 || len(resolved) != 2 || len(unchanged) != 2 {
+		t.Errorf("%d new, %d resolved, %d unchanged, want 2 of each", len(newFindings), len(resolved), len(unchanged))
+	}
+	if len(newFindings)+len(unchanged) != len(after) || len(resolved)+len(unchanged) != len(before) {
+		t.Errorf("counts don't sum to the scans: %d new + %d unchanged of %d after, %d resolved + %d unchanged of %d before",
+			len(newFindings), len(unchanged), len(after), len(resolved), len(unchanged), len(before))
+	}
+
+	var text bytes.Buffer
+	if err := writeTextDiff(&text, false, newFindings, resolved, unchanged); err != nil {
+		t.Fatal(err)
+	}
+	out := text.String()
+	for _, want := range []string{
+		"+ [high] [xss] http://b.test/search?q=%3Cscript%3E\n",
+		"+ [critical] [exposed-env] http://d.test/.env\n",
+		"- [high] [env-file] http://a.test/.env\n",
+		"- [low] [old-jquery] http://c.test/js/jquery.js\n",
+		"  [info] [admin-panel] http://b.test/admin\n",
+		"2 new, 2 resolved, 2 unchanged\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("text diff misses %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "\033[") {
+		t.Errorf("text diff without color has color codes:\n%s", out)
+	}
+	var colored bytes.Buffer
+	if err := writeTextDiff(&colored, true, newFindings, resolved, unchanged); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(colored.String(), colorGreen+"+ [high] [xss]") || !strings.Contains(colored.String(), colorRed+"- [high] [env-file]") {
+		t.Errorf("colored diff = %q", colored.String())
+	}
+
+	reporter, err := report.NewDiffReporter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var page bytes.Buffer
+	if err := reporter.Render(&page, "before.json", "after.jsonl", newFindings, resolved, unchanged); err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	for _, want := range []string{"before.json", "after.jsonl", "exposed-env", "old-jquery", "admin-panel"} {
+		if !strings.Contains(page.String(), want) {
+			t.Errorf("HTML comparison misses %q", want)
+		}
+	}
+}
+
+func TestLoadFindingsInvalid(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := loadFindings(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("missing file loaded")
+	}
+	for name, content := range map[string]string{"array.json": `[{"template_id": 1}]`, "lines.jsonl": "{\"template_id\": \"a\"}\n{bad\n"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if findings, err := loadFindings(path); err == nil {
+			t.Errorf("malformed %s loaded as %v", name, findings)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+// Command enrich fills the missing cvss-score and description of CVE templates from the NVD CVE API
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+	"gopkg.in/yaml.v3"
+
+	"github.com/artnikel/nuclei/internal/templates"
+)
+
+const (
+	// defaultNVDURL is the CVE endpoint of the NVD API 2.0
+	defaultNVDURL = "https://services.nvd.nist.gov/rest/json/cves/2.0"
+	// nvdRequestsPerSecond follows the NVD guidelines on request rates
+	nvdRequestsPerSecond = 5
+	// nvdTimeout bounds a single NVD request
+	nvdTimeout = 30 * time.Second
+)
+
+// cveInfo is the part of an NVD CVE entry the templates are enriched with
+type cveInfo struct {
+	Score       float64
+	Description string
+}
+
+// nvdResponse is the part of the NVD API 2.0 response that is read
+type nvdResponse struct {
+	Vulnerabilities []struct {
+		CVE struct {
+			ID           string `json:"id"`
+			Descriptions []struct {
+				Lang  string `json:"lang"`
+				Value string `json:"value"`
+			} `json:"descriptions"`
+			Metrics struct {
+				CVSSMetricV31 []struct {
+					Type     string `json:"type"`
+					CVSSData struct {
+						BaseScore float64 `json:"baseScore"`
+					} `json:"cvssData"`
+				} `json:"cvssMetricV31"`
+			} `json:"metrics"`
+		} `json:"cve"`
+	} `json:"vulnerabilities"`
+}
+
+// nvdClient queries the NVD CVE API without exceeding its request rate
+type nvdClient struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+	limiter *rate.Limiter
+}
+
+func main() {
+	templatesDir := flag.String("templates", "", "directory with templates")
+	nvdURL := flag.String("nvd-url", defaultNVDURL, "NVD CVE API endpoint")
+	apiKey := flag.String("api-key", "", "NVD API key")
+	dryRun := flag.Bool("dry-run", false, "print the changes without writing the templates")
+	flag.Parse()
+
+	if *templatesDir == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	tmpls, _, err := templates.LoadTemplates(*templatesDir, nil, false, templates.DefaultAdvancedSettings(), func(err error) {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	})
+	if err != nil {
+		log.Fatalf("failed to load templates: %v", err)
+	}
+
+	nvd := &nvdClient{
+		baseURL: *nvdURL,
+		apiKey:  *apiKey,
+		client:  &http.Client{Timeout: nvdTimeout},
+		limiter: rate.NewLimiter(nvdRequestsPerSecond, 1),
+	}
+	ctx := context.Background()
+	var enriched int
+	for _, tmpl := range tmpls {
+		if tmpl.Info.CVEID == "" || tmpl.Info.CVSSScore != 0 {
+			continue
+		}
+		info, err := nvd.lookup(ctx, tmpl.Info.CVEID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %s: %v\n", tmpl.Info.CVEID, err)
+			continue
+		}
+		if tmpl.Info.Description != "" {
+			info.Description = ""
+		}
+		if info.Score == 0 && info.Description == "" {
+			continue
+		}
+
+		if *dryRun {
+			fmt.Printf("%s (%s): cvss-score %v", tmpl.FilePath, tmpl.Info.CVEID, info.Score)
+			if info.Description != "" {
+				fmt.Printf(", description %q", info.Description)
+			}
+			fmt.Println()
+		} else if err := enrichTemplateFile(tmpl.FilePath, info); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %s: %v\n", tmpl.FilePath, err)
+			continue
+		}
+		enriched++
+	}
+	fmt.Printf("Enriched %d templates\n", enriched)
+}
+
+// lookup returns the CVSS v3.1 base score and the English description of the CVE
+func (c *nvdClient) lookup(ctx context.Context, cveID string) (cveInfo, error) {
+	if err := c.limiter.Wait(ctx); err != nil {
+		return cveInfo{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"?cveId="+url.QueryEscape(strings.ToUpper(cveID)), nil)
+	if err != nil {
+		return cveInfo{}, err
+	}
+	if c.apiKey != "" {
+		req.Header.Set("apiKey", c.apiKey)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return cveInfo{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return cveInfo{}, fmt.Errorf("NVD returned status %d", resp.StatusCode)
+	}
+
+	var body nvdResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return cveInfo{}, fmt.Errorf("failed to decode NVD response: %w", err)
+	}
+	if len(body.Vulnerabilities) == 0 {
+		return cveInfo{}, fmt.Errorf("not found in NVD")
+	}
+
+	cve := body.Vulnerabilities[0].CVE
+	var info cveInfo
+	for _, d := range cve.Descriptions {
+		if d.Lang == "en" {
+			info.Description = d.Value
+			break
+		}
+	}
+	// the primary metric is the one scored by NVD, the others come from CNAs
+	if metrics := cve.Metrics.CVSSMetricV31; len(metrics) > 0 {
+		info.Score = metrics[0].CVSSData.BaseScore
+		for _, m := range metrics {
+			if m.Type == "Primary" {
+				info.Score = m.CVSSData.BaseScore
+				break
+			}
+		}
+	}
+	return info, nil
+}
+
+// enrichTemplateFile sets the info cvss-score and, if not empty, description of the template file. The file is
+// edited as a YAML node tree so its comments and key order are kept
+func enrichTemplateFile(path string, info cveInfo) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return fmt.Errorf("template is not a YAML mapping")
+	}
+	infoNode := mappingValue(doc.Content[0], "info")
+	if infoNode == nil || infoNode.Kind != yaml.MappingNode {
+		return fmt.Errorf("template has no info section")
+	}
+
+	if info.Score != 0 {
+		// CVSS scores have a single decimal
+		setMappingValue(infoNode, "cvss-score", &yaml.Node{Kind: yaml.ScalarNode, Value: strconv.FormatFloat(info.Score, 'f', 1, 64)})
+	}
+	if info.Description != "" {
+		setMappingValue(infoNode, "description", &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: info.Description})
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	st, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), st.Mode().Perm())
+}
+
+// mappingValue returns the value of key in the mapping node, nil if it's missing
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// setMappingValue replaces the value of key in the mapping node, appending the key if it's missing
+func setMappingValue(mapping *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			// keep the comments attached to the old value
+			value.LineComment = mapping.Content[i+1].LineComment
+			mapping.Content[i+1] = value
+			return
+		}
+	}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/artnikel/nuclei/internal/templates"
+)
+
+const log4shellDescription = "Apache Log4j2 JNDI features do not protect against attacker controlled LDAP endpoints."
+
+// nvdLog4Shell is the NVD API 2.0 response for CVE-2021-44228, trimmed to the fields read by lookup
+var nvdLog4Shell = fmt.Sprintf(`{
+  "resultsPerPage": 1,
+  "vulnerabilities": [{
+    "cve": {
+      "id": "CVE-2021-44228",
+      "descriptions": [
+        {"lang": "es", "value": "Las funciones JNDI de Apache Log4j2 no protegen"},
+        {"lang": "en", "value": %q}
+      ],
+      "metrics": {
+        "cvssMetricV31": [
+          {"source": "security@apache.org", "type": "Secondary", "cvssData": {"baseScore": 9.8}},
+          {"source": "nvd@nist.gov", "type": "Primary", "cvssData": {"baseScore": 10.0}}
+        ]
+      }
+    }
+  }]
+}`, log4shellDescription)
+
+const cveTemplate = `# detects Log4Shell through the X-Api-Version header
+id: log4shell
+info:
+  name: Log4Shell
+  author: test
+  severity: critical
+  cve-id: CVE-2021-44228 # the CVE enriched from NVD
+http:
+  - method: GET
+    path:
+      - "{{BaseURL}}/"
+    matchers:
+      - type: status
+        status:
+          - 200
+`
+
+// newNVDServer mocks the NVD CVE API answering with the Log4Shell entry and an empty result for other CVEs
+func newNVDServer(t *testing.T) (*httptest.Server, *[]string) {
+	t.Helper()
+	var requested []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cveID := r.URL.Query().Get("cveId")
+		requested = append(requested, cveID)
+		if r.Header.Get("apiKey") != "nvd-key" {
+			http.Error(w, "missing api key", http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if cveID == "CVE-2021-44228" {
+			fmt.Fprint(w, nvdLog4Shell)
+			return
+		}
+		fmt.Fprint(w, `{"resultsPerPage": 0, "vulnerabilities": []}`)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requested
+}
+
+func TestNVDLookup(t *testing.T) {
+	srv, requested := newNVDServer(t)
+	nvd := &nvdClient{baseURL: srv.URL, apiKey: "nvd-key", client: srv.Client(), limiter: rate.NewLimiter(rate.Inf, 1)}
+
+	info, err := nvd.lookup(context.Background(), "cve-2021-44228")
+	if err != nil {
+		t.Fatalf("lookup: %v", err)
+	}
+	if info.Score != 10.0 || info.Description != log4shellDescription {
+		t.Errorf("lookup = %+v, want the primary score 10.0 and the English description", info)
+	}
+	if len(*requested) != 1 || (*requested)[0] != "CVE-2021-44228" {
+		t.Errorf("requested %v, want the uppercased CVE ID", *requested)
+	}
+
+	if _, err := nvd.lookup(context.Background(), "CVE-2000-0001"); err == nil {
+		t.Error("unknown CVE found")
+	}
+	nvd.apiKey = ""
+	if _, err := nvd.lookup(context.Background(), "CVE-2021-44228"); err == nil {
+		t.Error("error status accepted")
+	}
+}
+
+func TestNVDLookupRateLimit(t *testing.T) {
+	srv, _ := newNVDServer(t)
+	nvd := &nvdClient{baseURL: srv.URL, apiKey: "nvd-key", client: srv.Client(), limiter: rate.NewLimiter(nvdRequestsPerSecond, 1)}
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		if _, err := nvd.lookup(context.Background(), "CVE-2021-44228"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < time.Second-50*time.Millisecond {
+		t.Errorf("6 lookups took %v, want about 1s at %d requests per second", elapsed, nvdRequestsPerSecond)
+	}
+}
+
+func TestEnrichTemplateFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log4shell.yaml")
+	if err := os.WriteFile(path, []byte(cveTemplate), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := enrichTemplateFile(path, cveInfo{Score: 10, Description: log4shellDescription}); err != nil {
+		t.Fatalf("enrichTemplateFile: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"# detects Log4Shell through the X-Api-Version header", "# the CVE enriched from NVD", "cvss-score: 10.0"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("enriched template misses %q:\n%s", want, data)
+		}
+	}
+	tmpl, err := templates.LoadTemplate(path)
+	if err != nil {
+		t.Fatalf("enriched template doesn't load: %v\n%s", err, data)
+	}
+	if tmpl.Info.CVSSScore != 10 || tmpl.Info.Description != log4shellDescription || tmpl.Info.CVEID != "CVE-2021-44228" {
+		t.Errorf("enriched info = %+v", tmpl.Info)
+	}
+	if strings.Index(string(data), "cve-id") > strings.Index(string(data), "cvss-score") {
+		t.Errorf("enriched keys added before the existing ones:\n%s", data)
+	}
+}
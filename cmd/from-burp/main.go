@@ -0,0 +1,223 @@
+// Command from-burp generates a template per host from the requests of a Burp Suite export
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/artnikel/nuclei/internal/constants"
+	"github.com/artnikel/nuclei/internal/templates"
+)
+
+var (
+	// nonAlphanumeric matches the runs of characters dropped from template IDs
+	nonAlphanumeric = regexp.MustCompile(`[^a-z0-9]+`)
+	// http2RequestLine matches the protocol of HTTP/2 request lines, which net/http can't parse
+	http2RequestLine = regexp.MustCompile(`^(\S+ \S+) HTTP/2(\.0)?\r?\n`)
+)
+
+// skippedHeaders are set by the scanner for every request and not copied into templates
+var skippedHeaders = map[string]bool{
+	"Host":           true,
+	"Content-Length": true,
+	"Connection":     true,
+}
+
+// burpItems is the root of a Burp XML export
+type burpItems struct {
+	Items []burpItem `xml:"item"`
+}
+
+// burpItem is a captured request, as exported to XML by Burp or as an object of a JSON export
+type burpItem struct {
+	URL     string      `xml:"url" json:"url"`
+	Host    string      `xml:"host" json:"host"`
+	Request burpRequest `xml:"request" json:"request"`
+}
+
+// burpRequest is the raw HTTP request, base64-encoded unless Burp was told to export it as is
+type burpRequest struct {
+	Base64 bool   `xml:"base64,attr" json:"base64"`
+	Raw    string `xml:",chardata" json:"raw"`
+}
+
+// UnmarshalJSON accepts the request as an object or as a base64-encoded string
+func (r *burpRequest) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err == nil {
+		r.Base64, r.Raw = true, raw
+		return nil
+	}
+	type plain burpRequest
+	return json.Unmarshal(data, (*plain)(r))
+}
+
+func main() {
+	exportPath := flag.String("input", "", "Burp Suite export (XML, or JSON array of items)")
+	outDir := flag.String("output", "templates-burp", "directory the templates are written to")
+	flag.Parse()
+
+	if *exportPath == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	data, err := os.ReadFile(*exportPath)
+	if err != nil {
+		log.Fatalf("failed to read export: %v", err)
+	}
+	items, err := parseExport(data)
+	if err != nil {
+		log.Fatalf("failed to parse export: %v", err)
+	}
+
+	tmpls := generateTemplates(items, func(err error) {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	})
+	if err := os.MkdirAll(*outDir, 0o755); err != nil {
+		log.Fatalf("failed to create output directory: %v", err)
+	}
+	for _, tmpl := range tmpls {
+		out, err := yaml.Marshal(tmpl)
+		if err != nil {
+			log.Fatalf("failed to encode template %s: %v", tmpl.ID, err)
+		}
+		path := filepath.Join(*outDir, tmpl.ID+constants.YamlFileFormat)
+		if err := os.WriteFile(path, out, constants.FilePerm); err != nil {
+			log.Fatalf("failed to write template: %v", err)
+		}
+	}
+	fmt.Printf("Generated %d templates in %s\n", len(tmpls), *outDir)
+}
+
+// parseExport returns the items of an XML or JSON export
+func parseExport(data []byte) ([]burpItem, error) {
+	data = bytes.TrimSpace(data)
+	if bytes.HasPrefix(data, []byte("[")) {
+		var items []burpItem
+		err := json.Unmarshal(data, &items)
+		return items, err
+	}
+	var export burpItems
+	if err := xml.Unmarshal(data, &export); err != nil {
+		return nil, err
+	}
+	return export.Items, nil
+}
+
+// generateTemplates returns a template per host with a request for each of its captured requests, sorted by
+// host. Items that can't be parsed are passed to warn and skipped
+func generateTemplates(items []burpItem, warn func(err error)) []*templates.Template {
+	byHost := make(map[string]*templates.Template)
+	for _, item := range items {
+		req, host, err := parseItem(item)
+		if err != nil {
+			warn(fmt.Errorf("skipping %s: %w", item.URL, err))
+			continue
+		}
+		tmpl, ok := byHost[host]
+		if !ok {
+			tmpl = &templates.Template{
+				ID: "burp-" + sanitizeID(host),
+				Info: templates.Info{
+					Name:     "Burp requests to " + host,
+					Author:   "from-burp",
+					Severity: "info",
+					Tags:     templates.Tags{"burp"},
+				},
+			}
+			byHost[host] = tmpl
+		}
+		tmpl.HTTPRaw = append(tmpl.HTTPRaw, req)
+	}
+
+	hosts := make([]string, 0, len(byHost))
+	for host := range byHost {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	tmpls := make([]*templates.Template, 0, len(hosts))
+	for _, host := range hosts {
+		tmpls = append(tmpls, byHost[host])
+	}
+	return tmpls
+}
+
+// parseItem decodes the raw request of the item and returns its template request and host
+func parseItem(item burpItem) (*templates.Request, string, error) {
+	raw := []byte(strings.TrimLeft(item.Request.Raw, " \t\r\n"))
+	if item.Request.Base64 {
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(item.Request.Raw))
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to decode request: %w", err)
+		}
+		raw = decoded
+	}
+	raw = http2RequestLine.ReplaceAll(raw, []byte("$1 HTTP/1.1\r\n"))
+	// exports of requests without a body may lack the blank line ending the headers
+	if !bytes.Contains(raw, []byte("\r\n\r\n")) && !bytes.Contains(raw, []byte("\n\n")) {
+		raw = append(bytes.TrimRight(raw, "\r\n"), "\r\n\r\n"...)
+	}
+
+	httpReq, err := http.ReadRequest(bufio.NewReader(bytes.NewReader(raw)))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to parse request: %w", err)
+	}
+	body, err := io.ReadAll(httpReq.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read request body: %w", err)
+	}
+
+	host := httpReq.Host
+	if host == "" {
+		host = item.Host
+	}
+	if u, err := url.Parse(item.URL); err == nil && u.Host != "" {
+		host = u.Host
+	}
+	if host == "" {
+		return nil, "", fmt.Errorf("request has no host")
+	}
+
+	var headers map[string]string
+	for name, values := range httpReq.Header {
+		if skippedHeaders[name] {
+			continue
+		}
+		if headers == nil {
+			headers = make(map[string]string)
+		}
+		headers[name] = strings.Join(values, ", ")
+	}
+
+	return &templates.Request{
+		Method:  httpReq.Method,
+		Path:    []string{"{{BaseURL}}" + httpReq.URL.RequestURI()},
+		Headers: headers,
+		Body:    string(body),
+		Matchers: []templates.Matcher{
+			{Type: "status", Status: []int{http.StatusOK}},
+		},
+	}, host, nil
+}
+
+// sanitizeID lowercases the ID and replaces every run of non-alphanumeric characters with a dash
+func sanitizeID(id string) string {
+	return strings.Trim(nonAlphanumeric.ReplaceAllString(strings.ToLower(id), "-"), "-")
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/artnikel/nuclei/internal/templates"
+)
+
+// loadExport parses the fixture export
+func loadExport(t *testing.T, name string) []burpItem {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	items, err := parseExport(data)
+	if err != nil {
+		t.Fatalf("parseExport: %v", err)
+	}
+	return items
+}
+
+func TestGenerateTemplatesXML(t *testing.T) {
+	items := loadExport(t, "requests.xml")
+	if len(items) != 2 {
+		t.Fatalf("parsed %d items, want 2", len(items))
+	}
+	tmpls := generateTemplates(items, func(err error) { t.Errorf("unexpected warning: %v", err) })
+	if len(tmpls) != 1 {
+		t.Fatalf("generated %d templates, want 1 for the single host", len(tmpls))
+	}
+	tmpl := tmpls[0]
+	if tmpl.ID != "burp-shop-example-com" || len(tmpl.HTTPRaw) != 2 {
+		t.Fatalf("template %s with %d requests, want burp-shop-example-com with 2", tmpl.ID, len(tmpl.HTTPRaw))
+	}
+
+	login, cart := tmpl.HTTPRaw[0], tmpl.HTTPRaw[1]
+	if login.Method != "GET" || !reflect.DeepEqual(login.Path, []string{"{{BaseURL}}/admin/login?next=%2Fdashboard"}) || login.Body != "" {
+		t.Errorf("login request = %s %v body %q", login.Method, login.Path, login.Body)
+	}
+	if want := map[string]string{"User-Agent": "Mozilla/5.0", "Accept": "text/html"}; !reflect.DeepEqual(login.Headers, want) {
+		t.Errorf("login headers = %v, want %v without Host and Connection", login.Headers, want)
+	}
+	if cart.Method != "POST" || !reflect.DeepEqual(cart.Path, []string{"{{BaseURL}}/api/cart"}) || cart.Body != `{"item":"sku-1","qty":2}` {
+		t.Errorf("cart request = %s %v body %q", cart.Method, cart.Path, cart.Body)
+	}
+	if want := map[string]string{"Content-Type": "application/json", "Cookie": "session=abc123"}; !reflect.DeepEqual(cart.Headers, want) {
+		t.Errorf("cart headers = %v, want %v without Host and Content-Length", cart.Headers, want)
+	}
+	for _, req := range tmpl.HTTPRaw {
+		if len(req.Matchers) != 1 || req.Matchers[0].Type != "status" || !reflect.DeepEqual(req.Matchers[0].Status, []int{200}) {
+			t.Errorf("matchers of %v = %+v, want status 200", req.Path, req.Matchers)
+		}
+	}
+
+	// the template is written like the command does and must load back
+	data, err := yaml.Marshal(tmpl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), tmpl.ID+".yaml")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := templates.LoadTemplate(path)
+	if err != nil {
+		t.Fatalf("generated template doesn't load: %v\n%s", err, data)
+	}
+	if len(loaded.Requests) != 2 {
+		t.Errorf("loaded %d requests, want 2", len(loaded.Requests))
+	}
+}
+
+func TestGenerateTemplatesJSON(t *testing.T) {
+	var warnings []error
+	tmpls := generateTemplates(loadExport(t, "requests.json"), func(err error) { warnings = append(warnings, err) })
+	if len(warnings) != 1 {
+		t.Errorf("warnings = %v, want one for the undecodable request", warnings)
+	}
+	var got []string
+	for _, tmpl := range tmpls {
+		for _, req := range tmpl.HTTPRaw {
+			got = append(got, tmpl.ID+" "+req.Method+" "+req.Path[0])
+		}
+	}
+	want := []string{
+		"burp-intranet-test GET {{BaseURL}}/status",
+		"burp-shop-example-com GET {{BaseURL}}/robots.txt",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("templates = %v, want %v", got, want)
+	}
+}
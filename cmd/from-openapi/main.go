@@ -0,0 +1,242 @@
+// Command from-openapi generates a template per operation of an OpenAPI 3 spec
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"gopkg.in/yaml.v3"
+
+	"github.com/artnikel/nuclei/internal/constants"
+	"github.com/artnikel/nuclei/internal/templates"
+)
+
+// defaultParamValue is the value of path parameters without an example or default
+const defaultParamValue = "1"
+
+var (
+	// nonAlphanumeric matches the runs of characters dropped from template IDs
+	nonAlphanumeric = regexp.MustCompile(`[^a-z0-9]+`)
+	// pathParam matches the {name} parameters of OpenAPI paths
+	pathParam = regexp.MustCompile(`\{([^}]+)\}`)
+)
+
+func main() {
+	specPath := flag.String("spec", "", "OpenAPI 3 spec file (json or yaml)")
+	outDir := flag.String("output", "templates-openapi", "directory the templates are written to")
+	filterTag := flag.String("filter-tag", "", "comma-separated operation tags, only the operations having one of them are generated")
+	flag.Parse()
+
+	if *specPath == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	doc, err := openapi3.NewLoader().LoadFromFile(*specPath)
+	if err != nil {
+		log.Fatalf("failed to load spec: %v", err)
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		log.Fatalf("invalid spec: %v", err)
+	}
+
+	tmpls := generateTemplates(doc, splitTags(*filterTag))
+	if err := os.MkdirAll(*outDir, 0o755); err != nil {
+		log.Fatalf("failed to create output directory: %v", err)
+	}
+	for _, tmpl := range tmpls {
+		data, err := yaml.Marshal(tmpl)
+		if err != nil {
+			log.Fatalf("failed to encode template %s: %v", tmpl.ID, err)
+		}
+		path := filepath.Join(*outDir, tmpl.ID+constants.YamlFileFormat)
+		if err := os.WriteFile(path, data, constants.FilePerm); err != nil {
+			log.Fatalf("failed to write template: %v", err)
+		}
+	}
+	fmt.Printf("Generated %d templates in %s\n", len(tmpls), *outDir)
+}
+
+// generateTemplates returns a template for every operation of the spec, only for the operations having one of
+// tags if any are given. Template IDs are unique
+func generateTemplates(doc *openapi3.T, tags []string) []*templates.Template {
+	var tmpls []*templates.Template
+	seen := make(map[string]int)
+	paths := doc.Paths.Map()
+	for _, path := range sortedKeys(paths) {
+		item := paths[path]
+		ops := item.Operations()
+		for _, method := range sortedKeys(ops) {
+			op := ops[method]
+			if len(tags) > 0 && !slices.ContainsFunc(op.Tags, func(tag string) bool { return slices.Contains(tags, tag) }) {
+				continue
+			}
+			tmpl := operationTemplate(path, method, item, op)
+			if n := seen[tmpl.ID]; n > 0 {
+				seen[tmpl.ID]++
+				tmpl.ID += "-" + strconv.Itoa(n+1)
+			} else {
+				seen[tmpl.ID] = 1
+			}
+			tmpls = append(tmpls, tmpl)
+		}
+	}
+	return tmpls
+}
+
+// operationTemplate builds the template requesting the operation and matching its documented 2xx responses
+func operationTemplate(path, method string, item *openapi3.PathItem, op *openapi3.Operation) *templates.Template {
+	id := op.OperationID
+	if id == "" {
+		id = method + "-" + path
+	}
+	name := op.Summary
+	if name == "" {
+		name = method + " " + path
+	}
+
+	variables := make(map[string]interface{})
+	for _, param := range append(item.Parameters, op.Parameters...) {
+		if param.Value != nil && param.Value.In == openapi3.ParameterInPath {
+			variables[param.Value.Name] = paramValue(param.Value)
+		}
+	}
+	// every path parameter needs a value, documented or not
+	templatePath := pathParam.ReplaceAllStringFunc(path, func(m string) string {
+		param := strings.Trim(m, "{}")
+		if _, ok := variables[param]; !ok {
+			variables[param] = defaultParamValue
+		}
+		return "{{" + param + "}}"
+	})
+	if len(variables) == 0 {
+		variables = nil
+	}
+
+	req := &templates.Request{
+		Method:            method,
+		Path:              []string{"{{BaseURL}}" + templatePath},
+		MatchersCondition: "and",
+	}
+	if statuses := successStatuses(op.Responses); len(statuses) > 0 {
+		req.Matchers = append(req.Matchers, templates.Matcher{Type: "status", Status: statuses})
+	}
+	for _, jsonPath := range responseJSONPaths(op.Responses) {
+		req.Matchers = append(req.Matchers, templates.Matcher{Type: "json", JSONPath: jsonPath})
+	}
+
+	return &templates.Template{
+		ID: sanitizeID(id),
+		Info: templates.Info{
+			Name:        name,
+			Author:      "from-openapi",
+			Severity:    "info",
+			Description: op.Description,
+			Tags:        append(templates.Tags{"openapi"}, op.Tags...),
+		},
+		Variables: variables,
+		HTTPRaw:   []*templates.Request{req},
+	}
+}
+
+// paramValue returns the example or default of the parameter, defaultParamValue if it has neither
+func paramValue(param *openapi3.Parameter) string {
+	if param.Example != nil {
+		return fmt.Sprint(param.Example)
+	}
+	if param.Schema != nil && param.Schema.Value != nil {
+		if v := param.Schema.Value.Example; v != nil {
+			return fmt.Sprint(v)
+		}
+		if v := param.Schema.Value.Default; v != nil {
+			return fmt.Sprint(v)
+		}
+	}
+	return defaultParamValue
+}
+
+// successStatuses returns the documented 2xx status codes, sorted
+func successStatuses(responses *openapi3.Responses) []int {
+	if responses == nil {
+		return nil
+	}
+	var statuses []int
+	for code := range responses.Map() {
+		if status, err := strconv.Atoi(code); err == nil && status >= 200 && status < 300 {
+			statuses = append(statuses, status)
+		}
+	}
+	sort.Ints(statuses)
+	return statuses
+}
+
+// responseJSONPaths returns the paths of the required properties, or of all properties if none are required,
+// of the JSON schema of the first documented 2xx response. Array schemas use the properties of their first item
+func responseJSONPaths(responses *openapi3.Responses) []string {
+	statuses := successStatuses(responses)
+	if len(statuses) == 0 {
+		return nil
+	}
+	resp := responses.Status(statuses[0])
+	if resp == nil || resp.Value == nil {
+		return nil
+	}
+	media := resp.Value.Content.Get("application/json")
+	if media == nil || media.Schema == nil || media.Schema.Value == nil {
+		return nil
+	}
+
+	schema, prefix := media.Schema.Value, ""
+	if schema.Type.Is(openapi3.TypeArray) && schema.Items != nil && schema.Items.Value != nil {
+		schema, prefix = schema.Items.Value, "0."
+	}
+	props := schema.Required
+	if len(props) == 0 {
+		props = sortedKeys(schema.Properties)
+	}
+	paths := make([]string, 0, len(props))
+	for _, prop := range props {
+		paths = append(paths, prefix+prop)
+	}
+	return paths
+}
+
+// sanitizeID lowercases the ID and replaces every run of non-alphanumeric characters with a dash
+func sanitizeID(id string) string {
+	id = strings.Trim(nonAlphanumeric.ReplaceAllString(strings.ToLower(id), "-"), "-")
+	if id == "" {
+		return "openapi-operation"
+	}
+	return id
+}
+
+// splitTags splits a comma-separated list of tags, dropping empty entries
+func splitTags(list string) []string {
+	var tags []string
+	for _, tag := range strings.Split(list, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// sortedKeys returns the keys of m in order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"gopkg.in/yaml.v3"
+
+	"github.com/artnikel/nuclei/internal/templates"
+)
+
+// loadSpec loads and validates the fixture spec
+func loadSpec(t *testing.T) *openapi3.T {
+	t.Helper()
+	doc, err := openapi3.NewLoader().LoadFromFile(filepath.Join("testdata", "petstore.yaml"))
+	if err != nil {
+		t.Fatalf("load spec: %v", err)
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		t.Fatalf("invalid spec: %v", err)
+	}
+	return doc
+}
+
+func TestGenerateTemplates(t *testing.T) {
+	tmpls := generateTemplates(loadSpec(t), nil)
+	byID := make(map[string]*templates.Template)
+	var ids []string
+	for _, tmpl := range tmpls {
+		byID[tmpl.ID] = tmpl
+		ids = append(ids, tmpl.ID)
+	}
+	if len(tmpls) != 4 || len(byID) != 4 {
+		t.Fatalf("generated templates %v, want 4 with unique IDs", ids)
+	}
+
+	tests := []struct {
+		id        string
+		method    string
+		path      string
+		variables map[string]interface{}
+		statuses  []int
+		jsonPaths []string
+	}{
+		{id: "listpets", method: "GET", path: "{{BaseURL}}/pets", statuses: []int{200}, jsonPaths: []string{"0.id", "0.name"}},
+		{id: "createpet", method: "POST", path: "{{BaseURL}}/pets", statuses: []int{201}},
+		{id: "get-pet-by-id", method: "GET", path: "{{BaseURL}}/pets/{{petId}}", variables: map[string]interface{}{"petId": "42"},
+			statuses: []int{200}, jsonPaths: []string{"id", "name"}},
+		{id: "get-stores-storeid-orders-orderid", method: "GET", path: "{{BaseURL}}/stores/{{storeId}}/orders/{{orderId}}",
+			variables: map[string]interface{}{"storeId": "main", "orderId": defaultParamValue}, statuses: []int{200, 204}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			tmpl := byID[tt.id]
+			if tmpl == nil {
+				t.Fatalf("no template %s", tt.id)
+			}
+			if len(tmpl.HTTPRaw) != 1 {
+				t.Fatalf("%d requests, want 1", len(tmpl.HTTPRaw))
+			}
+			req := tmpl.HTTPRaw[0]
+			if req.Method != tt.method || len(req.Path) != 1 || req.Path[0] != tt.path {
+				t.Errorf("request = %s %v, want %s %s", req.Method, req.Path, tt.method, tt.path)
+			}
+			if len(tt.variables) > 0 && !reflect.DeepEqual(tmpl.Variables, tt.variables) || len(tt.variables) == 0 && tmpl.Variables != nil {
+				t.Errorf("variables = %v, want %v", tmpl.Variables, tt.variables)
+			}
+			var statuses []int
+			var jsonPaths []string
+			for _, m := range req.Matchers {
+				switch m.Type {
+				case "status":
+					statuses = append(statuses, m.Status...)
+				case "json":
+					jsonPaths = append(jsonPaths, m.JSONPath)
+				}
+			}
+			if !reflect.DeepEqual(statuses, tt.statuses) || !reflect.DeepEqual(jsonPaths, tt.jsonPaths) {
+				t.Errorf("matchers status %v json %v, want %v %v", statuses, jsonPaths, tt.statuses, tt.jsonPaths)
+			}
+		})
+	}
+}
+
+func TestGenerateTemplatesFilterTag(t *testing.T) {
+	tmpls := generateTemplates(loadSpec(t), splitTags(" admin, store ,"))
+	var ids []string
+	for _, tmpl := range tmpls {
+		ids = append(ids, tmpl.ID)
+	}
+	if want := []string{"createpet", "get-stores-storeid-orders-orderid"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("filtered templates = %v, want %v", ids, want)
+	}
+}
+
+// TestGeneratedTemplatesLoad writes the generated templates like the command does and loads them back
+func TestGeneratedTemplatesLoad(t *testing.T) {
+	dir := t.TempDir()
+	for _, tmpl := range generateTemplates(loadSpec(t), nil) {
+		data, err := yaml.Marshal(tmpl)
+		if err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(dir, tmpl.ID+".yaml")
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		loaded, err := templates.LoadTemplate(path)
+		if err != nil {
+			t.Errorf("generated template %s doesn't load: %v\n%s", tmpl.ID, err, data)
+			continue
+		}
+		if loaded.ID != tmpl.ID || len(loaded.Requests) != 1 {
+			t.Errorf("loaded %s with %d requests, want %s with 1", loaded.ID, len(loaded.Requests), tmpl.ID)
+		}
+	}
+}
+
+func TestSanitizeID(t *testing.T) {
+	tests := map[string]string{
+		"listPets":          "listpets",
+		"Get Pet.By-ID":     "get-pet-by-id",
+		"--weird__name--":   "weird-name",
+		"get-/pets/{petId}": "get-pets-petid",
+		"!!!":               "openapi-operation",
+	}
+	for in, want := range tests {
+		if got := sanitizeID(in); got != want {
+			t.Errorf("sanitizeID(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+// Command gendocs generates Markdown documentation or a JSON catalog from template metadata
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/artnikel/nuclei/internal/constants"
+	"github.com/artnikel/nuclei/internal/docs"
+	"github.com/artnikel/nuclei/internal/templates"
+)
+
+// Supported output formats
+const (
+	formatMarkdown = "markdown"
+	formatJSON     = "json"
+)
+
+func main() {
+	templatesDir := flag.String("templates", "templates", "directory with templates")
+	outDir := flag.String("output", "docs/templates", "directory to write the documentation to")
+	format := flag.String("format", formatMarkdown, "output format: markdown or json")
+	pageTemplate := flag.String("page-template", "", "text/template file for template pages (default built-in)")
+	indexTemplate := flag.String("index-template", "", "text/template file for TEMPLATES.md (default built-in)")
+	flag.Parse()
+
+	tmpls, _, err := templates.LoadTemplates(*templatesDir, nil, false, templates.DefaultAdvancedSettings(), func(err error) {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	})
+	if err != nil {
+		log.Fatalf("failed to load templates: %v", err)
+	}
+	templateDocs := make([]docs.TemplateDoc, 0, len(tmpls))
+	for _, tmpl := range tmpls {
+		templateDocs = append(templateDocs, docs.NewTemplateDoc(tmpl))
+	}
+
+	if err := os.MkdirAll(*outDir, constants.DirPerm); err != nil {
+		log.Fatalf("failed to create output directory: %v", err)
+	}
+
+	switch *format {
+	case formatJSON:
+		err = writeFile(filepath.Join(*outDir, "catalog.json"), func(f *os.File) error {
+			return docs.WriteCatalog(f, templateDocs)
+		})
+	case formatMarkdown:
+		err = writeMarkdown(*outDir, *pageTemplate, *indexTemplate, templateDocs)
+	default:
+		err = fmt.Errorf("unsupported format: %s", *format)
+	}
+	if err != nil {
+		log.Fatalf("failed to generate docs: %v", err)
+	}
+	fmt.Printf("documented %d templates in %s\n", len(templateDocs), *outDir)
+}
+
+// writeMarkdown writes one page per template and the combined TEMPLATES.md into outDir
+func writeMarkdown(outDir, pageTemplate, indexTemplate string, templateDocs []docs.TemplateDoc) error {
+	renderer, err := docs.NewRenderer(pageTemplate, indexTemplate)
+	if err != nil {
+		return err
+	}
+	for _, doc := range templateDocs {
+		err := writeFile(filepath.Join(outDir, doc.DocFile), func(f *os.File) error {
+			return renderer.RenderPage(f, doc)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return writeFile(filepath.Join(outDir, "TEMPLATES.md"), func(f *os.File) error {
+		return renderer.RenderIndex(f, templateDocs)
+	})
+}
+
+// writeFile creates the file at path and fills it with write
+func writeFile(path string, write func(f *os.File) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
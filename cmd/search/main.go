@@ -0,0 +1,38 @@
+// Command search looks up the templates covering a CVE
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/artnikel/nuclei/internal/templates"
+)
+
+func main() {
+	templatesDir := flag.String("templates", "", "directory with templates")
+	cve := flag.String("cve", "", "CVE ID to search for (e.g. CVE-2021-44228)")
+	flag.Parse()
+
+	if *templatesDir == "" || *cve == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	_, index, err := templates.LoadTemplates(*templatesDir, nil, false, templates.DefaultAdvancedSettings(), func(err error) {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	})
+	if err != nil {
+		log.Fatalf("failed to load templates: %v", err)
+	}
+
+	found := index.AllByCVE(*cve)
+	if len(found) == 0 {
+		fmt.Fprintf(os.Stderr, "no templates found for %s\n", *cve)
+		os.Exit(1)
+	}
+	for _, tmpl := range found {
+		fmt.Printf("%s\t%s\t%s\n", tmpl.ID, tmpl.Info.Name, tmpl.FilePath)
+	}
+}
@@ -0,0 +1,56 @@
+// Command sign-template appends an HMAC-SHA256 signature to template files
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/artnikel/nuclei/internal/config"
+	"github.com/artnikel/nuclei/internal/constants"
+	"github.com/artnikel/nuclei/internal/templates"
+)
+
+func main() {
+	configPath := flag.String("config", "config.yaml", "config file with the template signing key")
+	key := flag.String("key", "", "signing key, overrides the key from the config file")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] template.yaml...\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	signingKey := *key
+	if signingKey == "" {
+		cfg, err := config.LoadConfig(*configPath)
+		if err != nil {
+			log.Fatalf("failed to load config: %v", err)
+		}
+		signingKey = cfg.Security.TemplateSigningKey
+	}
+	if signingKey == "" {
+		log.Fatal("template signing key is not configured")
+	}
+
+	for _, path := range flag.Args() {
+		if err := signFile(path, []byte(signingKey)); err != nil {
+			log.Fatalf("failed to sign %s: %v", path, err)
+		}
+		fmt.Println("signed", path)
+	}
+}
+
+// signFile rewrites the template at path with its signature as the last line
+func signFile(path string, key []byte) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, templates.SignTemplate(data, key), constants.FilePerm)
+}
@@ -0,0 +1,186 @@
+// Command test-templates runs templates against mock servers described by test cases and reports regressions
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/artnikel/nuclei/internal/constants"
+	"github.com/artnikel/nuclei/internal/logging"
+	"github.com/artnikel/nuclei/internal/templates"
+)
+
+// TestCase describes the response of the mock server and the expected result of the template
+type TestCase struct {
+	Template          string            `yaml:"template"`
+	Response          MockResponse      `yaml:"response"`
+	ExpectedMatch     bool              `yaml:"expected_match"`
+	ExpectedExtracted map[string]string `yaml:"expected_extracted"`
+}
+
+// MockResponse is returned by the mock server for every request
+type MockResponse struct {
+	Status  int               `yaml:"status"`
+	Headers map[string]string `yaml:"headers"`
+	Body    string            `yaml:"body"`
+}
+
+func main() {
+	failFast := flag.Bool("fail-fast", false, "stop after the first failed test case")
+	timeout := flag.Duration("timeout", constants.TenSecTimeout, "timeout for a single test case")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] templates-dir testcases-dir\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	templatesDir, casesDir := flag.Arg(0), flag.Arg(1)
+
+	paths, err := findTestCases(casesDir)
+	if err != nil {
+		log.Fatalf("failed to read test cases: %v", err)
+	}
+	if len(paths) == 0 {
+		log.Fatalf("no test cases found in %s", casesDir)
+	}
+
+	logger := &logging.Logger{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+
+	var failed int
+	for _, path := range paths {
+		diffs, err := runTestCase(path, templatesDir, *timeout, logger)
+		name, _ := filepath.Rel(casesDir, path)
+		switch {
+		case err != nil:
+			fmt.Printf("FAIL %s: %v\n", name, err)
+		case len(diffs) > 0:
+			fmt.Printf("FAIL %s\n", name)
+			for _, d := range diffs {
+				fmt.Println("    " + d)
+			}
+		default:
+			fmt.Printf("ok   %s\n", name)
+			continue
+		}
+		failed++
+		if *failFast {
+			break
+		}
+	}
+
+	fmt.Printf("%d of %d test cases failed\n", failed, len(paths))
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// findTestCases returns the sorted YAML files under dir
+func findTestCases(dir string) ([]string, error) {
+	var paths []string
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		ext := strings.ToLower(filepath.Ext(path))
+		if !d.IsDir() && (ext == constants.YamlFileFormat || ext == constants.YmlFileFormat) {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	sort.Strings(paths)
+	return paths, err
+}
+
+// loadTestCase reads the test case at path
+func loadTestCase(path string) (*TestCase, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var tc TestCase
+	if err := yaml.Unmarshal(data, &tc); err != nil {
+		return nil, err
+	}
+	if tc.Template == "" {
+		return nil, fmt.Errorf("template is not set")
+	}
+	if tc.Response.Status == 0 {
+		tc.Response.Status = http.StatusOK
+	}
+	return &tc, nil
+}
+
+// runTestCase runs the template of the test case against a mock server and returns the differences from the expected result
+func runTestCase(path, templatesDir string, timeout time.Duration, logger *logging.Logger) ([]string, error) {
+	tc, err := loadTestCase(path)
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := templates.LoadTemplate(filepath.Join(templatesDir, tc.Template))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load template: %w", err)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for k, v := range tc.Response.Headers {
+			w.Header().Set(k, v)
+		}
+		w.WriteHeader(tc.Response.Status)
+		io.WriteString(w, tc.Response.Body)
+	}))
+	defer srv.Close()
+
+	advanced := templates.DefaultAdvancedSettings()
+	advanced.DisableHeadless = true
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	matched, extracted, err := templates.MatchTemplate(ctx, srv.URL, tc.Response.Body, tmpl, advanced, logger)
+	if err != nil {
+		return nil, err
+	}
+	return compareResult(tc, matched, extracted), nil
+}
+
+// compareResult lists the differences between the expected and the actual result, only expected keys are compared
+func compareResult(tc *TestCase, matched bool, extracted map[string]string) []string {
+	var diffs []string
+	if matched != tc.ExpectedMatch {
+		diffs = append(diffs, fmt.Sprintf("match: want %t, got %t", tc.ExpectedMatch, matched))
+	}
+
+	keys := make([]string, 0, len(tc.ExpectedExtracted))
+	for k := range tc.ExpectedExtracted {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		want := tc.ExpectedExtracted[k]
+		got, ok := extracted[k]
+		switch {
+		case !ok:
+			diffs = append(diffs, fmt.Sprintf("extracted[%s]: want %q, got nothing", k, want))
+		case got != want:
+			diffs = append(diffs, fmt.Sprintf("extracted[%s]: want %q, got %q", k, want, got))
+		}
+	}
+	return diffs
+}
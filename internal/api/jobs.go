@@ -0,0 +1,136 @@
+// Package api implements the headless REST API for running scans
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/artnikel/nuclei/internal/templates"
+)
+
+// JobStatus is the lifecycle state of a scan job
+type JobStatus string
+
+const (
+	JobQueued    JobStatus = "queued"
+	JobRunning   JobStatus = "running"
+	JobCompleted JobStatus = "completed"
+	JobFailed    JobStatus = "failed"
+	JobCancelled JobStatus = "cancelled"
+)
+
+// ScanJob holds the state of a single scan started through the API
+type ScanJob struct {
+	ID     string
+	Target string
+
+	mu        sync.Mutex
+	status    JobStatus
+	processed int
+	total     int
+	results   []templates.Finding
+	err       string
+	cancel    context.CancelFunc
+	// finishedAt is when the job reached a final state, zero while it's queued or running
+	finishedAt time.Time
+}
+
+// JobSnapshot is a consistent copy of the job state safe to encode as JSON
+type JobSnapshot struct {
+	ID        string              `json:"id"`
+	Target    string              `json:"target"`
+	Status    JobStatus           `json:"status"`
+	Processed int                 `json:"processed"`
+	Total     int                 `json:"total"`
+	Error     string              `json:"error,omitempty"`
+	Results   []templates.Finding `json:"results"`
+}
+
+// newJobID returns a random hex identifier for a scan job
+func newJobID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// setProgress records the number of checked templates
+func (j *ScanJob) setProgress(processed, total int) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.processed = processed
+	j.total = total
+}
+
+// setStatus updates the job status unless the job was already cancelled
+func (j *ScanJob) setStatus(status JobStatus) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.status == JobCancelled {
+		return
+	}
+	j.status = status
+}
+
+// addFinding stores a finding as soon as the scan reports it so it's part of the partial results
+func (j *ScanJob) addFinding(f *templates.Finding) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.results = append(j.results, *f)
+}
+
+// finish moves the job to its final state
+func (j *ScanJob) finish(err error, now time.Time) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.finishedAt = now
+	if j.status == JobCancelled {
+		return
+	}
+	if err != nil {
+		j.status = JobFailed
+		j.err = err.Error()
+		return
+	}
+	j.status = JobCompleted
+}
+
+// expired reports whether the job finished longer than ttl before now
+func (j *ScanJob) expired(now time.Time, ttl time.Duration) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return !j.finishedAt.IsZero() && now.Sub(j.finishedAt) > ttl
+}
+
+// Cancel stops a queued or running job, it returns false if the job is already finished
+func (j *ScanJob) Cancel() bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.status != JobQueued && j.status != JobRunning {
+		return false
+	}
+	j.status = JobCancelled
+	j.cancel()
+	return true
+}
+
+// Snapshot returns a copy of the current job state
+func (j *ScanJob) Snapshot() JobSnapshot {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	results := make([]templates.Finding, len(j.results))
+	copy(results, j.results)
+	return JobSnapshot{
+		ID:        j.ID,
+		Target:    j.Target,
+		Status:    j.status,
+		Processed: j.processed,
+		Total:     j.total,
+		Error:     j.err,
+		Results:   results,
+	}
+}
@@ -0,0 +1,234 @@
+// Package api - HTTP handlers of the scan REST API
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/artnikel/nuclei/internal/config"
+	"github.com/artnikel/nuclei/internal/constants"
+	"github.com/artnikel/nuclei/internal/logging"
+	"github.com/artnikel/nuclei/internal/templates"
+)
+
+const (
+	// DefaultAddr only accepts connections from the local machine
+	DefaultAddr = "127.0.0.1:8080"
+	// defaultJobTTL is how long finished jobs are kept when APIConfig.JobTTL is not set
+	defaultJobTTL = time.Hour
+)
+
+// ScanRequest is the body accepted by POST /scan. Advanced settings left out of the request keep their defaults
+type ScanRequest struct {
+	Target       string                             `json:"target"`
+	TemplatesDir string                             `json:"templatesDir"`
+	Tags         []string                           `json:"tags,omitempty"`
+	Severity     []string                           `json:"severity,omitempty"`
+	Advanced     *templates.AdvancedSettingsChecker `json:"advanced,omitempty"`
+}
+
+// ScanResponse is returned by POST /scan
+type ScanResponse struct {
+	ID string `json:"id"`
+}
+
+// errorResponse is the body of every non-2xx response
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// Server runs scan jobs and exposes them over HTTP
+type Server struct {
+	jobs   sync.Map // jobs maps job IDs to *ScanJob
+	cfg    config.APIConfig
+	logger *logging.Logger
+	// now is the clock finished jobs are expired with
+	now func() time.Time
+}
+
+// NewServer creates an API server, requests must carry cfg.Token as a bearer token. An empty token rejects
+// every request
+func NewServer(cfg config.APIConfig, logger *logging.Logger) *Server {
+	if cfg.JobTimeout <= 0 {
+		cfg.JobTimeout = constants.FiveMinTimeout
+	}
+	if cfg.JobTTL <= 0 {
+		cfg.JobTTL = defaultJobTTL
+	}
+	return &Server{cfg: cfg, logger: logger, now: time.Now}
+}
+
+// Handler returns the HTTP handler serving the API routes
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /scan", s.handleStartScan)
+	mux.HandleFunc("GET /scan/{id}/status", s.handleStatus)
+	mux.HandleFunc("POST /scan/{id}/cancel", s.handleCancel)
+	mux.HandleFunc("GET /scan/{id}/results", s.handleResults)
+	return s.authenticate(mux)
+}
+
+// authenticate rejects requests without the configured bearer token
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || s.cfg.Token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(s.cfg.Token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, http.StatusUnauthorized, "missing or invalid token")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handleStartScan validates the scan request, starts the job and returns its ID
+func (s *Server) handleStartScan(w http.ResponseWriter, r *http.Request) {
+	req := ScanRequest{Advanced: templates.DefaultAdvancedSettings()}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+	req.Target = strings.TrimSpace(req.Target)
+	if req.Target == "" || req.TemplatesDir == "" {
+		writeError(w, http.StatusBadRequest, "target and templatesDir are required")
+		return
+	}
+	advanced := req.Advanced
+	if advanced == nil {
+		advanced = templates.DefaultAdvancedSettings()
+	}
+
+	id, err := newJobID()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to create job id")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.cfg.JobTimeout)
+	job := &ScanJob{
+		ID:     id,
+		Target: req.Target,
+		status: JobQueued,
+		cancel: cancel,
+	}
+	s.jobs.Store(id, job)
+	advanced.OnFinding = job.addFinding
+
+	go s.runJob(ctx, job, req.TemplatesDir, req.Tags, req.Severity, advanced)
+
+	writeJSON(w, http.StatusAccepted, ScanResponse{ID: id})
+}
+
+// runJob executes the scan for the job, findings are added to the job by advanced.OnFinding as they're made
+func (s *Server) runJob(ctx context.Context, job *ScanJob, templatesDir string, tags, severities []string, advanced *templates.AdvancedSettingsChecker) {
+	defer job.cancel()
+	job.setStatus(JobRunning)
+
+	_, err := templates.FindMatchingTemplates(ctx, job.Target, templatesDir, tags, severities, constants.FiveSecTimeout, advanced, s.logger, job.setProgress)
+	if err != nil {
+		s.logger.Error("API scan failed", slog.String("job_id", job.ID), slog.String("target", job.Target), slog.Any("error", err))
+	}
+	job.finish(err, s.now())
+}
+
+// evictExpired drops the jobs that finished longer than the job TTL ago
+func (s *Server) evictExpired() {
+	now := s.now()
+	s.jobs.Range(func(id, v any) bool {
+		if v.(*ScanJob).expired(now, s.cfg.JobTTL) {
+			s.jobs.Delete(id)
+		}
+		return true
+	})
+}
+
+// evictLoop runs evictExpired periodically until ctx is cancelled
+func (s *Server) evictLoop(ctx context.Context) {
+	ticker := time.NewTicker(min(s.cfg.JobTTL, time.Minute))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.evictExpired()
+		}
+	}
+}
+
+// handleStatus returns progress and partial results of the job
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	job, ok := s.lookupJob(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, job.Snapshot())
+}
+
+// handleCancel stops a running job
+func (s *Server) handleCancel(w http.ResponseWriter, r *http.Request) {
+	job, ok := s.lookupJob(w, r)
+	if !ok {
+		return
+	}
+	if !job.Cancel() {
+		writeError(w, http.StatusConflict, "scan is already finished")
+		return
+	}
+	writeJSON(w, http.StatusOK, job.Snapshot())
+}
+
+// handleResults returns the findings of the job
+func (s *Server) handleResults(w http.ResponseWriter, r *http.Request) {
+	job, ok := s.lookupJob(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, job.Snapshot().Results)
+}
+
+// lookupJob finds the job referenced by the {id} path value and writes 404 if it does not exist
+func (s *Server) lookupJob(w http.ResponseWriter, r *http.Request) (*ScanJob, bool) {
+	v, ok := s.jobs.Load(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "scan not found")
+		return nil, false
+	}
+	return v.(*ScanJob), true
+}
+
+// writeJSON encodes v as the JSON response body with the given status code
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes a JSON error response
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, errorResponse{Error: msg})
+}
+
+// ListenAndServe starts the API on addr and shuts it down when ctx is cancelled
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	srv := &http.Server{Addr: addr, Handler: s.Handler()}
+	go s.evictLoop(ctx)
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), constants.TenSecTimeout)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+	err := srv.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
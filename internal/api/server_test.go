@@ -0,0 +1,306 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/artnikel/nuclei/internal/config"
+	"github.com/artnikel/nuclei/internal/logging"
+	"github.com/artnikel/nuclei/internal/templates"
+)
+
+const testToken = "test-token"
+
+const testTemplate = `id: exposed-admin
+info:
+  name: Exposed admin
+  author: test
+  severity: high
+http:
+  - method: GET
+    path:
+      - "{{BaseURL}}/admin"
+    matchers-condition: and
+    matchers:
+      - type: status
+        status: [200]
+      - type: word
+        words: ["admin panel"]
+`
+
+func newTestServer(t *testing.T) (*Server, *httptest.Server) {
+	t.Helper()
+	logger := &logging.Logger{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	s := NewServer(config.APIConfig{Token: testToken}, logger)
+	ts := httptest.NewServer(s.Handler())
+	t.Cleanup(ts.Close)
+	return s, ts
+}
+
+func newTarget(t *testing.T) *httptest.Server {
+	t.Helper()
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/admin" {
+			io.WriteString(w, "admin panel")
+			return
+		}
+		io.WriteString(w, "<html><body>home</body></html>")
+	}))
+	t.Cleanup(target.Close)
+	return target
+}
+
+func writeTemplates(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "admin.yaml"), []byte(testTemplate), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func doRequest(t *testing.T, method, url, token string, body any) *http.Response {
+	t.Helper()
+	var r io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, url, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func decode[T any](t *testing.T, resp *http.Response) T {
+	t.Helper()
+	var v T
+	if err := json.NewDecoder(resp.Body).Decode(&v); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	return v
+}
+
+func scanRequest(target, dir string) map[string]any {
+	return map[string]any{
+		"target":       target,
+		"templatesDir": dir,
+		"advanced":     map[string]any{"disableHeadless": true, "cacheDir": ""},
+	}
+}
+
+func waitForStatus(t *testing.T, ts *httptest.Server, id string, want JobStatus) JobSnapshot {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		resp := doRequest(t, http.MethodGet, ts.URL+"/scan/"+id+"/status", testToken, nil)
+		snap := decode[JobSnapshot](t, resp)
+		if snap.Status == want {
+			return snap
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job status = %s (error %q), want %s", snap.Status, snap.Error, want)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestAuthentication(t *testing.T) {
+	_, ts := newTestServer(t)
+	tests := []struct {
+		name  string
+		token string
+		want  int
+	}{
+		{name: "missing token", token: "", want: http.StatusUnauthorized},
+		{name: "wrong token", token: "wrong", want: http.StatusUnauthorized},
+		{name: "valid token", token: testToken, want: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := doRequest(t, http.MethodGet, ts.URL+"/scan/missing/status", tt.token, nil)
+			if resp.StatusCode != tt.want {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.want)
+			}
+			if resp.Header.Get("Content-Type") != "application/json" {
+				t.Errorf("content type = %q", resp.Header.Get("Content-Type"))
+			}
+		})
+	}
+}
+
+func TestEmptyTokenRejectsRequests(t *testing.T) {
+	logger := &logging.Logger{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	ts := httptest.NewServer(NewServer(config.APIConfig{}, logger).Handler())
+	defer ts.Close()
+	resp := doRequest(t, http.MethodGet, ts.URL+"/scan/x/status", "", nil)
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusUnauthorized)
+	}
+}
+
+func TestStartScanValidation(t *testing.T) {
+	_, ts := newTestServer(t)
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "invalid json", body: "{"},
+		{name: "missing target", body: `{"templatesDir":"templates"}`},
+		{name: "missing templates dir", body: `{"target":"http://example.com"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodPost, ts.URL+"/scan", strings.NewReader(tt.body))
+			req.Header.Set("Authorization", "Bearer "+testToken)
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusBadRequest {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusBadRequest)
+			}
+			if e := decode[errorResponse](t, resp); e.Error == "" {
+				t.Error("error message is empty")
+			}
+		})
+	}
+}
+
+func TestScanLifecycle(t *testing.T) {
+	_, ts := newTestServer(t)
+	target := newTarget(t)
+
+	resp := doRequest(t, http.MethodPost, ts.URL+"/scan", testToken, scanRequest(target.URL, writeTemplates(t)))
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("POST /scan status = %d, want %d", resp.StatusCode, http.StatusAccepted)
+	}
+	id := decode[ScanResponse](t, resp).ID
+	if id == "" {
+		t.Fatal("POST /scan returned an empty id")
+	}
+
+	snap := waitForStatus(t, ts, id, JobCompleted)
+	if snap.ID != id || snap.Target != target.URL || snap.Total != 1 || snap.Processed != 1 {
+		t.Errorf("status = %+v", snap)
+	}
+	if len(snap.Results) != 1 {
+		t.Fatalf("status results = %+v, want 1 finding", snap.Results)
+	}
+
+	resp = doRequest(t, http.MethodGet, ts.URL+"/scan/"+id+"/results", testToken, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET results status = %d", resp.StatusCode)
+	}
+	results := decode[[]templates.Finding](t, resp)
+	if len(results) != 1 || results[0].TemplateID != "exposed-admin" || results[0].Severity != "high" {
+		t.Fatalf("results = %+v", results)
+	}
+
+	resp = doRequest(t, http.MethodPost, ts.URL+"/scan/"+id+"/cancel", testToken, nil)
+	if resp.StatusCode != http.StatusConflict {
+		t.Fatalf("cancel of finished job status = %d, want %d", resp.StatusCode, http.StatusConflict)
+	}
+}
+
+func TestStatusReturnsPartialResults(t *testing.T) {
+	s, ts := newTestServer(t)
+	job := &ScanJob{ID: "partial", Target: "http://example.com", status: JobRunning, cancel: func() {}}
+	s.jobs.Store(job.ID, job)
+	job.addFinding(&templates.Finding{TemplateID: "first"})
+
+	resp := doRequest(t, http.MethodGet, ts.URL+"/scan/partial/status", testToken, nil)
+	snap := decode[JobSnapshot](t, resp)
+	if snap.Status != JobRunning || len(snap.Results) != 1 || snap.Results[0].TemplateID != "first" {
+		t.Fatalf("status = %+v, want running job with the first finding", snap)
+	}
+}
+
+func TestCancelScan(t *testing.T) {
+	s, ts := newTestServer(t)
+	cancelled := false
+	job := &ScanJob{ID: "running", status: JobRunning, cancel: func() { cancelled = true }}
+	s.jobs.Store(job.ID, job)
+
+	resp := doRequest(t, http.MethodPost, ts.URL+"/scan/running/cancel", testToken, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("cancel status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if snap := decode[JobSnapshot](t, resp); snap.Status != JobCancelled || !cancelled {
+		t.Fatalf("cancel = %+v, context cancelled %v", snap, cancelled)
+	}
+
+	resp = doRequest(t, http.MethodPost, ts.URL+"/scan/missing/cancel", testToken, nil)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("cancel of unknown job status = %d, want %d", resp.StatusCode, http.StatusNotFound)
+	}
+}
+
+func TestAdvancedSettingsKeepDefaults(t *testing.T) {
+	req := ScanRequest{Advanced: templates.DefaultAdvancedSettings()}
+	body := `{"target":"t","templatesDir":"d","advanced":{"proxy":"http://proxy:8080","dryRun":true,"retries":3,"idnNormalize":false}}`
+	if err := json.Unmarshal([]byte(body), &req); err != nil {
+		t.Fatal(err)
+	}
+	defaults := templates.DefaultAdvancedSettings()
+	a := req.Advanced
+	if a.Proxy != "http://proxy:8080" || !a.DryRun || a.Retries != 3 || a.IDNNormalize {
+		t.Errorf("request settings not applied: %+v", a)
+	}
+	if a.HeadlessTabs != defaults.HeadlessTabs || a.MaxPayloadCombinations != defaults.MaxPayloadCombinations ||
+		a.MaxFlowIterations != defaults.MaxFlowIterations {
+		t.Errorf("defaults not kept: %+v", a)
+	}
+}
+
+func TestEvictExpired(t *testing.T) {
+	s, _ := newTestServer(t)
+	now := time.Now()
+	s.now = func() time.Time { return now }
+
+	finished := &ScanJob{ID: "old", status: JobRunning, cancel: func() {}}
+	finished.finish(nil, now.Add(-2*defaultJobTTL))
+	recent := &ScanJob{ID: "recent", status: JobRunning, cancel: func() {}}
+	recent.finish(nil, now.Add(-time.Minute))
+	running := &ScanJob{ID: "running", status: JobRunning, cancel: func() {}}
+	for _, j := range []*ScanJob{finished, recent, running} {
+		s.jobs.Store(j.ID, j)
+	}
+
+	s.evictExpired()
+	for id, want := range map[string]bool{"old": false, "recent": true, "running": true} {
+		if _, ok := s.jobs.Load(id); ok != want {
+			t.Errorf("job %s kept = %v, want %v", id, ok, want)
+		}
+	}
+}
+
+func TestNewServerDefaults(t *testing.T) {
+	logger := &logging.Logger{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	s := NewServer(config.APIConfig{Token: testToken, JobTimeout: time.Second}, logger)
+	if s.cfg.JobTimeout != time.Second || s.cfg.JobTTL != defaultJobTTL {
+		t.Fatalf("config = %+v", s.cfg)
+	}
+}
@@ -0,0 +1,119 @@
+// Package baseline compares scan findings against a previously accepted set of findings
+package baseline
+
+import (
+	"encoding/json"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/artnikel/nuclei/internal/constants"
+	"github.com/artnikel/nuclei/internal/templates"
+)
+
+// Baseline holds the accepted findings keyed by template ID and normalized target
+type Baseline struct {
+	keys     map[string]struct{}
+	findings []templates.Finding
+}
+
+// NewBaseline creates a baseline from the findings
+func NewBaseline(findings []templates.Finding) *Baseline {
+	b := &Baseline{keys: make(map[string]struct{}, len(findings))}
+	for _, f := range findings {
+		if _, ok := b.keys[findingKey(f)]; ok {
+			continue
+		}
+		b.keys[findingKey(f)] = struct{}{}
+		b.findings = append(b.findings, f)
+	}
+	return b
+}
+
+// Contains reports whether the finding is part of the baseline
+func (b *Baseline) Contains(f templates.Finding) bool {
+	_, ok := b.keys[findingKey(f)]
+	return ok
+}
+
+// Findings returns the findings the baseline was built from
+func (b *Baseline) Findings() []templates.Finding {
+	return b.findings
+}
+
+// Compare returns the findings in current that are missing from the baseline as new,
+// and the baseline findings missing from current as resolved
+func Compare(current []templates.Finding, baseline *Baseline) (newFindings, resolved []templates.Finding) {
+	currentKeys := make(map[string]struct{}, len(current))
+	for _, f := range current {
+		currentKeys[findingKey(f)] = struct{}{}
+		if !baseline.Contains(f) {
+			newFindings = append(newFindings, f)
+		}
+	}
+	for _, f := range baseline.findings {
+		if _, ok := currentKeys[findingKey(f)]; !ok {
+			resolved = append(resolved, f)
+		}
+	}
+	return newFindings, resolved
+}
+
+// Diff compares two scans, returning the findings of after missing from before as new, the findings of before
+// missing from after as resolved and the findings of after also in before as unchanged. Duplicates count once
+func Diff(before, after []templates.Finding) (newFindings, resolved, unchanged []templates.Finding) {
+	previous := NewBaseline(before)
+	current := NewBaseline(after).findings
+	newFindings, resolved = Compare(current, previous)
+	for _, f := range current {
+		if previous.Contains(f) {
+			unchanged = append(unchanged, f)
+		}
+	}
+	return newFindings, resolved, unchanged
+}
+
+// Load reads a baseline saved with Save
+func Load(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var findings []templates.Finding
+	if err := json.Unmarshal(data, &findings); err != nil {
+		return nil, err
+	}
+	return NewBaseline(findings), nil
+}
+
+// Save writes the baseline findings to path as JSON
+func (b *Baseline) Save(path string) error {
+	findings := b.findings
+	if findings == nil {
+		findings = []templates.Finding{}
+	}
+	data, err := json.MarshalIndent(findings, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, constants.FilePerm)
+}
+
+// findingKey identifies a finding by template ID and normalized target
+func findingKey(f templates.Finding) string {
+	return f.TemplateID + "|" + normalizeTarget(f.Target)
+}
+
+// normalizeTarget lowercases the scheme and host and drops a trailing slash so equivalent targets compare equal
+func normalizeTarget(target string) string {
+	target = strings.TrimSpace(target)
+	u, err := url.Parse(target)
+	if err != nil || u.Host == "" {
+		return strings.TrimRight(strings.ToLower(target), "/")
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	u.Fragment = ""
+	u.Path = strings.TrimRight(u.Path, "/")
+	return u.String()
+}
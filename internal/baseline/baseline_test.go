@@ -0,0 +1,118 @@
+package baseline
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/artnikel/nuclei/internal/templates"
+)
+
+func finding(templateID, target string) templates.Finding {
+	return templates.Finding{TemplateID: templateID, Target: target}
+}
+
+// keys returns the sorted keys of the findings
+func keys(findings []templates.Finding) string {
+	res := make([]string, 0, len(findings))
+	for _, f := range findings {
+		res = append(res, f.TemplateID+"@"+f.Target)
+	}
+	sort.Strings(res)
+	return strings.Join(res, ",")
+}
+
+var (
+	previousScan = []templates.Finding{
+		finding("git-config", "http://a.test"),
+		finding("env-file", "http://a.test"),
+		finding("admin-panel", "http://b.test/"),
+	}
+	currentScan = []templates.Finding{
+		finding("git-config", "HTTP://A.TEST/"),
+		finding("admin-panel", "http://b.test"),
+		finding("xss", "http://b.test"),
+	}
+)
+
+func TestCompare(t *testing.T) {
+	newFindings, resolved := Compare(currentScan, NewBaseline(previousScan))
+	if got := keys(newFindings); got != "xss@http://b.test" {
+		t.Errorf("new = %s, want only xss", got)
+	}
+	if got := keys(resolved); got != "env-file@http://a.test" {
+		t.Errorf("resolved = %s, want only env-file", got)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	after := append(append([]templates.Finding(nil), currentScan...), finding("xss", "http://B.test/"))
+	newFindings, resolved, unchanged := Diff(previousScan, after)
+	if got := keys(newFindings); got != "xss@http://b.test" {
+		t.Errorf("new = %s, want only xss", got)
+	}
+	if got := keys(resolved); got != "env-file@http://a.test" {
+		t.Errorf("resolved = %s, want only env-file", got)
+	}
+	if got := keys(unchanged); got != "admin-panel@http://b.test,git-config@HTTP://A.TEST/" {
+		t.Errorf("unchanged = %s, want admin-panel and git-config as found by the later scan", got)
+	}
+	if len(newFindings)+len(unchanged) != 3 || len(resolved)+len(unchanged) != len(previousScan) {
+		t.Errorf("%d new, %d resolved, %d unchanged don't add up to the scans", len(newFindings), len(resolved), len(unchanged))
+	}
+}
+
+func TestCompareEmptyBaseline(t *testing.T) {
+	newFindings, resolved := Compare(currentScan, NewBaseline(nil))
+	if len(newFindings) != len(currentScan) || len(resolved) != 0 {
+		t.Errorf("new = %d, resolved = %d against an empty baseline", len(newFindings), len(resolved))
+	}
+}
+
+func TestNewBaselineDeduplicates(t *testing.T) {
+	b := NewBaseline([]templates.Finding{finding("a", "http://x.test"), finding("a", "http://X.test/"), finding("a", "http://y.test")})
+	if len(b.Findings()) != 2 {
+		t.Errorf("baseline has %d findings, want 2", len(b.Findings()))
+	}
+}
+
+func TestNormalizeTarget(t *testing.T) {
+	tests := map[string]string{
+		"HTTP://Example.COM/":       "http://example.com",
+		"http://example.com/admin/": "http://example.com/admin",
+		"http://example.com/a#frag": "http://example.com/a",
+		" http://example.com ":      "http://example.com",
+		"Example.com/":              "example.com",
+		"http://example.com/a?q=1":  "http://example.com/a?q=1",
+	}
+	for in, want := range tests {
+		if got := normalizeTarget(in); got != want {
+			t.Errorf("normalizeTarget(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	if err := NewBaseline(previousScan).Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if keys(loaded.Findings()) != keys(previousScan) {
+		t.Errorf("loaded %s, want %s", keys(loaded.Findings()), keys(previousScan))
+	}
+
+	if err := NewBaseline(nil).Save(path); err != nil {
+		t.Fatalf("Save empty: %v", err)
+	}
+	if loaded, err := Load(path); err != nil || len(loaded.Findings()) != 0 {
+		t.Errorf("empty baseline loaded as %v, %v", loaded, err)
+	}
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("Load of a missing file didn't fail")
+	}
+}
@@ -2,7 +2,11 @@
 package config
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
 	"os"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -15,27 +19,95 @@ type LicenseConfig struct {
 
 // AppConfig holds app-related settings
 type AppConfig struct {
-	ID string `yaml:"id"`
+	ID          string `yaml:"id"`
+	MetricsAddr string `yaml:"metrics_addr"`
 }
 
 // LoggingConfig holds logging-related settings
 type LoggingConfig struct {
-	Path string `yaml:"path"`
+	Path   string `yaml:"path"`
+	Format string `yaml:"format"`
+}
+
+// TracingConfig holds OpenTelemetry tracing settings
+type TracingConfig struct {
+	Endpoint string `yaml:"endpoint"`
+}
+
+// TemplatesConfig holds template library settings
+type TemplatesConfig struct {
+	Dir       string `yaml:"dir"`
+	UpdateURL string `yaml:"update_url"`
+	HMACKey   string `yaml:"hmac_key"`
+}
+
+// SecurityConfig holds template signing settings
+type SecurityConfig struct {
+	TemplateSigningKey string `yaml:"template_signing_key"`
+}
+
+// ScannerConfig holds the advanced scan settings applied in the GUI, zero values keep the defaults
+type ScannerConfig struct {
+	Workers              int `yaml:"workers,omitempty"`
+	HeadlessTabs         int `yaml:"headless_tabs,omitempty"`
+	RateLimiterFrequency int `yaml:"rate_limiter_frequency,omitempty"`
+	RateLimiterBurstSize int `yaml:"rate_limiter_burst_size,omitempty"`
+}
+
+// JiraConfig holds the Jira project issues are created in for high and critical findings, Token is an API
+// token of the Email account
+type JiraConfig struct {
+	URL     string `yaml:"url"`
+	Email   string `yaml:"email"`
+	Token   string `yaml:"token"`
+	Project string `yaml:"project"`
+}
+
+// GitHubConfig holds the repository issues are created in for findings, Token is a personal access token and
+// APIURL overrides the GitHub API endpoint for GitHub Enterprise
+type GitHubConfig struct {
+	Token  string `yaml:"token"`
+	Owner  string `yaml:"owner"`
+	Repo   string `yaml:"repo"`
+	APIURL string `yaml:"api_url,omitempty"`
+}
+
+// APIConfig holds the REST API server settings. Clients authenticate with Token as a bearer token, finished jobs
+// are dropped JobTTL after they end
+type APIConfig struct {
+	Addr       string        `yaml:"addr"`
+	Token      string        `yaml:"token"`
+	JobTimeout time.Duration `yaml:"job_timeout,omitempty"`
+	JobTTL     time.Duration `yaml:"job_ttl,omitempty"`
 }
 
 // Config aggregates all service configurations
 type Config struct {
-	License LicenseConfig `yaml:"license"`
-	App     AppConfig     `yaml:"app"`
-	Logging LoggingConfig `yaml:"logging"`
+	License   LicenseConfig   `yaml:"license"`
+	App       AppConfig       `yaml:"app"`
+	Logging   LoggingConfig   `yaml:"logging"`
+	Tracing   TracingConfig   `yaml:"tracing"`
+	Templates TemplatesConfig `yaml:"templates"`
+	Security  SecurityConfig  `yaml:"security"`
+	Scanner   ScannerConfig   `yaml:"scanner"`
+	Jira      JiraConfig      `yaml:"jira"`
+	GitHub    GitHubConfig    `yaml:"github"`
+	API       APIConfig       `yaml:"api"`
 }
 
+// DefaultPath is the configuration file the application loads and saves
+const DefaultPath = "config.yaml"
+
 // LoadConfig loads the configuration from the given YAML file path
 func LoadConfig(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
+	data, err = resolveEnvVars(data)
+	if err != nil {
+		return nil, err
+	}
 	var cfg Config
 	err = yaml.Unmarshal(data, &cfg)
 	if err != nil {
@@ -43,3 +115,59 @@ func LoadConfig(path string) (*Config, error) {
 	}
 	return &cfg, nil
 }
+
+// SaveScannerConfig replaces the scanner section of the YAML file at path with scanCfg, replacing the file
+// atomically. The other sections, comments and environment variable references are kept as written so
+// that no secret resolved by LoadConfig ends up in the file
+func SaveScannerConfig(path string, scanCfg ScannerConfig) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("%s: top level is not a mapping", path)
+	}
+	var value yaml.Node
+	if err := value.Encode(scanCfg); err != nil {
+		return err
+	}
+	setMappingValue(root, "scanner", &value)
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, buf.Bytes(), 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
+
+// setMappingValue sets key of the mapping node to value, appending the key when it's missing
+func setMappingValue(mapping *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content[i+1] = value
+			return
+		}
+	}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const savedConfig = `# license server used by the scanner
+license:
+  server_url: https://license.example.com
+  key: ${NUCLEI_LICENSE_KEY}
+scanner:
+  workers: 4
+  headless_tabs: 10
+`
+
+func TestSaveScannerConfig(t *testing.T) {
+	t.Setenv("NUCLEI_LICENSE_KEY", "secret-license-key")
+	tests := []struct {
+		name     string
+		original string
+	}{
+		{name: "existing scanner section", original: savedConfig},
+		{name: "missing scanner section", original: savedConfig[:strings.Index(savedConfig, "scanner:")]},
+		{name: "missing file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if tt.original != "" {
+				if err := os.WriteFile(path, []byte(tt.original), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want := ScannerConfig{Workers: 16, HeadlessTabs: 5, RateLimiterFrequency: 20, RateLimiterBurstSize: 50}
+			if err := SaveScannerConfig(path, want); err != nil {
+				t.Fatalf("SaveScannerConfig: %v", err)
+			}
+
+			cfg, err := LoadConfig(path)
+			if err != nil {
+				t.Fatalf("LoadConfig: %v", err)
+			}
+			if cfg.Scanner != want {
+				t.Errorf("scanner config read back = %+v, want %+v", cfg.Scanner, want)
+			}
+			if tt.original == "" {
+				return
+			}
+			if cfg.License.Key != "secret-license-key" {
+				t.Errorf("license key = %q, want the environment value", cfg.License.Key)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			saved := string(data)
+			if strings.Contains(saved, "secret-license-key") || !strings.Contains(saved, "${NUCLEI_LICENSE_KEY}") {
+				t.Errorf("saved config resolved the environment reference:\n%s", saved)
+			}
+			if !strings.Contains(saved, "# license server used by the scanner") {
+				t.Errorf("saved config lost the comment:\n%s", saved)
+			}
+			if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+				t.Errorf("temporary file left behind: %v", err)
+			}
+		})
+	}
+}
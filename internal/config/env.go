@@ -0,0 +1,56 @@
+// Package config - environment variable interpolation
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// envVarPattern matches $$ escapes and ${NAME} / ${NAME:-default} references
+var envVarPattern = regexp.MustCompile(`\$\$|\$\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`)
+
+// UnresolvedEnvError lists required variables that are not set in the environment
+type UnresolvedEnvError struct {
+	Vars []string
+}
+
+// Error implements the error interface
+func (e *UnresolvedEnvError) Error() string {
+	return fmt.Sprintf("unresolved environment variables: %s", strings.Join(e.Vars, ", "))
+}
+
+// resolveEnvVars substitutes ${NAME} and ${NAME:-default} references with environment values.
+// $$ produces a literal dollar sign, anything else that looks like ${ is left untouched
+func resolveEnvVars(data []byte) ([]byte, error) {
+	var missing []string
+	seen := make(map[string]bool)
+
+	out := envVarPattern.ReplaceAllFunc(data, func(match []byte) []byte {
+		if string(match) == "$$" {
+			return []byte("$")
+		}
+		groups := envVarPattern.FindSubmatch(match)
+		name := string(groups[1])
+		hasDefault := strings.Contains(string(match), ":-")
+
+		value, ok := os.LookupEnv(name)
+		if hasDefault && (!ok || value == "") {
+			return groups[2]
+		}
+		if !ok {
+			if !seen[name] {
+				seen[name] = true
+				missing = append(missing, name)
+			}
+			return match
+		}
+		return []byte(value)
+	})
+
+	if len(missing) > 0 {
+		return nil, &UnresolvedEnvError{Vars: missing}
+	}
+	return out, nil
+}
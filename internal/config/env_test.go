@@ -0,0 +1,73 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestResolveEnvVars(t *testing.T) {
+	t.Setenv("NUCLEI_KEY", "secret")
+	t.Setenv("EMPTY_VAR", "")
+	t.Setenv("VAR_2_NAME_3", "digits")
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "simple substitution", in: "key: ${NUCLEI_KEY}", want: "key: secret"},
+		{name: "default when unset", in: "url: ${UNSET_VAR_FOR_TEST:-http://localhost}", want: "url: http://localhost"},
+		{name: "default when empty", in: "v: ${EMPTY_VAR:-fallback}", want: "v: fallback"},
+		{name: "set value wins over default", in: "v: ${NUCLEI_KEY:-fallback}", want: "v: secret"},
+		{name: "empty default", in: "v: '${UNSET_VAR_FOR_TEST:-}'", want: "v: ''"},
+		{name: "escaped dollar", in: "price: $$5 and $${NUCLEI_KEY}", want: "price: $5 and ${NUCLEI_KEY}"},
+		{name: "underscores and digits", in: "v: ${VAR_2_NAME_3}", want: "v: digits"},
+		{name: "regex-like text left untouched", in: `pattern: "\${1,3}" other: ${ not a var }`, want: `pattern: "\${1,3}" other: ${ not a var }`},
+		{name: "unterminated reference", in: "v: ${NUCLEI_KEY", want: "v: ${NUCLEI_KEY"},
+		{name: "lone dollar", in: "v: $NUCLEI_KEY $", want: "v: $NUCLEI_KEY $"},
+		{name: "multiple references", in: "${NUCLEI_KEY}-${VAR_2_NAME_3}", want: "secret-digits"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveEnvVars([]byte(tt.in))
+			if err != nil {
+				t.Fatalf("resolveEnvVars: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("resolveEnvVars(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveEnvVarsMissing(t *testing.T) {
+	os.Unsetenv("MISSING_ONE")
+	os.Unsetenv("MISSING_TWO")
+	_, err := resolveEnvVars([]byte("a: ${MISSING_ONE}\nb: ${MISSING_TWO}\nc: ${MISSING_ONE}\nd: ${MISSING_THREE:-ok}"))
+	var envErr *UnresolvedEnvError
+	if !errors.As(err, &envErr) {
+		t.Fatalf("error = %v, want *UnresolvedEnvError", err)
+	}
+	if want := []string{"MISSING_ONE", "MISSING_TWO"}; !reflect.DeepEqual(envErr.Vars, want) {
+		t.Errorf("unresolved vars = %v, want %v", envErr.Vars, want)
+	}
+}
+
+func TestLoadConfigResolvesEnvVars(t *testing.T) {
+	t.Setenv("LICENSE_KEY_FOR_TEST", "abc123")
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	data := "license:\n  key: ${LICENSE_KEY_FOR_TEST}\n  server_url: ${LICENSE_URL_FOR_TEST:-https://license.example.com}\n"
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.License.Key != "abc123" || cfg.License.ServerURL != "https://license.example.com" {
+		t.Errorf("license = %+v", cfg.License)
+	}
+}
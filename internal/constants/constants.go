@@ -14,6 +14,10 @@ const (
 	TxtFileFormat = ".txt"
 	YmlFileFormat = ".yml"
 	YamlFileFormat = ".yaml"
+	// Storage
+	DatabaseFile = "nuclei.db"
+	BaselineFile = "baseline.json"
+	TargetCacheFile = "targets.db"
 	// Permissions
 	FilePerm = 0o600
 	DirPerm = 0o750
@@ -0,0 +1,73 @@
+// Package dedup remembers which target and template pairs were already scanned
+package dedup
+
+import (
+	"encoding/binary"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/artnikel/nuclei/internal/constants"
+)
+
+// bucketName is the bbolt bucket holding the last check time of every target and template pair
+var bucketName = []byte("checked")
+
+// TargetCache stores when a target was last checked with a template
+type TargetCache struct {
+	db *bolt.DB
+}
+
+// NewTargetCache opens or creates the cache database at path
+func NewTargetCache(path string) (*TargetCache, error) {
+	db, err := bolt.Open(path, constants.FilePerm, &bolt.Options{Timeout: constants.FiveSecTimeout})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(bucketName)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &TargetCache{db: db}, nil
+}
+
+// ShouldSkip reports whether the pair was checked less than interval ago, a non-positive interval never skips
+func (c *TargetCache) ShouldSkip(target, templateID string, interval time.Duration) bool {
+	if interval <= 0 {
+		return false
+	}
+	var skip bool
+	_ = c.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(bucketName).Get(cacheKey(target, templateID))
+		if len(v) != 8 {
+			return nil
+		}
+		checkedAt := time.Unix(0, int64(binary.BigEndian.Uint64(v)))
+		skip = time.Since(checkedAt) < interval
+		return nil
+	})
+	return skip
+}
+
+// Record stores the current time as the last check of the pair
+func (c *TargetCache) Record(target, templateID string) error {
+	return c.db.Update(func(tx *bolt.Tx) error {
+		v := make([]byte, 8)
+		binary.BigEndian.PutUint64(v, uint64(time.Now().UnixNano()))
+		return tx.Bucket(bucketName).Put(cacheKey(target, templateID), v)
+	})
+}
+
+// Close closes the cache database
+func (c *TargetCache) Close() error {
+	return c.db.Close()
+}
+
+// cacheKey builds the key of a target and template pair
+func cacheKey(target, templateID string) []byte {
+	return []byte(target + "+" + templateID)
+}
@@ -0,0 +1,66 @@
+package dedup
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestCache(t *testing.T) (*TargetCache, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "targets.db")
+	cache, err := NewTargetCache(path)
+	if err != nil {
+		t.Fatalf("NewTargetCache: %v", err)
+	}
+	t.Cleanup(func() { cache.Close() })
+	return cache, path
+}
+
+func TestTargetCache(t *testing.T) {
+	cache, _ := newTestCache(t)
+	if cache.ShouldSkip("http://a.test", "t1", time.Hour) {
+		t.Fatal("unrecorded pair skipped")
+	}
+	if err := cache.Record("http://a.test", "t1"); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		target   string
+		template string
+		interval time.Duration
+		want     bool
+	}{
+		{name: "recorded within interval", target: "http://a.test", template: "t1", interval: time.Hour, want: true},
+		{name: "interval elapsed", target: "http://a.test", template: "t1", interval: time.Nanosecond, want: false},
+		{name: "no interval", target: "http://a.test", template: "t1", interval: 0, want: false},
+		{name: "other template", target: "http://a.test", template: "t2", interval: time.Hour, want: false},
+		{name: "other target", target: "http://b.test", template: "t1", interval: time.Hour, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cache.ShouldSkip(tt.target, tt.template, tt.interval); got != tt.want {
+				t.Errorf("ShouldSkip(%s, %s, %v) = %v, want %v", tt.target, tt.template, tt.interval, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTargetCachePersists(t *testing.T) {
+	cache, path := newTestCache(t)
+	if err := cache.Record("http://a.test", "t1"); err != nil {
+		t.Fatal(err)
+	}
+	cache.Close()
+
+	reopened, err := NewTargetCache(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer reopened.Close()
+	if !reopened.ShouldSkip("http://a.test", "t1", time.Hour) {
+		t.Error("recorded pair forgotten after reopening the cache")
+	}
+}
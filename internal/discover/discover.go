@@ -0,0 +1,142 @@
+// Package discover finds paths disclosed by robots.txt and sitemap.xml
+package discover
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	// maxSitemapDepth limits how deep nested sitemap indexes are followed
+	maxSitemapDepth = 3
+	// maxSitemaps limits the number of sitemap files fetched for one target
+	maxSitemaps = 50
+	// maxBodySize limits the size of a fetched robots.txt or sitemap
+	maxBodySize = 10 << 20
+)
+
+// FetchRobotsTxt returns the paths listed in Allow and Disallow rules of the target robots.txt
+func FetchRobotsTxt(ctx context.Context, baseURL string, client *http.Client) ([]string, error) {
+	robotsURL, err := resolve(baseURL, "/robots.txt")
+	if err != nil {
+		return nil, err
+	}
+	body, err := fetch(ctx, robotsURL, client)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]struct{})
+	var paths []string
+	sc := bufio.NewScanner(bytes.NewReader(body))
+	for sc.Scan() {
+		line := sc.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "allow", "disallow":
+			value = strings.TrimSpace(value)
+			if value == "" {
+				continue
+			}
+			if _, ok := seen[value]; ok {
+				continue
+			}
+			seen[value] = struct{}{}
+			paths = append(paths, value)
+		}
+	}
+	return paths, sc.Err()
+}
+
+// sitemapDoc covers both sitemap indexes and url sets
+type sitemapDoc struct {
+	Sitemaps []struct {
+		Loc string `xml:"loc"`
+	} `xml:"sitemap"`
+	URLs []struct {
+		Loc string `xml:"loc"`
+	} `xml:"url"`
+}
+
+// FetchSitemap returns every <loc> of the target sitemap.xml, following sitemap indexes recursively
+func FetchSitemap(ctx context.Context, baseURL string, client *http.Client) ([]string, error) {
+	sitemapURL, err := resolve(baseURL, "/sitemap.xml")
+	if err != nil {
+		return nil, err
+	}
+	var locs []string
+	fetched := 0
+	err = fetchSitemap(ctx, sitemapURL, client, 0, &fetched, &locs)
+	return locs, err
+}
+
+// fetchSitemap appends the url locations of the sitemap at target to locs
+func fetchSitemap(ctx context.Context, target string, client *http.Client, depth int, fetched *int, locs *[]string) error {
+	if depth > maxSitemapDepth || *fetched >= maxSitemaps {
+		return nil
+	}
+	*fetched++
+
+	body, err := fetch(ctx, target, client)
+	if err != nil {
+		return err
+	}
+	var doc sitemapDoc
+	if err := xml.Unmarshal(body, &doc); err != nil {
+		return fmt.Errorf("failed to parse sitemap %s: %w", target, err)
+	}
+	for _, u := range doc.URLs {
+		if loc := strings.TrimSpace(u.Loc); loc != "" {
+			*locs = append(*locs, loc)
+		}
+	}
+	for _, sm := range doc.Sitemaps {
+		loc := strings.TrimSpace(sm.Loc)
+		if loc == "" {
+			continue
+		}
+		if err := fetchSitemap(ctx, loc, client, depth+1, fetched, locs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolve builds the URL of path on the host of baseURL
+func resolve(baseURL, path string) (string, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return "", err
+	}
+	return (&url.URL{Scheme: u.Scheme, Host: u.Host, Path: path}).String(), nil
+}
+
+// fetch returns the body of a successful GET request
+func fetch(ctx context.Context, target string, client *http.Client) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned status %d", target, resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+}
@@ -0,0 +1,107 @@
+package discover
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+const robotsTxt = `# robots for the test site
+User-agent: *
+Disallow: /admin/
+Disallow: /backup.zip  # old backup
+Allow: /public
+Disallow:
+
+User-agent: Googlebot
+Disallow: /admin/
+Crawl-delay: 10
+Sitemap: /sitemap.xml
+`
+
+// newSiteServer serves robots.txt, a sitemap index and the two sitemaps it lists
+func newSiteServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, robotsTxt)
+	})
+	mux.HandleFunc("/sitemap.xml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap><loc>%[1]s/sitemap-pages.xml</loc></sitemap>
+  <sitemap><loc> %[1]s/sitemap-blog.xml </loc></sitemap>
+</sitemapindex>`, srv.URL)
+	})
+	mux.HandleFunc("/sitemap-pages.xml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>%[1]s/</loc></url>
+  <url><loc>%[1]s/about</loc></url>
+</urlset>`, srv.URL)
+	})
+	mux.HandleFunc("/sitemap-blog.xml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>%s/blog/first-post</loc></url>
+  <url><loc></loc></url>
+</urlset>`, srv.URL)
+	})
+	return srv
+}
+
+func TestFetchRobotsTxt(t *testing.T) {
+	srv := newSiteServer(t)
+	paths, err := FetchRobotsTxt(context.Background(), srv.URL+"/some/page", srv.Client())
+	if err != nil {
+		t.Fatalf("FetchRobotsTxt: %v", err)
+	}
+	if want := []string{"/admin/", "/backup.zip", "/public"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("FetchRobotsTxt = %v, want %v", paths, want)
+	}
+}
+
+func TestFetchSitemap(t *testing.T) {
+	srv := newSiteServer(t)
+	locs, err := FetchSitemap(context.Background(), srv.URL, srv.Client())
+	if err != nil {
+		t.Fatalf("FetchSitemap: %v", err)
+	}
+	want := []string{srv.URL + "/", srv.URL + "/about", srv.URL + "/blog/first-post"}
+	if !reflect.DeepEqual(locs, want) {
+		t.Errorf("FetchSitemap = %v, want %v", locs, want)
+	}
+}
+
+func TestFetchSitemapNestedLimit(t *testing.T) {
+	var fetches int
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		fmt.Fprintf(w, `<sitemapindex><sitemap><loc>%s/sitemap-%d.xml</loc></sitemap></sitemapindex>`, srv.URL, fetches)
+	}))
+	defer srv.Close()
+
+	locs, err := FetchSitemap(context.Background(), srv.URL, srv.Client())
+	if err != nil {
+		t.Fatalf("FetchSitemap: %v", err)
+	}
+	if len(locs) != 0 || fetches != maxSitemapDepth+1 {
+		t.Errorf("self-referencing index: %d locations after %d fetches, want 0 after %d", len(locs), fetches, maxSitemapDepth+1)
+	}
+}
+
+func TestFetchMissing(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+	if _, err := FetchRobotsTxt(context.Background(), srv.URL, srv.Client()); err == nil {
+		t.Error("FetchRobotsTxt succeeded on a missing robots.txt")
+	}
+	if _, err := FetchSitemap(context.Background(), srv.URL, srv.Client()); err == nil {
+		t.Error("FetchSitemap succeeded on a missing sitemap.xml")
+	}
+}
@@ -0,0 +1,187 @@
+// Package docs renders documentation for templates from their metadata
+package docs
+
+import (
+	_ "embed"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/artnikel/nuclei/internal/templates"
+)
+
+//go:embed template.md.tmpl
+var defaultPageTemplate string
+
+//go:embed index.md.tmpl
+var defaultIndexTemplate string
+
+// untaggedGroup collects the templates without tags in the index
+const untaggedGroup = "untagged"
+
+// TemplateDoc holds the template metadata shown in the documentation
+type TemplateDoc struct {
+	ID             string   `json:"id"`
+	Name           string   `json:"name"`
+	Severity       string   `json:"severity"`
+	Author         string   `json:"author,omitempty"`
+	Description    string   `json:"description,omitempty"`
+	Tags           []string `json:"tags,omitempty"`
+	CVSSScore      float64  `json:"cvss_score,omitempty"`
+	CVEID          string   `json:"cve_id,omitempty"`
+	RequestTypes   []string `json:"request_types"`
+	ExampleCommand string   `json:"example_command"`
+	FilePath       string   `json:"file_path,omitempty"`
+	DocFile        string   `json:"doc_file"`
+}
+
+// TagGroup lists the templates sharing a tag
+type TagGroup struct {
+	Tag       string
+	Templates []TemplateDoc
+}
+
+// NewTemplateDoc collects the documentation of the template
+func NewTemplateDoc(tmpl *templates.Template) TemplateDoc {
+	authors := tmpl.Authors
+	if tmpl.Info.Author != "" {
+		authors = append([]string{tmpl.Info.Author}, authors...)
+	}
+	return TemplateDoc{
+		ID:             tmpl.ID,
+		Name:           tmpl.Info.Name,
+		Severity:       tmpl.SeverityLevel(),
+		Author:         strings.Join(authors, ", "),
+		Description:    strings.TrimSpace(tmpl.DescriptionText()),
+		Tags:           tmpl.AllTags(),
+		CVSSScore:      tmpl.Info.CVSSScore,
+		CVEID:          tmpl.Info.CVEID,
+		RequestTypes:   requestTypes(tmpl),
+		ExampleCommand: exampleCommand(tmpl),
+		FilePath:       tmpl.FilePath,
+		DocFile:        tmpl.ID + ".md",
+	}
+}
+
+// requestTypes returns the distinct request types of the template, requests without a type are http
+func requestTypes(tmpl *templates.Template) []string {
+	seen := make(map[string]struct{})
+	types := []string{}
+	for _, req := range tmpl.Requests {
+		t := req.Type
+		if t == "" {
+			t = "http"
+		}
+		if _, ok := seen[t]; ok {
+			continue
+		}
+		seen[t] = struct{}{}
+		types = append(types, t)
+	}
+	return types
+}
+
+// exampleCommand returns a CLI invocation running the folder of the template
+func exampleCommand(tmpl *templates.Template) string {
+	dir := "templates"
+	if tmpl.FilePath != "" {
+		dir = filepath.Dir(tmpl.FilePath)
+	}
+	cmd := "nuclei-cli -targets targets.txt -templates " + dir
+	if sev := tmpl.SeverityLevel(); sev != "" {
+		cmd += " -severity " + sev
+	}
+	return cmd
+}
+
+// GroupByTag groups the docs by lowercased tag in alphabetical order, untagged templates come last
+func GroupByTag(docs []TemplateDoc) []TagGroup {
+	groups := make(map[string][]TemplateDoc)
+	for _, d := range docs {
+		seen := make(map[string]struct{})
+		for _, tag := range d.Tags {
+			tag = strings.ToLower(strings.TrimSpace(tag))
+			if _, ok := seen[tag]; ok || tag == "" {
+				continue
+			}
+			seen[tag] = struct{}{}
+			groups[tag] = append(groups[tag], d)
+		}
+		if len(seen) == 0 {
+			groups[untaggedGroup] = append(groups[untaggedGroup], d)
+		}
+	}
+
+	tags := make([]string, 0, len(groups))
+	for tag := range groups {
+		if tag != untaggedGroup {
+			tags = append(tags, tag)
+		}
+	}
+	sort.Strings(tags)
+	if _, ok := groups[untaggedGroup]; ok {
+		tags = append(tags, untaggedGroup)
+	}
+
+	res := make([]TagGroup, 0, len(tags))
+	for _, tag := range tags {
+		res = append(res, TagGroup{Tag: tag, Templates: groups[tag]})
+	}
+	return res
+}
+
+// Renderer renders template pages and the combined index as Markdown
+type Renderer struct {
+	page  *template.Template
+	index *template.Template
+}
+
+// NewRenderer parses the page and index templates from the given files, an empty path uses the built-in template
+func NewRenderer(pageTemplatePath, indexTemplatePath string) (*Renderer, error) {
+	page, err := parseTemplate("page", pageTemplatePath, defaultPageTemplate)
+	if err != nil {
+		return nil, err
+	}
+	index, err := parseTemplate("index", indexTemplatePath, defaultIndexTemplate)
+	if err != nil {
+		return nil, err
+	}
+	return &Renderer{page: page, index: index}, nil
+}
+
+// parseTemplate parses the file at path, or fallback when path is empty
+func parseTemplate(name, path, fallback string) (*template.Template, error) {
+	text := fallback
+	if path != "" {
+		bs, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		text = string(bs)
+	}
+	return template.New(name).Parse(text)
+}
+
+// RenderPage writes the Markdown page of one template
+func (r *Renderer) RenderPage(w io.Writer, doc TemplateDoc) error {
+	return r.page.Execute(w, doc)
+}
+
+// RenderIndex writes the combined Markdown index of all templates grouped by tag
+func (r *Renderer) RenderIndex(w io.Writer, docs []TemplateDoc) error {
+	return r.index.Execute(w, struct {
+		Templates []TemplateDoc
+		Groups    []TagGroup
+	}{Templates: docs, Groups: GroupByTag(docs)})
+}
+
+// WriteCatalog writes the docs as an indented JSON array
+func WriteCatalog(w io.Writer, docs []TemplateDoc) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(docs)
+}
@@ -0,0 +1,19 @@
+// package gui implements the user interface of the project - application menu and about dialog
+package gui
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+
+	"github.com/artnikel/nuclei/internal/templates"
+)
+
+// BuildMainMenu creates the main window menu with the Help > About item
+func BuildMainMenu(a fyne.App, w fyne.Window) *fyne.MainMenu {
+	about := fyne.NewMenuItem("About", func() {
+		dialog.ShowInformation("About", fmt.Sprintf("Nuclei GUI Scanner\nVersion: %s", templates.ToolVersion), w)
+	})
+	return fyne.NewMainMenu(fyne.NewMenu("Help", about))
+}
@@ -0,0 +1,67 @@
+// package gui implements the user interface of the project - drag and drop of files onto the window
+package gui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+
+	"github.com/artnikel/nuclei/internal/constants"
+	"github.com/artnikel/nuclei/internal/templates"
+)
+
+// FileDrop dispatches the files dropped onto a window to the sections registered for them, a nil callback
+// rejects its kind of file
+type FileDrop struct {
+	w fyne.Window
+	// OnTargets receives a dropped .txt targets file
+	OnTargets func(path string)
+	// OnTemplate receives a dropped .yaml or .yml file once it loaded as a template
+	OnTemplate func(path string, tmpl *templates.Template)
+	// OnFolder receives a dropped templates folder
+	OnFolder func(path string)
+}
+
+// NewFileDrop creates a file drop handling the files dropped onto w
+func NewFileDrop(w fyne.Window) *FileDrop {
+	d := &FileDrop{w: w}
+	w.SetOnDropped(d.handle)
+	return d
+}
+
+// handle dispatches every dropped URI and shows an error for the ones no section accepts
+func (d *FileDrop) handle(_ fyne.Position, uris []fyne.URI) {
+	for _, uri := range uris {
+		if err := d.dispatch(uri.Path()); err != nil {
+			dialog.ShowError(err, d.w)
+		}
+	}
+}
+
+// dispatch passes path to the callback of its kind of file
+func (d *FileDrop) dispatch(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	ext := strings.ToLower(filepath.Ext(path))
+	switch {
+	case info.IsDir() && d.OnFolder != nil:
+		d.OnFolder(path)
+	case ext == constants.TxtFileFormat && d.OnTargets != nil:
+		d.OnTargets(path)
+	case (ext == constants.YamlFileFormat || ext == constants.YmlFileFormat) && d.OnTemplate != nil:
+		tmpl, err := templates.LoadTemplate(path)
+		if err != nil {
+			return fmt.Errorf("failed to load template: %w", err)
+		}
+		d.OnTemplate(path, tmpl)
+	default:
+		return fmt.Errorf("unsupported file dropped: %s", filepath.Base(path))
+	}
+	return nil
+}
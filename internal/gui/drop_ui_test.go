@@ -0,0 +1,81 @@
+package gui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/test"
+
+	"github.com/artnikel/nuclei/internal/templates"
+)
+
+func TestFileDrop(t *testing.T) {
+	test.NewTempApp(t)
+	dir := t.TempDir()
+	files := map[string]string{
+		"targets.txt":      "http://a.test\n",
+		"scan.yaml":        scanTemplate,
+		"scan.yml":         scanTemplate,
+		"broken.yaml":      "id: [",
+		"notes.md":         "# notes",
+		"templates/a.yaml": scanTemplate,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w := test.NewTempWindow(t, nil)
+	drop := NewFileDrop(w)
+	var gotTargets, gotFolder string
+	var gotTemplates []string
+	drop.OnTargets = func(path string) { gotTargets = path }
+	drop.OnTemplate = func(path string, tmpl *templates.Template) {
+		if tmpl.ID == "gui-scan" {
+			gotTemplates = append(gotTemplates, filepath.Base(path))
+		}
+	}
+	drop.OnFolder = func(path string) { gotFolder = path }
+
+	tests := []struct {
+		name      string
+		path      string
+		wantError bool
+	}{
+		{name: "targets file", path: "targets.txt"},
+		{name: "yaml template", path: "scan.yaml"},
+		{name: "yml template", path: "scan.yml"},
+		{name: "templates folder", path: "templates"},
+		{name: "invalid template", path: "broken.yaml", wantError: true},
+		{name: "unsupported file", path: "notes.md", wantError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for w.Canvas().Overlays().Top() != nil {
+				w.Canvas().Overlays().Remove(w.Canvas().Overlays().Top())
+			}
+			drop.handle(fyne.NewPos(10, 10), []fyne.URI{storage.NewFileURI(filepath.Join(dir, tt.path))})
+			if shown := w.Canvas().Overlays().Top() != nil; shown != tt.wantError {
+				t.Errorf("error dialog shown = %v, want %v", shown, tt.wantError)
+			}
+		})
+	}
+
+	if gotTargets != filepath.Join(dir, "targets.txt") {
+		t.Errorf("targets file = %q", gotTargets)
+	}
+	if len(gotTemplates) != 2 || gotTemplates[0] != "scan.yaml" || gotTemplates[1] != "scan.yml" {
+		t.Errorf("templates = %v, want scan.yaml and scan.yml", gotTemplates)
+	}
+	if gotFolder != filepath.Join(dir, "templates") {
+		t.Errorf("templates folder = %q", gotFolder)
+	}
+}
@@ -0,0 +1,73 @@
+// package gui implements the user interface of the project - export of scan results
+package gui
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/artnikel/nuclei/internal/logging"
+	"github.com/artnikel/nuclei/internal/output"
+	"github.com/artnikel/nuclei/internal/templates"
+)
+
+// exportFormats are the options of the export dialog, their lowercase names are output formats
+var exportFormats = []string{"JSON", "CSV", "HTML"}
+
+// newExportResultsButton creates a button exporting the findings returned by findings into a file
+func newExportResultsButton(w fyne.Window, findings func() []templates.Finding, logger *logging.Logger) *widget.Button {
+	return widget.NewButton("Export Results", func() {
+		showExportDialog(w, findings(), logger)
+	})
+}
+
+// showExportDialog asks for the export format and the destination file and writes the findings into it
+func showExportDialog(w fyne.Window, findings []templates.Finding, logger *logging.Logger) {
+	if len(findings) == 0 {
+		dialog.ShowInformation("Export Results", "There are no results to export", w)
+		return
+	}
+	formatRadio := widget.NewRadioGroup(exportFormats, nil)
+	formatRadio.SetSelected(exportFormats[0])
+	dialog.NewCustomConfirm("Export Results", "Export", "Cancel", formatRadio, func(ok bool) {
+		if !ok {
+			return
+		}
+		format := strings.ToLower(formatRadio.Selected)
+		fd := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+			if err != nil || writer == nil {
+				return
+			}
+			defer writer.Close()
+
+			if err := writeFindings(writer, format, findings); err != nil {
+				logger.Error("Failed to export results", slog.String("format", format), slog.Any("error", err))
+				dialog.ShowError(fmt.Errorf("failed to export results: %w", err), w)
+				return
+			}
+			dialog.ShowInformation("Export Results", "Results saved to "+writer.URI().Path(), w)
+		}, w)
+		fd.SetFileName("results." + format)
+		fd.Resize(fyne.NewSize(800, 600))
+		fd.Show()
+	}, w).Show()
+}
+
+// writeFindings writes the findings into dst in the output format
+func writeFindings(dst io.Writer, format string, findings []templates.Finding) error {
+	writer, err := output.NewWriter(format, dst)
+	if err != nil {
+		return err
+	}
+	for i := range findings {
+		if err := writer.Write(&findings[i]); err != nil {
+			return err
+		}
+	}
+	return writer.Close()
+}
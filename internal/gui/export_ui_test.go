@@ -0,0 +1,53 @@
+package gui
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/artnikel/nuclei/internal/templates"
+)
+
+func TestWriteFindings(t *testing.T) {
+	findings := []templates.Finding{
+		{TemplateID: "git-config", Target: "http://a.test", Severity: "high"},
+		{TemplateID: "exposed-env", Target: "http://b.test", Severity: "critical"},
+		{TemplateID: "tech-detect", Target: "http://c.test", Severity: "info"},
+	}
+	for _, option := range exportFormats {
+		t.Run(option, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeFindings(&buf, strings.ToLower(option), findings); err != nil {
+				t.Fatalf("writeFindings: %v", err)
+			}
+			out := buf.String()
+			for _, f := range findings {
+				if !strings.Contains(out, f.TemplateID) || !strings.Contains(out, f.Target) {
+					t.Errorf("%s export misses %s on %s:\n%s", option, f.TemplateID, f.Target, out)
+				}
+			}
+			switch option {
+			case "JSON":
+				var decoded []templates.Finding
+				if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil || len(decoded) != len(findings) {
+					t.Errorf("JSON export = %d findings, %v, want %d", len(decoded), err, len(findings))
+				}
+			case "CSV":
+				records, err := csv.NewReader(&buf).ReadAll()
+				if err != nil || len(records) != len(findings)+1 {
+					t.Errorf("CSV export = %d records, %v, want a header and %d rows", len(records), err, len(findings))
+				}
+			case "HTML":
+				if !strings.Contains(out, "<html") {
+					t.Errorf("HTML export is not a page:\n%s", out)
+				}
+			}
+		})
+	}
+
+	if err := writeFindings(&bytes.Buffer{}, "pdf", findings); err == nil {
+		t.Error("unknown export format accepted")
+	}
+}
@@ -0,0 +1,172 @@
+// package gui implements the user interface of the project - live scan findings list
+package gui
+
+import (
+	"context"
+	"image/color"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/artnikel/nuclei/internal/templates"
+)
+
+const (
+	// severityIconSize is the size of the severity circle of a findings list row
+	severityIconSize = 12
+	// allSeverities is the severity filter option showing every finding
+	allSeverities = "All"
+)
+
+// findingsList is the list of the findings of the running scan, it shows the findings matching the filter and
+// is only accessed on the main goroutine
+type findingsList struct {
+	allFindings      []templates.Finding
+	filteredFindings []templates.Finding
+	query            string
+	severity         string
+	list             *widget.List
+}
+
+// newFindingsList creates an empty findings list whose rows open the finding details in w
+func newFindingsList(w fyne.Window) *findingsList {
+	fl := &findingsList{severity: allSeverities}
+	fl.list = widget.NewList(
+		func() int { return len(fl.filteredFindings) },
+		func() fyne.CanvasObject {
+			icon := container.NewGridWrap(fyne.NewSquareSize(severityIconSize), canvas.NewCircle(color.Transparent))
+			return container.NewBorder(nil, nil, container.NewHBox(icon, widget.NewLabel("")), nil, widget.NewLabel(""))
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			f := fl.filteredFindings[id]
+			row := obj.(*fyne.Container)
+			target := row.Objects[0].(*widget.Label)
+			left := row.Objects[1].(*fyne.Container)
+			circle := left.Objects[0].(*fyne.Container).Objects[0].(*canvas.Circle)
+			circle.FillColor = severityColor(f.Severity)
+			circle.Refresh()
+			left.Objects[1].(*widget.Label).SetText(f.TemplateID)
+			target.SetText(f.Target)
+		},
+	)
+	fl.list.OnSelected = func(id widget.ListItemID) {
+		fl.list.Unselect(id)
+		showFindingDetails(w, fl.filteredFindings[id])
+	}
+	return fl
+}
+
+// Add appends the finding to the list
+func (fl *findingsList) Add(f templates.Finding) {
+	fl.allFindings = append(fl.allFindings, f)
+	if fl.matches(f) {
+		fl.filteredFindings = append(fl.filteredFindings, f)
+		fl.list.Refresh()
+	}
+}
+
+// Clear removes every finding from the list
+func (fl *findingsList) Clear() {
+	fl.allFindings = nil
+	fl.filteredFindings = nil
+	fl.list.Refresh()
+}
+
+// SetFilter shows only the findings whose template ID or target contains query and, unless severity is
+// allSeverities, having that severity
+func (fl *findingsList) SetFilter(query, severity string) {
+	fl.query = strings.ToLower(strings.TrimSpace(query))
+	fl.severity = severity
+	fl.filteredFindings = nil
+	for _, f := range fl.allFindings {
+		if fl.matches(f) {
+			fl.filteredFindings = append(fl.filteredFindings, f)
+		}
+	}
+	fl.list.Refresh()
+}
+
+// matches reports whether the finding passes the filter
+func (fl *findingsList) matches(f templates.Finding) bool {
+	if fl.severity != allSeverities && !strings.EqualFold(f.Severity, fl.severity) {
+		return false
+	}
+	return fl.query == "" ||
+		strings.Contains(strings.ToLower(f.TemplateID), fl.query) ||
+		strings.Contains(strings.ToLower(f.Target), fl.query)
+}
+
+// filterBar creates the search entry, severity select and clear button filtering the list as they change
+func (fl *findingsList) filterBar() fyne.CanvasObject {
+	searchEntry := widget.NewEntry()
+	searchEntry.SetPlaceHolder("Search template ID or target")
+	severitySelect := widget.NewSelect([]string{allSeverities, "critical", "high", "medium", "low", "info"}, nil)
+	severitySelect.SetSelected(allSeverities)
+
+	searchEntry.OnChanged = func(query string) {
+		fl.SetFilter(query, severitySelect.Selected)
+	}
+	severitySelect.OnChanged = func(severity string) {
+		fl.SetFilter(searchEntry.Text, severity)
+	}
+	clearBtn := widget.NewButton("Clear", func() {
+		searchEntry.SetText("")
+		severitySelect.SetSelected(allSeverities)
+	})
+	return container.NewBorder(nil, nil, nil, container.NewHBox(severitySelect, clearBtn), searchEntry)
+}
+
+// collect adds the findings received from findingsCh to the list until the channel is closed or ctx is done
+func (fl *findingsList) collect(ctx context.Context, a fyne.App, findingsCh <-chan templates.Finding) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case f, ok := <-findingsCh:
+			if !ok {
+				return
+			}
+			a.Driver().DoFromGoroutine(func() {
+				fl.Add(f)
+			}, false)
+		}
+	}
+}
+
+// showFindingDetails opens a dialog with the description, matched URL and extracted values of the finding
+func showFindingDetails(w fyne.Window, f templates.Finding) {
+	extracted := "-"
+	if len(f.ExtractedValues) > 0 {
+		extracted = formatExtractedValues(f.ExtractedValues)
+	}
+	description := widget.NewLabel(f.Description)
+	description.Wrapping = fyne.TextWrapWord
+	content := widget.NewForm(
+		widget.NewFormItem("Name", widget.NewLabel(f.Name)),
+		widget.NewFormItem("Severity", widget.NewLabel(f.Severity)),
+		widget.NewFormItem("Description", description),
+		widget.NewFormItem("Matched URL", widget.NewLabel(f.MatchedURL)),
+		widget.NewFormItem("Extracted values", widget.NewLabel(extracted)),
+	)
+	d := dialog.NewCustom(f.TemplateID, "Close", content, w)
+	d.Resize(fyne.NewSize(500, 300))
+	d.Show()
+}
+
+// severityColor returns the color of the severity circle of a finding
+func severityColor(severity string) color.Color {
+	switch strings.ToLower(severity) {
+	case "critical":
+		return color.NRGBA{R: 0xd3, G: 0x2f, B: 0x2f, A: 0xff}
+	case "high":
+		return color.NRGBA{R: 0xf5, G: 0x7c, B: 0x00, A: 0xff}
+	case "medium":
+		return color.NRGBA{R: 0xfb, G: 0xc0, B: 0x2d, A: 0xff}
+	default:
+		return color.NRGBA{R: 0x19, G: 0x76, B: 0xd2, A: 0xff}
+	}
+}
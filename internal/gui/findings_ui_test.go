@@ -0,0 +1,102 @@
+package gui
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/artnikel/nuclei/internal/templates"
+)
+
+func TestFindingsList(t *testing.T) {
+	a := test.NewTempApp(t)
+	fl := newFindingsList(a.NewWindow("findings"))
+
+	findingsCh := make(chan templates.Finding)
+	done := make(chan struct{})
+	go func() {
+		fl.collect(context.Background(), a, findingsCh)
+		close(done)
+	}()
+	for _, f := range []templates.Finding{
+		{TemplateID: "git-config", Target: "http://a.test", Severity: "high"},
+		{TemplateID: "exposed-env", Target: "http://b.test", Severity: "critical"},
+		{TemplateID: "tech-detect", Target: "http://a.test", Severity: "info"},
+	} {
+		findingsCh <- f
+	}
+	close(findingsCh)
+	<-done
+
+	var length int
+	fyne.DoAndWait(func() { length = fl.list.Length() })
+	if length != 3 {
+		t.Fatalf("list length = %d, want 3", length)
+	}
+
+	fl.SetFilter("", "critical")
+	if n := fl.list.Length(); n != 1 || fl.filteredFindings[0].TemplateID != "exposed-env" {
+		t.Errorf("critical filter = %d rows %v, want exposed-env", n, fl.filteredFindings)
+	}
+	fl.SetFilter("A.TEST", allSeverities)
+	if n := fl.list.Length(); n != 2 {
+		t.Errorf("target filter = %d rows, want 2", n)
+	}
+	fl.SetFilter("", allSeverities)
+
+	fl.Clear()
+	if n := fl.list.Length(); n != 0 || len(fl.allFindings) != 0 {
+		t.Errorf("after Clear: %d rows, %d findings, want none", n, len(fl.allFindings))
+	}
+}
+
+func TestFindingsFilterBar(t *testing.T) {
+	a := test.NewTempApp(t)
+	fl := newFindingsList(a.NewWindow("findings"))
+	bar := fl.filterBar()
+	severitySelect, ok := findWidget[*widget.Select](bar)
+	if !ok {
+		t.Fatal("severity select not found in the filter bar")
+	}
+	searchEntry, ok := findWidget[*widget.Entry](bar)
+	if !ok {
+		t.Fatal("search entry not found in the filter bar")
+	}
+	clearBtn, ok := findWidget[*widget.Button](bar)
+	if !ok {
+		t.Fatal("clear button not found in the filter bar")
+	}
+
+	severities := []string{"critical", "high", "info"}
+	for i := 0; i < 10; i++ {
+		fl.Add(templates.Finding{
+			TemplateID: fmt.Sprintf("template-%d", i),
+			Target:     fmt.Sprintf("http://host%d.test", i%2),
+			Severity:   severities[i%3],
+		})
+	}
+
+	severitySelect.SetSelected("critical")
+	if n := fl.list.Length(); n != 4 {
+		t.Errorf("critical filter = %d rows, want 4", n)
+	}
+	for _, f := range fl.filteredFindings {
+		if f.Severity != "critical" {
+			t.Errorf("critical filter shows %s with severity %s", f.TemplateID, f.Severity)
+		}
+	}
+
+	test.Type(searchEntry, "host1")
+	if n := fl.list.Length(); n != 2 {
+		t.Errorf("critical filter on host1 = %d rows, want 2", n)
+	}
+
+	test.Tap(clearBtn)
+	if n := fl.list.Length(); n != 10 || searchEntry.Text != "" || severitySelect.Selected != allSeverities {
+		t.Errorf("after Clear: %d rows, search %q, severity %q, want all 10", n, searchEntry.Text, severitySelect.Selected)
+	}
+}
@@ -0,0 +1,213 @@
+// package gui implements the user interface of the project - scan history section
+package gui
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/artnikel/nuclei/internal/baseline"
+	"github.com/artnikel/nuclei/internal/constants"
+	"github.com/artnikel/nuclei/internal/logging"
+	"github.com/artnikel/nuclei/internal/report"
+	"github.com/artnikel/nuclei/internal/storage"
+	"github.com/artnikel/nuclei/internal/templates"
+)
+
+// historyDateLayout is the date format accepted by the history filter fields
+const historyDateLayout = "2006-01-02"
+
+// BuildHistorySection creates the UI section for browsing previously saved findings
+func BuildHistorySection(a fyne.App, w fyne.Window, store storage.Store, logger *logging.Logger) fyne.CanvasObject {
+	severitySelect := widget.NewSelect([]string{"all", "critical", "high", "medium", "low", "info"}, nil)
+	severitySelect.SetSelected("all")
+
+	templateIDEntry := widget.NewEntry()
+	templateIDEntry.SetPlaceHolder("Template ID")
+
+	fromEntry := widget.NewEntry()
+	fromEntry.SetPlaceHolder(historyDateLayout)
+	toEntry := widget.NewEntry()
+	toEntry.SetPlaceHolder(historyDateLayout)
+
+	resultsOutput := widget.NewMultiLineEntry()
+	resultsOutput.SetMinRowsVisible(15)
+	resultsOutput.Wrapping = fyne.TextWrapWord
+
+	findingsView := widget.NewRichText()
+	findingsView.Wrapping = fyne.TextWrapWord
+
+	var lastFindings []templates.Finding
+
+	searchBtn := widget.NewButton("Search", func() {
+		if store == nil {
+			dialog.ShowError(fmt.Errorf("scan history storage is not available"), w)
+			return
+		}
+		filter, err := buildFindingFilter(severitySelect.Selected, templateIDEntry.Text, fromEntry.Text, toEntry.Text)
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		findings, err := store.Query(filter)
+		if err != nil {
+			logger.Error("Failed to query scan history", slog.Any("error", err))
+			dialog.ShowError(err, w)
+			return
+		}
+
+		lastFindings = findings
+		segments := []widget.RichTextSegment{
+			&widget.TextSegment{Text: fmt.Sprintf("Found %d findings", len(findings)), Style: widget.RichTextStyleParagraph},
+		}
+		for _, f := range findings {
+			segments = append(segments, findingSegments(f)...)
+		}
+		findingsView.Segments = segments
+		findingsView.Refresh()
+		resultsOutput.SetText("")
+	})
+
+	reportBtn := widget.NewButton("Generate Report", func() {
+		if len(lastFindings) == 0 {
+			dialog.ShowInformation("Generate Report", "Search for findings first", w)
+			return
+		}
+		saveHTMLReport(w, lastFindings, logger)
+	})
+
+	setBaselineBtn := widget.NewButton("Set Baseline", func() {
+		if err := baseline.NewBaseline(lastFindings).Save(constants.BaselineFile); err != nil {
+			logger.Error("Failed to save baseline", slog.Any("error", err))
+			dialog.ShowError(fmt.Errorf("failed to save baseline: %w", err), w)
+			return
+		}
+		dialog.ShowInformation("Set Baseline", fmt.Sprintf("Baseline saved with %d findings", len(lastFindings)), w)
+	})
+
+	compareBtn := widget.NewButton("Compare to Baseline", func() {
+		b, err := baseline.Load(constants.BaselineFile)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("failed to load baseline: %w", err), w)
+			return
+		}
+		newFindings, resolved := baseline.Compare(lastFindings, b)
+		lines := []string{fmt.Sprintf("New findings: %d", len(newFindings))}
+		for _, f := range newFindings {
+			lines = append(lines, fmt.Sprintf("+ [%s] %s %s", f.Severity, f.TemplateID, f.Target))
+		}
+		lines = append(lines, fmt.Sprintf("\nResolved findings: %d", len(resolved)))
+		for _, f := range resolved {
+			lines = append(lines, fmt.Sprintf("- [%s] %s %s", f.Severity, f.TemplateID, f.Target))
+		}
+		resultsOutput.SetText(strings.Join(lines, "\n"))
+	})
+
+	section := container.NewVBox(
+		widget.NewLabel("Scan History Section"),
+		widget.NewForm(
+			widget.NewFormItem("Severity", severitySelect),
+			widget.NewFormItem("Template ID", templateIDEntry),
+			widget.NewFormItem("From", fromEntry),
+			widget.NewFormItem("To", toEntry),
+		),
+		container.NewHBox(searchBtn, reportBtn, setBaselineBtn, compareBtn),
+		findingsView,
+		resultsOutput,
+	)
+
+	return container.NewScroll(section)
+}
+
+// findingSegments renders the finding as one line, with the CVSS score as a badge colored by its rating
+func findingSegments(f templates.Finding) []widget.RichTextSegment {
+	var segments []widget.RichTextSegment
+	if f.CVSSScore > 0 {
+		segments = append(segments, &widget.TextSegment{
+			Text: fmt.Sprintf("CVSS %.1f ", f.CVSSScore),
+			Style: widget.RichTextStyle{
+				Inline:    true,
+				ColorName: cvssColorName(f.CVSSScore),
+				TextStyle: fyne.TextStyle{Bold: true},
+			},
+		})
+	}
+	line := fmt.Sprintf("%s [%s] %s %s", f.Timestamp.Local().Format(time.DateTime), f.Severity, f.TemplateID, f.MatchedURL)
+	if f.CVEID != "" {
+		line += " " + f.CVEID
+	}
+	if len(f.ExtractedValues) > 0 {
+		line += " " + formatExtractedValues(f.ExtractedValues)
+	}
+	return append(segments, &widget.TextSegment{Text: line, Style: widget.RichTextStyleParagraph})
+}
+
+// cvssColorName returns the theme color of the CVSS v3 rating of score, matching the HTML report badges
+func cvssColorName(score float64) fyne.ThemeColorName {
+	switch {
+	case score >= 9:
+		return theme.ColorNamePrimary
+	case score >= 7:
+		return theme.ColorNameError
+	case score >= 4:
+		return theme.ColorNameWarning
+	default:
+		return theme.ColorNameSuccess
+	}
+}
+
+// saveHTMLReport asks for a file name and writes the HTML report of findings into it
+func saveHTMLReport(w fyne.Window, findings []templates.Finding, logger *logging.Logger) {
+	fd := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil || writer == nil {
+			return
+		}
+		defer writer.Close()
+
+		reporter, err := report.NewHTMLReporter()
+		if err == nil {
+			err = reporter.Render(writer, findings)
+		}
+		if err != nil {
+			logger.Error("Failed to generate report", slog.Any("error", err))
+			dialog.ShowError(fmt.Errorf("failed to generate report: %w", err), w)
+			return
+		}
+		dialog.ShowInformation("Generate Report", "Report saved to "+writer.URI().Path(), w)
+	}, w)
+	fd.SetFileName("report.html")
+	fd.Resize(fyne.NewSize(800, 600))
+	fd.Show()
+}
+
+// buildFindingFilter converts the history form values into a storage filter
+func buildFindingFilter(severity, templateID, from, to string) (storage.FindingFilter, error) {
+	var filter storage.FindingFilter
+	if severity != "all" {
+		filter.Severity = severity
+	}
+	filter.TemplateID = strings.TrimSpace(templateID)
+
+	if from = strings.TrimSpace(from); from != "" {
+		t, err := time.ParseInLocation(historyDateLayout, from, time.Local)
+		if err != nil {
+			return filter, fmt.Errorf("invalid from date: %w", err)
+		}
+		filter.From = t
+	}
+	if to = strings.TrimSpace(to); to != "" {
+		t, err := time.ParseInLocation(historyDateLayout, to, time.Local)
+		if err != nil {
+			return filter, fmt.Errorf("invalid to date: %w", err)
+		}
+		filter.To = t.Add(24*time.Hour - time.Second)
+	}
+	return filter, nil
+}
@@ -0,0 +1,60 @@
+// package gui implements the user interface of the project - live application log section
+package gui
+
+import (
+	"log/slog"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/artnikel/nuclei/internal/logging"
+)
+
+// maxLogLines is the number of latest log records kept in the log view
+const maxLogLines = 1000
+
+// logLevels maps the options of the level dropdown to the lowest level shown
+var logLevels = map[string]slog.Level{
+	"Debug": slog.LevelDebug,
+	"Info":  slog.LevelInfo,
+	"Warn":  slog.LevelWarn,
+	"Error": slog.LevelError,
+}
+
+// BuildLogsSection creates the UI section showing the records the logger writes to guiWriter as they arrive
+func BuildLogsSection(w fyne.Window, guiWriter *logging.GUIWriter) fyne.CanvasObject {
+	logOutput := widget.NewMultiLineEntry()
+	logOutput.Wrapping = fyne.TextWrapWord
+	logOutput.SetMinRowsVisible(20)
+
+	// lines is only accessed on the main goroutine
+	var lines []string
+	guiWriter.SetSink(func(msg string) {
+		fyne.Do(func() {
+			lines = append(lines, strings.TrimRight(msg, "\n"))
+			if len(lines) > maxLogLines {
+				lines = lines[len(lines)-maxLogLines:]
+			}
+			logOutput.SetText(strings.Join(lines, "\n"))
+			logOutput.CursorRow = len(lines) - 1
+		})
+	})
+
+	clearBtn := widget.NewButton("Clear", func() {
+		lines = nil
+		logOutput.SetText("")
+	})
+
+	levelSelect := widget.NewSelect([]string{"Debug", "Info", "Warn", "Error"}, func(choice string) {
+		guiWriter.SetLevel(logLevels[choice])
+	})
+	levelSelect.SetSelected("Info")
+
+	return container.NewBorder(
+		container.NewHBox(widget.NewLabel("Level"), levelSelect, clearBtn),
+		nil, nil, nil,
+		logOutput,
+	)
+}
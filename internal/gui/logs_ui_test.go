@@ -0,0 +1,65 @@
+package gui
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/artnikel/nuclei/internal/logging"
+)
+
+// findWidget returns the first object of type T in the tree under obj
+func findWidget[T fyne.CanvasObject](obj fyne.CanvasObject) (T, bool) {
+	if w, ok := obj.(T); ok {
+		return w, true
+	}
+	if c, ok := obj.(*fyne.Container); ok {
+		for _, child := range c.Objects {
+			if w, ok := findWidget[T](child); ok {
+				return w, true
+			}
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+func TestLogsSection(t *testing.T) {
+	a := test.NewTempApp(t)
+	w := a.NewWindow("logs")
+	guiWriter := logging.NewGUIWriter()
+	logger, err := logging.NewLogger(t.TempDir(), logging.FormatText, guiWriter)
+	if err != nil {
+		t.Fatalf("NewLogger: %v", err)
+	}
+
+	section := BuildLogsSection(w, guiWriter)
+	output, ok := findWidget[*widget.Entry](section)
+	if !ok {
+		t.Fatal("logs section has no text entry")
+	}
+
+	logger.Info("Template matched", slog.String("template_id", "git-config"))
+	logger.Debug("Dialing target")
+	var text string
+	fyne.DoAndWait(func() { text = output.Text })
+	if !strings.Contains(text, `msg="Template matched"`) || !strings.Contains(text, "template_id=git-config") {
+		t.Errorf("log view = %q, want the info record", text)
+	}
+	if strings.Contains(text, "Dialing target") {
+		t.Errorf("log view = %q, debug record shown at the info level", text)
+	}
+
+	clear, ok := findWidget[*widget.Button](section)
+	if !ok {
+		t.Fatal("logs section has no clear button")
+	}
+	test.Tap(clear)
+	if output.Text != "" {
+		t.Errorf("log view after clear = %q, want empty", output.Text)
+	}
+}
@@ -0,0 +1,38 @@
+// package gui implements the user interface of the project - desktop notifications
+package gui
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"fyne.io/fyne/v2"
+
+	"github.com/artnikel/nuclei/internal/logging"
+)
+
+// notificationsPreferenceKey stores whether scan completion notifications are sent, they are by default
+const notificationsPreferenceKey = "notifications"
+
+// notificationsEnabled reports whether the user enabled scan completion notifications
+func notificationsEnabled(a fyne.App) bool {
+	return a.Preferences().BoolWithFallback(notificationsPreferenceKey, true)
+}
+
+// scanCompleteNotification returns the notification announcing a completed scan
+func scanCompleteNotification(findings int64, duration time.Duration) *fyne.Notification {
+	return &fyne.Notification{
+		Title:   "Scan Complete",
+		Content: fmt.Sprintf("%d findings — %s", findings, duration.Round(time.Second)),
+	}
+}
+
+// notifyScanComplete sends the scan completion notification, platforms without notification support only log it
+func notifyScanComplete(a fyne.App, findings int64, duration time.Duration, logger *logging.Logger) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Warn("Desktop notifications are not supported", slog.Any("error", r))
+		}
+	}()
+	a.SendNotification(scanCompleteNotification(findings, duration))
+}
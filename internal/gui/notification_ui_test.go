@@ -0,0 +1,50 @@
+package gui
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/artnikel/nuclei/internal/logging"
+)
+
+func TestNotifyScanComplete(t *testing.T) {
+	a := test.NewTempApp(t)
+	logger, err := logging.NewLogger(filepath.Join(t.TempDir(), "logs"), logging.FormatText)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &fyne.Notification{Title: "Scan Complete", Content: "3 findings — 1m5s"}
+	test.AssertNotificationSent(t, want, func() {
+		notifyScanComplete(a, 3, 65*time.Second+300*time.Millisecond, logger)
+	})
+}
+
+func TestNotificationsToggle(t *testing.T) {
+	a := test.NewTempApp(t)
+	logger, err := logging.NewLogger(filepath.Join(t.TempDir(), "logs"), logging.FormatText)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !notificationsEnabled(a) {
+		t.Fatal("notifications disabled by default")
+	}
+	section := BuildSettingsSection(a, a.NewWindow("settings"), logger)
+	var toggle *widget.Check
+	for _, obj := range section.(*fyne.Container).Objects {
+		if check, ok := obj.(*widget.Check); ok && check.Text == "Notify when a scan completes" {
+			toggle = check
+		}
+	}
+	if toggle == nil || !toggle.Checked {
+		t.Fatal("notifications toggle missing or unchecked")
+	}
+	test.Tap(toggle)
+	if notificationsEnabled(a) {
+		t.Error("notifications still enabled after unchecking the toggle")
+	}
+}
@@ -2,10 +2,11 @@
 package gui
 
 import (
-	"bufio"
 	"context"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"net/url"
 	"os"
 	"runtime"
 	"strconv"
@@ -22,59 +23,147 @@ import (
 
 	"github.com/artnikel/nuclei/internal/constants"
 	"github.com/artnikel/nuclei/internal/logging"
+	"github.com/artnikel/nuclei/internal/metrics"
+	"github.com/artnikel/nuclei/internal/output"
 	"github.com/artnikel/nuclei/internal/scanner"
+	scanstorage "github.com/artnikel/nuclei/internal/storage"
 	"github.com/artnikel/nuclei/internal/templates"
 )
 
-// BuildScannerSection builds the scanner UI section and returns it along with the start flag and cancel function
-func BuildScannerSection(a fyne.App, w fyne.Window, logger *logging.Logger) (fyne.CanvasObject, *atomic.Bool, *context.CancelFunc) {
+// ScanControl lets other parts of the GUI follow and control the scans of the scanner section, Pause and Cancel
+// must be called on the main goroutine
+type ScanControl struct {
+	Running *atomic.Bool
+	Pause   func()
+	Cancel  func()
+	// processed and total are the counts of the running scan, updated every second
+	processed atomic.Int64
+	total     atomic.Int64
+}
+
+// Progress returns the number of processed and total targets of the running scan
+func (c *ScanControl) Progress() (processed, total int64) {
+	return c.processed.Load(), c.total.Load()
+}
+
+// setProgress updates the counts returned by Progress
+func (c *ScanControl) setProgress(processed, total int64) {
+	c.processed.Store(processed)
+	c.total.Store(total)
+}
+
+// BuildScannerSection builds the scanner UI section and returns it along with the control of its scans,
+// targets and template files dropped onto the window are selected for the next scan
+func BuildScannerSection(a fyne.App, w fyne.Window, store scanstorage.Store, drop *FileDrop, logger *logging.Logger) (fyne.CanvasObject, *ScanControl) {
 	var targetsFile string
 	var templatesDir string
 
 	isRunning := &atomic.Bool{}
+	isPaused := &atomic.Bool{}
 	var cancelScan context.CancelFunc
+	control := &ScanControl{Running: isRunning}
 
 	targetsLabel := widget.NewLabel("Targets: (not selected)")
 	templatesLabel := widget.NewLabel("Templates: (not selected)")
 
 	selectTargetsBtn := newSelectTargetsButton(w, &targetsFile, targetsLabel)
 	selectTemplatesBtn := newSelectTemplateButton(w, &templatesDir, templatesLabel)
+	drop.OnTargets = func(path string) {
+		targetsFile = path
+		targetsLabel.SetText("Targets: " + targetsFile)
+	}
+	drop.OnTemplate = func(path string, _ *templates.Template) {
+		templatesDir = path
+		templatesLabel.SetText("Template: " + templatesDir)
+	}
 
 	maxThreads := runtime.NumCPU()
 	threadsEntry := newThreadsEntry(maxThreads)
 	timeoutEntry := newTimeoutEntry()
+	liveOutputEntry := widget.NewEntry()
+	liveOutputEntry.SetPlaceHolder("(none)")
+
+	severityCheck := newSeverityCheckGroup()
 
 	statsBinding := binding.NewString()
 	_ = statsBinding.Set(initialStatsText())
 	statsLabel := widget.NewLabelWithData(statsBinding)
+	progressBar := widget.NewProgressBar()
+	etaLabel := widget.NewLabel("")
+	findings := newFindingsList(w)
+	clearResultsBtn := widget.NewButton("Clear results", findings.Clear)
+	exportResultsBtn := newExportResultsButton(w, func() []templates.Finding { return findings.allFindings }, logger)
 
 	startBtn := widget.NewButton("Start", nil)
 	stopBtn := widget.NewButton("Stop", nil)
 	stopBtn.Disable()
+	pauseBtn := widget.NewButton("Pause", nil)
+	resumeBtn := widget.NewButton("Resume", nil)
+	resumeBtn.Disable()
 
 	startBtn.OnTapped = func() {
-		handleStartButtonClick(a, w, targetsFile, templatesDir, threadsEntry, timeoutEntry, statsBinding, isRunning, startBtn, stopBtn, &cancelScan, logger)
+		isPaused.Store(false)
+		pauseBtn.Enable()
+		resumeBtn.Disable()
+		handleStartButtonClick(a, w, targetsFile, templatesDir, severityCheck.Selected, threadsEntry, timeoutEntry, liveOutputEntry.Text, statsBinding, progressBar, etaLabel, findings, control, isPaused, startBtn, stopBtn, &cancelScan, store, logger)
 	}
 
+	quickScanBtn := widget.NewButton("Quick Scan from Clipboard", func() {
+		path, err := clipboardTargetsFile(a.Clipboard().Content())
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		targetsFile = path
+		targetsLabel.SetText("Targets: " + targetsFile)
+		startBtn.OnTapped()
+	})
+
 	stopBtn.OnTapped = func() {
 		if cancelScan != nil {
 			cancelScan()
 		}
 	}
 
+	pauseBtn.OnTapped = func() {
+		isPaused.Store(true)
+		pauseBtn.Disable()
+		resumeBtn.Enable()
+	}
+	control.Pause = func() {
+		if isRunning.Load() && !isPaused.Load() {
+			pauseBtn.OnTapped()
+		}
+	}
+	control.Cancel = stopBtn.OnTapped
+
+	resumeBtn.OnTapped = func() {
+		isPaused.Store(false)
+		resumeBtn.Disable()
+		pauseBtn.Enable()
+	}
+
 	section := container.NewVBox(
 		widget.NewLabel("Scan Targets Section"),
-		selectTargetsBtn, targetsLabel,
+		container.NewHBox(selectTargetsBtn, quickScanBtn), targetsLabel,
 		selectTemplatesBtn, templatesLabel,
 		widget.NewForm(
 			widget.NewFormItem("Number of threads", threadsEntry),
 			widget.NewFormItem("Timeout (seconds)", timeoutEntry),
+			widget.NewFormItem("NDJSON live output file", liveOutputEntry),
+			widget.NewFormItem("Severity", severityCheck),
 		),
-		container.NewHBox(startBtn, stopBtn),
+		container.NewHBox(startBtn, stopBtn, pauseBtn, resumeBtn),
+		progressBar, etaLabel,
 		statsLabel,
 	)
 
-	return section, isRunning, &cancelScan
+	results := container.NewBorder(
+		container.NewVBox(container.NewHBox(widget.NewLabel("Findings"), clearResultsBtn, exportResultsBtn), findings.filterBar()),
+		nil, nil, nil,
+		findings.list,
+	)
+	return container.NewBorder(section, nil, nil, nil, results), control
 }
 
 // newSelectTargetsButton creates a button to select a file with scan targets
@@ -93,6 +182,24 @@ func newSelectTargetsButton(w fyne.Window, targetsFile *string, label *widget.La
 	})
 }
 
+// clipboardTargetsFile writes the URL copied to the clipboard into a temporary targets file and returns its path
+func clipboardTargetsFile(content string) (string, error) {
+	target := strings.TrimSpace(content)
+	u, err := url.ParseRequestURI(target)
+	if err != nil || u.Host == "" {
+		return "", fmt.Errorf("clipboard does not contain a URL: %q", target)
+	}
+	f, err := os.CreateTemp("", "nuclei-targets-*"+constants.TxtFileFormat)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if _, err := f.WriteString(target + "\n"); err != nil {
+		return "", err
+	}
+	return f.Name(), nil
+}
+
 // newSelectTemplateButton creates a button to select a template directory
 func newSelectTemplateButton(w fyne.Window, templatesFile *string, label *widget.Label) *widget.Button {
 	return widget.NewButton("Select template (.yaml/.yml)", func() {
@@ -130,6 +237,13 @@ func newTimeoutEntry() *widget.Entry {
 	return e
 }
 
+// newSeverityCheckGroup creates a horizontal checkbox group with all template severities
+func newSeverityCheckGroup() *widget.CheckGroup {
+	check := widget.NewCheckGroup([]string{"critical", "high", "medium", "low", "info"}, nil)
+	check.Horizontal = true
+	return check
+}
+
 // initialStatsText returns a string with initial statistics values
 func initialStatsText() string {
 	return "Statistics:\nTargets loaded: 0\nProcessed: 0\nSuccesses: 0\nErrors: 0\nAvg time (ms): 0"
@@ -140,14 +254,22 @@ func handleStartButtonClick(
 	a fyne.App,
 	w fyne.Window,
 	targetsFile, templateFile string,
+	severityFilter []string,
 	threadsEntry *widget.Entry,
 	timeoutEntry *widget.Entry,
+	liveOutputPath string,
 	statsBinding binding.String,
-	isRunning *atomic.Bool,
+	progressBar *widget.ProgressBar,
+	etaLabel *widget.Label,
+	findings *findingsList,
+	control *ScanControl,
+	isPaused *atomic.Bool,
 	startBtn, stopBtn *widget.Button,
 	cancelScan *context.CancelFunc,
+	store scanstorage.Store,
 	logger *logging.Logger,
 ) {
+	isRunning := control.Running
 	if isRunning.Load() {
 		dialog.ShowInformation("Scanner running", "Scanner is already running", w)
 		return
@@ -175,10 +297,24 @@ func handleStartButtonClick(
 	}
 	template, err := templates.LoadTemplate(templateFile)
 	if err != nil {
-		logger.Error.Printf("failed to load template: %v", err)
+		logger.Error("Failed to load template", slog.String("path", templateFile), slog.Any("error", err))
 		dialog.ShowError(fmt.Errorf("failed to load template: %w", err), w)
 		return
 	}
+	if !template.MatchesSeverity(severityFilter) {
+		dialog.ShowError(fmt.Errorf("template severity %q is excluded by the severity filter", template.SeverityLevel()), w)
+		return
+	}
+
+	var liveOutput output.Writer
+	if liveOutputPath = strings.TrimSpace(liveOutputPath); liveOutputPath != "" {
+		f, err := os.OpenFile(liveOutputPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("failed to open the NDJSON live output file: %w", err), w)
+			return
+		}
+		liveOutput = &closingWriter{Writer: output.NewJSONLinesWriter(f), file: f}
+	}
 
 	isRunning.Store(true)
 	startBtn.Disable()
@@ -190,7 +326,13 @@ func handleStartButtonClick(
 	statsUpdateCh := make(chan string, 10)
 	go updateStatsBinding(statsBinding, statsUpdateCh)
 
-	go runScan(ctx, targetsFile, threads, template, statsUpdateCh, a, isRunning, startBtn, stopBtn, logger)
+	findingsCh := make(chan templates.Finding, 100)
+	go findings.collect(ctx, a, findingsCh)
+
+	progressBar.SetValue(0)
+	etaLabel.SetText("")
+	control.setProgress(0, 0)
+	go runScan(ctx, targetsFile, threads, template, statsUpdateCh, findingsCh, liveOutput, progressBar, etaLabel, a, control, isPaused, startBtn, stopBtn, store, logger)
 }
 
 // updateStatsBinding listens to the update channel and updates the statistics string binding
@@ -207,15 +349,26 @@ func runScan(
 	threads int,
 	template *templates.Template,
 	statsUpdateCh chan<- string,
+	findingsCh chan<- templates.Finding,
+	liveOutput output.Writer,
+	progressBar *widget.ProgressBar,
+	etaLabel *widget.Label,
 	a fyne.App,
-	isRunning *atomic.Bool,
+	control *ScanControl,
+	isPaused *atomic.Bool,
 	startBtn, stopBtn *widget.Button,
+	store scanstorage.Store,
 	logger *logging.Logger,
 ) {
 	defer func() {
+		if liveOutput != nil {
+			if err := liveOutput.Close(); err != nil {
+				logger.Error("Failed to close the NDJSON live output file", slog.Any("error", err))
+			}
+		}
 		close(statsUpdateCh)
 		a.Driver().DoFromGoroutine(func() {
-			isRunning.Store(false)
+			control.Running.Store(false)
 			startBtn.Enable()
 			stopBtn.Disable()
 		}, true)
@@ -224,69 +377,162 @@ func runScan(
 	var totalTargets, processed, success, errors, totalDuration int64
 	targetsChan := make(chan string, 1000)
 
-	go feedTargets(ctx, targetsFile, targetsChan, &totalTargets)
+	advanced := templates.DefaultAdvancedSettings()
+	advanced.Paused = isPaused
+	advanced.NotificationsEnabled = notificationsEnabled(a)
+	go feedTargets(ctx, targetsFile, targetsChan, &totalTargets, advanced.EnableDeduplication, logger)
 
 	processFn := func(ctx context.Context, target string) error {
 		startTime := time.Now()
-		matched, err := templates.MatchTemplate(ctx, target,"", template, &templates.AdvancedSettingsChecker{}, logger)
+		matched, extracted, err := templates.MatchTemplate(ctx, target,"", template, advanced, logger)
 		durationMs := time.Since(startTime).Milliseconds()
 
 		atomic.AddInt64(&processed, 1)
 		atomic.AddInt64(&totalDuration, durationMs)
 
 		if err != nil {
-			logger.Info.Printf("Error processing target %s: %v\n", target, err)
+			logger.Info("Error processing target", slog.String("target", target), slog.Any("error", err))
 			atomic.AddInt64(&errors, 1)
 			return err
 		}
 
 		if matched {
 			atomic.AddInt64(&success, 1)
+			metrics.RecordMatch(template.Info.Severity)
+			finding := templates.NewFinding(target, template)
+			finding.ExtractedValues = extracted
+			finding.Duration = time.Since(startTime)
+			if liveOutput != nil {
+				if err := liveOutput.Write(finding); err != nil {
+					logger.Error("Failed to write finding to the NDJSON live output file", slog.String("target", target), slog.Any("error", err))
+				}
+			}
+			if store != nil {
+				if err := store.Save(finding); err != nil {
+					logger.Error("Failed to save finding", slog.String("target", target), slog.Any("error", err))
+				}
+			}
+			select {
+			case findingsCh <- *finding:
+			case <-ctx.Done():
+			}
 			return nil
 		}
 
+		// a target without a match is not an error of the scan, it's only counted in the GUI stats
 		atomic.AddInt64(&errors, 1)
-		return fmt.Errorf("no match found")
+		return nil
 	}
 
-	resultsDone := scanner.StartWorkers(ctx, targetsChan, threads, processFn, logger)
+	resultsDone := scanner.StartWorkers(ctx, targetsChan, threads, advanced.Paused, processFn, logger)
+	start := time.Now()
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
 
-	select {
-	case <-ctx.Done():
-		return
-	case <-resultsDone:
+	for done := false; !done; {
+		select {
+		case <-ctx.Done():
+			a.Driver().DoFromGoroutine(func() {
+				progressBar.SetValue(0)
+				etaLabel.SetText("")
+			}, false)
+			return
+		case <-resultsDone:
+			// the workers are done, nothing sends findings anymore
+			close(findingsCh)
+			done = true
+		case <-ticker.C:
+			processedNow, total, elapsed := atomic.LoadInt64(&processed), atomic.LoadInt64(&totalTargets), time.Since(start)
+			control.setProgress(processedNow, total)
+			a.Driver().DoFromGoroutine(func() {
+				updateProgressBar(progressBar, etaLabel, processedNow, total, elapsed)
+			}, false)
+		}
 	}
 
+	control.setProgress(atomic.LoadInt64(&processed), atomic.LoadInt64(&totalTargets))
+	a.Driver().DoFromGoroutine(func() {
+		progressBar.SetValue(1)
+		etaLabel.SetText("ETA: 0s")
+	}, false)
+
 	statsUpdateCh <- "Scan finished.\n" + formatStats(totalTargets, processed, success, errors, totalDuration)
+	if advanced.NotificationsEnabled {
+		notifyScanComplete(a, atomic.LoadInt64(&success), time.Since(start), logger)
+	}
+}
+
+// closingWriter is an output writer closing the file it writes to after itself
+type closingWriter struct {
+	output.Writer
+	file *os.File
 }
 
-// feedTargets reads targets from the file and sends them to the channel for scanning
-func feedTargets(ctx context.Context, targetsFile string, targetsChan chan<- string, totalTargets *int64) {
+// Close closes the writer, then the file
+func (c *closingWriter) Close() error {
+	return errors.Join(c.Writer.Close(), c.file.Close())
+}
+
+// feedTargets reads targets from the file, optionally deduplicates them and sends them to the channel for scanning
+func feedTargets(ctx context.Context, targetsFile string, targetsChan chan<- string, totalTargets *int64, deduplicate bool, logger *logging.Logger) {
 	defer close(targetsChan)
 
 	file, err := os.Open(targetsFile)
 	if err != nil {
-		log.Printf("Error opening targets file %s: %v\n", targetsFile, err)
+		logger.Error("Error opening targets file", slog.String("path", targetsFile), slog.Any("error", err))
 		return
 	}
 	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
+	targets, err := scanner.ReadTargets(file)
+	if err != nil {
+		logger.Error("Error reading targets file", slog.String("path", targetsFile), slog.Any("error", err))
+		return
+	}
+	if deduplicate {
+		targets = scanner.DeduplicateTargets(targets)
+	}
+
+	for _, target := range targets {
 		select {
 		case <-ctx.Done():
 			return
-		default:
-			target := strings.TrimSpace(scanner.Text())
-			if target == "" {
-				continue
-			}
-			targetsChan <- target
+		case targetsChan <- target:
 			atomic.AddInt64(totalTargets, 1)
 		}
 	}
 }
 
+// updateProgressBar shows the share of processed targets and the time left at the average wall time per target so far
+func updateProgressBar(bar *widget.ProgressBar, etaLabel *widget.Label, processed, total int64, elapsed time.Duration) {
+	if total <= 0 {
+		bar.SetValue(0)
+		etaLabel.SetText("")
+		return
+	}
+	bar.SetValue(min(float64(processed)/float64(total), 1))
+	if processed == 0 {
+		etaLabel.SetText("ETA: unknown")
+		return
+	}
+	avgTimePerTarget := elapsed / time.Duration(processed)
+	etaLabel.SetText("ETA: " + formatETA(time.Duration(max(total-processed, 0))*avgTimePerTarget))
+}
+
+// formatETA formats the duration rounded to seconds as e.g. 1h 2m 30s, leaving out leading zero units
+func formatETA(d time.Duration) string {
+	d = d.Round(time.Second)
+	h, m, sec := int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60
+	switch {
+	case h > 0:
+		return fmt.Sprintf("%dh %dm %ds", h, m, sec)
+	case m > 0:
+		return fmt.Sprintf("%dm %ds", m, sec)
+	default:
+		return fmt.Sprintf("%ds", sec)
+	}
+}
+
 // formatStats formats the collected statistics at the end of scanning
 func formatStats(totalTargets, processed, success, errors, totalDuration int64) string {
 	var avgMs int64
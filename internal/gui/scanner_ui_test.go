@@ -0,0 +1,147 @@
+package gui
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/artnikel/nuclei/internal/logging"
+	"github.com/artnikel/nuclei/internal/templates"
+)
+
+const scanTemplate = `id: gui-scan
+info:
+  name: GUI scan
+  author: test
+  severity: info
+http:
+  - method: GET
+    path:
+      - "{{BaseURL}}"
+    matchers:
+      - type: status
+        status:
+          - 200
+`
+
+func TestRunScanProgress(t *testing.T) {
+	a := test.NewTempApp(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(srv.Close)
+
+	dir := t.TempDir()
+	var targets []string
+	for i := 0; i < 5; i++ {
+		targets = append(targets, fmt.Sprintf("%s/t%d", srv.URL, i))
+	}
+	targetsFile := filepath.Join(dir, "targets.txt")
+	templateFile := filepath.Join(dir, "template.yaml")
+	if err := os.WriteFile(targetsFile, []byte(strings.Join(targets, "\n")), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(templateFile, []byte(scanTemplate), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	tmpl, err := templates.LoadTemplate(templateFile)
+	if err != nil {
+		t.Fatalf("LoadTemplate: %v", err)
+	}
+	logger, err := logging.NewLogger(filepath.Join(dir, "logs"), logging.FormatText)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	progressBar, etaLabel := widget.NewProgressBar(), widget.NewLabel("")
+	startBtn, stopBtn := widget.NewButton("Start", nil), widget.NewButton("Stop", nil)
+	control := &ScanControl{Running: &atomic.Bool{}}
+	control.Running.Store(true)
+	statsCh := make(chan string, 10)
+	findingsCh := make(chan templates.Finding, len(targets))
+
+	done := make(chan struct{})
+	go func() {
+		runScan(context.Background(), targetsFile, 2, tmpl, statsCh, findingsCh, nil, progressBar, etaLabel, a,
+			control, &atomic.Bool{}, startBtn, stopBtn, nil, logger)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(30 * time.Second):
+		t.Fatal("scan of 5 targets did not finish")
+	}
+
+	var value float64
+	var eta string
+	fyne.DoAndWait(func() { value, eta = progressBar.Value, etaLabel.Text })
+	if value != 1 || eta != "ETA: 0s" {
+		t.Errorf("progress after the scan = %v, %q, want 1, ETA: 0s", value, eta)
+	}
+	if processed, total := control.Progress(); processed != 5 || total != 5 {
+		t.Errorf("Progress = %d/%d, want 5/5", processed, total)
+	}
+	var findings int
+	for range findingsCh {
+		findings++
+	}
+	if findings != 5 {
+		t.Errorf("%d findings, want 5", findings)
+	}
+}
+
+func TestUpdateProgressBar(t *testing.T) {
+	test.NewTempApp(t)
+	bar, eta := widget.NewProgressBar(), widget.NewLabel("")
+	tests := []struct {
+		processed, total int64
+		elapsed          time.Duration
+		wantValue        float64
+		wantETA          string
+	}{
+		{processed: 0, total: 0, wantValue: 0, wantETA: ""},
+		{processed: 0, total: 10, elapsed: time.Second, wantValue: 0, wantETA: "ETA: unknown"},
+		{processed: 2, total: 8, elapsed: 50 * time.Second, wantValue: 0.25, wantETA: "ETA: 2m 30s"},
+		{processed: 1, total: 200, elapsed: 20 * time.Second, wantValue: 0.005, wantETA: "ETA: 1h 6m 20s"},
+		{processed: 5, total: 5, elapsed: 5 * time.Second, wantValue: 1, wantETA: "ETA: 0s"},
+	}
+	for _, tt := range tests {
+		updateProgressBar(bar, eta, tt.processed, tt.total, tt.elapsed)
+		if bar.Value != tt.wantValue || eta.Text != tt.wantETA {
+			t.Errorf("updateProgressBar(%d/%d, %v) = %v, %q, want %v, %q", tt.processed, tt.total, tt.elapsed, bar.Value, eta.Text, tt.wantValue, tt.wantETA)
+		}
+	}
+}
+
+func TestClipboardTargetsFile(t *testing.T) {
+	a := test.NewTempApp(t)
+	t.Setenv("TMPDIR", t.TempDir())
+
+	a.Clipboard().SetContent("  https://example.com/login?next=/admin\n")
+	path, err := clipboardTargetsFile(a.Clipboard().Content())
+	if err != nil {
+		t.Fatalf("clipboardTargetsFile: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "https://example.com/login?next=/admin\n" || filepath.Ext(path) != ".txt" {
+		t.Errorf("targets file %s = %q, want the clipboard URL", path, data)
+	}
+
+	for _, content := range []string{"", "not a url", "/relative/path", "example.com"} {
+		if path, err := clipboardTargetsFile(content); err == nil {
+			t.Errorf("clipboardTargetsFile(%q) = %s, want an error", content, path)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+// package gui implements the user interface of the project - application settings section
+package gui
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/artnikel/nuclei/internal/config"
+	"github.com/artnikel/nuclei/internal/constants"
+	"github.com/artnikel/nuclei/internal/logging"
+	"github.com/artnikel/nuclei/internal/templates"
+)
+
+// BuildSettingsSection creates the UI section with the application preferences
+func BuildSettingsSection(a fyne.App, w fyne.Window, logger *logging.Logger) fyne.CanvasObject {
+	darkMode := widget.NewCheck("Dark Mode", func(enabled bool) {
+		if enabled {
+			SetTheme(a, ThemeDark)
+		} else {
+			SetTheme(a, ThemeLight)
+		}
+	})
+	darkMode.SetChecked(a.Preferences().StringWithFallback(themePreferenceKey, ThemeDark) == ThemeDark)
+
+	notifications := widget.NewCheck("Notify when a scan completes", func(enabled bool) {
+		a.Preferences().SetBool(notificationsPreferenceKey, enabled)
+	})
+	notifications.SetChecked(notificationsEnabled(a))
+
+	updateTemplatesBtn := widget.NewButton("Update Templates", func() {
+		updateTemplatesAction(w, logger)
+	})
+
+	return container.NewVBox(
+		widget.NewLabel("Settings Section"),
+		darkMode,
+		notifications,
+		updateTemplatesBtn,
+	)
+}
+
+// updateTemplatesAction downloads changed templates from the configured update URL into the configured templates folder
+func updateTemplatesAction(parentWindow fyne.Window, logger *logging.Logger) {
+	cfg, err := config.LoadConfig(config.DefaultPath)
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("failed to load config: %w", err), parentWindow)
+		return
+	}
+	if cfg.Templates.UpdateURL == "" || cfg.Templates.Dir == "" {
+		dialog.ShowError(fmt.Errorf("fill the templates fields in config.yaml"), parentWindow)
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), constants.FiveMinTimeout)
+		defer cancel()
+
+		updater := templates.NewUpdater(cfg.Templates.UpdateURL, cfg.Templates.Dir, cfg.Templates.HMACKey)
+		result, err := updater.CheckForUpdates(ctx)
+		fyne.CurrentApp().Driver().DoFromGoroutine(func() {
+			if err != nil {
+				logger.Error("Template update failed", slog.Any("error", err))
+				dialog.ShowError(err, parentWindow)
+				return
+			}
+			dialog.ShowInformation("Success", fmt.Sprintf("Updated %d templates", len(result.Changed)), parentWindow)
+		}, true)
+	}()
+}
@@ -0,0 +1,171 @@
+// package gui implements the user interface of the project - template browser section
+package gui
+
+import (
+	"sort"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/artnikel/nuclei/internal/templates"
+)
+
+const (
+	// untaggedGroup is the tree node of templates without tags
+	untaggedGroup = "(untagged)"
+	// tagNodePrefix is the prefix of the tree node IDs of tags, it can't start a template file path
+	tagNodePrefix = "\x00tag:"
+)
+
+// templateBrowser is a tree of the templates of a folder grouped by their first tag, template nodes are
+// the template file paths
+type templateBrowser struct {
+	groups   map[string][]*templates.Template
+	byPath   map[string]*templates.Template
+	filter   string
+	tree     *widget.Tree
+	onSelect func(tmpl *templates.Template)
+}
+
+// newTemplateBrowser creates an empty template browser calling onSelect when a template is selected
+func newTemplateBrowser(onSelect func(tmpl *templates.Template)) *templateBrowser {
+	b := &templateBrowser{onSelect: onSelect}
+	b.tree = widget.NewTree(b.childUIDs, b.isBranch,
+		func(bool) fyne.CanvasObject { return widget.NewLabel("") },
+		func(uid widget.TreeNodeID, _ bool, obj fyne.CanvasObject) {
+			obj.(*widget.Label).SetText(b.nodeText(uid))
+		},
+	)
+	b.tree.OnSelected = func(uid widget.TreeNodeID) {
+		if tmpl, ok := b.byPath[uid]; ok && b.onSelect != nil {
+			b.onSelect(tmpl)
+		}
+	}
+	return b
+}
+
+// Load replaces the tree with the templates of dir
+func (b *templateBrowser) Load(dir string) error {
+	tmpls, _, err := templates.LoadTemplates(dir, nil, false, templates.DefaultAdvancedSettings(), nil)
+	if err != nil {
+		return err
+	}
+	b.SetTemplates(tmpls)
+	return nil
+}
+
+// SetTemplates replaces the tree with tmpls
+func (b *templateBrowser) SetTemplates(tmpls []*templates.Template) {
+	b.groups = make(map[string][]*templates.Template)
+	b.byPath = make(map[string]*templates.Template, len(tmpls))
+	for _, tmpl := range tmpls {
+		group := untaggedGroup
+		if tags := tmpl.Info.Tags; len(tags) > 0 && strings.TrimSpace(tags[0]) != "" {
+			group = strings.ToLower(strings.TrimSpace(tags[0]))
+		}
+		b.groups[group] = append(b.groups[group], tmpl)
+		b.byPath[tmpl.FilePath] = tmpl
+	}
+	for _, group := range b.groups {
+		sort.Slice(group, func(i, j int) bool { return templateName(group[i]) < templateName(group[j]) })
+	}
+	b.tree.UnselectAll()
+	b.tree.Refresh()
+}
+
+// SetFilter shows only the templates whose name or ID contains query, opening the tags holding them
+// and scrolling to the first one
+func (b *templateBrowser) SetFilter(query string) {
+	b.filter = strings.ToLower(strings.TrimSpace(query))
+	b.tree.Refresh()
+	if b.filter == "" {
+		b.tree.CloseAllBranches()
+		return
+	}
+	var first widget.TreeNodeID
+	for _, tagUID := range b.childUIDs("") {
+		b.tree.OpenBranch(tagUID)
+		if children := b.childUIDs(tagUID); first == "" && len(children) > 0 {
+			first = children[0]
+		}
+	}
+	if first != "" {
+		b.tree.ScrollTo(first)
+	}
+}
+
+// childUIDs returns the visible tags of the root and the visible templates of a tag
+func (b *templateBrowser) childUIDs(uid widget.TreeNodeID) []widget.TreeNodeID {
+	if uid == "" {
+		var tags []string
+		for tag := range b.groups {
+			if len(b.visibleTemplates(tag)) > 0 {
+				tags = append(tags, tag)
+			}
+		}
+		sort.Strings(tags)
+		ids := make([]widget.TreeNodeID, len(tags))
+		for i, tag := range tags {
+			ids[i] = tagNodePrefix + tag
+		}
+		return ids
+	}
+	visible := b.visibleTemplates(strings.TrimPrefix(uid, tagNodePrefix))
+	ids := make([]widget.TreeNodeID, len(visible))
+	for i, tmpl := range visible {
+		ids[i] = tmpl.FilePath
+	}
+	return ids
+}
+
+// isBranch reports whether uid is the root or a tag
+func (b *templateBrowser) isBranch(uid widget.TreeNodeID) bool {
+	return uid == "" || strings.HasPrefix(uid, tagNodePrefix)
+}
+
+// nodeText returns the label of a tree node
+func (b *templateBrowser) nodeText(uid widget.TreeNodeID) string {
+	if tag, ok := strings.CutPrefix(uid, tagNodePrefix); ok {
+		return tag
+	}
+	if tmpl, ok := b.byPath[uid]; ok {
+		return templateName(tmpl)
+	}
+	return uid
+}
+
+// visibleTemplates returns the templates of the tag matching the filter
+func (b *templateBrowser) visibleTemplates(tag string) []*templates.Template {
+	if b.filter == "" {
+		return b.groups[tag]
+	}
+	var visible []*templates.Template
+	for _, tmpl := range b.groups[tag] {
+		if strings.Contains(strings.ToLower(templateName(tmpl)), b.filter) || strings.Contains(strings.ToLower(tmpl.ID), b.filter) {
+			visible = append(visible, tmpl)
+		}
+	}
+	return visible
+}
+
+// templateName returns the name of the template, its ID if it has none
+func templateName(tmpl *templates.Template) string {
+	if tmpl.Info.Name != "" {
+		return tmpl.Info.Name
+	}
+	return tmpl.ID
+}
+
+// buildTemplateBrowserSection lays out the search entry above the template tree
+func buildTemplateBrowserSection(b *templateBrowser) fyne.CanvasObject {
+	searchEntry := widget.NewEntry()
+	searchEntry.SetPlaceHolder("Search templates")
+	searchEntry.OnChanged = b.SetFilter
+	return container.NewBorder(
+		container.NewVBox(widget.NewLabel("Templates of the Template Checker folder"), searchEntry),
+		nil, nil, nil,
+		b.tree,
+	)
+}
@@ -0,0 +1,79 @@
+package gui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/artnikel/nuclei/internal/templates"
+)
+
+const browserTemplate = `id: %s
+info:
+  name: %s
+  author: test
+  severity: info
+  tags: %s
+http:
+  - method: GET
+    path:
+      - "{{BaseURL}}/"
+    matchers:
+      - type: status
+        status:
+          - 200
+`
+
+func TestTemplateBrowserSelect(t *testing.T) {
+	test.NewTempApp(t)
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	dir := t.TempDir()
+	for _, tmpl := range []struct{ id, name, tags string }{
+		{id: "git-config", name: "Git config exposure", tags: "exposure,git"},
+		{id: "env-file", name: "Env file exposure", tags: "exposure"},
+		{id: "wp-version", name: "WordPress version", tags: "tech"},
+		{id: "no-tags", name: "No tags", tags: `""`},
+	} {
+		content := fmt.Sprintf(browserTemplate, tmpl.id, tmpl.name, tmpl.tags)
+		if err := os.WriteFile(filepath.Join(dir, tmpl.id+".yaml"), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	urlEntry := widget.NewEntry()
+	browser := newTemplateBrowser(func(tmpl *templates.Template) {
+		urlEntry.SetText(tmpl.FilePath)
+	})
+	if err := browser.Load(dir); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	tags := browser.childUIDs("")
+	want := []string{tagNodePrefix + untaggedGroup, tagNodePrefix + "exposure", tagNodePrefix + "tech"}
+	if fmt.Sprint(tags) != fmt.Sprint(want) {
+		t.Fatalf("tag nodes = %q, want %q", tags, want)
+	}
+	exposure := browser.childUIDs(tagNodePrefix + "exposure")
+	if len(exposure) != 2 || browser.nodeText(exposure[0]) != "Env file exposure" {
+		t.Fatalf("exposure templates = %q, want the env and git templates by name", exposure)
+	}
+
+	gitPath := filepath.Join(dir, "git-config.yaml")
+	browser.tree.Select(gitPath)
+	if urlEntry.Text != gitPath {
+		t.Errorf("entry after selecting git-config = %q, want %q", urlEntry.Text, gitPath)
+	}
+	browser.tree.Select(tagNodePrefix + "tech")
+	if urlEntry.Text != gitPath {
+		t.Errorf("selecting a tag changed the entry to %q", urlEntry.Text)
+	}
+
+	browser.SetFilter("WORDPRESS")
+	if tags := browser.childUIDs(""); len(tags) != 1 || tags[0] != tagNodePrefix+"tech" {
+		t.Errorf("tag nodes with the filter = %q, want only tech", tags)
+	}
+}
@@ -4,8 +4,11 @@ package gui
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"fyne.io/fyne/v2"
@@ -14,13 +17,15 @@ import (
 	"fyne.io/fyne/v2/storage"
 	"fyne.io/fyne/v2/widget"
 
+	"github.com/artnikel/nuclei/internal/config"
 	"github.com/artnikel/nuclei/internal/constants"
 	"github.com/artnikel/nuclei/internal/logging"
 	"github.com/artnikel/nuclei/internal/templates"
 )
 
-// BuildTemplateCheckerSection creates a UI section for checking and generating templates from URLs
-func BuildTemplateCheckerSection(a fyne.App, parentWindow fyne.Window, logger *logging.Logger) fyne.CanvasObject {
+// BuildTemplateCheckerSection creates a UI section for checking and generating templates from URLs and the
+// browser section of the templates in its folder, a folder dropped onto the window becomes its templates folder
+func BuildTemplateCheckerSection(a fyne.App, parentWindow fyne.Window, drop *FileDrop, logger *logging.Logger) (fyne.CanvasObject, fyne.CanvasObject) {
 	urlEntry := widget.NewEntry()
 	urlEntry.SetPlaceHolder("Enter URL to check templates")
 
@@ -35,8 +40,38 @@ func BuildTemplateCheckerSection(a fyne.App, parentWindow fyne.Window, logger *l
 
 	var checkTemplatesDir string
 
+	severityCheck := newSeverityCheckGroup()
+
+	tagsCheck := widget.NewCheckGroup(nil, nil)
+	tagsCheck.Horizontal = true
+
+	dryRunCheck := widget.NewCheck("Dry Run", nil)
+
+	browser := newTemplateBrowser(func(tmpl *templates.Template) {
+		urlEntry.SetText(tmpl.FilePath)
+	})
+
+	onFolderSelected := func(dir string) {
+		tagsCheck.Options = collectTemplateTags(dir)
+		tagsCheck.SetSelected(nil)
+		tagsCheck.Refresh()
+		if err := browser.Load(dir); err != nil {
+			logger.Error("Failed to load templates for browsing", slog.String("dir", dir), slog.Any("error", err))
+		}
+	}
 	selectTemplateCheckDirBtn := widget.NewButton("Select templates folder for checking", func() {
-		selectTemplatesFolder(parentWindow, &checkTemplatesDir, templateCheckLabel)
+		selectTemplatesFolder(parentWindow, &checkTemplatesDir, templateCheckLabel, onFolderSelected)
+	})
+	drop.OnFolder = func(dir string) {
+		checkTemplatesDir = dir
+		templateCheckLabel.SetText("Template folder: " + dir)
+		onFolderSelected(dir)
+	}
+
+	cveEntry := widget.NewEntry()
+	cveEntry.SetPlaceHolder("CVE-2021-44228")
+	searchCVEBtn := widget.NewButton("Search CVE", func() {
+		searchCVEAction(parentWindow, checkTemplatesDir, cveEntry.Text, resultsOutput)
 	})
 
 	createTemplateBtn.OnTapped = func() {
@@ -53,14 +88,33 @@ func BuildTemplateCheckerSection(a fyne.App, parentWindow fyne.Window, logger *l
 
 	rateBurstEntry := widget.NewEntry()
 	rateBurstEntry.SetText("100")
-	advanced := &templates.AdvancedSettingsChecker{}
+
+	workersEntry := widget.NewEntry()
+	workersEntry.SetText("0")
+	advanced := templates.DefaultAdvancedSettings()
+	if cfg, err := config.LoadConfig(config.DefaultPath); err == nil {
+		loadScannerConfig(cfg.Scanner, advanced, semaphoreEntry, rateFreqEntry, rateBurstEntry, workersEntry)
+	}
+	dryRunCheck.OnChanged = func(on bool) {
+		advanced.DryRun = on
+	}
+
+	profileLabel := widget.NewLabel("Profile: (none)")
+	selectProfileBtn := widget.NewButton("Select profile", func() {
+		selectProfileFile(parentWindow, advanced, profileLabel, logger)
+	})
+	clearProfileBtn := widget.NewButton("Clear profile", func() {
+		advanced.Profile = nil
+		profileLabel.SetText("Profile: (none)")
+	})
 
 	applyAdvancedBtn := widget.NewButton("Apply settings", func() {
 		headlessTabs, err1 := strconv.Atoi(semaphoreEntry.Text)
 		rateFreq, err2 := strconv.Atoi(rateFreqEntry.Text)
 		burstSize, err3 := strconv.Atoi(rateBurstEntry.Text)
+		workers, err4 := strconv.Atoi(workersEntry.Text)
 
-		if err1 != nil || err2 != nil || err3 != nil {
+		if err1 != nil || err2 != nil || err3 != nil || err4 != nil || workers < 0 {
 			dialog.ShowError(fmt.Errorf("incorrect values"), parentWindow)
 			return
 		}
@@ -68,7 +122,13 @@ func BuildTemplateCheckerSection(a fyne.App, parentWindow fyne.Window, logger *l
 		advanced.HeadlessTabs = headlessTabs
 		advanced.RateLimiterFrequency = rateFreq
 		advanced.RateLimiterBurstSize = burstSize
+		advanced.Workers = workers
 
+		if err := saveScannerConfig(config.DefaultPath, advanced); err != nil {
+			logger.Error("Failed to save settings", slog.String("path", config.DefaultPath), slog.Any("error", err))
+			dialog.ShowError(fmt.Errorf("settings changed but not saved: %w", err), parentWindow)
+			return
+		}
 		dialog.ShowInformation("Success", "Settings changed", parentWindow)
 	})
 
@@ -78,14 +138,21 @@ func BuildTemplateCheckerSection(a fyne.App, parentWindow fyne.Window, logger *l
 			widget.NewFormItem("Semaphore limit (tabs)", semaphoreEntry),
 			widget.NewFormItem("Rate limiter frequency (milisecond)", rateFreqEntry),
 			widget.NewFormItem("Rate limiter burst", rateBurstEntry),
+			widget.NewFormItem("Workers (0 for no limit)", workersEntry),
 		),
 		applyAdvancedBtn,
 	)
 	advancedSettingsForm.Hide()
 
+	// results holds the findings of the last check, it's only accessed on the main goroutine
+	var results []templates.Finding
 	checkTemplatesBtn := widget.NewButton("Check templates", func() {
-		checkTemplatesAction(parentWindow, urlEntry, checkTemplatesDir, resultsOutput, createTemplateBtn, advanced, logger)
+		results = nil
+		checkTemplatesAction(parentWindow, urlEntry, checkTemplatesDir, tagsCheck.Selected, severityCheck.Selected, resultsOutput, createTemplateBtn, advanced, func(findings []templates.Finding) {
+			results = findings
+		}, logger)
 	})
+	exportResultsBtn := newExportResultsButton(parentWindow, func() []templates.Finding { return results }, logger)
 
 	var toggleAdvancedBtn *widget.Button
 	toggleAdvancedBtn = widget.NewButton("Advanced settings", func() {
@@ -105,37 +172,154 @@ func BuildTemplateCheckerSection(a fyne.App, parentWindow fyne.Window, logger *l
 		urlEntry,
 		selectTemplateCheckDirBtn,
 		templateCheckLabel,
+		container.NewBorder(nil, nil, nil, searchCVEBtn, cveEntry),
+		widget.NewLabel("Filter by tags (none selected runs all templates)"),
+		tagsCheck,
+		widget.NewLabel("Filter by severity (none selected runs all templates)"),
+		severityCheck,
+		dryRunCheck,
+		container.NewHBox(selectProfileBtn, clearProfileBtn),
+		profileLabel,
 		checkTemplatesBtn,
 		resultsOutput,
-		createTemplateBtn,
+		container.NewHBox(createTemplateBtn, exportResultsBtn),
 		toggleAdvancedBtn,
 		advancedSettingsForm,
 	)
 
-	return section
+	return section, buildTemplateBrowserSection(browser)
 }
 
 // selectTemplatesFolder opens the dialog box for selecting a folder with templates and updates the path
-func selectTemplatesFolder(parentWindow fyne.Window, dir *string, label *widget.Label) {
+func selectTemplatesFolder(parentWindow fyne.Window, dir *string, label *widget.Label, onSelected func(dir string)) {
 	fd := dialog.NewFolderOpen(func(uri fyne.ListableURI, err error) {
 		if err != nil || uri == nil {
 			return
 		}
 		*dir = uri.Path()
 		label.SetText("Template folder: " + *dir)
+		if onSelected != nil {
+			onSelected(*dir)
+		}
+	}, parentWindow)
+	fd.Resize(fyne.NewSize(800, 600))
+	fd.Show()
+}
+
+// loadScannerConfig applies the saved advanced settings to advanced and the entries showing them
+func loadScannerConfig(scanCfg config.ScannerConfig, advanced *templates.AdvancedSettingsChecker, semaphoreEntry, rateFreqEntry, rateBurstEntry, workersEntry *widget.Entry) {
+	if scanCfg.HeadlessTabs > 0 {
+		advanced.HeadlessTabs = scanCfg.HeadlessTabs
+		semaphoreEntry.SetText(strconv.Itoa(scanCfg.HeadlessTabs))
+	}
+	if scanCfg.RateLimiterFrequency > 0 {
+		advanced.RateLimiterFrequency = scanCfg.RateLimiterFrequency
+		rateFreqEntry.SetText(strconv.Itoa(scanCfg.RateLimiterFrequency))
+	}
+	if scanCfg.RateLimiterBurstSize > 0 {
+		advanced.RateLimiterBurstSize = scanCfg.RateLimiterBurstSize
+		rateBurstEntry.SetText(strconv.Itoa(scanCfg.RateLimiterBurstSize))
+	}
+	if scanCfg.Workers > 0 {
+		advanced.Workers = scanCfg.Workers
+		workersEntry.SetText(strconv.Itoa(scanCfg.Workers))
+	}
+}
+
+// saveScannerConfig writes the advanced settings into the scanner section of the configuration file at path
+func saveScannerConfig(path string, advanced *templates.AdvancedSettingsChecker) error {
+	return config.SaveScannerConfig(path, config.ScannerConfig{
+		Workers:              advanced.Workers,
+		HeadlessTabs:         advanced.HeadlessTabs,
+		RateLimiterFrequency: advanced.RateLimiterFrequency,
+		RateLimiterBurstSize: advanced.RateLimiterBurstSize,
+	})
+}
+
+// selectProfileFile opens the dialog box for selecting a scan profile and applies it to the advanced settings
+func selectProfileFile(parentWindow fyne.Window, advanced *templates.AdvancedSettingsChecker, label *widget.Label, logger *logging.Logger) {
+	fd := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil || reader == nil {
+			return
+		}
+		reader.Close()
+		path := reader.URI().Path()
+		profile, err := templates.LoadProfile(path)
+		if err != nil {
+			logger.Error("Failed to load profile", slog.String("path", path), slog.Any("error", err))
+			dialog.ShowError(fmt.Errorf("failed to load profile: %w", err), parentWindow)
+			return
+		}
+		advanced.Profile = profile
+		label.SetText("Profile: " + path)
 	}, parentWindow)
 	fd.Resize(fyne.NewSize(800, 600))
+	fd.SetFilter(storage.NewExtensionFileFilter([]string{constants.YamlFileFormat, constants.YmlFileFormat}))
 	fd.Show()
 }
 
-// checkTemplatesAction checks for matching templates for a given URL and updates the interface
+// searchCVEAction lists the templates in dir that cover the CVE
+func searchCVEAction(parentWindow fyne.Window, dir, cveID string, resultsOutput *widget.Entry) {
+	if dir == "" {
+		dialog.ShowInformation("Error", "Please select a templates folder", parentWindow)
+		return
+	}
+	cveID = strings.TrimSpace(cveID)
+	if cveID == "" {
+		dialog.ShowInformation("Error", "Please enter a CVE ID", parentWindow)
+		return
+	}
+	_, index, err := templates.LoadTemplates(dir, nil, false, templates.DefaultAdvancedSettings(), nil)
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("failed to load templates: %w", err), parentWindow)
+		return
+	}
+	found := index.AllByCVE(cveID)
+	if len(found) == 0 {
+		resultsOutput.SetText("No templates found for " + cveID)
+		return
+	}
+	lines := []string{fmt.Sprintf("Templates for %s: %d", cveID, len(found))}
+	for _, tmpl := range found {
+		lines = append(lines, fmt.Sprintf("%s - %s (%s)", tmpl.ID, tmpl.Info.Name, tmpl.FilePath))
+	}
+	resultsOutput.SetText(strings.Join(lines, "\n"))
+}
+
+// collectTemplateTags returns the sorted unique tags of all templates in dir
+func collectTemplateTags(dir string) []string {
+	tmpls, _, err := templates.LoadTemplates(dir, nil, false, templates.DefaultAdvancedSettings(), nil)
+	if err != nil {
+		return nil
+	}
+	seen := make(map[string]struct{})
+	for _, tmpl := range tmpls {
+		for _, tag := range tmpl.AllTags() {
+			if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" {
+				seen[tag] = struct{}{}
+			}
+		}
+	}
+	tags := make([]string, 0, len(seen))
+	for tag := range seen {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// checkTemplatesAction checks for matching templates for a given URL, updates the interface and passes the findings
+// to onResults on the main goroutine
 func checkTemplatesAction(
 	parentWindow fyne.Window,
 	urlEntry *widget.Entry,
 	templatesDir string,
+	tagFilter []string,
+	severityFilter []string,
 	resultsOutput *widget.Entry,
 	createBtn *widget.Button,
 	advanced *templates.AdvancedSettingsChecker,
+	onResults func(findings []templates.Finding),
 	logger *logging.Logger,
 ) {
 	if templatesDir == "" {
@@ -151,6 +335,18 @@ func checkTemplatesAction(
 	createBtn.Disable()
 	resultsOutput.SetText("Starting template check...\n")
 
+	// the run gets its own copy of the settings so the dry-run hook doesn't leak into the next check
+	settings := *advanced
+	var dryRunMu sync.Mutex
+	var dryRunLines []string
+	if settings.DryRun {
+		settings.OnDryRun = func(line string) {
+			dryRunMu.Lock()
+			dryRunLines = append(dryRunLines, line)
+			dryRunMu.Unlock()
+		}
+	}
+
 	go func() {
 		ctx, cancel := context.WithTimeout(context.Background(), constants.FiveMinTimeout)
 		defer cancel()
@@ -166,7 +362,7 @@ func checkTemplatesAction(
 			}, true)
 		}
 
-		matched, err := templates.FindMatchingTemplates(ctx, url, templatesDir, constants.FiveSecTimeout, advanced, logger, progressCallback)
+		matched, err := templates.FindMatchingTemplates(ctx, url, templatesDir, tagFilter, severityFilter, constants.FiveSecTimeout, &settings, logger, progressCallback)
 		duration := time.Since(startTime)
 		if err != nil {
 			fyne.CurrentApp().Driver().DoFromGoroutine(func() {
@@ -179,16 +375,35 @@ func checkTemplatesAction(
 			fmt.Sprintf("Checked %d templates in %s", totalTemplates, duration.Round(time.Second)),
 		}
 
+		if settings.DryRun {
+			dryRunMu.Lock()
+			lines = append(lines, dryRunLines...)
+			dryRunMu.Unlock()
+			fyne.CurrentApp().Driver().DoFromGoroutine(func() {
+				resultsOutput.SetText(strings.Join(lines, "\n"))
+			}, true)
+			return
+		}
+
 		fyne.CurrentApp().Driver().DoFromGoroutine(func() {
 			if len(matched) == 0 {
 				lines = append(lines, "No matching templates found.\nYou can create a new template.")
 				resultsOutput.SetText(strings.Join(lines, "\n"))
 				createBtn.Enable()
 			} else {
+				findings := make([]templates.Finding, len(matched))
+				for i, f := range matched {
+					findings[i] = *f
+				}
+				onResults(findings)
 				lines = append(lines, "\nTotal matching: "+strconv.Itoa(len(matched)))
 				lines = append(lines, "\nMatching templates:")
-				for _, tmpl := range matched {
-					lines = append(lines, tmpl.ID)
+				for _, f := range matched {
+					line := f.TemplateID
+					if len(f.ExtractedValues) > 0 {
+						line += " " + formatExtractedValues(f.ExtractedValues)
+					}
+					lines = append(lines, line)
 				}
 				resultsOutput.SetText(strings.Join(lines, "\n"))
 			}
@@ -196,6 +411,16 @@ func checkTemplatesAction(
 	}()
 }
 
+// formatExtractedValues renders extracted values as sorted [key=value] pairs
+func formatExtractedValues(values map[string]string) string {
+	pairs := make([]string, 0, len(values))
+	for k, v := range values {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return "[" + strings.Join(pairs, ", ") + "]"
+}
+
 // createTemplateAction generates a template for the specified URL and offers to save it to a file
 func createTemplateAction(parentWindow fyne.Window, urlEntry *widget.Entry) {
 	url := strings.TrimSpace(urlEntry.Text)
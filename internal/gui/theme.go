@@ -0,0 +1,78 @@
+// package gui implements the user interface of the project - application themes
+package gui
+
+import (
+	"image/color"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
+)
+
+// Theme preference values
+const (
+	themePreferenceKey = "theme"
+	ThemeDark          = "dark"
+	ThemeLight         = "light"
+)
+
+// brandPrimaryColor is the accent color of the dark theme
+var brandPrimaryColor = color.NRGBA{R: 0x7c, G: 0x4d, B: 0xff, A: 0xff}
+
+// NucleiDarkTheme is the dark application theme with the brand accent colors
+type NucleiDarkTheme struct{}
+
+var _ fyne.Theme = (*NucleiDarkTheme)(nil)
+
+// Color returns the dark variant of the default colors with the brand accent
+func (t *NucleiDarkTheme) Color(name fyne.ThemeColorName, _ fyne.ThemeVariant) color.Color {
+	switch name {
+	case theme.ColorNamePrimary, theme.ColorNameFocus, theme.ColorNameHyperlink:
+		return brandPrimaryColor
+	}
+	return theme.DefaultTheme().Color(name, theme.VariantDark)
+}
+
+// Font returns the default font
+func (t *NucleiDarkTheme) Font(style fyne.TextStyle) fyne.Resource {
+	return theme.DefaultTheme().Font(style)
+}
+
+// Icon returns the default icon
+func (t *NucleiDarkTheme) Icon(name fyne.ThemeIconName) fyne.Resource {
+	return theme.DefaultTheme().Icon(name)
+}
+
+// Size returns the default size
+func (t *NucleiDarkTheme) Size(name fyne.ThemeSizeName) float32 {
+	return theme.DefaultTheme().Size(name)
+}
+
+// lightTheme forces the light variant of the default theme
+type lightTheme struct {
+	fyne.Theme
+}
+
+// Color returns the light variant of the default colors
+func (t *lightTheme) Color(name fyne.ThemeColorName, _ fyne.ThemeVariant) color.Color {
+	return t.Theme.Color(name, theme.VariantLight)
+}
+
+// SetTheme applies the dark or light theme and stores the choice in the app preferences
+func SetTheme(a fyne.App, choice string) {
+	a.Preferences().SetString(themePreferenceKey, choice)
+	applyTheme(a, choice)
+}
+
+// ApplySavedTheme restores the theme chosen in a previous session, dark is the default
+func ApplySavedTheme(a fyne.App) {
+	applyTheme(a, a.Preferences().StringWithFallback(themePreferenceKey, ThemeDark))
+}
+
+// applyTheme sets the application theme for the choice
+func applyTheme(a fyne.App, choice string) {
+	if choice == ThemeLight {
+		a.Settings().SetTheme(&lightTheme{Theme: theme.DefaultTheme()})
+		return
+	}
+	a.Settings().SetTheme(&NucleiDarkTheme{})
+}
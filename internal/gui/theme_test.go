@@ -0,0 +1,43 @@
+package gui
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/theme"
+)
+
+func TestSetTheme(t *testing.T) {
+	a := test.NewTempApp(t)
+
+	SetTheme(a, ThemeDark)
+	if _, ok := a.Settings().Theme().(*NucleiDarkTheme); !ok {
+		t.Fatalf("theme = %T, want *NucleiDarkTheme", a.Settings().Theme())
+	}
+	if got := a.Settings().Theme().Color(theme.ColorNamePrimary, theme.VariantLight); got != brandPrimaryColor {
+		t.Errorf("primary color = %v, want the brand color", got)
+	}
+
+	SetTheme(a, ThemeLight)
+	if _, ok := a.Settings().Theme().(*lightTheme); !ok {
+		t.Fatalf("theme = %T, want the light theme", a.Settings().Theme())
+	}
+	if got := a.Preferences().String(themePreferenceKey); got != ThemeLight {
+		t.Errorf("saved theme = %q, want %q", got, ThemeLight)
+	}
+
+	// a new session restores the saved choice
+	a.Settings().SetTheme(theme.DefaultTheme())
+	ApplySavedTheme(a)
+	if _, ok := a.Settings().Theme().(*lightTheme); !ok {
+		t.Errorf("restored theme = %T, want the light theme", a.Settings().Theme())
+	}
+}
+
+func TestApplySavedThemeDefaultsToDark(t *testing.T) {
+	a := test.NewTempApp(t)
+	ApplySavedTheme(a)
+	if _, ok := a.Settings().Theme().(*NucleiDarkTheme); !ok {
+		t.Errorf("theme without a saved choice = %T, want *NucleiDarkTheme", a.Settings().Theme())
+	}
+}
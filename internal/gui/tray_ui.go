@@ -0,0 +1,68 @@
+// package gui implements the user interface of the project - system tray icon
+package gui
+
+import (
+	_ "embed"
+	"fmt"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/driver/desktop"
+
+	"github.com/artnikel/nuclei/internal/constants"
+)
+
+//go:embed tray.png
+var trayIconPNG []byte
+
+// BuildTrayIcon adds the system tray icon whose menu shows the scan status, refreshed every five seconds, and
+// controls the window and the scan. It returns false if the app has no system tray
+func BuildTrayIcon(a fyne.App, w fyne.Window, scan *ScanControl) bool {
+	desk, ok := a.(desktop.App)
+	if !ok {
+		return false
+	}
+
+	status := fyne.NewMenuItem(trayStatus(scan), nil)
+	status.Disabled = true
+	exit := fyne.NewMenuItem("Exit", a.Quit)
+	exit.IsQuit = true
+	menu := fyne.NewMenu("Nuclei",
+		status,
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("Show Window", func() {
+			w.Show()
+			w.RequestFocus()
+		}),
+		fyne.NewMenuItem("Pause Scan", scan.Pause),
+		fyne.NewMenuItem("Cancel Scan", scan.Cancel),
+		fyne.NewMenuItemSeparator(),
+		exit,
+	)
+	desk.SetSystemTrayIcon(fyne.NewStaticResource("tray.png", trayIconPNG))
+	desk.SetSystemTrayMenu(menu)
+
+	go func() {
+		ticker := time.NewTicker(constants.FiveSecTimeout)
+		defer ticker.Stop()
+		for range ticker.C {
+			label := trayStatus(scan)
+			fyne.Do(func() {
+				if status.Label != label {
+					status.Label = label
+					menu.Refresh()
+				}
+			})
+		}
+	}()
+	return true
+}
+
+// trayStatus returns the scan status shown in the tray menu
+func trayStatus(scan *ScanControl) string {
+	if !scan.Running.Load() {
+		return "Nuclei: Idle"
+	}
+	processed, total := scan.Progress()
+	return fmt.Sprintf("Nuclei: Scanning %d/%d", processed, total)
+}
@@ -0,0 +1,56 @@
+package gui
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/test"
+)
+
+// trayApp is a test app with a system tray recording the icon and menu set on it
+type trayApp struct {
+	fyne.App
+	icon fyne.Resource
+	menu *fyne.Menu
+}
+
+func (a *trayApp) SetSystemTrayMenu(menu *fyne.Menu)    { a.menu = menu }
+func (a *trayApp) SetSystemTrayIcon(icon fyne.Resource) { a.icon = icon }
+
+func TestBuildTrayIcon(t *testing.T) {
+	a := &trayApp{App: test.NewTempApp(t)}
+	var paused, cancelled bool
+	scan := &ScanControl{Running: &atomic.Bool{}, Pause: func() { paused = true }, Cancel: func() { cancelled = true }}
+
+	if BuildTrayIcon(test.NewTempApp(t), test.NewTempWindow(t, nil), scan) {
+		t.Error("tray icon built for an app without a system tray")
+	}
+	if !BuildTrayIcon(a, test.NewTempWindow(t, nil), scan) {
+		t.Fatal("tray icon not built")
+	}
+	if a.icon == nil || len(a.icon.Content()) == 0 || a.menu == nil {
+		t.Fatal("tray icon or menu not set")
+	}
+
+	items := make(map[string]*fyne.MenuItem)
+	for _, item := range a.menu.Items {
+		items[item.Label] = item
+	}
+	for _, label := range []string{"Nuclei: Idle", "Show Window", "Pause Scan", "Cancel Scan", "Exit"} {
+		if items[label] == nil {
+			t.Errorf("tray menu misses %q", label)
+		}
+	}
+	items["Pause Scan"].Action()
+	items["Cancel Scan"].Action()
+	if !paused || !cancelled {
+		t.Errorf("tray menu paused %v, cancelled %v, want both", paused, cancelled)
+	}
+
+	scan.Running.Store(true)
+	scan.setProgress(3, 10)
+	if status := trayStatus(scan); status != "Nuclei: Scanning 3/10" {
+		t.Errorf("trayStatus = %q, want Nuclei: Scanning 3/10", status)
+	}
+}
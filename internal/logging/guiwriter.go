@@ -0,0 +1,64 @@
+// package logging - forwarding of log records to the GUI
+package logging
+
+import (
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// GUIWriter forwards every written log record at or above its level to a sink, records written before a sink
+// is set are dropped. It's safe for concurrent use
+type GUIWriter struct {
+	mu    sync.Mutex
+	sink  func(msg string)
+	level slog.Level
+}
+
+// NewGUIWriter returns a writer forwarding records of every level once a sink is set
+func NewGUIWriter() *GUIWriter {
+	return &GUIWriter{level: slog.LevelDebug}
+}
+
+// SetSink sets the function receiving the records, nil stops forwarding
+func (w *GUIWriter) SetSink(sink func(msg string)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.sink = sink
+}
+
+// SetLevel drops the records below level
+func (w *GUIWriter) SetLevel(level slog.Level) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.level = level
+}
+
+// Write forwards the record in p to the sink, it never fails
+func (w *GUIWriter) Write(p []byte) (int, error) {
+	msg := string(p)
+	w.mu.Lock()
+	sink, level := w.sink, w.level
+	w.mu.Unlock()
+	if sink != nil && recordLevel(msg) >= level {
+		sink(msg)
+	}
+	return len(p), nil
+}
+
+// recordLevel returns the level of a record written by the text or JSON handler, info if it has none
+func recordLevel(record string) slog.Level {
+	// key and the character ending the value in the text and the JSON format
+	for _, format := range [][2]string{{"level=", " "}, {`"level":"`, `"`}} {
+		_, rest, ok := strings.Cut(record, format[0])
+		if !ok {
+			continue
+		}
+		name, _, _ := strings.Cut(rest, format[1])
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(name)); err == nil {
+			return level
+		}
+	}
+	return slog.LevelInfo
+}
@@ -1,22 +1,31 @@
-// Package logging provides a simple logging setup for informational and error messages
+// Package logging provides a structured logging setup for informational and error messages
 package logging
 
 import (
-	"log"
+	"fmt"
+	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/artnikel/nuclei/internal/constants"
 )
 
-// Logger holds separate loggers for informational and error messages
+// Supported log formats
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Logger writes structured log records to the application log file
 type Logger struct {
-	Info  *log.Logger
-	Error *log.Logger
+	*slog.Logger
 }
 
-// NewLogger sets up the logging system
-func NewLogger(dir string) (*Logger, error) {
+// NewLogger sets up the logging system, format selects the "text" (default) or "json" handler.
+// Records are written to the log file and to every extra writer, e.g. a GUIWriter
+func NewLogger(dir, format string, extra ...io.Writer) (*Logger, error) {
 	err := os.MkdirAll(dir, constants.DirPerm)
 	if err != nil {
 		return nil, err
@@ -28,8 +37,24 @@ func NewLogger(dir string) (*Logger, error) {
 		return nil, err
 	}
 
-	return &Logger{
-		Info:  log.New(logFile, "INFO: ", log.Ldate|log.Ltime|log.Lshortfile),
-		Error: log.New(logFile, "ERROR: ", log.Ldate|log.Ltime|log.Lshortfile),
-	}, nil
-}
\ No newline at end of file
+	out := io.MultiWriter(append([]io.Writer{logFile}, extra...)...)
+	opts := &slog.HandlerOptions{AddSource: true}
+	var handler slog.Handler
+	switch strings.ToLower(format) {
+	case FormatText, "":
+		handler = slog.NewTextHandler(out, opts)
+	case FormatJSON:
+		handler = slog.NewJSONHandler(out, opts)
+	default:
+		logFile.Close()
+		return nil, fmt.Errorf("unsupported log format: %s", format)
+	}
+
+	return &Logger{Logger: slog.New(handler)}, nil
+}
+
+// Fatal logs the message at error level and terminates the process
+func (l *Logger) Fatal(msg string, args ...any) {
+	l.Error(msg, args...)
+	os.Exit(1)
+}
@@ -0,0 +1,110 @@
+package logging
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewLoggerJSON(t *testing.T) {
+	dir := t.TempDir()
+	var extra bytes.Buffer
+	logger, err := NewLogger(dir, FormatJSON, &extra)
+	if err != nil {
+		t.Fatalf("NewLogger: %v", err)
+	}
+	logger.Info("Template matched", slog.String("template_id", "git-config"), slog.Int("status", 200))
+
+	data, err := os.ReadFile(filepath.Join(dir, "app.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, out := range map[string][]byte{"file": data, "extra writer": extra.Bytes()} {
+		scanner := bufio.NewScanner(bytes.NewReader(out))
+		if !scanner.Scan() {
+			t.Fatalf("%s: no log record written", name)
+		}
+		var record map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("%s: record is not JSON: %v\n%s", name, err, scanner.Bytes())
+		}
+		for _, key := range []string{"time", "level", "msg", "source", "template_id", "status"} {
+			if _, ok := record[key]; !ok {
+				t.Errorf("%s: record lacks key %q: %v", name, key, record)
+			}
+		}
+		if record["msg"] != "Template matched" || record["template_id"] != "git-config" || record["status"] != float64(200) {
+			t.Errorf("%s: record = %v", name, record)
+		}
+	}
+}
+
+func TestNewLoggerText(t *testing.T) {
+	dir := t.TempDir()
+	logger, err := NewLogger(dir, "")
+	if err != nil {
+		t.Fatalf("NewLogger: %v", err)
+	}
+	logger.Warn("Skipping template", slog.String("template_id", "a b"))
+	data, err := os.ReadFile(filepath.Join(dir, "app.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	line := string(data)
+	if !strings.Contains(line, "level=WARN") || !strings.Contains(line, `template_id="a b"`) {
+		t.Errorf("text record = %q", line)
+	}
+}
+
+func TestNewLoggerUnsupportedFormat(t *testing.T) {
+	if _, err := NewLogger(t.TempDir(), "xml"); err == nil {
+		t.Fatal("NewLogger accepted an unsupported format")
+	}
+}
+
+func TestNewLoggerAppends(t *testing.T) {
+	dir := t.TempDir()
+	for i := 0; i < 2; i++ {
+		logger, err := NewLogger(dir, FormatJSON)
+		if err != nil {
+			t.Fatalf("NewLogger: %v", err)
+		}
+		logger.Info("run")
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "app.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(data), "\n"); n != 2 {
+		t.Errorf("log file has %d records, want 2", n)
+	}
+}
+
+func TestGUIWriter(t *testing.T) {
+	for _, format := range []string{FormatText, FormatJSON} {
+		t.Run(format, func(t *testing.T) {
+			w := NewGUIWriter()
+			logger, err := NewLogger(t.TempDir(), format, w)
+			if err != nil {
+				t.Fatalf("NewLogger: %v", err)
+			}
+			logger.Info("before the sink")
+
+			var got []string
+			w.SetSink(func(msg string) { got = append(got, msg) })
+			w.SetLevel(slog.LevelWarn)
+			logger.Info("scan started")
+			logger.Warn("target unreachable")
+			logger.Error("template failed")
+
+			if len(got) != 2 || !strings.Contains(got[0], "target unreachable") || !strings.Contains(got[1], "template failed") {
+				t.Errorf("forwarded records = %q, want the warn and error records", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+// Package metrics exposes scan telemetry in the Prometheus format
+package metrics
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	TargetsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "nuclei_targets_total",
+		Help: "Number of targets submitted for scanning.",
+	})
+	TargetsProcessed = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "nuclei_targets_processed",
+		Help: "Number of targets whose scan has finished.",
+	})
+	MatchesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "nuclei_matches_total",
+		Help: "Number of template matches by severity.",
+	}, []string{"severity"})
+	ErrorsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "nuclei_errors_total",
+		Help: "Number of targets whose scan failed.",
+	})
+	TemplatesLoaded = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "nuclei_templates_loaded",
+		Help: "Number of templates loaded for the last scan.",
+	})
+	ScanDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "nuclei_scan_duration_seconds",
+		Help:    "Time spent scanning a single target.",
+		Buckets: prometheus.ExponentialBuckets(0.1, 2, 12),
+	})
+)
+
+func init() {
+	prometheus.MustRegister(TargetsTotal, TargetsProcessed, MatchesTotal, ErrorsTotal, TemplatesLoaded, ScanDuration)
+}
+
+// RecordMatch increments the match counter for the given severity
+func RecordMatch(severity string) {
+	severity = strings.ToLower(severity)
+	if severity == "" {
+		severity = "unknown"
+	}
+	MatchesTotal.WithLabelValues(severity).Inc()
+}
+
+// Serve exposes the /metrics endpoint on addr, it blocks until the server fails
+func Serve(addr string) error {
+	http.Handle("/metrics", promhttp.Handler())
+	return http.ListenAndServe(addr, nil)
+}
@@ -0,0 +1,98 @@
+package metrics_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/artnikel/nuclei/internal/logging"
+	"github.com/artnikel/nuclei/internal/metrics"
+	"github.com/artnikel/nuclei/internal/scanner"
+)
+
+// freeAddr returns a local address nothing listens on
+func freeAddr(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	return l.Addr().String()
+}
+
+// scrape fetches /metrics, retrying until the server is up
+func scrape(t *testing.T, addr string) string {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		resp, err := http.Get("http://" + addr + "/metrics")
+		if err == nil {
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return string(body)
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("metrics server not reachable: %v", err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestMetricsEndpoint(t *testing.T) {
+	addr := freeAddr(t)
+	go metrics.Serve(addr)
+
+	targets := make(chan string, 3)
+	for _, target := range []string{"http://a.test", "http://b.test", "http://c.test"} {
+		targets <- target
+	}
+	close(targets)
+
+	logger := &logging.Logger{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	processFn := func(ctx context.Context, target string) error {
+		switch target {
+		case "http://a.test":
+			metrics.RecordMatch("High")
+		case "http://b.test":
+			return errors.New("connection refused")
+		}
+		return nil
+	}
+	<-scanner.StartWorkers(context.Background(), targets, 2, nil, processFn, logger)
+	metrics.TemplatesLoaded.Set(7)
+
+	body := scrape(t, addr)
+	for _, want := range []string{
+		"nuclei_targets_total 3",
+		"nuclei_targets_processed 3",
+		"nuclei_errors_total 1",
+		`nuclei_matches_total{severity="high"} 1`,
+		"nuclei_templates_loaded 7",
+		"nuclei_scan_duration_seconds_count 3",
+	} {
+		if !strings.Contains(body, want+"\n") {
+			t.Errorf("metrics output lacks %q", want)
+		}
+	}
+}
+
+func TestRecordMatchUnknownSeverity(t *testing.T) {
+	before := testutil.ToFloat64(metrics.MatchesTotal.WithLabelValues("unknown"))
+	metrics.RecordMatch("")
+	metrics.RecordMatch("")
+	if got := testutil.ToFloat64(metrics.MatchesTotal.WithLabelValues("unknown")) - before; got != 2 {
+		t.Errorf("matches without severity recorded as unknown = %v, want 2", got)
+	}
+}
@@ -0,0 +1,180 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/artnikel/nuclei/internal/config"
+	"github.com/artnikel/nuclei/internal/constants"
+	"github.com/artnikel/nuclei/internal/logging"
+	"github.com/artnikel/nuclei/internal/templates"
+)
+
+const (
+	// defaultGitHubAPIURL is the endpoint of the public GitHub API
+	defaultGitHubAPIURL = "https://api.github.com"
+	// githubLabel is set on every created issue, existing issues are looked up by it
+	githubLabel = "nuclei"
+	// githubRequestsPerMinute keeps the notifier below the GitHub secondary rate limits
+	githubRequestsPerMinute = 30
+	// githubPageSize is the number of issues listed per request
+	githubPageSize = 100
+)
+
+// githubSeverityLabels are the severities with a label of their own in the repository
+var githubSeverityLabels = map[string]bool{
+	"critical": true,
+	"high":     true,
+	"medium":   true,
+}
+
+// GitHubIssueNotifier creates a GitHub issue for every finding, unless an open issue labeled nuclei has the same
+// title. Failed requests are logged so they never block the scan
+type GitHubIssueNotifier struct {
+	mu      sync.Mutex
+	cfg     config.GitHubConfig
+	client  *http.Client
+	limiter *rate.Limiter
+	logger  *logging.Logger
+	// titles holds the titles of the open issues, listed on the first finding
+	titles map[string]bool
+}
+
+// NewGitHubIssueNotifier creates a GitHubIssueNotifier for the repository of cfg
+func NewGitHubIssueNotifier(cfg config.GitHubConfig, logger *logging.Logger) *GitHubIssueNotifier {
+	if cfg.APIURL == "" {
+		cfg.APIURL = defaultGitHubAPIURL
+	}
+	cfg.APIURL = strings.TrimRight(cfg.APIURL, "/")
+	return &GitHubIssueNotifier{
+		cfg:     cfg,
+		client:  &http.Client{Timeout: constants.TenSecTimeout},
+		limiter: rate.NewLimiter(rate.Every(time.Minute/githubRequestsPerMinute), 1),
+		logger:  logger,
+	}
+}
+
+// Write creates the issue of the finding
+func (g *GitHubIssueNotifier) Write(f *templates.Finding) error {
+	if err := g.Notify(f); err != nil && g.logger != nil {
+		g.logger.Error("Failed to create GitHub issue",
+			slog.String("template_id", f.TemplateID),
+			slog.String("target", f.Target),
+			slog.Any("error", err),
+		)
+	}
+	return nil
+}
+
+// Close is a no-op, issues are created immediately
+func (g *GitHubIssueNotifier) Close() error {
+	return nil
+}
+
+// Notify creates the issue of the finding, findings with an open issue are skipped
+func (g *GitHubIssueNotifier) Notify(f *templates.Finding) error {
+	title := issueTitle(f)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.titles == nil {
+		titles, err := g.openIssueTitles()
+		if err != nil {
+			return fmt.Errorf("failed to list issues: %w", err)
+		}
+		g.titles = titles
+	}
+	if g.titles[title] {
+		return nil
+	}
+
+	labels := []string{githubLabel}
+	if severity := strings.ToLower(f.Severity); githubSeverityLabels[severity] {
+		labels = append(labels, severity)
+	}
+	body, err := json.Marshal(map[string]any{
+		"title":  title,
+		"body":   strings.Join(issueDescription(f), "\n"),
+		"labels": labels,
+	})
+	if err != nil {
+		return err
+	}
+	if err := g.do(http.MethodPost, g.repoPath()+"/issues", body, nil); err != nil {
+		return fmt.Errorf("failed to create issue: %w", err)
+	}
+	g.titles[title] = true
+	return nil
+}
+
+// openIssueTitles returns the titles of all open issues labeled nuclei
+func (g *GitHubIssueNotifier) openIssueTitles() (map[string]bool, error) {
+	titles := make(map[string]bool)
+	for page := 1; ; page++ {
+		query := url.Values{
+			"state":    {"open"},
+			"labels":   {githubLabel},
+			"per_page": {strconv.Itoa(githubPageSize)},
+			"page":     {strconv.Itoa(page)},
+		}
+		var issues []struct {
+			Title string `json:"title"`
+		}
+		if err := g.do(http.MethodGet, g.repoPath()+"/issues?"+query.Encode(), nil, &issues); err != nil {
+			return nil, err
+		}
+		for _, issue := range issues {
+			titles[issue.Title] = true
+		}
+		if len(issues) < githubPageSize {
+			return titles, nil
+		}
+	}
+}
+
+// repoPath returns the API path of the repository
+func (g *GitHubIssueNotifier) repoPath() string {
+	return "/repos/" + url.PathEscape(g.cfg.Owner) + "/" + url.PathEscape(g.cfg.Repo)
+}
+
+// do sends a rate-limited request to the GitHub API and decodes the response into out if it's not nil
+func (g *GitHubIssueNotifier) do(method, path string, body []byte, out any) error {
+	if err := g.limiter.Wait(context.Background()); err != nil {
+		return err
+	}
+	req, err := http.NewRequest(method, g.cfg.APIURL+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+g.cfg.Token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("github returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
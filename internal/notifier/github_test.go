@@ -0,0 +1,136 @@
+package notifier
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/artnikel/nuclei/internal/config"
+	"github.com/artnikel/nuclei/internal/templates"
+)
+
+// githubIssue is an issue created through the mock GitHub API
+type githubIssue struct {
+	Title  string   `json:"title"`
+	Body   string   `json:"body"`
+	Labels []string `json:"labels"`
+}
+
+// mockGitHub is the issues API of the acme/webapp repository, open holds the titles of its open nuclei issues
+type mockGitHub struct {
+	t       *testing.T
+	mu      sync.Mutex
+	open    []string
+	created []githubIssue
+	listed  []int
+}
+
+func (m *mockGitHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if r.Header.Get("Authorization") != "Bearer gh-token" || r.Header.Get("Accept") != "application/vnd.github+json" {
+		http.Error(w, `{"message":"Bad credentials"}`, http.StatusUnauthorized)
+		return
+	}
+	if r.URL.Path != "/api/v3/repos/acme/webapp/issues" {
+		http.NotFound(w, r)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		q := r.URL.Query()
+		if q.Get("state") != "open" || q.Get("labels") != githubLabel {
+			m.t.Errorf("issues listed with %v", q)
+		}
+		page, _ := strconv.Atoi(q.Get("page"))
+		perPage, _ := strconv.Atoi(q.Get("per_page"))
+		m.listed = append(m.listed, page)
+		issues := []githubIssue{}
+		for i := (page - 1) * perPage; i < len(m.open) && i < page*perPage; i++ {
+			issues = append(issues, githubIssue{Title: m.open[i]})
+		}
+		json.NewEncoder(w).Encode(issues)
+	case http.MethodPost:
+		var issue githubIssue
+		if err := json.NewDecoder(r.Body).Decode(&issue); err != nil {
+			m.t.Errorf("decode issue: %v", err)
+		}
+		m.created = append(m.created, issue)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]int{"number": len(m.created)})
+	}
+}
+
+func TestGitHubIssueNotifier(t *testing.T) {
+	mock := &mockGitHub{t: t}
+	for i := 0; i < githubPageSize; i++ {
+		mock.open = append(mock.open, fmt.Sprintf("Old finding %d on http://old.test", i))
+	}
+	// the duplicate is on the second page of open issues
+	mock.open = append(mock.open, "Exposed git config on http://b.test")
+	srv := httptest.NewServer(mock)
+	t.Cleanup(srv.Close)
+
+	n := NewGitHubIssueNotifier(config.GitHubConfig{Token: "gh-token", Owner: "acme", Repo: "webapp", APIURL: srv.URL + "/api/v3/"}, nil)
+	if n.limiter.Limit() != rate.Every(time.Minute/githubRequestsPerMinute) {
+		t.Errorf("rate limit = %v requests per second, want %d per minute", n.limiter.Limit(), githubRequestsPerMinute)
+	}
+	n.limiter = rate.NewLimiter(rate.Inf, 1)
+
+	findings := []*templates.Finding{
+		{TemplateID: "log4shell", Name: "Log4Shell", Target: "http://a.test", Severity: "critical", MatchedURL: "http://a.test/api",
+			Description: "JNDI lookup evaluated", TemplatePath: "cves/2021/CVE-2021-44228.yaml", ExtractedValues: map[string]string{"version": "2.14.1"}},
+		{TemplateID: "git-config", Name: "Exposed git config", Target: "http://b.test", Severity: "high"},
+		{TemplateID: "tech-detect", Name: "Tech detect", Target: "http://a.test", Severity: "info"},
+		{TemplateID: "log4shell", Name: "Log4Shell", Target: "http://a.test", Severity: "critical"},
+	}
+	for _, f := range findings {
+		if err := n.Notify(f); err != nil {
+			t.Fatalf("Notify %s on %s: %v", f.TemplateID, f.Target, err)
+		}
+	}
+
+	mock.mu.Lock()
+	defer mock.mu.Unlock()
+	if len(mock.listed) != 2 || mock.listed[0] != 1 || mock.listed[1] != 2 {
+		t.Errorf("listed pages %v, want 1 and 2 once", mock.listed)
+	}
+	if len(mock.created) != 2 {
+		t.Fatalf("created %d issues %+v, want 2", len(mock.created), mock.created)
+	}
+	critical, info := mock.created[0], mock.created[1]
+	if critical.Title != "Log4Shell on http://a.test" || strings.Join(critical.Labels, ",") != "nuclei,critical" {
+		t.Errorf("critical issue = %q labeled %v", critical.Title, critical.Labels)
+	}
+	for _, want := range []string{"JNDI lookup evaluated", "**Matched URL:** http://a.test/api", "**Template file:** `cves/2021/CVE-2021-44228.yaml`", "- version: `2.14.1`"} {
+		if !strings.Contains(critical.Body, want) {
+			t.Errorf("issue body misses %q:\n%s", want, critical.Body)
+		}
+	}
+	if info.Title != "Tech detect on http://a.test" || strings.Join(info.Labels, ",") != "nuclei" {
+		t.Errorf("info issue = %q labeled %v, want only the nuclei label", info.Title, info.Labels)
+	}
+}
+
+func TestGitHubIssueNotifierErrors(t *testing.T) {
+	srv := httptest.NewServer(&mockGitHub{t: t})
+	t.Cleanup(srv.Close)
+	n := NewGitHubIssueNotifier(config.GitHubConfig{Token: "wrong", Owner: "acme", Repo: "webapp", APIURL: srv.URL + "/api/v3"}, nil)
+	n.limiter = rate.NewLimiter(rate.Inf, 1)
+
+	f := &templates.Finding{TemplateID: "log4shell", Name: "Log4Shell", Target: "http://a.test", Severity: "critical"}
+	if err := n.Notify(f); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("Notify with a wrong token = %v, want the status error", err)
+	}
+	if err := n.Write(f); err != nil {
+		t.Errorf("Write = %v, failed requests must not stop the scan", err)
+	}
+}
@@ -0,0 +1,194 @@
+// Package notifier creates issues in external trackers for the findings of a scan
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/artnikel/nuclei/internal/config"
+	"github.com/artnikel/nuclei/internal/constants"
+	"github.com/artnikel/nuclei/internal/logging"
+	"github.com/artnikel/nuclei/internal/templates"
+)
+
+// jiraIssueType is the type of the created issues
+const jiraIssueType = "Bug"
+
+// jiraPriorities maps the reported severities to Jira priorities
+var jiraPriorities = map[string]string{
+	"critical": "Blocker",
+	"high":     "Major",
+}
+
+// JiraNotifier creates a Jira issue for every high and critical finding, unless an open issue with the same
+// summary already exists. Failed requests are logged so they never block the scan
+type JiraNotifier struct {
+	mu     sync.Mutex
+	cfg    config.JiraConfig
+	client *http.Client
+	logger *logging.Logger
+	// reported holds the summaries already checked in this run
+	reported map[string]bool
+}
+
+// NewJiraNotifier creates a JiraNotifier for the project of cfg
+func NewJiraNotifier(cfg config.JiraConfig, logger *logging.Logger) *JiraNotifier {
+	cfg.URL = strings.TrimRight(cfg.URL, "/")
+	return &JiraNotifier{
+		cfg:      cfg,
+		client:   &http.Client{Timeout: constants.TenSecTimeout},
+		logger:   logger,
+		reported: make(map[string]bool),
+	}
+}
+
+// Write creates the issue of the finding if its severity is high or critical
+func (j *JiraNotifier) Write(f *templates.Finding) error {
+	if err := j.Notify(f); err != nil && j.logger != nil {
+		j.logger.Error("Failed to create Jira issue",
+			slog.String("template_id", f.TemplateID),
+			slog.String("target", f.Target),
+			slog.Any("error", err),
+		)
+	}
+	return nil
+}
+
+// Close is a no-op, issues are created immediately
+func (j *JiraNotifier) Close() error {
+	return nil
+}
+
+// Notify creates the issue of the finding, findings below high and findings with an open issue are skipped
+func (j *JiraNotifier) Notify(f *templates.Finding) error {
+	priority, ok := jiraPriorities[strings.ToLower(f.Severity)]
+	if !ok {
+		return nil
+	}
+	summary := issueTitle(f)
+
+	// concurrent findings with the same summary must not both pass the duplicate check
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.reported[summary] {
+		return nil
+	}
+	exists, err := j.openIssueExists(summary)
+	if err != nil {
+		return fmt.Errorf("failed to search issues: %w", err)
+	}
+	if !exists {
+		if err := j.createIssue(f, summary, priority); err != nil {
+			return err
+		}
+	}
+	j.reported[summary] = true
+	return nil
+}
+
+// openIssueExists reports whether the project has an unresolved issue with exactly this summary
+func (j *JiraNotifier) openIssueExists(summary string) (bool, error) {
+	jql := fmt.Sprintf(`project = %s AND statusCategory != Done AND summary ~ %s`, jqlQuote(j.cfg.Project), jqlQuote(summary))
+	body, err := json.Marshal(map[string]any{
+		"jql":        jql,
+		"fields":     []string{"summary"},
+		"maxResults": 50,
+	})
+	if err != nil {
+		return false, err
+	}
+	var result struct {
+		Issues []struct {
+			Fields struct {
+				Summary string `json:"summary"`
+			} `json:"fields"`
+		} `json:"issues"`
+	}
+	if err := j.do(http.MethodPost, "/rest/api/3/search", body, &result); err != nil {
+		return false, err
+	}
+	// summary ~ is a text search, only an exact match is a duplicate
+	for _, issue := range result.Issues {
+		if issue.Fields.Summary == summary {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// createIssue creates the issue of the finding
+func (j *JiraNotifier) createIssue(f *templates.Finding, summary, priority string) error {
+	labels := make([]string, 0, len(f.Tags))
+	for _, tag := range f.Tags {
+		// Jira labels can't contain spaces
+		if tag = strings.Join(strings.Fields(tag), "-"); tag != "" {
+			labels = append(labels, tag)
+		}
+	}
+	body, err := json.Marshal(map[string]any{
+		"fields": map[string]any{
+			"project":     map[string]string{"key": j.cfg.Project},
+			"issuetype":   map[string]string{"name": jiraIssueType},
+			"priority":    map[string]string{"name": priority},
+			"summary":     summary,
+			"description": jiraDocument(issueDescription(f)),
+			"labels":      labels,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if err := j.do(http.MethodPost, "/rest/api/3/issue", body, nil); err != nil {
+		return fmt.Errorf("failed to create issue: %w", err)
+	}
+	return nil
+}
+
+// do sends a request to the Jira REST API and decodes the response into out if it's not nil
+func (j *JiraNotifier) do(method, path string, body []byte, out any) error {
+	req, err := http.NewRequest(method, j.cfg.URL+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(j.cfg.Email, j.cfg.Token)
+
+	resp, err := j.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("jira returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// jiraDocument wraps the lines into the Atlassian Document Format required by the v3 API, one paragraph per line
+func jiraDocument(lines []string) map[string]any {
+	content := make([]map[string]any, 0, len(lines))
+	for _, line := range lines {
+		paragraph := map[string]any{"type": "paragraph"}
+		if line != "" {
+			paragraph["content"] = []map[string]string{{"type": "text", "text": line}}
+		}
+		content = append(content, paragraph)
+	}
+	return map[string]any{"type": "doc", "version": 1, "content": content}
+}
+
+// jqlQuote quotes s as a JQL string
+func jqlQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
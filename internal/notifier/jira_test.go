@@ -0,0 +1,161 @@
+package notifier
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/artnikel/nuclei/internal/config"
+	"github.com/artnikel/nuclei/internal/templates"
+)
+
+// jiraIssue is an issue created through the mock Jira API
+type jiraIssue struct {
+	Summary     string
+	Priority    string
+	Labels      []string
+	Description string
+}
+
+// mockJira is a Jira REST API v3 keeping the created issues, open holds the summaries of issues created before
+type mockJira struct {
+	t        *testing.T
+	mu       sync.Mutex
+	open     []string
+	created  []jiraIssue
+	searches int
+}
+
+func (m *mockJira) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if email, token, ok := r.BasicAuth(); !ok || email != "bot@example.com" || token != "jira-token" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	switch r.URL.Path {
+	case "/rest/api/3/search":
+		m.searches++
+		var search struct {
+			JQL string `json:"jql"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&search); err != nil || !strings.HasPrefix(search.JQL, `project = "SEC" AND statusCategory != Done AND summary ~ `) {
+			m.t.Errorf("search jql = %q, %v", search.JQL, err)
+		}
+		var issues []map[string]any
+		for _, summary := range m.open {
+			// summary ~ is a text search, it also returns issues whose summary only contains the searched one
+			if strings.Contains(search.JQL, summary) {
+				issues = append(issues, map[string]any{"fields": map[string]string{"summary": summary}})
+				issues = append(issues, map[string]any{"fields": map[string]string{"summary": summary + " (staging)"}})
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]any{"issues": issues})
+	case "/rest/api/3/issue":
+		var body struct {
+			Fields struct {
+				Project     struct{ Key string }  `json:"project"`
+				IssueType   struct{ Name string } `json:"issuetype"`
+				Priority    struct{ Name string } `json:"priority"`
+				Summary     string                `json:"summary"`
+				Labels      []string              `json:"labels"`
+				Description struct {
+					Type    string `json:"type"`
+					Content []struct {
+						Content []struct {
+							Text string `json:"text"`
+						} `json:"content"`
+					} `json:"content"`
+				} `json:"description"`
+			} `json:"fields"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			m.t.Errorf("decode issue: %v", err)
+		}
+		if body.Fields.Project.Key != "SEC" || body.Fields.IssueType.Name != jiraIssueType || body.Fields.Description.Type != "doc" {
+			m.t.Errorf("issue fields = %+v", body.Fields)
+		}
+		var lines []string
+		for _, paragraph := range body.Fields.Description.Content {
+			for _, text := range paragraph.Content {
+				lines = append(lines, text.Text)
+			}
+		}
+		m.created = append(m.created, jiraIssue{
+			Summary:     body.Fields.Summary,
+			Priority:    body.Fields.Priority.Name,
+			Labels:      body.Fields.Labels,
+			Description: strings.Join(lines, "\n"),
+		})
+		m.open = append(m.open, body.Fields.Summary)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]string{"key": "SEC-1"})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestJiraNotifier(t *testing.T) {
+	mock := &mockJira{t: t, open: []string{"Exposed git config on http://old.test"}}
+	srv := httptest.NewServer(mock)
+	t.Cleanup(srv.Close)
+	n := NewJiraNotifier(config.JiraConfig{URL: srv.URL + "/", Email: "bot@example.com", Token: "jira-token", Project: "SEC"}, nil)
+
+	findings := []*templates.Finding{
+		{TemplateID: "log4shell", Name: "Log4Shell", Target: "http://a.test", Severity: "critical", CVEID: "CVE-2021-44228",
+			MatchedURL: "http://a.test/api", Description: "JNDI lookup evaluated", Tags: []string{"cve", "remote code"},
+			ExtractedValues: map[string]string{"version": "2.14.1"}},
+		{TemplateID: "git-config", Name: "Exposed git config", Target: "http://b.test", Severity: "high", MatchedURL: "http://b.test/.git/config"},
+		{TemplateID: "git-config", Name: "Exposed git config", Target: "http://old.test", Severity: "high"},
+		{TemplateID: "tech-detect", Name: "Tech detect", Target: "http://a.test", Severity: "medium"},
+		{TemplateID: "log4shell", Name: "Log4Shell", Target: "http://a.test", Severity: "critical"},
+	}
+	for _, f := range findings {
+		if err := n.Notify(f); err != nil {
+			t.Fatalf("Notify %s on %s: %v", f.TemplateID, f.Target, err)
+		}
+	}
+
+	mock.mu.Lock()
+	defer mock.mu.Unlock()
+	if len(mock.created) != 2 {
+		t.Fatalf("created %d issues %+v, want 2", len(mock.created), mock.created)
+	}
+	critical, high := mock.created[0], mock.created[1]
+	if critical.Summary != "Log4Shell on http://a.test" || critical.Priority != "Blocker" {
+		t.Errorf("critical issue = %+v, want Blocker Log4Shell on http://a.test", critical)
+	}
+	if strings.Join(critical.Labels, ",") != "cve,remote-code" {
+		t.Errorf("labels = %v, want the template tags", critical.Labels)
+	}
+	for _, want := range []string{"JNDI lookup evaluated", "**Matched URL:** http://a.test/api", "**CVE:** CVE-2021-44228", "- version: `2.14.1`"} {
+		if !strings.Contains(critical.Description, want) {
+			t.Errorf("description misses %q:\n%s", want, critical.Description)
+		}
+	}
+	if high.Summary != "Exposed git config on http://b.test" || high.Priority != "Major" {
+		t.Errorf("high issue = %+v, want Major Exposed git config on http://b.test", high)
+	}
+	// the medium finding isn't searched and the repeated critical one is known from this run
+	if mock.searches != 3 {
+		t.Errorf("%d searches, want 3", mock.searches)
+	}
+}
+
+func TestJiraNotifierErrors(t *testing.T) {
+	mock := &mockJira{t: t}
+	srv := httptest.NewServer(mock)
+	t.Cleanup(srv.Close)
+	n := NewJiraNotifier(config.JiraConfig{URL: srv.URL, Email: "bot@example.com", Token: "wrong", Project: "SEC"}, nil)
+
+	f := &templates.Finding{TemplateID: "log4shell", Name: "Log4Shell", Target: "http://a.test", Severity: "critical"}
+	if err := n.Notify(f); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("Notify with a wrong token = %v, want the status error", err)
+	}
+	if err := n.Write(f); err != nil {
+		t.Errorf("Write = %v, failed requests must not stop the scan", err)
+	}
+}
@@ -0,0 +1,40 @@
+package notifier
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/artnikel/nuclei/internal/templates"
+)
+
+// issueTitle returns the title of the issue reporting the finding, issues are deduplicated by it
+func issueTitle(f *templates.Finding) string {
+	return f.Name + " on " + f.Target
+}
+
+// issueDescription returns the markdown lines describing the finding
+func issueDescription(f *templates.Finding) []string {
+	var lines []string
+	if f.Description != "" {
+		lines = append(lines, f.Description, "")
+	}
+	lines = append(lines, "**Template:** "+f.TemplateID, "**Matched URL:** "+f.MatchedURL)
+	if f.TemplatePath != "" {
+		lines = append(lines, "**Template file:** `"+f.TemplatePath+"`")
+	}
+	if f.CVEID != "" {
+		lines = append(lines, "**CVE:** "+f.CVEID)
+	}
+	if len(f.ExtractedValues) > 0 {
+		lines = append(lines, "", "**Extracted values:**")
+		names := make([]string, 0, len(f.ExtractedValues))
+		for name := range f.ExtractedValues {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			lines = append(lines, fmt.Sprintf("- %s: `%s`", name, f.ExtractedValues[name]))
+		}
+	}
+	return lines
+}
@@ -0,0 +1,193 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/artnikel/nuclei/internal/constants"
+	"github.com/artnikel/nuclei/internal/logging"
+	"github.com/artnikel/nuclei/internal/templates"
+)
+
+const (
+	// elasticsearchBatchSize is the number of findings sent per bulk request
+	elasticsearchBatchSize = 50
+	// elasticsearchFlushInterval is the longest time a finding waits in the batch
+	elasticsearchFlushInterval = constants.FiveSecTimeout
+)
+
+// ElasticsearchConfig is the Elasticsearch or OpenSearch cluster findings are indexed into, the credentials
+// are sent with basic auth when the username is set
+type ElasticsearchConfig struct {
+	URL      string
+	Index    string
+	Username string
+	Password string
+}
+
+// ElasticsearchWriter indexes findings through the bulk API in batches, sent when full and every
+// elasticsearchFlushInterval. Batches are sent by a background goroutine and the ones the cluster rejects are
+// logged and dropped so they never block the scan
+type ElasticsearchWriter struct {
+	mu     sync.Mutex
+	cfg    ElasticsearchConfig
+	client *http.Client
+	logger *logging.Logger
+	batch  []templates.Finding
+	full   chan struct{}
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+// NewElasticsearchWriter creates an ElasticsearchWriter and starts its periodic flush
+func NewElasticsearchWriter(cfg ElasticsearchConfig, logger *logging.Logger) *ElasticsearchWriter {
+	cfg.URL = strings.TrimRight(cfg.URL, "/")
+	e := &ElasticsearchWriter{
+		cfg:    cfg,
+		client: &http.Client{Timeout: constants.TenSecTimeout},
+		logger: logger,
+		full:   make(chan struct{}, 1),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go e.flushPeriodically()
+	return e
+}
+
+// Write adds the finding to the batch and signals the flush goroutine once the batch is full
+func (e *ElasticsearchWriter) Write(f *templates.Finding) error {
+	e.mu.Lock()
+	e.batch = append(e.batch, *f)
+	full := len(e.batch) >= elasticsearchBatchSize
+	e.mu.Unlock()
+
+	if full {
+		select {
+		case e.full <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// Close stops the periodic flush and sends the findings left in the batch
+func (e *ElasticsearchWriter) Close() error {
+	close(e.stop)
+	<-e.done
+	var errs error
+	for batch := e.takeBatch(true); batch != nil; batch = e.takeBatch(true) {
+		errs = errors.Join(errs, e.send(batch))
+	}
+	return errs
+}
+
+// flushPeriodically sends the full batches when Write signals them and every batch every
+// elasticsearchFlushInterval until Close
+func (e *ElasticsearchWriter) flushPeriodically() {
+	defer close(e.done)
+	ticker := time.NewTicker(elasticsearchFlushInterval)
+	defer ticker.Stop()
+	for {
+		partial := false
+		select {
+		case <-e.stop:
+			return
+		case <-e.full:
+		case <-ticker.C:
+			partial = true
+		}
+		for batch := e.takeBatch(partial); batch != nil; batch = e.takeBatch(partial) {
+			e.logFailedBatch(e.send(batch), len(batch))
+		}
+	}
+}
+
+// takeBatch removes up to elasticsearchBatchSize findings from the batch and returns them, nil when the batch is
+// empty or, unless partial, holds less than elasticsearchBatchSize findings
+func (e *ElasticsearchWriter) takeBatch(partial bool) []templates.Finding {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if len(e.batch) == 0 || (!partial && len(e.batch) < elasticsearchBatchSize) {
+		return nil
+	}
+	n := min(len(e.batch), elasticsearchBatchSize)
+	batch := e.batch[:n:n]
+	e.batch = e.batch[n:]
+	return batch
+}
+
+// logFailedBatch logs the error of a dropped batch
+func (e *ElasticsearchWriter) logFailedBatch(err error, size int) {
+	if err != nil && e.logger != nil {
+		e.logger.Error("Failed to index findings in Elasticsearch, batch dropped",
+			slog.String("index", e.cfg.Index),
+			slog.Int("findings", size),
+			slog.Any("error", err),
+		)
+	}
+}
+
+// send indexes the findings with a single bulk request
+func (e *ElasticsearchWriter) send(findings []templates.Finding) error {
+	body, err := bulkBody(e.cfg.Index, findings)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, e.cfg.URL+"/_bulk", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	if e.cfg.Username != "" {
+		req.SetBasicAuth(e.cfg.Username, e.cfg.Password)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("bulk request failed with status %d: %s", resp.StatusCode, bytes.TrimSpace(respBody))
+	}
+
+	var result struct {
+		Errors bool `json:"errors"`
+	}
+	if err := json.Unmarshal(respBody, &result); err == nil && result.Errors {
+		return fmt.Errorf("bulk request rejected some findings: %s", bytes.TrimSpace(respBody))
+	}
+	return nil
+}
+
+// bulkBody returns the NDJSON bulk API body indexing every finding into index
+func bulkBody(index string, findings []templates.Finding) ([]byte, error) {
+	action, err := json.Marshal(map[string]map[string]string{"index": {"_index": index}})
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	for i := range findings {
+		doc, err := json.Marshal(&findings[i])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(action)
+		buf.WriteByte('\n')
+		buf.Write(doc)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
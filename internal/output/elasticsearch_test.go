@@ -0,0 +1,129 @@
+package output
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// bulkServer is a mock Elasticsearch bulk API recording the number of findings of every batch
+type bulkServer struct {
+	t       *testing.T
+	status  int
+	mu      sync.Mutex
+	batches []int
+}
+
+func (s *bulkServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.URL.Path != "/_bulk" || r.Header.Get("Content-Type") != "application/x-ndjson" {
+		s.t.Errorf("bulk request %s %s with content type %q", r.Method, r.URL.Path, r.Header.Get("Content-Type"))
+	}
+	if user, pass, ok := r.BasicAuth(); !ok || user != "elastic" || pass != "changeme" {
+		s.t.Errorf("basic auth = %q, %q, %v", user, pass, ok)
+	}
+
+	var findings int
+	scanner := bufio.NewScanner(r.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for line := 0; scanner.Scan(); line++ {
+		if line%2 == 0 {
+			var action struct {
+				Index struct {
+					Index string `json:"_index"`
+				} `json:"index"`
+			}
+			if err := json.Unmarshal(scanner.Bytes(), &action); err != nil || action.Index.Index != "findings" {
+				s.t.Errorf("action line %d = %s, want index findings", line, scanner.Bytes())
+			}
+			continue
+		}
+		var doc map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &doc); err != nil || doc["template_id"] == nil {
+			s.t.Errorf("document line %d = %s, want a finding", line, scanner.Bytes())
+		}
+		findings++
+	}
+	s.mu.Lock()
+	s.batches = append(s.batches, findings)
+	s.mu.Unlock()
+
+	w.WriteHeader(s.status)
+	fmt.Fprint(w, `{"errors":false,"items":[]}`)
+}
+
+func (s *bulkServer) sent() []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]int(nil), s.batches...)
+}
+
+func TestElasticsearchWriterBatches(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+	}{
+		{name: "indexed", status: http.StatusOK},
+		{name: "rejected batches are dropped", status: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &bulkServer{t: t, status: tt.status}
+			srv := httptest.NewServer(mock)
+			t.Cleanup(srv.Close)
+
+			w := NewElasticsearchWriter(ElasticsearchConfig{URL: srv.URL + "/", Index: "findings", Username: "elastic", Password: "changeme"}, nil)
+			for i := 0; i < 2*elasticsearchBatchSize+20; i++ {
+				f := testFinding()
+				f.TemplateID = fmt.Sprintf("template-%d", i)
+				if err := w.Write(f); err != nil {
+					t.Fatalf("Write %d: %v", i, err)
+				}
+			}
+			deadline := time.Now().Add(5 * time.Second)
+			for len(mock.sent()) < 2 && time.Now().Before(deadline) {
+				time.Sleep(10 * time.Millisecond)
+			}
+			if got := mock.sent(); len(got) != 2 || got[0] != elasticsearchBatchSize || got[1] != elasticsearchBatchSize {
+				t.Errorf("batches sent while writing = %v, want two of %d", got, elasticsearchBatchSize)
+			}
+
+			err := w.Close()
+			if (err != nil) != (tt.status != http.StatusOK) {
+				t.Errorf("Close = %v with status %d", err, tt.status)
+			}
+			if got := mock.sent(); len(got) != 3 || got[2] != 20 {
+				t.Errorf("batches = %v, want the last 20 findings sent on Close", got)
+			}
+		})
+	}
+}
+
+func TestElasticsearchWriterSlowCluster(t *testing.T) {
+	unblock := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-unblock
+		fmt.Fprint(w, `{"errors":false,"items":[]}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	w := NewElasticsearchWriter(ElasticsearchConfig{URL: srv.URL, Index: "findings"}, nil)
+	start := time.Now()
+	for i := 0; i < 3*elasticsearchBatchSize; i++ {
+		if err := w.Write(testFinding()); err != nil {
+			t.Fatalf("Write %d: %v", i, err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("writing to a stalled cluster took %v, want the batches sent in the background", elapsed)
+	}
+
+	close(unblock)
+	if err := w.Close(); err != nil {
+		t.Errorf("Close = %v", err)
+	}
+}
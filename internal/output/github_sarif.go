@@ -0,0 +1,92 @@
+package output
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"strings"
+
+	"github.com/artnikel/nuclei/internal/constants"
+)
+
+// defaultGitHubAPIURL is the endpoint of the public GitHub API
+const defaultGitHubAPIURL = "https://api.github.com"
+
+// GitHubSARIFUploader uploads SARIF documents to the code scanning API of a repository
+type GitHubSARIFUploader struct {
+	// APIURL is the GitHub API endpoint, overridden for GitHub Enterprise
+	APIURL string
+	Token  string
+	Owner  string
+	Repo   string
+	client *http.Client
+}
+
+// NewGitHubSARIFUploader creates a GitHubSARIFUploader for the repository, token needs the security_events scope
+func NewGitHubSARIFUploader(token, owner, repo string) *GitHubSARIFUploader {
+	return &GitHubSARIFUploader{
+		APIURL: defaultGitHubAPIURL,
+		Token:  token,
+		Owner:  owner,
+		Repo:   repo,
+		client: &http.Client{Timeout: constants.TenSecTimeout},
+	}
+}
+
+// Upload sends the gzipped and base64-encoded SARIF document as the analysis of the commit on ref
+// (e.g. refs/heads/main). GitHub processes uploads asynchronously, a nil error only means it was accepted
+func (u *GitHubSARIFUploader) Upload(ctx context.Context, sarif []byte, commitSHA, ref string) error {
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	if _, err := zw.Write(sarif); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	body, err := json.Marshal(map[string]string{
+		"commit_sha": commitSHA,
+		"ref":        ref,
+		"sarif":      base64.StdEncoding.EncodeToString(gz.Bytes()),
+		"tool_name":  sarifToolName,
+	})
+	if err != nil {
+		return err
+	}
+
+	endpoint := strings.TrimRight(u.APIURL, "/") + "/repos/" + url.PathEscape(u.Owner) + "/" + url.PathEscape(u.Repo) + "/code-scanning/sarifs"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+u.Token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("github returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// DetectCommitSHA returns the commit checked out in the working directory
+func DetectCommitSHA(ctx context.Context) (string, error) {
+	out, err := exec.CommandContext(ctx, "git", "rev-parse", "HEAD").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to detect commit: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,115 @@
+package output
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"regexp"
+	"testing"
+)
+
+func TestGitHubSARIFUpload(t *testing.T) {
+	var sarif bytes.Buffer
+	w := NewSARIFWriter(&sarif)
+	if err := w.Write(testFinding()); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var uploaded []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/repos/acme/webapp/code-scanning/sarifs" {
+			t.Errorf("upload sent to %s %s", r.Method, r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "Bearer gh-token" || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("upload headers = %v", r.Header)
+		}
+		var payload map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode payload: %v", err)
+		}
+		if len(payload) != 4 || payload["commit_sha"] != "0123456789abcdef0123456789abcdef01234567" ||
+			payload["ref"] != "refs/heads/main" || payload["tool_name"] != sarifToolName {
+			t.Errorf("payload = %v", payload)
+		}
+		gz, err := base64.StdEncoding.DecodeString(payload["sarif"])
+		if err != nil {
+			t.Errorf("sarif is not base64: %v", err)
+		}
+		zr, err := gzip.NewReader(bytes.NewReader(gz))
+		if err != nil {
+			t.Errorf("sarif is not gzipped: %v", err)
+			return
+		}
+		uploaded, _ = io.ReadAll(zr)
+		w.WriteHeader(http.StatusAccepted)
+		io.WriteString(w, `{"id":"47177e22-5596-11eb-80a1-c1e54ef945c6"}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	u := NewGitHubSARIFUploader("gh-token", "acme", "webapp")
+	u.APIURL = srv.URL + "/"
+	if err := u.Upload(context.Background(), sarif.Bytes(), "0123456789abcdef0123456789abcdef01234567", "refs/heads/main"); err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	if !bytes.Equal(uploaded, sarif.Bytes()) {
+		t.Errorf("uploaded SARIF differs from the written one:\n%s", uploaded)
+	}
+
+	u.Token = "wrong"
+	srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message":"Bad credentials"}`, http.StatusUnauthorized)
+	})
+	if err := u.Upload(context.Background(), sarif.Bytes(), "0123456789abcdef0123456789abcdef01234567", "refs/heads/main"); err == nil {
+		t.Error("rejected upload reported as accepted")
+	}
+}
+
+func TestDetectCommitSHA(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "scan"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	sha, err := DetectCommitSHA(context.Background())
+	if err != nil {
+		t.Fatalf("DetectCommitSHA: %v", err)
+	}
+	if !regexp.MustCompile(`^[0-9a-f]{40}$`).MatchString(sha) {
+		t.Errorf("DetectCommitSHA = %q, want a commit hash", sha)
+	}
+
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	if sha, err := DetectCommitSHA(context.Background()); err == nil {
+		t.Errorf("DetectCommitSHA outside a repository = %q", sha)
+	}
+}
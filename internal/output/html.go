@@ -0,0 +1,41 @@
+package output
+
+import (
+	"io"
+	"sync"
+
+	"github.com/artnikel/nuclei/internal/report"
+	"github.com/artnikel/nuclei/internal/templates"
+)
+
+// HTMLWriter collects findings and renders them as an HTML report on Close
+type HTMLWriter struct {
+	mu       sync.Mutex
+	w        io.Writer
+	reporter *report.HTMLReporter
+	findings []templates.Finding
+}
+
+// NewHTMLWriter creates an HTMLWriter
+func NewHTMLWriter(w io.Writer) (*HTMLWriter, error) {
+	reporter, err := report.NewHTMLReporter()
+	if err != nil {
+		return nil, err
+	}
+	return &HTMLWriter{w: w, reporter: reporter}, nil
+}
+
+// Write buffers the finding
+func (h *HTMLWriter) Write(f *templates.Finding) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.findings = append(h.findings, *f)
+	return nil
+}
+
+// Close renders the report with all buffered findings
+func (h *HTMLWriter) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.reporter.Render(h.w, h.findings)
+}
@@ -0,0 +1,40 @@
+package output
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"sync"
+
+	"github.com/artnikel/nuclei/internal/templates"
+)
+
+// JSONLinesWriter writes every finding as one compact JSON object per line as soon as it's received
+type JSONLinesWriter struct {
+	mu  sync.Mutex
+	w   *bufio.Writer
+	enc *json.Encoder
+}
+
+// NewJSONLinesWriter creates a JSONLinesWriter
+func NewJSONLinesWriter(w io.Writer) *JSONLinesWriter {
+	bw := bufio.NewWriter(w)
+	return &JSONLinesWriter{w: bw, enc: json.NewEncoder(bw)}
+}
+
+// Write writes the finding as a line and flushes it
+func (j *JSONLinesWriter) Write(f *templates.Finding) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err := j.enc.Encode(f); err != nil {
+		return err
+	}
+	return j.w.Flush()
+}
+
+// Close flushes anything left, lines are flushed as they are written
+func (j *JSONLinesWriter) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.w.Flush()
+}
@@ -0,0 +1,56 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/artnikel/nuclei/internal/templates"
+)
+
+func TestJSONLinesWriterStreams(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "live.jsonl")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.Close() })
+	w := NewJSONLinesWriter(f)
+
+	for i, id := range []string{"app-version", "git-config"} {
+		finding := testFinding()
+		finding.TemplateID = id
+		matched := time.Now()
+		written := make(chan error, 1)
+		go func() { written <- w.Write(finding) }()
+
+		// poll the file like a tailing consumer until the line shows up
+		var lines [][]byte
+		for time.Since(matched) < 100*time.Millisecond {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if lines = bytes.Split(bytes.TrimSuffix(data, []byte("\n")), []byte("\n")); len(data) > 0 && len(lines) == i+1 {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+		if err := <-written; err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+		if len(lines) != i+1 {
+			t.Fatalf("finding %s not in the file within 100ms", id)
+		}
+		var got templates.Finding
+		if err := json.Unmarshal(lines[i], &got); err != nil || got.TemplateID != id {
+			t.Errorf("line %d = %s, %v, want %s", i, lines[i], err, id)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+}
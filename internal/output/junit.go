@@ -0,0 +1,146 @@
+package output
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/artnikel/nuclei/internal/templates"
+)
+
+// junitSuiteName is the name of the test suite holding the template executions
+const junitSuiteName = "nuclei"
+
+// PassWriter is implemented by writers that also report template executions without a match
+type PassWriter interface {
+	WritePass(target, templateID string, duration time.Duration) error
+}
+
+// junitTestSuites is the root element of a JUnit XML report
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+// junitTestSuite groups the test cases of a scan
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+// junitTestCase is one template execution against a target
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+// junitFailure marks a test case whose template matched
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Details string `xml:",chardata"`
+}
+
+// JUnitWriter collects template executions and writes them as a JUnit XML report on Close, matches are
+// failed test cases and executions reported through WritePass are passed ones
+type JUnitWriter struct {
+	mu      sync.Mutex
+	w       io.Writer
+	started time.Time
+	cases   []junitTestCase
+	failed  int
+	total   time.Duration
+}
+
+// NewJUnitWriter creates a JUnitWriter
+func NewJUnitWriter(w io.Writer) *JUnitWriter {
+	return &JUnitWriter{w: w, started: time.Now()}
+}
+
+// Write buffers the finding as a failed test case
+func (j *JUnitWriter) Write(f *templates.Finding) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	details := f.MatchedURL
+	if len(f.ExtractedValues) > 0 {
+		details += "\nextracted: " + formatExtracted(f.ExtractedValues)
+	}
+	j.add(junitTestCase{
+		Name:      f.TemplateID,
+		ClassName: f.Target,
+		Time:      formatJUnitTime(f.Duration),
+		Failure: &junitFailure{
+			Message: fmt.Sprintf("[%s] %s matched %s", f.Severity, f.Name, f.MatchedURL),
+			Type:    f.Severity,
+			Details: details,
+		},
+	}, f.Duration)
+	j.failed++
+	return nil
+}
+
+// WritePass buffers a template execution without a match as a passed test case
+func (j *JUnitWriter) WritePass(target, templateID string, duration time.Duration) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.add(junitTestCase{
+		Name:      templateID,
+		ClassName: target,
+		Time:      formatJUnitTime(duration),
+	}, duration)
+	return nil
+}
+
+// add appends the test case, the caller holds the lock
+func (j *JUnitWriter) add(tc junitTestCase, duration time.Duration) {
+	j.cases = append(j.cases, tc)
+	j.total += duration
+}
+
+// Close writes the buffered test cases as a single test suite
+func (j *JUnitWriter) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	total := formatJUnitTime(j.total)
+	report := junitTestSuites{
+		Tests:    len(j.cases),
+		Failures: j.failed,
+		Time:     total,
+		Suites: []junitTestSuite{{
+			Name:      junitSuiteName,
+			Tests:     len(j.cases),
+			Failures:  j.failed,
+			Time:      total,
+			Timestamp: j.started.UTC().Format("2006-01-02T15:04:05"),
+			Cases:     j.cases,
+		}},
+	}
+	if _, err := io.WriteString(j.w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(j.w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(j.w, "\n")
+	return err
+}
+
+// formatJUnitTime formats the duration in seconds with millisecond precision
+func formatJUnitTime(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
+}
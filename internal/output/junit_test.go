@@ -0,0 +1,101 @@
+package output
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJUnitWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewJUnitWriter(&buf)
+	first := testFinding()
+	first.Duration = 1500 * time.Millisecond
+	second := testFinding()
+	second.TemplateID = "git-config"
+	second.Target = "http://other.example.com"
+	second.Severity = "high"
+	second.Duration = 250 * time.Millisecond
+	if err := w.Write(first); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WritePass("http://example.com", "tech-detect", time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(second); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), xml.Header) {
+		t.Errorf("report misses the XML declaration:\n%s", buf.String())
+	}
+
+	// the elements and required attributes of the JUnit XSD
+	var report struct {
+		XMLName  xml.Name `xml:"testsuites"`
+		Tests    *int     `xml:"tests,attr"`
+		Failures *int     `xml:"failures,attr"`
+		Suites   []struct {
+			Name      *string `xml:"name,attr"`
+			Tests     *int    `xml:"tests,attr"`
+			Failures  *int    `xml:"failures,attr"`
+			Errors    *int    `xml:"errors,attr"`
+			Time      *string `xml:"time,attr"`
+			Timestamp *string `xml:"timestamp,attr"`
+			Cases     []struct {
+				Name      *string `xml:"name,attr"`
+				ClassName *string `xml:"classname,attr"`
+				Time      *string `xml:"time,attr"`
+				Failure   *struct {
+					Message string `xml:"message,attr"`
+					Type    string `xml:"type,attr"`
+				} `xml:"failure"`
+			} `xml:"testcase"`
+		} `xml:"testsuite"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("report is not valid XML: %v", err)
+	}
+	if report.Tests == nil || *report.Tests != 3 || report.Failures == nil || *report.Failures != 2 || len(report.Suites) != 1 {
+		t.Fatalf("testsuites = %+v, want 1 suite with 3 tests and 2 failures", report)
+	}
+	suite := report.Suites[0]
+	if suite.Name == nil || suite.Tests == nil || suite.Failures == nil || suite.Errors == nil || suite.Time == nil || suite.Timestamp == nil {
+		t.Fatalf("testsuite misses required attributes: %+v", suite)
+	}
+	if *suite.Time != "2.750" {
+		t.Errorf("suite time = %s, want 2.750", *suite.Time)
+	}
+	if _, err := time.Parse("2006-01-02T15:04:05", *suite.Timestamp); err != nil {
+		t.Errorf("suite timestamp %q is not an ISO 8601 date: %v", *suite.Timestamp, err)
+	}
+
+	wantCases := []struct {
+		name, classname, time string
+		failed                bool
+	}{
+		{name: "app-version", classname: "http://example.com", time: "1.500", failed: true},
+		{name: "tech-detect", classname: "http://example.com", time: "1.000"},
+		{name: "git-config", classname: "http://other.example.com", time: "0.250", failed: true},
+	}
+	if len(suite.Cases) != len(wantCases) {
+		t.Fatalf("%d test cases, want %d", len(suite.Cases), len(wantCases))
+	}
+	for i, want := range wantCases {
+		tc := suite.Cases[i]
+		if tc.Name == nil || tc.ClassName == nil || tc.Time == nil {
+			t.Errorf("test case %d misses required attributes", i)
+			continue
+		}
+		if *tc.Name != want.name || *tc.ClassName != want.classname || *tc.Time != want.time || (tc.Failure != nil) != want.failed {
+			t.Errorf("test case %d = %s %s %s failure %v, want %+v", i, *tc.Name, *tc.ClassName, *tc.Time, tc.Failure != nil, want)
+		}
+		if tc.Failure != nil && (tc.Failure.Message == "" || tc.Failure.Type == "") {
+			t.Errorf("failure of %s misses its message or type", *tc.Name)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/artnikel/nuclei/internal/templates"
+)
+
+const (
+	// sarifSchema is the JSON schema of the written SARIF version
+	sarifSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+	// sarifVersion is the written SARIF version
+	sarifVersion = "2.1.0"
+	// sarifToolName is the name the results are reported under
+	sarifToolName = "nuclei"
+)
+
+// sarifLog is the root object of a SARIF document
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+// sarifRun holds the results of a single scan
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+// sarifTool describes the scanner and the rules, one per matched template
+type sarifTool struct {
+	Driver struct {
+		Name  string      `json:"name"`
+		Rules []sarifRule `json:"rules"`
+	} `json:"driver"`
+}
+
+// sarifRule describes a template
+type sarifRule struct {
+	ID               string          `json:"id"`
+	Name             string          `json:"name,omitempty"`
+	ShortDescription sarifMessage    `json:"shortDescription"`
+	FullDescription  *sarifMessage   `json:"fullDescription,omitempty"`
+	Properties       sarifProperties `json:"properties"`
+}
+
+// sarifProperties are the rule properties GitHub code scanning reads
+type sarifProperties struct {
+	Tags             []string `json:"tags,omitempty"`
+	SecuritySeverity string   `json:"security-severity,omitempty"`
+}
+
+// sarifResult is a finding
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+// sarifMessage is a plain text message
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+// sarifLocation points at the template file of the finding
+type sarifLocation struct {
+	PhysicalLocation struct {
+		ArtifactLocation struct {
+			URI string `json:"uri"`
+		} `json:"artifactLocation"`
+		Region struct {
+			StartLine int `json:"startLine"`
+		} `json:"region"`
+	} `json:"physicalLocation"`
+}
+
+// SARIFWriter collects findings and writes them as a SARIF 2.1.0 document on Close. Findings are located at
+// their template file as code scanning requires a file location, the target is part of the message
+type SARIFWriter struct {
+	mu       sync.Mutex
+	w        io.Writer
+	findings []templates.Finding
+}
+
+// NewSARIFWriter creates a SARIFWriter
+func NewSARIFWriter(w io.Writer) *SARIFWriter {
+	return &SARIFWriter{w: w}
+}
+
+// Write buffers the finding
+func (s *SARIFWriter) Write(f *templates.Finding) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.findings = append(s.findings, *f)
+	return nil
+}
+
+// Close writes the buffered findings as a single run
+func (s *SARIFWriter) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	enc := json.NewEncoder(s.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(buildSARIF(s.findings))
+}
+
+// buildSARIF returns the SARIF document of the findings with a rule per template
+func buildSARIF(findings []templates.Finding) sarifLog {
+	run := sarifRun{Results: make([]sarifResult, 0, len(findings))}
+	run.Tool.Driver.Name = sarifToolName
+	rules := make(map[string]sarifRule)
+	for _, f := range findings {
+		if _, ok := rules[f.TemplateID]; !ok {
+			rule := sarifRule{
+				ID:               f.TemplateID,
+				Name:             f.Name,
+				ShortDescription: sarifMessage{Text: f.Name},
+				Properties: sarifProperties{
+					Tags:             append([]string{"security"}, f.Tags...),
+					SecuritySeverity: sarifSecuritySeverity(f),
+				},
+			}
+			if f.Description != "" {
+				rule.FullDescription = &sarifMessage{Text: f.Description}
+			}
+			rules[f.TemplateID] = rule
+		}
+
+		text := fmt.Sprintf("[%s] %s matched %s", f.Severity, f.Name, f.MatchedURL)
+		if len(f.ExtractedValues) > 0 {
+			text += ", extracted: " + formatExtracted(f.ExtractedValues)
+		}
+		var loc sarifLocation
+		loc.PhysicalLocation.ArtifactLocation.URI = sarifURI(f)
+		loc.PhysicalLocation.Region.StartLine = 1
+		run.Results = append(run.Results, sarifResult{
+			RuleID:    f.TemplateID,
+			Level:     sarifLevel(f.Severity),
+			Message:   sarifMessage{Text: text},
+			Locations: []sarifLocation{loc},
+		})
+	}
+
+	ids := make([]string, 0, len(rules))
+	for id := range rules {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	run.Tool.Driver.Rules = make([]sarifRule, 0, len(ids))
+	for _, id := range ids {
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, rules[id])
+	}
+	return sarifLog{Schema: sarifSchema, Version: sarifVersion, Runs: []sarifRun{run}}
+}
+
+// sarifLevel maps the severity to a SARIF result level
+func sarifLevel(severity string) string {
+	switch strings.ToLower(severity) {
+	case "critical", "high":
+		return "error"
+	case "medium":
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// sarifSecuritySeverity returns the CVSS score of the finding, or a score standing for its severity
+func sarifSecuritySeverity(f templates.Finding) string {
+	score := f.CVSSScore
+	if score == 0 {
+		switch strings.ToLower(f.Severity) {
+		case "critical":
+			score = 9.5
+		case "high":
+			score = 8
+		case "medium":
+			score = 5.5
+		case "low":
+			score = 2
+		default:
+			return ""
+		}
+	}
+	return strconv.FormatFloat(score, 'f', 1, 64)
+}
+
+// sarifURI returns the slash-separated template path of the finding relative to the working directory, the
+// template ID if the path is unknown
+func sarifURI(f templates.Finding) string {
+	if f.TemplatePath == "" {
+		return f.TemplateID
+	}
+	path := filepath.Clean(f.TemplatePath)
+	if wd, err := os.Getwd(); err == nil && filepath.IsAbs(path) {
+		if rel, err := filepath.Rel(wd, path); err == nil && !strings.HasPrefix(rel, "..") {
+			path = rel
+		}
+	}
+	return filepath.ToSlash(path)
+}
@@ -0,0 +1,53 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+	"text/template"
+
+	"github.com/artnikel/nuclei/internal/templates"
+)
+
+// TemplateData is the data the template of a TemplateWriter is executed with, it exposes every finding field
+type TemplateData struct {
+	templates.Finding
+}
+
+// TemplateWriter formats every finding with a text/template, each output ends with a newline
+type TemplateWriter struct {
+	mu   sync.Mutex
+	w    io.Writer
+	tmpl *template.Template
+	buf  bytes.Buffer
+}
+
+// NewTemplateWriter compiles the per-finding text/template and creates a TemplateWriter
+func NewTemplateWriter(w io.Writer, text string) (*TemplateWriter, error) {
+	tmpl, err := template.New("output").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid output template: %w", err)
+	}
+	return &TemplateWriter{w: w, tmpl: tmpl}, nil
+}
+
+// Write executes the template for the finding and writes the result
+func (t *TemplateWriter) Write(f *templates.Finding) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.buf.Reset()
+	if err := t.tmpl.Execute(&t.buf, TemplateData{Finding: *f}); err != nil {
+		return fmt.Errorf("failed to execute output template: %w", err)
+	}
+	if !bytes.HasSuffix(t.buf.Bytes(), []byte("\n")) {
+		t.buf.WriteByte('\n')
+	}
+	_, err := t.w.Write(t.buf.Bytes())
+	return err
+}
+
+// Close is a no-op, findings are written immediately
+func (t *TemplateWriter) Close() error {
+	return nil
+}
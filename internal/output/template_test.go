@@ -0,0 +1,71 @@
+package output
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+)
+
+const xmlFindingTemplate = `<finding id="{{html .TemplateID}}" severity="{{html .Severity}}"><target>{{html .MatchedURL}}</target>` +
+	`{{range $name, $value := .ExtractedValues}}<extracted name="{{html $name}}">{{html $value}}</extracted>{{end}}</finding>`
+
+func TestTemplateWriterXML(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewTemplateWriter(&buf, xmlFindingTemplate)
+	if err != nil {
+		t.Fatalf("NewTemplateWriter: %v", err)
+	}
+	first := testFinding()
+	second := testFinding()
+	second.TemplateID = "open-redirect"
+	second.MatchedURL = "http://example.com/login?next=<script>&a=1"
+	if err := w.Write(first); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(second); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var doc struct {
+		Findings []struct {
+			ID        string `xml:"id,attr"`
+			Severity  string `xml:"severity,attr"`
+			Target    string `xml:"target"`
+			Extracted []struct {
+				Name  string `xml:"name,attr"`
+				Value string `xml:",chardata"`
+			} `xml:"extracted"`
+		} `xml:"finding"`
+	}
+	data := append(append([]byte("<findings>"), buf.Bytes()...), "</findings>"...)
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("output is not valid XML: %v\n%s", err, buf.String())
+	}
+	if len(doc.Findings) != 2 || doc.Findings[0].ID != "app-version" || len(doc.Findings[0].Extracted) != 2 {
+		t.Fatalf("findings = %+v, want app-version with 2 extracted values and open-redirect", doc.Findings)
+	}
+	if doc.Findings[1].Target != second.MatchedURL {
+		t.Errorf("escaped target read back as %q, want %q", doc.Findings[1].Target, second.MatchedURL)
+	}
+	if lines := bytes.Count(buf.Bytes(), []byte("\n")); lines != 2 {
+		t.Errorf("%d lines, want one per finding", lines)
+	}
+}
+
+func TestNewTemplateWriterInvalid(t *testing.T) {
+	for _, text := range []string{"{{.TemplateID", "{{range .ExtractedValues}}"} {
+		if _, err := NewTemplateWriter(&bytes.Buffer{}, text); err == nil {
+			t.Errorf("NewTemplateWriter(%q) accepted an invalid template", text)
+		}
+	}
+	w, err := NewTemplateWriter(&bytes.Buffer{}, "{{.Missing}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(testFinding()); err == nil {
+		t.Error("template with an unknown field executed")
+	}
+}
@@ -0,0 +1,73 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+
+	"github.com/artnikel/nuclei/internal/templates"
+)
+
+// ANSI escape sequences of the terminal output
+const (
+	ansiReset     = "\033[0m"
+	ansiClearLine = "\r\033[K"
+	ansiRed       = "\033[31m"
+	ansiYellow    = "\033[33m"
+	ansiCyan      = "\033[36m"
+	ansiWhite     = "\033[37m"
+)
+
+// TerminalWriter prints every finding as a line colored by its severity as soon as it's received
+type TerminalWriter struct {
+	mu    sync.Mutex
+	w     io.Writer
+	color bool
+}
+
+// NewTerminalWriter creates a TerminalWriter, color enables the ANSI colors
+func NewTerminalWriter(w io.Writer, color bool) *TerminalWriter {
+	return &TerminalWriter{w: w, color: color}
+}
+
+// IsTerminal reports whether f is a terminal, colors are only written to terminals
+func IsTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
+
+// Write prints the finding as [SEVERITY] [TEMPLATE-ID] target (TIMESTAMP)
+func (t *TerminalWriter) Write(f *templates.Finding) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	line := fmt.Sprintf("[%s] [%s] %s (%s)", strings.ToUpper(f.Severity), f.TemplateID, f.Target, f.Timestamp.Format(time.RFC3339))
+	if t.color {
+		// the line replaces the progress line the CLI keeps rewriting on the terminal
+		line = ansiClearLine + severityANSIColor(f.Severity) + line + ansiReset
+	}
+	_, err := fmt.Fprintln(t.w, line)
+	return err
+}
+
+// Close is a no-op, lines are written immediately
+func (t *TerminalWriter) Close() error {
+	return nil
+}
+
+// severityANSIColor returns the color of the severity
+func severityANSIColor(severity string) string {
+	switch strings.ToLower(severity) {
+	case "critical":
+		return ansiRed
+	case "high":
+		return ansiYellow
+	case "medium":
+		return ansiCyan
+	default:
+		return ansiWhite
+	}
+}
@@ -0,0 +1,74 @@
+package output
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestTerminalWriterColors(t *testing.T) {
+	tests := []struct {
+		severity string
+		color    string
+	}{
+		{severity: "critical", color: ansiRed},
+		{severity: "high", color: ansiYellow},
+		{severity: "medium", color: ansiCyan},
+		{severity: "low", color: ansiWhite},
+		{severity: "info", color: ansiWhite},
+	}
+	for _, tt := range tests {
+		t.Run(tt.severity, func(t *testing.T) {
+			f := testFinding()
+			f.Severity = tt.severity
+			want := "[" + strings.ToUpper(tt.severity) + "] [app-version] http://example.com (2024-05-01T10:00:00Z)"
+
+			var colored bytes.Buffer
+			if err := NewTerminalWriter(&colored, true).Write(f); err != nil {
+				t.Fatal(err)
+			}
+			if got := colored.String(); got != ansiClearLine+tt.color+want+ansiReset+"\n" {
+				t.Errorf("colored line = %q", got)
+			}
+
+			var plain bytes.Buffer
+			if err := NewTerminalWriter(&plain, false).Write(f); err != nil {
+				t.Fatal(err)
+			}
+			if got := plain.String(); got != want+"\n" {
+				t.Errorf("no-color line = %q, want %q", got, want+"\n")
+			}
+		})
+	}
+}
+
+// TestTerminalWriterPipedStderr writes to a stderr replaced by a pipe, as when the CLI output is piped, where
+// colors are disabled
+func TestTerminalWriterPipedStderr(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stderr := os.Stderr
+	os.Stderr = w
+	t.Cleanup(func() { os.Stderr = stderr })
+
+	if IsTerminal(os.Stderr) {
+		t.Fatal("pipe reported as a terminal")
+	}
+	f := testFinding()
+	f.Severity = "critical"
+	if err := NewTerminalWriter(os.Stderr, IsTerminal(os.Stderr)).Write(f); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(out, []byte("\033[")) || !bytes.HasPrefix(out, []byte("[CRITICAL] [app-version]")) {
+		t.Errorf("piped stderr = %q, want a line without color codes", out)
+	}
+}
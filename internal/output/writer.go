@@ -0,0 +1,223 @@
+// Package output provides writers that export scan findings in different formats
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/artnikel/nuclei/internal/templates"
+)
+
+// Supported output formats
+const (
+	FormatText  = "text"
+	FormatJSON  = "json"
+	FormatJSONL = "jsonl"
+	FormatCSV   = "csv"
+	FormatHTML  = "html"
+	FormatJUnit = "junit"
+	FormatSARIF = "sarif"
+)
+
+// Writer receives findings one by one and flushes them to the destination on Close
+type Writer interface {
+	Write(f *templates.Finding) error
+	Close() error
+}
+
+// NewWriter returns a writer for the requested format writing into w
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch strings.ToLower(format) {
+	case FormatText, "":
+		return NewTextWriter(w), nil
+	case FormatJSON:
+		return NewJSONWriter(w), nil
+	case FormatJSONL:
+		return NewJSONLinesWriter(w), nil
+	case FormatCSV:
+		return NewCSVWriter(w), nil
+	case FormatHTML:
+		return NewHTMLWriter(w)
+	case FormatJUnit:
+		return NewJUnitWriter(w), nil
+	case FormatSARIF:
+		return NewSARIFWriter(w), nil
+	default:
+		return nil, fmt.Errorf("unsupported output format: %s", format)
+	}
+}
+
+// MultiWriter passes every finding to all of its writers
+type MultiWriter struct {
+	writers []Writer
+}
+
+// NewMultiWriter creates a MultiWriter
+func NewMultiWriter(writers ...Writer) *MultiWriter {
+	return &MultiWriter{writers: writers}
+}
+
+// Write passes the finding to every writer, stopping at the first error
+func (m *MultiWriter) Write(f *templates.Finding) error {
+	for _, w := range m.writers {
+		if err := w.Write(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WritePass passes the template execution to the writers reporting them
+func (m *MultiWriter) WritePass(target, templateID string, duration time.Duration) error {
+	for _, w := range m.writers {
+		if pw, ok := w.(PassWriter); ok {
+			if err := pw.WritePass(target, templateID, duration); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Close closes every writer and returns their errors
+func (m *MultiWriter) Close() error {
+	var errs []error
+	for _, w := range m.writers {
+		errs = append(errs, w.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// TextWriter prints one human-readable line per finding
+type TextWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewTextWriter creates a TextWriter
+func NewTextWriter(w io.Writer) *TextWriter {
+	return &TextWriter{w: w}
+}
+
+// Write prints the finding as a single line
+func (t *TextWriter) Write(f *templates.Finding) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(f.ExtractedValues) > 0 {
+		_, err := fmt.Fprintf(t.w, "[%s] [%s] %s [%s]\n", f.Severity, f.TemplateID, f.MatchedURL, formatExtracted(f.ExtractedValues))
+		return err
+	}
+	_, err := fmt.Fprintf(t.w, "[%s] [%s] %s\n", f.Severity, f.TemplateID, f.MatchedURL)
+	return err
+}
+
+// Close is a no-op, lines are written immediately
+func (t *TextWriter) Close() error {
+	return nil
+}
+
+// JSONWriter collects findings and writes them as a JSON array on Close
+type JSONWriter struct {
+	mu       sync.Mutex
+	w        io.Writer
+	findings []templates.Finding
+}
+
+// NewJSONWriter creates a JSONWriter
+func NewJSONWriter(w io.Writer) *JSONWriter {
+	return &JSONWriter{w: w, findings: []templates.Finding{}}
+}
+
+// Write buffers the finding
+func (j *JSONWriter) Write(f *templates.Finding) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.findings = append(j.findings, *f)
+	return nil
+}
+
+// Close writes the buffered findings as an indented JSON array
+func (j *JSONWriter) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	enc := json.NewEncoder(j.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(j.findings)
+}
+
+// csvHeader is the first row written by CSVWriter
+var csvHeader = []string{"timestamp", "target", "template_id", "severity", "cvss_score", "cve_id", "name", "matched_url", "extracted_values"}
+
+// CSVWriter writes one row per finding
+type CSVWriter struct {
+	mu          sync.Mutex
+	w           *csv.Writer
+	wroteHeader bool
+}
+
+// NewCSVWriter creates a CSVWriter
+func NewCSVWriter(w io.Writer) *CSVWriter {
+	return &CSVWriter{w: csv.NewWriter(w)}
+}
+
+// Write appends the finding as a CSV row, writing the header first if needed
+func (c *CSVWriter) Write(f *templates.Finding) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.wroteHeader {
+		if err := c.w.Write(csvHeader); err != nil {
+			return err
+		}
+		c.wroteHeader = true
+	}
+	return c.w.Write([]string{
+		f.Timestamp.Format(time.RFC3339),
+		f.Target,
+		f.TemplateID,
+		f.Severity,
+		formatCVSS(f.CVSSScore),
+		f.CVEID,
+		f.Name,
+		f.MatchedURL,
+		formatExtracted(f.ExtractedValues),
+	})
+}
+
+// Close flushes buffered rows
+func (c *CSVWriter) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.wroteHeader {
+		if err := c.w.Write(csvHeader); err != nil {
+			return err
+		}
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// formatCVSS formats the score with one decimal, an unset score is empty
+func formatCVSS(score float64) string {
+	if score == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(score, 'f', 1, 64)
+}
+
+// formatExtracted joins extracted values as sorted key=value pairs
+func formatExtracted(values map[string]string) string {
+	pairs := make([]string, 0, len(values))
+	for k, v := range values {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ";")
+}
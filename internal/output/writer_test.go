@@ -0,0 +1,91 @@
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/artnikel/nuclei/internal/templates"
+)
+
+// testFinding returns a finding with extracted values
+func testFinding() *templates.Finding {
+	return &templates.Finding{
+		Timestamp:       time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+		Target:          "http://example.com",
+		TemplateID:      "app-version",
+		Severity:        "info",
+		Name:            "App version",
+		MatchedURL:      "http://example.com/version",
+		ExtractedValues: map[string]string{"version": "4.2.1", "build": "77"},
+	}
+}
+
+func TestWritersIncludeExtractedValues(t *testing.T) {
+	tests := []struct {
+		format string
+		check  func(t *testing.T, out string)
+	}{
+		{format: FormatText, check: func(t *testing.T, out string) {
+			if want := "[info] [app-version] http://example.com/version [build=77;version=4.2.1]\n"; out != want {
+				t.Errorf("text output = %q, want %q", out, want)
+			}
+		}},
+		{format: FormatJSON, check: func(t *testing.T, out string) {
+			var findings []templates.Finding
+			if err := json.Unmarshal([]byte(out), &findings); err != nil {
+				t.Fatal(err)
+			}
+			if len(findings) != 1 || findings[0].ExtractedValues["version"] != "4.2.1" {
+				t.Errorf("json findings = %+v", findings)
+			}
+		}},
+		{format: FormatCSV, check: func(t *testing.T, out string) {
+			rows, err := csv.NewReader(strings.NewReader(out)).ReadAll()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rows) != 2 || rows[0][len(rows[0])-1] != "extracted_values" || rows[1][len(rows[1])-1] != "build=77;version=4.2.1" {
+				t.Errorf("csv rows = %v", rows)
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := NewWriter(tt.format, &buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := w.Write(testFinding()); err != nil {
+				t.Fatalf("Write: %v", err)
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("Close: %v", err)
+			}
+			tt.check(t, buf.String())
+		})
+	}
+}
+
+func TestTextWriterWithoutExtractedValues(t *testing.T) {
+	var buf bytes.Buffer
+	f := testFinding()
+	f.ExtractedValues = nil
+	w := NewTextWriter(&buf)
+	if err := w.Write(f); err != nil {
+		t.Fatal(err)
+	}
+	if want := "[info] [app-version] http://example.com/version\n"; buf.String() != want {
+		t.Errorf("text output = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestNewWriterUnsupportedFormat(t *testing.T) {
+	if _, err := NewWriter("yaml", &bytes.Buffer{}); err == nil {
+		t.Error("unsupported format accepted")
+	}
+}
@@ -0,0 +1,93 @@
+package report
+
+import (
+	_ "embed"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/artnikel/nuclei/internal/templates"
+)
+
+//go:embed diff.html.tmpl
+var diffTemplate string
+
+// Diff states of a comparison row
+const (
+	DiffNew       = "new"
+	DiffResolved  = "resolved"
+	DiffUnchanged = "unchanged"
+)
+
+// DiffRow is a line of the side-by-side comparison, Before or After is nil for new and resolved findings
+type DiffRow struct {
+	State  string
+	Before *templates.Finding
+	After  *templates.Finding
+}
+
+// diffData is passed to the HTML diff template
+type diffData struct {
+	GeneratedAt time.Time
+	Before      string
+	After       string
+	New         int
+	Resolved    int
+	Unchanged   int
+	Rows        []DiffRow
+}
+
+// DiffReporter renders the comparison of two scans as a single self-contained HTML page
+type DiffReporter struct {
+	tmpl *template.Template
+}
+
+// NewDiffReporter parses the embedded diff template
+func NewDiffReporter() (*DiffReporter, error) {
+	tmpl, err := template.New("diff").Funcs(template.FuncMap{
+		"severity": normalizeSeverity,
+	}).Parse(diffTemplate)
+	if err != nil {
+		return nil, err
+	}
+	return &DiffReporter{tmpl: tmpl}, nil
+}
+
+// Render writes the comparison of the before and after scans named by beforeName and afterName to w,
+// unchanged findings are shown as found by the after scan
+func (r *DiffReporter) Render(w io.Writer, beforeName, afterName string, newFindings, resolved, unchanged []templates.Finding) error {
+	rows := make([]DiffRow, 0, len(newFindings)+len(resolved)+len(unchanged))
+	for i := range resolved {
+		rows = append(rows, DiffRow{State: DiffResolved, Before: &resolved[i]})
+	}
+	for i := range newFindings {
+		rows = append(rows, DiffRow{State: DiffNew, After: &newFindings[i]})
+	}
+	for i := range unchanged {
+		rows = append(rows, DiffRow{State: DiffUnchanged, Before: &unchanged[i], After: &unchanged[i]})
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		return diffRowKey(rows[i]) < diffRowKey(rows[j])
+	})
+
+	return r.tmpl.Execute(w, diffData{
+		GeneratedAt: time.Now(),
+		Before:      beforeName,
+		After:       afterName,
+		New:         len(newFindings),
+		Resolved:    len(resolved),
+		Unchanged:   len(unchanged),
+		Rows:        rows,
+	})
+}
+
+// diffRowKey orders the rows by target, then template ID
+func diffRowKey(row DiffRow) string {
+	f := row.After
+	if f == nil {
+		f = row.Before
+	}
+	return strings.ToLower(f.Target) + "|" + f.TemplateID
+}
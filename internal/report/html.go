@@ -0,0 +1,112 @@
+// Package report renders scan findings into shareable reports
+package report
+
+import (
+	_ "embed"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/artnikel/nuclei/internal/templates"
+)
+
+//go:embed report.html.tmpl
+var reportTemplate string
+
+// severityOrder lists the severities from the most to the least critical
+var severityOrder = []string{"critical", "high", "medium", "low", "info", "unknown"}
+
+// SeverityCount is a row of the executive summary table
+type SeverityCount struct {
+	Severity string
+	Count    int
+}
+
+// reportData is passed to the HTML template
+type reportData struct {
+	GeneratedAt time.Time
+	Total       int
+	Summary     []SeverityCount
+	Findings    []templates.Finding
+}
+
+// HTMLReporter renders findings as a single self-contained HTML page
+type HTMLReporter struct {
+	tmpl *template.Template
+}
+
+// NewHTMLReporter parses the embedded report template
+func NewHTMLReporter() (*HTMLReporter, error) {
+	tmpl, err := template.New("report").Funcs(template.FuncMap{
+		"lower":     strings.ToLower,
+		"severity":  normalizeSeverity,
+		"extracted": sortedExtracted,
+	}).Parse(reportTemplate)
+	if err != nil {
+		return nil, err
+	}
+	return &HTMLReporter{tmpl: tmpl}, nil
+}
+
+// Render writes the report for findings to w
+func (r *HTMLReporter) Render(w io.Writer, findings []templates.Finding) error {
+	sorted := make([]templates.Finding, len(findings))
+	copy(sorted, findings)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return severityRank(sorted[i].Severity) < severityRank(sorted[j].Severity)
+	})
+
+	return r.tmpl.Execute(w, reportData{
+		GeneratedAt: time.Now(),
+		Total:       len(findings),
+		Summary:     summarize(findings),
+		Findings:    sorted,
+	})
+}
+
+// summarize counts findings per severity in severityOrder
+func summarize(findings []templates.Finding) []SeverityCount {
+	counts := make(map[string]int)
+	for _, f := range findings {
+		counts[normalizeSeverity(f.Severity)]++
+	}
+	summary := make([]SeverityCount, 0, len(severityOrder))
+	for _, s := range severityOrder {
+		summary = append(summary, SeverityCount{Severity: s, Count: counts[s]})
+	}
+	return summary
+}
+
+// severityRank returns the position of the severity in severityOrder
+func severityRank(severity string) int {
+	severity = normalizeSeverity(severity)
+	for i, s := range severityOrder {
+		if s == severity {
+			return i
+		}
+	}
+	return len(severityOrder)
+}
+
+// normalizeSeverity lowercases the severity, unknown and empty values become "unknown"
+func normalizeSeverity(severity string) string {
+	severity = strings.ToLower(strings.TrimSpace(severity))
+	for _, s := range severityOrder {
+		if s == severity {
+			return s
+		}
+	}
+	return "unknown"
+}
+
+// sortedExtracted returns extracted values as sorted key=value pairs
+func sortedExtracted(values map[string]string) []string {
+	pairs := make([]string, 0, len(values))
+	for k, v := range values {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return pairs
+}
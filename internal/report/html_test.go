@@ -0,0 +1,141 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/html"
+
+	"github.com/artnikel/nuclei/internal/templates"
+)
+
+// parseHTML parses the rendered report, failing the test on invalid markup
+func parseHTML(t *testing.T, data []byte) *html.Node {
+	t.Helper()
+	doc, err := html.Parse(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("report is not valid HTML: %v", err)
+	}
+	return doc
+}
+
+// findElements returns the elements with the tag and a class attribute containing class
+func findElements(n *html.Node, tag, class string) []*html.Node {
+	var res []*html.Node
+	if n.Type == html.ElementNode && n.Data == tag && (class == "" || hasClass(n, class)) {
+		res = append(res, n)
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		res = append(res, findElements(c, tag, class)...)
+	}
+	return res
+}
+
+func hasClass(n *html.Node, class string) bool {
+	for _, a := range n.Attr {
+		if a.Key == "class" {
+			for _, c := range strings.Fields(a.Val) {
+				if c == class {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// text returns the text content of n
+func text(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var sb strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		sb.WriteString(text(c))
+	}
+	return sb.String()
+}
+
+func TestHTMLReporterRender(t *testing.T) {
+	ts := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	findings := []templates.Finding{
+		{Timestamp: ts, TemplateID: "low-one", Severity: "low", MatchedURL: "http://a.test/"},
+		{Timestamp: ts, TemplateID: "crit-one", Severity: "Critical", MatchedURL: "http://b.test/", CVEID: "CVE-2024-1"},
+		{Timestamp: ts, TemplateID: "xss-<script>", Severity: "high", MatchedURL: "http://c.test/?q=<script>alert(1)</script>",
+			ExtractedValues: map[string]string{"token": "abc"}},
+		{Timestamp: ts, TemplateID: "no-severity", MatchedURL: "http://d.test/"},
+	}
+	reporter, err := NewHTMLReporter()
+	if err != nil {
+		t.Fatalf("NewHTMLReporter: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := reporter.Render(&buf, findings); err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	doc := parseHTML(t, buf.Bytes())
+
+	sections := findElements(doc, "details", "finding")
+	if len(sections) != len(findings) {
+		t.Fatalf("report has %d finding sections, want %d", len(sections), len(findings))
+	}
+	var order []string
+	for _, s := range sections {
+		order = append(order, attr(s, "data-severity"))
+	}
+	if got := strings.Join(order, ","); got != "critical,high,low,unknown" {
+		t.Errorf("findings order = %s, want most severe first", got)
+	}
+
+	for _, span := range findElements(doc, "span", "") {
+		if attr(span, "id") == "total" && text(span) != "4" {
+			t.Errorf("total = %q, want 4", text(span))
+		}
+	}
+	summary := findElements(doc, "table", "summary")
+	if len(summary) != 1 {
+		t.Fatal("report has no summary table")
+	}
+	counts := make(map[string]string)
+	for _, row := range findElements(summary[0], "tr", "")[1:] {
+		cells := findElements(row, "td", "")
+		counts[text(cells[0])] = text(cells[1])
+	}
+	for severity, want := range map[string]string{"critical": "1", "high": "1", "medium": "0", "low": "1", "unknown": "1"} {
+		if counts[severity] != want {
+			t.Errorf("summary %s = %q, want %s", severity, counts[severity], want)
+		}
+	}
+
+	if len(findElements(doc, "script", "")) != 1 {
+		t.Error("finding values were rendered as script elements")
+	}
+	if !strings.Contains(text(sections[1]), "token=abc") {
+		t.Error("extracted values missing from the finding section")
+	}
+}
+
+func TestHTMLReporterNoFindings(t *testing.T) {
+	reporter, err := NewHTMLReporter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := reporter.Render(&buf, nil); err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if sections := findElements(parseHTML(t, buf.Bytes()), "details", "finding"); len(sections) != 0 {
+		t.Errorf("empty report has %d finding sections", len(sections))
+	}
+}
@@ -0,0 +1,86 @@
+// package scanner - persistent goroutine pool shared between scans
+package scanner
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrPoolClosed is returned by Submit after the pool has been shut down
+var ErrPoolClosed = errors.New("worker pool is shut down")
+
+// pools holds one persistent pool per worker count so repeated scans reuse their goroutines
+var pools sync.Map
+
+// WorkerPool runs submitted tasks on a fixed set of goroutines
+type WorkerPool struct {
+	tasks chan func()
+
+	mu     sync.RWMutex
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// NewWorkerPool starts a pool with size goroutines
+func NewWorkerPool(size int) *WorkerPool {
+	if size <= 0 {
+		size = 1
+	}
+	p := &WorkerPool{tasks: make(chan func(), size)}
+	p.wg.Add(size)
+	for i := 0; i < size; i++ {
+		go func() {
+			defer p.wg.Done()
+			for fn := range p.tasks {
+				fn()
+			}
+		}()
+	}
+	return p
+}
+
+// Submit queues fn, blocking while all workers are busy and the queue is full
+func (p *WorkerPool) Submit(fn func()) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return ErrPoolClosed
+	}
+	p.tasks <- fn
+	return nil
+}
+
+// Shutdown stops accepting tasks and waits for the queued ones to finish
+func (p *WorkerPool) Shutdown() {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+	p.closed = true
+	close(p.tasks)
+	p.mu.Unlock()
+	p.wg.Wait()
+}
+
+// sharedPool returns the persistent pool with the given number of workers, creating it on first use
+func sharedPool(workers int) *WorkerPool {
+	if p, ok := pools.Load(workers); ok {
+		return p.(*WorkerPool)
+	}
+	p := NewWorkerPool(workers)
+	if existing, loaded := pools.LoadOrStore(workers, p); loaded {
+		p.Shutdown()
+		return existing.(*WorkerPool)
+	}
+	return p
+}
+
+// ShutdownWorkerPools stops the persistent pools once the process runs no more scans, a later scan starts new ones
+func ShutdownWorkerPools() {
+	pools.Range(func(workers, p any) bool {
+		pools.Delete(workers)
+		p.(*WorkerPool).Shutdown()
+		return true
+	})
+}
@@ -0,0 +1,98 @@
+package scanner
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWorkerPool(t *testing.T) {
+	p := NewWorkerPool(2)
+	var done atomic.Int32
+	for i := 0; i < 10; i++ {
+		if err := p.Submit(func() { done.Add(1) }); err != nil {
+			t.Fatalf("Submit: %v", err)
+		}
+	}
+	p.Shutdown()
+	if got := done.Load(); got != 10 {
+		t.Errorf("tasks run = %d, want 10", got)
+	}
+	if err := p.Submit(func() {}); err != ErrPoolClosed {
+		t.Errorf("Submit after Shutdown = %v, want ErrPoolClosed", err)
+	}
+}
+
+func TestWorkerPoolBackpressure(t *testing.T) {
+	p := NewWorkerPool(1)
+	defer p.Shutdown()
+	release := make(chan struct{})
+	// one task runs and one waits in the queue, the next Submit has to block
+	for i := 0; i < 2; i++ {
+		if err := p.Submit(func() { <-release }); err != nil {
+			t.Fatal(err)
+		}
+	}
+	submitted := make(chan struct{})
+	go func() {
+		_ = p.Submit(func() {})
+		close(submitted)
+	}()
+	select {
+	case <-submitted:
+		t.Fatal("Submit returned while all workers were busy")
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(release)
+	select {
+	case <-submitted:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Submit still blocked after the workers were freed")
+	}
+}
+
+// BenchmarkWorkerPoolReuse runs 1000 tasks per operation on goroutines spawned for the call and on a pool
+// started once
+func BenchmarkWorkerPoolReuse(b *testing.B) {
+	const tasks, workers = 1000, 16
+
+	b.Run("spawn", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var wg sync.WaitGroup
+			wg.Add(tasks)
+			task := func() { wg.Done() }
+			ch := make(chan func(), workers)
+			for w := 0; w < workers; w++ {
+				go func() {
+					for fn := range ch {
+						fn()
+					}
+				}()
+			}
+			for t := 0; t < tasks; t++ {
+				ch <- task
+			}
+			close(ch)
+			wg.Wait()
+		}
+	})
+	b.Run("pool", func(b *testing.B) {
+		b.ReportAllocs()
+		p := NewWorkerPool(workers)
+		defer p.Shutdown()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			var wg sync.WaitGroup
+			wg.Add(tasks)
+			task := func() { wg.Done() }
+			for t := 0; t < tasks; t++ {
+				if err := p.Submit(task); err != nil {
+					b.Fatal(err)
+				}
+			}
+			wg.Wait()
+		}
+	})
+}
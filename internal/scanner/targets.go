@@ -0,0 +1,76 @@
+// package scanner - reading scan targets
+package scanner
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/artnikel/nuclei/internal/templates"
+)
+
+// ReadTargets reads one target per line from r, skipping empty lines and # comments.
+// Internationalized hostnames are converted to punycode
+func ReadTargets(r io.Reader) ([]string, error) {
+	var targets []string
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		target := strings.TrimSpace(sc.Text())
+		if target == "" || strings.HasPrefix(target, "#") {
+			continue
+		}
+		targets = append(targets, templates.NormalizeIDN(target))
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return targets, nil
+}
+
+// DeduplicateTargets normalizes the targets and returns the distinct ones sorted.
+// Scheme and host are lowercased, default ports and trailing slashes are removed and the path is percent-decoded
+func DeduplicateTargets(targets []string) []string {
+	seen := make(map[string]struct{}, len(targets))
+	unique := make([]string, 0, len(targets))
+	for _, t := range targets {
+		n := normalizeTarget(t)
+		if _, ok := seen[n]; ok {
+			continue
+		}
+		seen[n] = struct{}{}
+		unique = append(unique, n)
+	}
+	sort.Strings(unique)
+	return unique
+}
+
+// normalizeTarget returns the canonical form of target, targets that aren't URLs are only trimmed
+func normalizeTarget(target string) string {
+	target = strings.TrimSpace(target)
+	u, err := url.Parse(target)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return target
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	host := strings.ToLower(u.Hostname())
+	port := u.Port()
+	if (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		port = ""
+	}
+	if port != "" {
+		u.Host = net.JoinHostPort(host, port)
+	} else if strings.Contains(host, ":") {
+		u.Host = "[" + host + "]"
+	} else {
+		u.Host = host
+	}
+	if path, err := url.PathUnescape(u.EscapedPath()); err == nil {
+		u.Path = path
+		u.RawPath = ""
+	}
+	u.Path = strings.TrimRight(u.Path, "/")
+	return u.String()
+}
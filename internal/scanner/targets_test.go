@@ -0,0 +1,39 @@
+package scanner
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadTargets(t *testing.T) {
+	input := "# targets\nhttp://example.com\n\n  http://hébergement.fr/login  \n# http://skipped.test\n"
+	targets, err := ReadTargets(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ReadTargets: %v", err)
+	}
+	want := []string{"http://example.com", "http://xn--hbergement-b7a.fr/login"}
+	if strings.Join(targets, " ") != strings.Join(want, " ") {
+		t.Errorf("ReadTargets() = %v, want %v", targets, want)
+	}
+}
+
+func TestDeduplicateTargets(t *testing.T) {
+	variants := []string{
+		"http://example.com",
+		"http://example.com/",
+		"HTTP://Example.COM:80",
+		"https://example.com",
+		"https://EXAMPLE.com:443/",
+		"  http://example.com  ",
+	}
+	got := DeduplicateTargets(variants)
+	want := []string{"http://example.com", "https://example.com"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("DeduplicateTargets() = %v, want %v", got, want)
+	}
+
+	paths := DeduplicateTargets([]string{"http://example.com/a%20b/", "http://example.com/a b", "http://example.com:8080/a b", "not a url"})
+	if len(paths) != 3 {
+		t.Errorf("DeduplicateTargets() = %v, want 3 distinct targets", paths)
+	}
+}
@@ -1,48 +1,85 @@
-// pacakage scanner implementing workers that process targets
+// package scanner implementing workers that process targets
 package scanner
 
 import (
 	"context"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/artnikel/nuclei/internal/logging"
+	"github.com/artnikel/nuclei/internal/metrics"
 )
 
 // ProcessTargetFunc defines a function for processing one target (target)
 type ProcessTargetFunc func(ctx context.Context, target string) error
 
-// StartWorkers starts the specified number of Workers that process targets from the targetsCh channel in parallel.
-// Returns the channel that will be closed after all Workers are finished
-func StartWorkers(ctx context.Context, targetsCh <-chan string, workers int, processFn ProcessTargetFunc, logger *logging.Logger) <-chan struct{} {
+// StartWorkers processes targets from the targetsCh channel in parallel on a persistent pool of the specified
+// number of Workers. While paused is set, running targets finish and no new target is handed to the pool.
+// Returns the channel that will be closed after all targets are processed
+func StartWorkers(ctx context.Context, targetsCh <-chan string, workers int, paused *atomic.Bool, processFn ProcessTargetFunc, logger *logging.Logger) <-chan struct{} {
 	doneCh := make(chan struct{})
+	processFn = instrumentProcessFunc(processFn)
+	pool := sharedPool(workers)
 
-	var wg sync.WaitGroup
-	wg.Add(workers)
-
-	for i := 0; i < workers; i++ {
-		go func() {
-			defer wg.Done()
-			for {
-				select {
-				case <-ctx.Done():
+	go func() {
+		defer close(doneCh)
+		var wg sync.WaitGroup
+		defer wg.Wait()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case target, ok := <-targetsCh:
+				if !ok {
+					return
+				}
+				// a paused scan waits here rather than on a pool worker, the pool is shared with other scans
+				if !waitWhilePaused(ctx, paused) {
+					return
+				}
+				wg.Add(1)
+				err := pool.Submit(func() {
+					defer wg.Done()
+					_ = processFn(ctx, target)
+				})
+				if err != nil {
+					wg.Done()
 					return
-				case target, ok := <-targetsCh:
-					if !ok {
-						return
-					}
-					err := processFn(ctx, target)
-					if err != nil {
-						//logger.Info.Printf("Error processing target %s: %v\n", target, err)
-					}
 				}
 			}
-		}()
-	}
-
-	go func() {
-		wg.Wait()
-		close(doneCh)
+		}
 	}()
 
 	return doneCh
 }
+
+// pausePollInterval is how often a paused worker checks whether the scan was resumed
+const pausePollInterval = 100 * time.Millisecond
+
+// waitWhilePaused blocks while paused is set and reports whether the worker may continue
+func waitWhilePaused(ctx context.Context, paused *atomic.Bool) bool {
+	for paused != nil && paused.Load() {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(pausePollInterval):
+		}
+	}
+	return ctx.Err() == nil
+}
+
+// instrumentProcessFunc wraps processFn to record target counters and scan duration
+func instrumentProcessFunc(processFn ProcessTargetFunc) ProcessTargetFunc {
+	return func(ctx context.Context, target string) error {
+		metrics.TargetsTotal.Inc()
+		start := time.Now()
+		err := processFn(ctx, target)
+		metrics.ScanDuration.Observe(time.Since(start).Seconds())
+		metrics.TargetsProcessed.Inc()
+		if err != nil {
+			metrics.ErrorsTotal.Inc()
+		}
+		return err
+	}
+}
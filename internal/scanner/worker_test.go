@@ -0,0 +1,91 @@
+package scanner
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/artnikel/nuclei/internal/logging"
+)
+
+func testLogger() *logging.Logger {
+	return &logging.Logger{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+}
+
+// feed sends targets on a new channel and closes it once all were received
+func feed(targets ...string) <-chan string {
+	ch := make(chan string)
+	go func() {
+		defer close(ch)
+		for _, t := range targets {
+			ch <- t
+		}
+	}()
+	return ch
+}
+
+func TestStartWorkersPauseResume(t *testing.T) {
+	t.Cleanup(ShutdownWorkerPools)
+	var paused atomic.Bool
+	var processed atomic.Int32
+	process := func(ctx context.Context, target string) error {
+		processed.Add(1)
+		return nil
+	}
+
+	paused.Store(true)
+	targets := make([]string, 10)
+	for i := range targets {
+		targets[i] = "http://example.com/" + string(rune('a'+i))
+	}
+	done := StartWorkers(context.Background(), feed(targets...), 4, &paused, process, testLogger())
+
+	time.Sleep(500 * time.Millisecond)
+	if n := processed.Load(); n != 0 {
+		t.Fatalf("%d targets processed while paused, want 0", n)
+	}
+
+	paused.Store(false)
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("scan did not finish after resuming")
+	}
+	if n := processed.Load(); n != 10 {
+		t.Errorf("%d targets processed after resuming, want 10", n)
+	}
+}
+
+func TestStartWorkersPausedScanDoesNotStallOthers(t *testing.T) {
+	t.Cleanup(ShutdownWorkerPools)
+	var paused atomic.Bool
+	paused.Store(true)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pausedDone := StartWorkers(ctx, feed("http://paused.test/1", "http://paused.test/2", "http://paused.test/3"), 2, &paused,
+		func(context.Context, string) error { return nil }, testLogger())
+	// give the paused scan time to take its targets
+	time.Sleep(200 * time.Millisecond)
+
+	var processed atomic.Int32
+	done := StartWorkers(context.Background(), feed("http://a.test", "http://b.test", "http://c.test"), 2, nil,
+		func(context.Context, string) error { processed.Add(1); return nil }, testLogger())
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("scan sharing the pool stalled behind the paused scan")
+	}
+	if n := processed.Load(); n != 3 {
+		t.Errorf("%d targets processed, want 3", n)
+	}
+
+	cancel()
+	select {
+	case <-pausedDone:
+	case <-time.After(5 * time.Second):
+		t.Fatal("paused scan did not stop after cancellation")
+	}
+}
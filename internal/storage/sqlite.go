@@ -0,0 +1,170 @@
+// Package storage persists scan findings for history and queries
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/artnikel/nuclei/internal/templates"
+	_ "modernc.org/sqlite"
+)
+
+const createFindingsTable = `CREATE TABLE IF NOT EXISTS findings (
+	id INTEGER PRIMARY KEY,
+	timestamp TEXT,
+	target TEXT,
+	template_id TEXT,
+	severity TEXT,
+	name TEXT,
+	matched_url TEXT,
+	extracted_values TEXT,
+	cvss_score REAL DEFAULT 0,
+	cve_id TEXT DEFAULT ''
+)`
+
+// findingsMigrations add the columns missing from findings tables created by older versions
+var findingsMigrations = map[string]string{
+	"cvss_score": `ALTER TABLE findings ADD COLUMN cvss_score REAL DEFAULT 0`,
+	"cve_id":     `ALTER TABLE findings ADD COLUMN cve_id TEXT DEFAULT ''`,
+}
+
+// Store saves findings and queries them back
+type Store interface {
+	Save(f *templates.Finding) error
+	Query(filter FindingFilter) ([]templates.Finding, error)
+	Close() error
+}
+
+// FindingFilter restricts the findings returned by Query, zero values are ignored
+type FindingFilter struct {
+	Severity   string
+	TemplateID string
+	From       time.Time
+	To         time.Time
+}
+
+// SQLiteStore is a Store backed by a SQLite database file
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteStore opens (or creates) the database at path and prepares the findings table
+func NewSQLiteStore(path string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database %s: %w", path, err)
+	}
+	if _, err := db.Exec(createFindingsTable); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create findings table: %w", err)
+	}
+	if err := migrateFindingsTable(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate findings table: %w", err)
+	}
+	return &SQLiteStore{db: db}, nil
+}
+
+// migrateFindingsTable adds the columns from findingsMigrations that the findings table lacks
+func migrateFindingsTable(db *sql.DB) error {
+	rows, err := db.Query(`SELECT name FROM pragma_table_info('findings')`)
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return err
+		}
+		existing[name] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for column, stmt := range findingsMigrations {
+		if existing[column] {
+			continue
+		}
+		if _, err := db.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Save inserts the finding into the findings table
+func (s *SQLiteStore) Save(f *templates.Finding) error {
+	extracted, err := json.Marshal(f.ExtractedValues)
+	if err != nil {
+		return fmt.Errorf("failed to encode extracted values: %w", err)
+	}
+	_, err = s.db.Exec(
+		`INSERT INTO findings (timestamp, target, template_id, severity, name, matched_url, extracted_values, cvss_score, cve_id) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		f.Timestamp.UTC().Format(time.RFC3339), f.Target, f.TemplateID, f.Severity, f.Name, f.MatchedURL, string(extracted), f.CVSSScore, f.CVEID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save finding: %w", err)
+	}
+	return nil
+}
+
+// Query returns the findings matching the filter, newest first
+func (s *SQLiteStore) Query(filter FindingFilter) ([]templates.Finding, error) {
+	var conds []string
+	var args []any
+	if filter.Severity != "" {
+		conds = append(conds, "LOWER(severity) = LOWER(?)")
+		args = append(args, filter.Severity)
+	}
+	if filter.TemplateID != "" {
+		conds = append(conds, "template_id = ?")
+		args = append(args, filter.TemplateID)
+	}
+	if !filter.From.IsZero() {
+		conds = append(conds, "timestamp >= ?")
+		args = append(args, filter.From.UTC().Format(time.RFC3339))
+	}
+	if !filter.To.IsZero() {
+		conds = append(conds, "timestamp <= ?")
+		args = append(args, filter.To.UTC().Format(time.RFC3339))
+	}
+
+	query := "SELECT timestamp, target, template_id, severity, name, matched_url, extracted_values, cvss_score, cve_id FROM findings"
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	query += " ORDER BY timestamp DESC, id DESC"
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query findings: %w", err)
+	}
+	defer rows.Close()
+
+	var findings []templates.Finding
+	for rows.Next() {
+		var f templates.Finding
+		var ts, extracted string
+		if err := rows.Scan(&ts, &f.Target, &f.TemplateID, &f.Severity, &f.Name, &f.MatchedURL, &extracted, &f.CVSSScore, &f.CVEID); err != nil {
+			return nil, fmt.Errorf("failed to scan finding: %w", err)
+		}
+		f.Timestamp, _ = time.Parse(time.RFC3339, ts)
+		if extracted != "" {
+			_ = json.Unmarshal([]byte(extracted), &f.ExtractedValues)
+		}
+		findings = append(findings, f)
+	}
+	return findings, rows.Err()
+}
+
+// Close closes the underlying database
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,154 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/artnikel/nuclei/internal/templates"
+)
+
+func newTestStore(t *testing.T) *SQLiteStore {
+	t.Helper()
+	store, err := NewSQLiteStore(filepath.Join(t.TempDir(), "nuclei.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestNewSQLiteStore(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    func(t *testing.T) string
+		wantErr bool
+	}{
+		{name: "new file", path: func(t *testing.T) string { return filepath.Join(t.TempDir(), "nuclei.db") }},
+		{name: "missing directory", path: func(t *testing.T) string {
+			return filepath.Join(t.TempDir(), "missing", "nuclei.db")
+		}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := NewSQLiteStore(tt.path(t))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewSQLiteStore() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if store != nil {
+				store.Close()
+			}
+		})
+	}
+}
+
+func TestNewSQLiteStoreReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nuclei.db")
+	store, err := NewSQLiteStore(path)
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	if err := store.Save(&templates.Finding{Timestamp: time.Now(), TemplateID: "kept"}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	store.Close()
+
+	store, err = NewSQLiteStore(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer store.Close()
+	findings, err := store.Query(FindingFilter{})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if len(findings) != 1 || findings[0].TemplateID != "kept" {
+		t.Fatalf("findings after reopen = %+v", findings)
+	}
+}
+
+func TestSQLiteStoreSave(t *testing.T) {
+	store := newTestStore(t)
+	ts := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	want := templates.Finding{
+		Timestamp:       ts,
+		Target:          "http://example.com",
+		TemplateID:      "git-config",
+		Severity:        "high",
+		Name:            "Git config exposure",
+		MatchedURL:      "http://example.com/.git/config",
+		ExtractedValues: map[string]string{"remote": "origin"},
+		CVSSScore:       7.5,
+		CVEID:           "CVE-2024-0001",
+	}
+	if err := store.Save(&want); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	findings, err := store.Query(FindingFilter{})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if len(findings) != 1 {
+		t.Fatalf("got %d findings, want 1", len(findings))
+	}
+	got := findings[0]
+	if !got.Timestamp.Equal(want.Timestamp) || got.Target != want.Target || got.TemplateID != want.TemplateID ||
+		got.Severity != want.Severity || got.Name != want.Name || got.MatchedURL != want.MatchedURL ||
+		got.CVSSScore != want.CVSSScore || got.CVEID != want.CVEID {
+		t.Errorf("saved finding = %+v, want %+v", got, want)
+	}
+	if got.ExtractedValues["remote"] != "origin" {
+		t.Errorf("extracted values = %v", got.ExtractedValues)
+	}
+}
+
+func TestSQLiteStoreQuery(t *testing.T) {
+	store := newTestStore(t)
+	day := func(d int) time.Time { return time.Date(2024, 5, d, 12, 0, 0, 0, time.UTC) }
+	for _, f := range []templates.Finding{
+		{Timestamp: day(1), TemplateID: "a", Severity: "high"},
+		{Timestamp: day(2), TemplateID: "b", Severity: "low"},
+		{Timestamp: day(3), TemplateID: "a", Severity: "HIGH"},
+		{Timestamp: day(4), TemplateID: "c", Severity: "critical"},
+	} {
+		if err := store.Save(&f); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter FindingFilter
+		want   []string
+	}{
+		{name: "no filter newest first", filter: FindingFilter{}, want: []string{"c", "a", "b", "a"}},
+		{name: "severity ignores case", filter: FindingFilter{Severity: "high"}, want: []string{"a", "a"}},
+		{name: "template id", filter: FindingFilter{TemplateID: "b"}, want: []string{"b"}},
+		{name: "from", filter: FindingFilter{From: day(3)}, want: []string{"c", "a"}},
+		{name: "to", filter: FindingFilter{To: day(2)}, want: []string{"b", "a"}},
+		{name: "date range", filter: FindingFilter{From: day(2), To: day(3)}, want: []string{"a", "b"}},
+		{name: "combined", filter: FindingFilter{Severity: "high", From: day(2)}, want: []string{"a"}},
+		{name: "no match", filter: FindingFilter{TemplateID: "missing"}, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings, err := store.Query(tt.filter)
+			if err != nil {
+				t.Fatalf("Query: %v", err)
+			}
+			var got []string
+			for _, f := range findings {
+				got = append(got, f.TemplateID)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Query() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("Query() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
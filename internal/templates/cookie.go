@@ -0,0 +1,46 @@
+// package templates - matching of cookie attributes set by the response
+package templates
+
+import (
+	"net/http"
+	"strings"
+)
+
+// cookieHasFlag reports whether the cookie sets the attribute named by flag, unknown flags are never set
+func cookieHasFlag(c *http.Cookie, flag string) bool {
+	switch strings.ToLower(strings.TrimSpace(flag)) {
+	case "httponly":
+		return c.HttpOnly
+	case "secure":
+		return c.Secure
+	case "samesite-none":
+		return c.SameSite == http.SameSiteNoneMode
+	case "samesite-strict":
+		return c.SameSite == http.SameSiteStrictMode
+	case "samesite-lax":
+		return c.SameSite == http.SameSiteLaxMode
+	default:
+		return false
+	}
+}
+
+// matchCookie reports whether a cookie named name (case-insensitive, empty for any cookie) is set with all flags.
+// With negate it reports whether such a cookie is set without at least one of them
+func matchCookie(resp *http.Response, name string, flags []string, negate bool) bool {
+	for _, c := range resp.Cookies() {
+		if name != "" && !strings.EqualFold(c.Name, name) {
+			continue
+		}
+		hasAll := true
+		for _, flag := range flags {
+			if !cookieHasFlag(c, flag) {
+				hasAll = false
+				break
+			}
+		}
+		if hasAll != negate {
+			return true
+		}
+	}
+	return false
+}
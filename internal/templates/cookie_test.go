@@ -0,0 +1,67 @@
+package templates
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCookieMatcher(t *testing.T) {
+	tests := []struct {
+		name      string
+		setCookie []string
+		matcher   Matcher
+		want      bool
+	}{
+		{name: "httponly set", setCookie: []string{"session=abc; HttpOnly"}, matcher: Matcher{CookieName: "session", CookieFlags: []string{"httponly"}}, want: true},
+		{name: "name is case-insensitive", setCookie: []string{"session=abc; HttpOnly"}, matcher: Matcher{CookieName: "SESSION", CookieFlags: []string{"HttpOnly"}}, want: true},
+		{name: "secure missing", setCookie: []string{"session=abc; HttpOnly"}, matcher: Matcher{CookieName: "session", CookieFlags: []string{"httponly", "secure"}}, want: false},
+		{name: "negate finds the missing secure flag", setCookie: []string{"session=abc; HttpOnly"}, matcher: Matcher{CookieName: "session", CookieFlags: []string{"secure"}, Negate: true}, want: true},
+		{name: "negate with every flag set", setCookie: []string{"session=abc; HttpOnly; Secure"}, matcher: Matcher{CookieName: "session", CookieFlags: []string{"httponly", "secure"}, Negate: true}, want: false},
+		{name: "samesite none", setCookie: []string{"session=abc; Secure; SameSite=None"}, matcher: Matcher{CookieFlags: []string{"samesite-none"}}, want: true},
+		{name: "samesite lax", setCookie: []string{"session=abc; SameSite=Lax"}, matcher: Matcher{CookieFlags: []string{"samesite-strict"}}, want: false},
+		{name: "other cookie", setCookie: []string{"tracking=1; HttpOnly"}, matcher: Matcher{CookieName: "session", CookieFlags: []string{"httponly"}}, want: false},
+		{name: "any cookie without httponly", setCookie: []string{"a=1; HttpOnly", "b=2"}, matcher: Matcher{CookieFlags: []string{"httponly"}, Negate: true}, want: true},
+		{name: "no cookies", matcher: Matcher{CookieFlags: []string{"httponly"}, Negate: true}, want: false},
+		{name: "unknown flag", setCookie: []string{"session=abc; HttpOnly"}, matcher: Matcher{CookieFlags: []string{"partitioned"}}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{"Set-Cookie": tt.setCookie}}
+			m := tt.matcher
+			m.Type = "cookie"
+			if got := checkSingleMatcher(m, MatchContext{Resp: resp}); got != tt.want {
+				t.Errorf("cookie matcher = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+const cookieTemplate = `id: insecure-cookie-%d
+info:
+  name: Session cookie without Secure
+  author: test
+  severity: low
+http:
+  - path:
+      - "{{BaseURL}}/login"
+    matchers:
+      - type: cookie
+        cookie-name: session
+        cookie-flags: [secure]
+        negate: true
+`
+
+func TestCookieMatcherTemplate(t *testing.T) {
+	for i, header := range []string{"session=abc; HttpOnly", "session=abc; HttpOnly; Secure"} {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Set-Cookie", header)
+		}))
+		t.Cleanup(srv.Close)
+		tmpl := loadTestTemplate(t, fmt.Sprintf(cookieTemplate, i))
+		if got, want := runRequests(t, srv.URL, tmpl, testSettings()), i == 0; got != want {
+			t.Errorf("Set-Cookie %q: template matched = %v, want %v", header, got, want)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+// package templates - CORS misconfiguration probing
+package templates
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// corsProbeOrigin is the foreign origin sent by the CORS probe
+const corsProbeOrigin = "https://evil.com"
+
+// CORSInfo describes how the target answers cross-origin requests
+type CORSInfo struct {
+	ReflectsOrigin    bool
+	AllowsNull        bool
+	AllowsCredentials bool
+}
+
+// hasCORSMatcher reports whether any matcher needs the CORS probe
+func hasCORSMatcher(matchers []Matcher) bool {
+	for _, m := range matchers {
+		if m.Type == "cors" {
+			return true
+		}
+	}
+	return false
+}
+
+// probeCORS requests targetURL with a foreign and a null Origin and records the CORS headers of the responses
+func probeCORS(ctx context.Context, client *http.Client, targetURL string) (*CORSInfo, error) {
+	info := &CORSInfo{}
+	for _, origin := range []string{corsProbeOrigin, "null"} {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, targetURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Origin", origin)
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		if resp.Header.Get("Access-Control-Allow-Origin") != origin {
+			continue
+		}
+		if origin == "null" {
+			info.AllowsNull = true
+		} else {
+			info.ReflectsOrigin = true
+		}
+		if strings.EqualFold(resp.Header.Get("Access-Control-Allow-Credentials"), "true") {
+			info.AllowsCredentials = true
+		}
+	}
+	return info, nil
+}
+
+// matchCORS checks the requested misconfigurations, a matcher without any of them set matches any misconfiguration
+func matchCORS(info *CORSInfo, m Matcher) bool {
+	if !m.ReflectOrigin && !m.AllowNull && !m.AllowCredentials {
+		return info.ReflectsOrigin || info.AllowsNull
+	}
+	if m.ReflectOrigin && !info.ReflectsOrigin {
+		return false
+	}
+	if m.AllowNull && !info.AllowsNull {
+		return false
+	}
+	if m.AllowCredentials && !info.AllowsCredentials {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,120 @@
+package templates
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newCORSServer answers with the CORS headers returned by policy for the request Origin
+func newCORSServer(t *testing.T, policy func(origin string) (allowOrigin, allowCredentials string)) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		allowOrigin, allowCredentials := policy(r.Header.Get("Origin"))
+		if allowOrigin != "" {
+			w.Header().Set("Access-Control-Allow-Origin", allowOrigin)
+		}
+		if allowCredentials != "" {
+			w.Header().Set("Access-Control-Allow-Credentials", allowCredentials)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// corsPolicies are mock server behaviours with the CORS info the probe should record for them
+var corsPolicies = []struct {
+	name   string
+	policy func(origin string) (string, string)
+	want   CORSInfo
+}{
+	{
+		name:   "reflects any origin with credentials",
+		policy: func(origin string) (string, string) { return origin, "true" },
+		want:   CORSInfo{ReflectsOrigin: true, AllowsNull: true, AllowsCredentials: true},
+	},
+	{
+		name: "reflects foreign origins without credentials",
+		policy: func(origin string) (string, string) {
+			if origin == "null" {
+				return "", ""
+			}
+			return origin, ""
+		},
+		want: CORSInfo{ReflectsOrigin: true},
+	},
+	{
+		name: "allows null",
+		policy: func(origin string) (string, string) {
+			if origin != "null" {
+				return "", ""
+			}
+			return "null", "TRUE"
+		},
+		want: CORSInfo{AllowsNull: true, AllowsCredentials: true},
+	},
+	{
+		name:   "wildcard",
+		policy: func(string) (string, string) { return "*", "" },
+	},
+	{
+		name:   "fixed trusted origin",
+		policy: func(string) (string, string) { return "https://app.example.com", "true" },
+	},
+}
+
+func TestProbeCORS(t *testing.T) {
+	for _, p := range corsPolicies {
+		t.Run(p.name, func(t *testing.T) {
+			srv := newCORSServer(t, p.policy)
+			info, err := probeCORS(context.Background(), srv.Client(), srv.URL+"/api")
+			if err != nil {
+				t.Fatalf("probeCORS: %v", err)
+			}
+			if *info != p.want {
+				t.Errorf("probeCORS = %+v, want %+v", *info, p.want)
+			}
+		})
+	}
+}
+
+const corsTemplate = `id: cors-%d
+info:
+  name: CORS misconfiguration
+  author: test
+  severity: medium
+http:
+  - method: GET
+    path:
+      - "{{BaseURL}}/api"
+    matchers:
+      - type: cors
+%s`
+
+func TestCORSMatcher(t *testing.T) {
+	reflecting := newCORSServer(t, corsPolicies[0].policy)
+	wildcard := newCORSServer(t, corsPolicies[3].policy)
+
+	tests := []struct {
+		name   string
+		fields string
+		target *httptest.Server
+		want   bool
+	}{
+		{name: "any misconfiguration", target: reflecting, want: true},
+		{name: "reflected origin with credentials", fields: "        reflect-origin: true\n        allow-credentials: true\n", target: reflecting, want: true},
+		{name: "null origin", fields: "        allow-null: true\n", target: reflecting, want: true},
+		{name: "wildcard is not reflected", fields: "        reflect-origin: true\n", target: wildcard, want: false},
+		{name: "wildcard any misconfiguration", target: wildcard, want: false},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl := loadTestTemplate(t, fmt.Sprintf(corsTemplate, i, tt.fields))
+			if got := runRequests(t, tt.target.URL, tmpl, testSettings()); got != tt.want {
+				t.Errorf("cors matcher = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+// package templates - ordering of templates by their requirements
+package templates
+
+import (
+	"context"
+	"fmt"
+)
+
+// templateRun tracks the execution of a template so templates requiring it can wait for its result
+type templateRun struct {
+	owner   *Template
+	done    chan struct{}
+	matched bool
+}
+
+// newTemplateRuns creates a run per template ID, the first template wins for duplicated IDs
+func newTemplateRuns(templates []*Template) map[string]*templateRun {
+	runs := make(map[string]*templateRun, len(templates))
+	for _, t := range templates {
+		if _, ok := runs[t.ID]; !ok {
+			runs[t.ID] = &templateRun{owner: t, done: make(chan struct{})}
+		}
+	}
+	return runs
+}
+
+// closeRun publishes the result of t to the templates requiring it
+func closeRun(runs map[string]*templateRun, t *Template, matched bool) {
+	run := runs[t.ID]
+	if run.owner != t {
+		return
+	}
+	run.matched = matched
+	close(run.done)
+}
+
+// requirementsMatched waits until every template required by t has run
+// and reports whether all of them matched. Requirements that aren't loaded never match
+func requirementsMatched(ctx context.Context, t *Template, runs map[string]*templateRun) bool {
+	for _, id := range t.Requires {
+		run, ok := runs[id]
+		if !ok {
+			return false
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-run.done:
+		}
+		if !run.matched {
+			return false
+		}
+	}
+	return true
+}
+
+// sortByDependencies orders templates so every template comes after the templates it requires.
+// Requirements missing from templates are ignored, a dependency cycle is an error
+func sortByDependencies(templates []*Template) ([]*Template, error) {
+	byID := make(map[string]*Template, len(templates))
+	for _, t := range templates {
+		if _, ok := byID[t.ID]; !ok {
+			byID[t.ID] = t
+		}
+	}
+
+	const (
+		grey  = 1 // on the current DFS path
+		black = 2 // finished
+	)
+	state := make(map[*Template]int, len(templates))
+	sorted := make([]*Template, 0, len(templates))
+
+	var visit func(t *Template, path []string) error
+	visit = func(t *Template, path []string) error {
+		switch state[t] {
+		case black:
+			return nil
+		case grey:
+			return fmt.Errorf("template dependency cycle: %v -> %s", path, t.ID)
+		}
+		state[t] = grey
+		for _, id := range t.Requires {
+			if dep, ok := byID[id]; ok {
+				if err := visit(dep, append(path, t.ID)); err != nil {
+					return err
+				}
+			}
+		}
+		state[t] = black
+		sorted = append(sorted, t)
+		return nil
+	}
+
+	for _, t := range templates {
+		if err := visit(t, nil); err != nil {
+			return nil, err
+		}
+	}
+	return sorted, nil
+}
@@ -0,0 +1,155 @@
+package templates
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// depTemplate returns a template with the given ID and requirements
+func depTemplate(id string, requires ...string) *Template {
+	return &Template{ID: id, Requires: requires}
+}
+
+// positions maps the template IDs to their index in tmpls
+func positions(tmpls []*Template) map[string]int {
+	pos := make(map[string]int, len(tmpls))
+	for i, t := range tmpls {
+		pos[t.ID] = i
+	}
+	return pos
+}
+
+func TestSortByDependencies(t *testing.T) {
+	tests := []struct {
+		name      string
+		templates []*Template
+		edges     [][2]string // every first ID must come before the second
+	}{
+		{
+			name:      "linear chain",
+			templates: []*Template{depTemplate("c", "b"), depTemplate("b", "a"), depTemplate("a")},
+			edges:     [][2]string{{"a", "b"}, {"b", "c"}},
+		},
+		{
+			name:      "diamond",
+			templates: []*Template{depTemplate("d", "b", "c"), depTemplate("c", "a"), depTemplate("b", "a"), depTemplate("a")},
+			edges:     [][2]string{{"a", "b"}, {"a", "c"}, {"b", "d"}, {"c", "d"}},
+		},
+		{
+			name:      "missing requirement",
+			templates: []*Template{depTemplate("b", "missing"), depTemplate("a")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sorted, err := sortByDependencies(tt.templates)
+			if err != nil {
+				t.Fatalf("sortByDependencies: %v", err)
+			}
+			if len(sorted) != len(tt.templates) {
+				t.Fatalf("sorted %d templates, want %d", len(sorted), len(tt.templates))
+			}
+			pos := positions(sorted)
+			for _, e := range tt.edges {
+				if pos[e[0]] > pos[e[1]] {
+					t.Errorf("%s runs after %s: %s", e[0], e[1], orderedIDs(sorted))
+				}
+			}
+		})
+	}
+
+	_, err := sortByDependencies([]*Template{depTemplate("a", "b"), depTemplate("b", "a")})
+	if err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Errorf("sortByDependencies(a->b->a) error = %v, want a cycle error", err)
+	}
+}
+
+// writeDepTemplates writes a template per ID requesting /<id> and requiring the listed IDs
+func writeDepTemplates(t *testing.T, requires map[string][]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for id, req := range requires {
+		var block string
+		if len(req) > 0 {
+			block = "requires: [" + strings.Join(req, ", ") + "]\n"
+		}
+		writeTestFile(t, dir, id+".yaml", fmt.Sprintf(`id: %s
+%sinfo:
+  name: Template %s
+  author: test
+  severity: info
+http:
+  - path:
+      - "{{BaseURL}}/%s"
+    matchers:
+      - type: status
+        status: [200]
+`, id, block, id, id))
+	}
+	return dir
+}
+
+func TestFindMatchingTemplatesRequires(t *testing.T) {
+	tests := []struct {
+		name     string
+		requires map[string][]string
+		matching []string
+		want     string // paths requested by the templates
+		err      bool
+	}{
+		{
+			name:     "chain runs after each match",
+			requires: map[string][]string{"a": nil, "b": {"a"}, "c": {"b"}},
+			matching: []string{"/a", "/b", "/c"},
+			want:     "/a,/b,/c",
+		},
+		{
+			name:     "chain stops at the first miss",
+			requires: map[string][]string{"a": nil, "b": {"a"}, "c": {"b"}},
+			matching: []string{"/a", "/c"},
+			want:     "/a,/b",
+		},
+		{
+			name:     "diamond needs both branches",
+			requires: map[string][]string{"a": nil, "b": {"a"}, "c": {"a"}, "d": {"b", "c"}},
+			matching: []string{"/a", "/b", "/d"},
+			want:     "/a,/b,/c",
+		},
+		{
+			name:     "cycle",
+			requires: map[string][]string{"a": {"b"}, "b": {"a"}},
+			err:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, srv := newRequestRecorder(t, tt.matching...)
+			dir := writeDepTemplates(t, tt.requires)
+			_, err := FindMatchingTemplates(context.Background(), srv.URL, dir, nil, nil, 10*time.Second,
+				testSettings(), testLogger(), func(i, total int) {})
+			if tt.err {
+				if err == nil {
+					t.Fatal("FindMatchingTemplates succeeded with a dependency cycle")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("FindMatchingTemplates: %v", err)
+			}
+			var sent []string
+			for _, uri := range rec.requests() {
+				if len(uri) == 2 {
+					sent = append(sent, uri)
+				}
+			}
+			sort.Strings(sent)
+			if got := strings.Join(sent, ","); got != tt.want {
+				t.Errorf("requests = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
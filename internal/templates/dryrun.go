@@ -0,0 +1,83 @@
+// package templates - dry run of template requests
+package templates
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+
+	"github.com/artnikel/nuclei/internal/logging"
+)
+
+// DryRunTemplate resolves the HTTP requests the template would send to baseURL without sending them.
+// Every entry has the "METHOD URL" form
+func DryRunTemplate(baseURL string, tmpl *Template, advanced *AdvancedSettingsChecker) ([]string, error) {
+	parsedBaseURL, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base url: %w", err)
+	}
+	vars := baseRequestVars(parsedBaseURL, tmpl.Variables)
+
+	var resolved []string
+	for _, req := range tmpl.Requests {
+		if req.Type != "http" && req.Type != "" {
+			continue
+		}
+		method := req.Method
+		if method == "" {
+			method = http.MethodGet
+		}
+		payloadSets, err := buildPayloadSets(req, tmpl, advanced)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range req.Path {
+			for _, payload := range payloadSets {
+				payload, err := expandPayloadValues(payload, vars)
+				if err != nil {
+					return nil, err
+				}
+				reqVars := withPayload(vars, payload)
+				path, err := expandDynamicPayloads(substitutePathVariables(p, reqVars), reqVars)
+				if err != nil {
+					return nil, err
+				}
+				resolved = append(resolved, method+" "+buildFullURL(parsedBaseURL, path))
+			}
+		}
+	}
+	return resolved, nil
+}
+
+// dryRunTemplates runs the templates in dry-run mode one by one, the requests are logged and nothing is matched
+func dryRunTemplates(
+	ctx context.Context,
+	targetURL string,
+	templates []*Template,
+	severityFilter []string,
+	targetHost string,
+	advanced *AdvancedSettingsChecker,
+	logger *logging.Logger,
+	progressCallback func(i, total int),
+) ([]*Finding, error) {
+	for i, tmpl := range templates {
+		if templateMatchesHost(tmpl, targetHost) && tmpl.MatchesSeverity(severityFilter) {
+			if _, _, err := matchTemplate(ctx, targetURL, "", tmpl, nil, advanced, logger); err != nil {
+				logger.Info("Dry run failed", slog.String("template_id", tmpl.ID), slog.Any("error", err))
+			}
+		}
+		progressCallback(i+1, len(templates))
+	}
+	return nil, nil
+}
+
+// logDryRun logs a request skipped in dry-run mode and passes it to advanced.OnDryRun
+func logDryRun(advanced *AdvancedSettingsChecker, logger *logging.Logger, line string) {
+	line = "[DRY-RUN] " + line
+	logger.Info(line)
+	if advanced.OnDryRun != nil {
+		advanced.OnDryRun(line)
+	}
+}
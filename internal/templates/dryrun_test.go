@@ -0,0 +1,105 @@
+package templates
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const dryRunHTTPTemplate = `id: dry-run-http
+info:
+  name: Dry run HTTP
+  author: test
+  severity: info
+variables:
+  token: abc123
+http:
+  - method: POST
+    path:
+      - "{{BaseURL}}/admin/{{token}}"
+    matchers:
+      - type: status
+        status: [200]
+`
+
+const dryRunNetworkTemplate = `id: dry-run-network
+info:
+  name: Dry run network
+  author: test
+  severity: info
+http:
+  - type: network
+    path:
+      - "{{Hostname}}"
+    matchers:
+      - type: word
+        words: ["OK"]
+`
+
+// countDials replaces dialContext for the test and returns the number of connections dialed through it
+func countDials(t *testing.T) *atomic.Int32 {
+	t.Helper()
+	var dials atomic.Int32
+	orig := dialContext
+	dialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		dials.Add(1)
+		return orig(ctx, network, addr)
+	}
+	t.Cleanup(func() { dialContext = orig })
+	ResetHTTPClient()
+	t.Cleanup(ResetHTTPClient)
+	return &dials
+}
+
+func TestDryRunOpensNoConnections(t *testing.T) {
+	dials := countDials(t)
+	_, srv := newRequestRecorder(t, "/admin/abc123")
+	dir := t.TempDir()
+	writeTestFile(t, dir, "http.yaml", dryRunHTTPTemplate)
+	writeTestFile(t, dir, "network.yaml", dryRunNetworkTemplate)
+
+	advanced := testSettings()
+	advanced.DryRun = true
+	var mu sync.Mutex
+	var lines []string
+	advanced.OnDryRun = func(line string) {
+		mu.Lock()
+		lines = append(lines, line)
+		mu.Unlock()
+	}
+
+	findings, err := FindMatchingTemplates(context.Background(), srv.URL, dir, nil, nil, 10*time.Second,
+		advanced, testLogger(), func(i, total int) {})
+	if err != nil || len(findings) != 0 {
+		t.Fatalf("dry run = %d findings, %v, want none", len(findings), err)
+	}
+	if n := dials.Load(); n != 0 {
+		t.Errorf("dry run dialed %d connections, want 0", n)
+	}
+	output := strings.Join(lines, "\n")
+	for _, want := range []string{
+		"[DRY-RUN] Would request: POST " + srv.URL + "/admin/abc123",
+		"[DRY-RUN] Would connect: tcp " + srv.Listener.Addr().String(),
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("dry run output misses %q:\n%s", want, output)
+		}
+	}
+
+	// the network template would wait for a greeting the HTTP server never sends
+	httpDir := t.TempDir()
+	writeTestFile(t, httpDir, "http.yaml", dryRunHTTPTemplate)
+	advanced.DryRun = false
+	findings, err = FindMatchingTemplates(context.Background(), srv.URL, httpDir, nil, nil, 10*time.Second,
+		advanced, testLogger(), func(i, total int) {})
+	if err != nil || len(findings) != 1 {
+		t.Errorf("scan = %d findings, %v, want the HTTP finding", len(findings), err)
+	}
+	if dials.Load() == 0 {
+		t.Error("dial hook saw no connections of a real scan")
+	}
+}
@@ -0,0 +1,251 @@
+// package templates - DSL expression evaluation
+package templates
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Knetic/govaluate"
+)
+
+// regexGroupsFunc is pre-evaluated before the expression is parsed, its capture groups become regex_groups_<n> parameters
+const regexGroupsFunc = "regex_groups"
+
+// randStringAlphabet is the character set used by the rand_string DSL function
+const randStringAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// dslFunctions are the helper functions available in DSL expressions
+var dslFunctions = map[string]govaluate.ExpressionFunction{
+	"now_unix": func(args ...interface{}) (interface{}, error) {
+		return float64(time.Now().Unix()), nil
+	},
+	"rand_string": func(args ...interface{}) (interface{}, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("rand_string expects 1 argument, got %d", len(args))
+		}
+		n, ok := args[0].(float64)
+		if !ok || n < 0 {
+			return nil, fmt.Errorf("rand_string expects a non-negative number")
+		}
+		b := make([]byte, int(n))
+		for i := range b {
+			idx, err := rand.Int(rand.Reader, big.NewInt(int64(len(randStringAlphabet))))
+			if err != nil {
+				return nil, err
+			}
+			b[i] = randStringAlphabet[idx.Int64()]
+		}
+		return string(b), nil
+	},
+	"len": func(args ...interface{}) (interface{}, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("len expects 1 argument, got %d", len(args))
+		}
+		switch v := args[0].(type) {
+		case string:
+			return float64(len(v)), nil
+		case []string:
+			return float64(len(v)), nil
+		case []interface{}:
+			return float64(len(v)), nil
+		default:
+			return float64(len(fmt.Sprint(v))), nil
+		}
+	},
+	"contains_all": func(args ...interface{}) (interface{}, error) {
+		if len(args) < 2 {
+			return nil, fmt.Errorf("contains_all expects at least 2 arguments, got %d", len(args))
+		}
+		s := fmt.Sprint(args[0])
+		for _, word := range args[1:] {
+			if !strings.Contains(s, fmt.Sprint(word)) {
+				return false, nil
+			}
+		}
+		return true, nil
+	},
+	"md5": func(args ...interface{}) (interface{}, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("md5 expects 1 argument, got %d", len(args))
+		}
+		sum := md5.Sum([]byte(fmt.Sprint(args[0])))
+		return hex.EncodeToString(sum[:]), nil
+	},
+	"sha256": func(args ...interface{}) (interface{}, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("sha256 expects 1 argument, got %d", len(args))
+		}
+		sum := sha256.Sum256([]byte(fmt.Sprint(args[0])))
+		return hex.EncodeToString(sum[:]), nil
+	},
+	"base64_decode": func(args ...interface{}) (interface{}, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("base64_decode expects 1 argument, got %d", len(args))
+		}
+		s := fmt.Sprint(args[0])
+		if decoded, err := base64.StdEncoding.DecodeString(s); err == nil {
+			return string(decoded), nil
+		}
+		if decoded, err := base64.RawURLEncoding.DecodeString(s); err == nil {
+			return string(decoded), nil
+		}
+		return "", nil
+	},
+	"url_decode": func(args ...interface{}) (interface{}, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("url_decode expects 1 argument, got %d", len(args))
+		}
+		decoded, err := url.QueryUnescape(fmt.Sprint(args[0]))
+		if err != nil {
+			return "", nil
+		}
+		return decoded, nil
+	},
+	regexGroupsFunc: func(args ...interface{}) (interface{}, error) {
+		groups, err := regexGroups(args...)
+		if err != nil || len(groups) == 0 {
+			return "", err
+		}
+		return groups[0], nil
+	},
+	"to_lower": func(args ...interface{}) (interface{}, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("to_lower expects 1 argument, got %d", len(args))
+		}
+		return strings.ToLower(fmt.Sprint(args[0])), nil
+	},
+	"to_upper": func(args ...interface{}) (interface{}, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("to_upper expects 1 argument, got %d", len(args))
+		}
+		return strings.ToUpper(fmt.Sprint(args[0])), nil
+	},
+}
+
+// regexGroups returns the whole match and the capture groups of pattern in subject
+func regexGroups(args ...interface{}) ([]string, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("%s expects 2 arguments, got %d", regexGroupsFunc, len(args))
+	}
+	re, err := getCompiledRegex(fmt.Sprint(args[0]))
+	if err != nil {
+		return nil, fmt.Errorf("%s: invalid pattern: %w", regexGroupsFunc, err)
+	}
+	return re.FindStringSubmatch(fmt.Sprint(args[1])), nil
+}
+
+// expandRegexGroups evaluates every regex_groups() call in expr, stores the groups as regex_groups_<n>
+// parameters and replaces the call with the whole match. Later calls overwrite the groups of earlier ones
+func expandRegexGroups(expr string, params map[string]interface{}) (string, error) {
+	for {
+		start := strings.Index(expr, regexGroupsFunc+"(")
+		if start < 0 {
+			return expr, nil
+		}
+		open := start + len(regexGroupsFunc)
+		end, args := splitCallArgs(expr, open)
+		if end < 0 {
+			return "", fmt.Errorf("unterminated %s call in %q", regexGroupsFunc, expr)
+		}
+
+		values := make([]interface{}, 0, len(args))
+		for _, arg := range args {
+			argExpr, err := govaluate.NewEvaluableExpressionWithFunctions(arg, dslFunctions)
+			if err != nil {
+				return "", fmt.Errorf("invalid %s argument %q: %w", regexGroupsFunc, arg, err)
+			}
+			v, err := argExpr.Evaluate(params)
+			if err != nil {
+				return "", err
+			}
+			values = append(values, v)
+		}
+		groups, err := regexGroups(values...)
+		if err != nil {
+			return "", err
+		}
+		for i, g := range groups {
+			params[fmt.Sprintf("%s_%d", regexGroupsFunc, i)] = g
+		}
+
+		name := regexGroupsFunc + "_0"
+		if len(groups) == 0 {
+			params[name] = ""
+		}
+		expr = expr[:start] + name + expr[end+1:]
+	}
+}
+
+// splitCallArgs splits the arguments of the call whose opening parenthesis is at expr[open]
+// and returns the index of the closing parenthesis, or -1 if the call is not terminated
+func splitCallArgs(expr string, open int) (int, []string) {
+	var args []string
+	depth := 0
+	argStart := open + 1
+	var quote byte
+	for i := open; i < len(expr); i++ {
+		c := expr[i]
+		if quote != 0 {
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '"', '\'':
+			quote = c
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				if arg := strings.TrimSpace(expr[argStart:i]); arg != "" {
+					args = append(args, arg)
+				}
+				return i, args
+			}
+		case ',':
+			if depth == 1 {
+				args = append(args, strings.TrimSpace(expr[argStart:i]))
+				argStart = i + 1
+			}
+		}
+	}
+	return -1, nil
+}
+
+// evaluateDSL evaluates the expression with the template variables as parameters
+func evaluateDSL(expr string, vars map[string]interface{}) (interface{}, error) {
+	params := make(map[string]interface{}, len(vars))
+	for k, v := range vars {
+		if ev, ok := v.(encodedValue); ok {
+			v = ev.Value
+		}
+		params[k] = v
+	}
+
+	expr, err := expandRegexGroups(expr, params)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DSL expression: %w", err)
+	}
+	expression, err := govaluate.NewEvaluableExpressionWithFunctions(expr, dslFunctions)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DSL expression %q: %w", expr, err)
+	}
+
+	result, err := expression.Evaluate(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate DSL expression %q: %w", expr, err)
+	}
+	return result, nil
+}
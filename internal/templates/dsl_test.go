@@ -0,0 +1,99 @@
+package templates
+
+import (
+	"strings"
+	"testing"
+)
+
+// dslTest is an expression evaluated with the vars and its expected result
+type dslTest struct {
+	name string
+	expr string
+	vars map[string]interface{}
+	want interface{}
+}
+
+func runDSLTests(t *testing.T, tests []dslTest) {
+	t.Helper()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := evaluateDSL(tt.expr, tt.vars)
+			if err != nil {
+				t.Fatalf("evaluateDSL(%q): %v", tt.expr, err)
+			}
+			if got != tt.want {
+				t.Errorf("evaluateDSL(%q) = %#v, want %#v", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDSLLenContainsAllMD5(t *testing.T) {
+	large := map[string]interface{}{"body": strings.Repeat("a", 6000)}
+	page := map[string]interface{}{"body": "<h1>admin</h1><form id=login>", "code": 404}
+	runDSLTests(t, []dslTest{
+		{name: "len above threshold", expr: "len(body) > 5000", vars: large, want: true},
+		{name: "len below threshold", expr: "len(body) > 5000", vars: page, want: false},
+		{name: "len value", expr: `len("hello")`, want: 5.0},
+		{name: "len of a number", expr: "len(code)", vars: page, want: 3.0},
+		{name: "len of a list", expr: "len(paths)", vars: map[string]interface{}{"paths": []string{"/a", "/b"}}, want: 2.0},
+		{name: "contains_all every word", expr: `contains_all(body, "admin", "login")`, vars: page, want: true},
+		{name: "contains_all missing word", expr: `contains_all(body, "admin", "logout")`, vars: page, want: false},
+		{name: "md5", expr: `md5("hello") == "5d41402abc4b2a76b9719d911017c592"`, want: true},
+	})
+}
+
+func TestDSLArgumentErrors(t *testing.T) {
+	for _, expr := range []string{`len()`, `len("a", "b")`, `contains_all("a")`, `md5()`} {
+		if _, err := evaluateDSL(expr, nil); err == nil {
+			t.Errorf("evaluateDSL(%q) succeeded, want an argument error", expr)
+		}
+	}
+}
+
+func TestDSLHashAndDecode(t *testing.T) {
+	runDSLTests(t, []dslTest{
+		{name: "sha256", expr: `sha256("hello")`, want: "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"},
+		{name: "base64 std", expr: `base64_decode("aGVsbG8gd29ybGQ=")`, want: "hello world"},
+		{name: "base64 raw url", expr: `base64_decode("PDw_Pz4-")`, want: "<<??>>"},
+		{name: "invalid base64", expr: `base64_decode("not*base64!")`, want: ""},
+		{name: "decode in comparison", expr: `base64_decode(token) == "admin:secret"`, vars: map[string]interface{}{"token": "YWRtaW46c2VjcmV0"}, want: true},
+		{name: "url_decode", expr: `url_decode("a%20b%2Fc+d")`, want: "a b/c d"},
+		{name: "invalid url encoding", expr: `url_decode("%zz")`, want: ""},
+		{name: "to_lower", expr: `to_lower("Server: NGINX")`, want: "server: nginx"},
+		{name: "to_upper", expr: `to_upper("get")`, want: "GET"},
+	})
+}
+
+func TestExpandRegexGroups(t *testing.T) {
+	params := map[string]interface{}{}
+	expr, err := expandRegexGroups(`regex_groups("v(\\d+)\\.(\\d+)", "v2.7") != ""`, params)
+	if err != nil {
+		t.Fatalf("expandRegexGroups: %v", err)
+	}
+	if expr != `regex_groups_0 != ""` {
+		t.Errorf("expanded expression = %q", expr)
+	}
+	for name, want := range map[string]string{"regex_groups_0": "v2.7", "regex_groups_1": "2", "regex_groups_2": "7"} {
+		if params[name] != want {
+			t.Errorf("%s = %#v, want %q", name, params[name], want)
+		}
+	}
+}
+
+func TestDSLRegexGroups(t *testing.T) {
+	banner := map[string]interface{}{"banner": "Server: nginx/1.18.0"}
+	runDSLTests(t, []dslTest{
+		{name: "groups as parameters", expr: `regex_groups("v(\\d+)\\.(\\d+)", "v2.7") != "" && regex_groups_1 == "2" && regex_groups_2 == "7"`, want: true},
+		{name: "version below patched", expr: `regex_groups("nginx/(\\d+)\\.(\\d+)", banner) != "" && regex_groups_1 == "1" && regex_groups_2 < "20"`, vars: banner, want: true},
+		{name: "no match", expr: `regex_groups("v(\\d+)", "none") == ""`, want: true},
+		{name: "nested call", expr: `regex_groups("id=(\\w+)", to_lower("ID=ABC")) != "" && regex_groups_1 == "abc"`, want: true},
+	})
+
+	if _, err := evaluateDSL(`regex_groups("v(\\d+)", "v1"`, nil); err == nil {
+		t.Error("unterminated regex_groups call evaluated without an error")
+	}
+	if _, err := evaluateDSL(`regex_groups("(", "v1") != ""`, nil); err == nil {
+		t.Error("invalid regex_groups pattern evaluated without an error")
+	}
+}
@@ -0,0 +1,57 @@
+// package templates - payload and extractor value encodings
+package templates
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"net/url"
+	"strings"
+)
+
+// Supported value encodings
+const (
+	EncodingURL       = "url"
+	EncodingDoubleURL = "double-url"
+	EncodingHTML      = "html"
+	EncodingBase64    = "base64"
+	EncodingHex       = "hex"
+)
+
+// rawPrefix marks a placeholder whose value is inserted without encoding, e.g. {{raw:name}}
+const rawPrefix = "raw:"
+
+// encodedValue is a variable value that is encoded when substituted into a placeholder
+type encodedValue struct {
+	Value    string
+	Encoding string
+}
+
+// validateEncoding checks that every encoding in the comma separated chain is supported
+func validateEncoding(encoding string) error {
+	_, err := applyEncoding("", encoding)
+	return err
+}
+
+// applyEncoding encodes value with the comma separated chain of encodings, applied left to right
+func applyEncoding(value, encoding string) (string, error) {
+	for _, enc := range strings.Split(encoding, ",") {
+		switch strings.ToLower(strings.TrimSpace(enc)) {
+		case "":
+		case EncodingURL:
+			value = url.PathEscape(value)
+		case EncodingDoubleURL:
+			value = url.PathEscape(url.PathEscape(value))
+		case EncodingHTML:
+			value = html.EscapeString(value)
+		case EncodingBase64:
+			value = base64.StdEncoding.EncodeToString([]byte(value))
+		case EncodingHex:
+			value = hex.EncodeToString([]byte(value))
+		default:
+			return "", fmt.Errorf("unsupported encoding: %s", enc)
+		}
+	}
+	return value, nil
+}
@@ -0,0 +1,195 @@
+package templates
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestApplyEncoding(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		encoding string
+		want     string
+		wantErr  bool
+	}{
+		{name: "none", value: "<script>", encoding: "", want: "<script>"},
+		{name: "url", value: "<script>", encoding: EncodingURL, want: "%3Cscript%3E"},
+		{name: "url special characters", value: "a b&c%d+e", encoding: EncodingURL, want: "a%20b&c%25d+e"},
+		{name: "double url", value: "<a>", encoding: EncodingDoubleURL, want: "%253Ca%253E"},
+		{name: "html", value: `<a href="x">&</a>`, encoding: EncodingHTML, want: "&lt;a href=&#34;x&#34;&gt;&amp;&lt;/a&gt;"},
+		{name: "base64", value: "<script>", encoding: EncodingBase64, want: "PHNjcmlwdD4="},
+		{name: "hex", value: "<>", encoding: EncodingHex, want: "3c3e"},
+		{name: "upper case", value: "<>", encoding: "HEX", want: "3c3e"},
+		{name: "chained", value: "<a>", encoding: "url,base64", want: "JTNDYSUzRQ=="},
+		{name: "chained with spaces", value: "<a>", encoding: "hex, url", want: "3c613e"},
+		{name: "unsupported", value: "x", encoding: "rot13", wantErr: true},
+		{name: "unsupported in chain", value: "x", encoding: "url,rot13", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := applyEncoding(tt.value, tt.encoding)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("applyEncoding(%q, %q) error = %v, wantErr %v", tt.value, tt.encoding, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("applyEncoding(%q, %q) = %q, want %q", tt.value, tt.encoding, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubstituteEncodedValues(t *testing.T) {
+	vars := map[string]interface{}{
+		"xss":   encodedValue{Value: "<script>", Encoding: EncodingURL},
+		"token": encodedValue{Value: "a&b", Encoding: "url,base64"},
+		"plain": "<b>",
+	}
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "encoded payload", in: "/search/{{xss}}", want: "/search/%3Cscript%3E"},
+		{name: "chained encoding", in: "t={{token}}", want: "t=YSZi"},
+		{name: "raw skips encoding", in: "/search/{{raw:xss}}", want: "/search/<script>"},
+		{name: "raw and encoded together", in: "{{raw:xss}}{{xss}}", want: "<script>%3Cscript%3E"},
+		{name: "plain value", in: "<p>{{plain}}</p>", want: "<p><b></p>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := substituteVariables(tt.in, vars); got != tt.want {
+				t.Errorf("substituteVariables(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadPayloadListsEncoding(t *testing.T) {
+	payloads := map[string]interface{}{
+		"xss":   map[string]interface{}{"values": []interface{}{"<script>"}, "encoding": "url,base64"},
+		"plain": []interface{}{"a"},
+	}
+	lists, encodings, err := loadPayloadLists(payloads, t.TempDir())
+	if err != nil {
+		t.Fatalf("loadPayloadLists: %v", err)
+	}
+	if len(lists["xss"]) != 1 || encodings["xss"] != "url,base64" || encodings["plain"] != "" {
+		t.Errorf("lists = %v, encodings = %v", lists, encodings)
+	}
+
+	payloads["bad"] = map[string]interface{}{"values": []interface{}{"x"}, "encoding": "rot13"}
+	if _, _, err := loadPayloadLists(payloads, t.TempDir()); err == nil {
+		t.Error("unsupported payload encoding accepted")
+	}
+}
+
+func TestExtractorEncoding(t *testing.T) {
+	ctx := MatchContext{Resp: &http.Response{StatusCode: http.StatusOK, Header: http.Header{}}, Body: []byte("name=<admin>")}
+	values := processExtractors([]Extractor{
+		{Type: "regex", Name: "url", Group: "1", Regex: []string{`name=(\S+)`}, Encoding: EncodingURL},
+		{Type: "regex", Name: "chained", Group: "1", Regex: []string{`name=(\S+)`}, Encoding: "html,hex"},
+		{Type: "regex", Name: "invalid", Group: "1", Regex: []string{`name=(\S+)`}, Encoding: "rot13"},
+	}, ctx)
+	if values["url"] != "%3Cadmin%3E" {
+		t.Errorf("url encoded extractor = %q", values["url"])
+	}
+	if values["chained"] != "266c743b61646d696e2667743b" {
+		t.Errorf("chained encoded extractor = %q", values["chained"])
+	}
+	if _, ok := values["invalid"]; ok {
+		t.Error("extractor with an unsupported encoding returned a value")
+	}
+}
+
+const pathEncodingTemplate = `id: path-encoding-%d
+info:
+  name: Path encoding
+  author: test
+  severity: info
+variables:
+  xss: "<script>"
+  file: "a b&c%%d"
+http:
+  - method: GET
+    path:
+      - "%s"
+    matchers:
+      - type: status
+        status:
+          - 200
+`
+
+func TestPathVariableEncoding(t *testing.T) {
+	var mu sync.Mutex
+	var sent []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		sent = append(sent, r.RequestURI)
+		mu.Unlock()
+	}))
+	t.Cleanup(srv.Close)
+
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{name: "escaped once", path: "{{BaseURL}}/search/{{xss}}", want: "/search/%3Cscript%3E"},
+		{name: "special characters", path: "/files/{{file}}", want: "/files/a%20b&c%25d"},
+		{name: "query kept", path: "/search/{{xss}}?page=1&sort=asc", want: "/search/%3Cscript%3E?page=1&sort=asc"},
+		{name: "raw", path: "{{BaseURL}}/search/{{raw:xss}}", want: srv.URL + "/search/<script>"},
+		{name: "raw with query", path: "/search/{{raw:xss}}?page=1", want: srv.URL + "/search/<script>?page=1"},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mu.Lock()
+			sent = nil
+			mu.Unlock()
+			tmpl := loadTestTemplate(t, fmt.Sprintf(pathEncodingTemplate, i, tt.path))
+			if !runRequests(t, srv.URL, tmpl, testSettings()) {
+				t.Fatal("request did not match")
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if len(sent) != 1 || sent[0] != tt.want {
+				t.Errorf("request URI = %q, want %q", sent, tt.want)
+			}
+		})
+	}
+}
+
+const pathPayloadTemplate = `id: path-payload
+info:
+  name: Path payload
+  author: test
+  severity: info
+http:
+  - method: GET
+    path:
+      - "{{BaseURL}}/static/{{file}}"
+    payloads:
+      file:
+        - "../../etc/passwd"
+        - "admin/config.php"
+        - "a b/<c>"
+    matchers:
+      - type: status
+        status:
+          - 200
+`
+
+func TestPathPayloadSlashesKept(t *testing.T) {
+	rec, srv := newRequestRecorder(t)
+	tmpl := loadTestTemplate(t, pathPayloadTemplate)
+	runRequests(t, srv.URL, tmpl, testSettings())
+
+	want := "/static/../../etc/passwd,/static/admin/config.php,/static/a%20b/%3Cc%3E"
+	if got := strings.Join(rec.requests(), ","); got != want {
+		t.Errorf("request URIs = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,47 @@
+// package templates - entropy based secret detection
+package templates
+
+import (
+	"math"
+	"strings"
+)
+
+// defaultMinTokenLength is the shortest token checked by the entropy matcher when none is configured
+const defaultMinTokenLength = 20
+
+// shannonEntropy returns the Shannon entropy of s in bits per character
+func shannonEntropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	counts := make(map[rune]int)
+	total := 0
+	for _, r := range s {
+		counts[r]++
+		total++
+	}
+	var entropy float64
+	for _, c := range counts {
+		p := float64(c) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// matchEntropy reports whether any whitespace-delimited token of body, long enough to be a secret,
+// has an entropy within [minEntropy, maxEntropy]. A zero maxEntropy means no upper bound
+func matchEntropy(body []byte, minEntropy, maxEntropy float64, minTokenLength int) bool {
+	if minTokenLength <= 0 {
+		minTokenLength = defaultMinTokenLength
+	}
+	for _, token := range strings.Fields(string(body)) {
+		if len(token) < minTokenLength {
+			continue
+		}
+		e := shannonEntropy(token)
+		if e >= minEntropy && (maxEntropy == 0 || e <= maxEntropy) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+package templates
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"math"
+	"strings"
+	"testing"
+)
+
+const plainSentence = "the quick brown fox jumps over the lazy dog while the farmer sleeps"
+
+func TestShannonEntropy(t *testing.T) {
+	tests := []struct {
+		in   string
+		want float64
+	}{
+		{in: "", want: 0},
+		{in: "aaaa", want: 0},
+		{in: "abab", want: 1},
+		{in: "0123456789abcdef", want: 4},
+	}
+	for _, tt := range tests {
+		if got := shannonEntropy(tt.in); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("shannonEntropy(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	if e := shannonEntropy(hex.EncodeToString(key)); e < 3.5 || e > 4 {
+		t.Errorf("entropy of a random hex key = %v, want about 3.9", e)
+	}
+	for _, word := range strings.Fields(plainSentence) {
+		if e := shannonEntropy(word); e >= 3 {
+			t.Errorf("entropy of %q = %v, want below 3", word, e)
+		}
+	}
+}
+
+func TestEntropyMatcher(t *testing.T) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	secret := "aws_secret_access_key = " + hex.EncodeToString(key)
+
+	tests := []struct {
+		name    string
+		body    string
+		matcher Matcher
+		want    bool
+	}{
+		{name: "hex key", body: secret, matcher: Matcher{Type: "entropy", EntropyMin: 3.5}, want: true},
+		{name: "hex key above max", body: secret, matcher: Matcher{Type: "entropy", EntropyMin: 2, EntropyMax: 3}, want: false},
+		{name: "plain sentence", body: plainSentence, matcher: Matcher{Type: "entropy", EntropyMin: 3}, want: false},
+		{name: "short words checked", body: plainSentence, matcher: Matcher{Type: "entropy", EntropyMin: 2, MinTokenLength: 5}, want: true},
+		{name: "long repetitive token", body: strings.Repeat("ab", 20), matcher: Matcher{Type: "entropy", EntropyMin: 3}, want: false},
+		{name: "key shorter than min length", body: secret, matcher: Matcher{Type: "entropy", EntropyMin: 3.5, MinTokenLength: 65}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := checkSingleMatcher(tt.matcher, MatchContext{Body: []byte(tt.body)}); got != tt.want {
+				t.Errorf("entropy matcher = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
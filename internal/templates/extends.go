@@ -0,0 +1,101 @@
+// package templates - inheritance of matchers and variables from base templates
+package templates
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// extendTemplate loads the base named by tmpl.Extends, relative to the template directory, and merges it into tmpl.
+// The base must stay inside the template directory. seen detects circular inheritance, a non-nil verify checks
+// the signature of the base before it is loaded
+func extendTemplate(tmpl *Template, path string, seen map[string]bool, verify func(path string) error) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	seen[abs] = true
+
+	if !filepath.IsLocal(tmpl.Extends) {
+		return fmt.Errorf("template %s: base template %s is outside the template directory", tmpl.ID, tmpl.Extends)
+	}
+	basePath := filepath.Join(filepath.Dir(path), tmpl.Extends)
+	baseAbs, err := filepath.Abs(basePath)
+	if err != nil {
+		return err
+	}
+	if seen[baseAbs] {
+		return fmt.Errorf("template %s: circular extends of %s", tmpl.ID, tmpl.Extends)
+	}
+	if verify != nil {
+		if err := verify(basePath); err != nil {
+			return fmt.Errorf("template %s: base template: %w", tmpl.ID, err)
+		}
+	}
+
+	base, err := loadTemplate(basePath, seen, verify)
+	if err != nil {
+		return fmt.Errorf("template %s: failed to load base template: %w", tmpl.ID, err)
+	}
+	mergeBaseTemplate(tmpl, base)
+	return nil
+}
+
+// mergeBaseTemplate fills tmpl from base: variables and info fields of tmpl override the base ones, and base
+// matchers are prepended to the matchers of the request at the same position. A single base request passes its
+// matchers to every request, and a template without requests takes the base requests
+func mergeBaseTemplate(tmpl, base *Template) {
+	vars := copyVariables(base.Variables)
+	for k, v := range tmpl.Variables {
+		vars[k] = v
+	}
+	tmpl.Variables = vars
+
+	mergeInfo(&tmpl.Info, base.Info)
+
+	if len(tmpl.Requests) == 0 {
+		tmpl.Requests = base.Requests
+		return
+	}
+	if len(base.Requests) == 0 {
+		return
+	}
+	for i, req := range tmpl.Requests {
+		baseReq := base.Requests[0]
+		if len(base.Requests) > 1 {
+			if i >= len(base.Requests) {
+				continue
+			}
+			baseReq = base.Requests[i]
+		}
+		req.Matchers = append(append([]Matcher{}, baseReq.Matchers...), req.Matchers...)
+	}
+}
+
+// mergeInfo copies the base info fields that are not set in info
+func mergeInfo(info *Info, base Info) {
+	if info.Name == "" {
+		info.Name = base.Name
+	}
+	if info.Author == "" {
+		info.Author = base.Author
+	}
+	if info.Severity == "" {
+		info.Severity = base.Severity
+	}
+	if info.Description == "" {
+		info.Description = base.Description
+	}
+	if len(info.Tags) == 0 {
+		info.Tags = base.Tags
+	}
+	if info.CVSSScore == 0 {
+		info.CVSSScore = base.CVSSScore
+	}
+	if info.CVEID == "" {
+		info.CVEID = base.CVEID
+	}
+	if info.MinToolVersion == "" {
+		info.MinToolVersion = base.MinToolVersion
+	}
+}
@@ -0,0 +1,121 @@
+package templates
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const baseCredsTemplate = `id: default-creds-base
+info:
+  name: Default credentials
+  author: base-author
+  severity: high
+  tags: default-login
+variables:
+  username: admin
+  password: admin
+http:
+  - method: POST
+    path:
+      - "{{BaseURL}}/login"
+    matchers:
+      - type: status
+        status: [200]
+      - type: word
+        words: ["Welcome"]
+`
+
+// matcherTypes lists the types of the matchers in order
+func matcherTypes(matchers []Matcher) string {
+	types := make([]string, 0, len(matchers))
+	for _, m := range matchers {
+		types = append(types, m.Type)
+	}
+	return strings.Join(types, ",")
+}
+
+func TestExtends(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "base/default-creds.yaml", baseCredsTemplate)
+	children := map[string]string{
+		"tomcat": `id: tomcat-default-creds
+extends: base/default-creds.yaml
+info:
+  name: Tomcat default credentials
+variables:
+  password: tomcat
+http:
+  - method: POST
+    path:
+      - "{{BaseURL}}/manager/html"
+    matchers:
+      - type: word
+        words: ["Tomcat Web Application Manager"]
+`,
+		"jenkins": `id: jenkins-default-creds
+extends: base/default-creds.yaml
+info:
+  name: Jenkins default credentials
+  severity: critical
+http:
+  - method: POST
+    path:
+      - "{{BaseURL}}/j_spring_security_check"
+    matchers:
+      - type: regex
+        regex: ["Dashboard \\[Jenkins\\]"]
+`,
+	}
+	wantPath := map[string]string{"tomcat": "{{BaseURL}}/manager/html", "jenkins": "{{BaseURL}}/j_spring_security_check"}
+	wantMatchers := map[string]string{"tomcat": "status,word,word", "jenkins": "status,word,regex"}
+	wantSeverity := map[string]string{"tomcat": "high", "jenkins": "critical"}
+	wantPassword := map[string]string{"tomcat": "tomcat", "jenkins": "admin"}
+
+	for name, content := range children {
+		t.Run(name, func(t *testing.T) {
+			tmpl, err := LoadTemplate(writeTestFile(t, dir, name+".yaml", content))
+			if err != nil {
+				t.Fatalf("LoadTemplate: %v", err)
+			}
+			if len(tmpl.Requests) != 1 {
+				t.Fatalf("%d requests, want the child request only", len(tmpl.Requests))
+			}
+			req := tmpl.Requests[0]
+			if strings.Join(req.Path, ",") != wantPath[name] {
+				t.Errorf("path = %v, want %s", req.Path, wantPath[name])
+			}
+			if got := matcherTypes(req.Matchers); got != wantMatchers[name] {
+				t.Errorf("matchers = %s, want base then child matchers %s", got, wantMatchers[name])
+			}
+			if tmpl.Info.Author != "base-author" || tmpl.Info.Severity != wantSeverity[name] || !strings.Contains(tmpl.Info.Name, "default credentials") {
+				t.Errorf("info = %+v", tmpl.Info)
+			}
+			if tmpl.Variables["username"] != "admin" || tmpl.Variables["password"] != wantPassword[name] {
+				t.Errorf("variables = %v", tmpl.Variables)
+			}
+		})
+	}
+
+	base, err := LoadTemplate(filepath.Join(dir, "base", "default-creds.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := matcherTypes(base.Requests[0].Matchers); got != "status,word" {
+		t.Errorf("base matchers changed by the children: %s", got)
+	}
+}
+
+func TestExtendsCircular(t *testing.T) {
+	dir := t.TempDir()
+	a := writeTestFile(t, dir, "a.yaml", strings.Replace(baseCredsTemplate, "id: default-creds-base\n", "id: a\nextends: b.yaml\n", 1))
+	writeTestFile(t, dir, "b.yaml", strings.Replace(baseCredsTemplate, "id: default-creds-base\n", "id: b\nextends: a.yaml\n", 1))
+	if _, err := LoadTemplate(a); err == nil || !strings.Contains(err.Error(), "circular extends") {
+		t.Errorf("LoadTemplate of A extends B extends A = %v, want a circular extends error", err)
+	}
+
+	self := writeTestFile(t, dir, "self.yaml", strings.Replace(baseCredsTemplate, "id: default-creds-base\n", "id: self\nextends: self.yaml\n", 1))
+	if _, err := LoadTemplate(self); err == nil || !strings.Contains(err.Error(), "circular extends") {
+		t.Errorf("LoadTemplate of a template extending itself = %v, want a circular extends error", err)
+	}
+}
@@ -0,0 +1,357 @@
+// package templates - extraction of values from responses
+package templates
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/antchfx/htmlquery"
+	"github.com/antchfx/xmlquery"
+)
+
+// processExtractors runs the extractors against the response and returns the extracted values by name:
+// a []string for list extractors and a string for the others. Unnamed extractors are stored as extractor_<index>
+func processExtractors(extractors []Extractor, ctx MatchContext) map[string]interface{} {
+	values := make(map[string]interface{})
+	for i, e := range extractors {
+		name := e.Name
+		if name == "" {
+			name = fmt.Sprintf("extractor_%d", i)
+		}
+
+		if listExtractors[e.Type] {
+			list, ok := runListExtractor(e, ctx)
+			if !ok {
+				continue
+			}
+			var decoded []string
+			for _, item := range list {
+				if item, ok := decodeExtracted(item, e); ok {
+					decoded = append(decoded, item)
+				}
+			}
+			if len(decoded) > 0 {
+				values[name] = decoded
+			}
+			continue
+		}
+
+		value, ok := runExtractor(e, ctx)
+		if !ok {
+			continue
+		}
+		if value, ok = decodeExtracted(value, e); ok {
+			values[name] = value
+		}
+	}
+	return values
+}
+
+// decodeExtracted applies the base64 decoding and the encoding of the extractor to an extracted value
+func decodeExtracted(value string, e Extractor) (string, bool) {
+	if e.Base64 {
+		if decoded, err := base64.StdEncoding.DecodeString(value); err == nil {
+			value = string(decoded)
+		}
+	}
+	if e.Encoding != "" {
+		encoded, err := applyEncoding(value, e.Encoding)
+		if err != nil {
+			return "", false
+		}
+		value = encoded
+	}
+	return value, true
+}
+
+// extractedString returns an extracted value as it is reported in findings, lists are joined with commas
+func extractedString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []string:
+		return strings.Join(v, ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// listExtractors are the extractor types whose values are kept as a list in the template variables
+var listExtractors = map[string]bool{"form-action": true, "html-links": true, "js-paths": true}
+
+// runListExtractor returns the values extracted by a list extractor
+func runListExtractor(e Extractor, ctx MatchContext) ([]string, bool) {
+	switch e.Type {
+	case "form-action":
+		if ctx.Resp == nil || ctx.Body == nil {
+			return nil, false
+		}
+		actions, err := ExtractFormActions(ctx.Body, responseURL(ctx.Resp))
+		if err != nil || len(actions) == 0 {
+			return nil, false
+		}
+		return actions, true
+	case "html-links":
+		if ctx.Resp == nil || ctx.Body == nil {
+			return nil, false
+		}
+		links, err := ExtractHTMLLinks(ctx.Body, responseURL(ctx.Resp))
+		if err != nil {
+			return nil, false
+		}
+		links = filterByRegex(links, e.Regex)
+		if len(links) == 0 {
+			return nil, false
+		}
+		return links, true
+	case "js-paths":
+		if ctx.Headless == nil || len(ctx.Headless.DiscoveredPaths) == 0 {
+			return nil, false
+		}
+		return ctx.Headless.DiscoveredPaths, true
+	default:
+		return nil, false
+	}
+}
+
+// runExtractor returns the first value extracted by e from the response
+func runExtractor(e Extractor, ctx MatchContext) (string, bool) {
+	switch e.Type {
+	case "regex":
+		if ctx.Resp == nil {
+			return "", false
+		}
+		return extractRegex(partText(ctx.Resp, ctx.Body, e.Part), e)
+	case "json":
+		if ctx.Body == nil {
+			return "", false
+		}
+		return extractJSON(ctx.Body, e.JSONPath)
+	case "xpath":
+		if ctx.Body == nil {
+			return "", false
+		}
+		return extractXPath(ctx.Body, e.XPath)
+	case "xmlpath":
+		if ctx.Body == nil {
+			return "", false
+		}
+		return extractXMLPath(ctx.Body, e.XPath)
+	case "html-comment":
+		if ctx.Body == nil {
+			return "", false
+		}
+		comments, err := ExtractHTMLComments(ctx.Body)
+		if err != nil {
+			return "", false
+		}
+		comments = filterByRegex(comments, e.Regex)
+		if e.Group != "" || e.NamedGroup != "" {
+			comments = extractGroups(comments, e)
+		}
+		if len(comments) == 0 {
+			return "", false
+		}
+		return strings.Join(comments, ","), true
+	case "redirect-chain":
+		return extractRedirectHop(ctx.RedirectChain, e.Index)
+	case "jwt":
+		if ctx.Resp == nil {
+			return "", false
+		}
+		return extractJWTClaim(partText(ctx.Resp, ctx.Body, e.Part), e.ClaimPath)
+	case "ssl":
+		return extractCertField(ctx.Resp, e.Field)
+	default:
+		return "", false
+	}
+}
+
+// extractRedirectHop returns the redirect chain joined with commas, or the hop at the 1-based index.
+// A negative index counts from the last hop
+func extractRedirectHop(chain []string, index int) (string, bool) {
+	if len(chain) == 0 {
+		return "", false
+	}
+	switch {
+	case index == 0:
+		return strings.Join(chain, ","), true
+	case index < 0:
+		index += len(chain)
+	default:
+		index--
+	}
+	if index < 0 || index >= len(chain) {
+		return "", false
+	}
+	return chain[index], true
+}
+
+// responseURL returns the URL the response was received from
+func responseURL(resp *http.Response) string {
+	if resp.Request == nil || resp.Request.URL == nil {
+		return ""
+	}
+	return resp.Request.URL.String()
+}
+
+// partText returns the part of the response the extractor works on: body (default), header or all
+func partText(resp *http.Response, body []byte, part string) string {
+	var headers []string
+	for k, v := range resp.Header {
+		headers = append(headers, k+": "+strings.Join(v, ","))
+	}
+	switch part {
+	case "header":
+		return strings.Join(headers, "\n")
+	case "all":
+		return string(body) + "\n" + strings.Join(headers, "\n")
+	default:
+		return string(body)
+	}
+}
+
+// extractRegex returns the requested group of the first matching regex of the extractor, group 0 is the whole
+// match. A named group takes precedence over the numbered one
+func extractRegex(text string, e Extractor) (string, bool) {
+	idx := 0
+	if e.Group != "" {
+		n, err := strconv.Atoi(e.Group)
+		if err != nil || n < 0 {
+			return "", false
+		}
+		idx = n
+	}
+	flags := regexFlags(e)
+	for _, pattern := range e.Regex {
+		re, err := getCompiledRegex(flags + pattern)
+		if err != nil {
+			continue
+		}
+		groupIdx := idx
+		if e.NamedGroup != "" {
+			if groupIdx = re.SubexpIndex(e.NamedGroup); groupIdx < 0 {
+				continue
+			}
+		}
+		if m := re.FindStringSubmatch(text); m != nil && groupIdx < len(m) {
+			return m[groupIdx], true
+		}
+	}
+	return "", false
+}
+
+// regexFlags returns the inline flags prepended to the patterns of the extractor
+func regexFlags(e Extractor) string {
+	var flags string
+	if e.NoCase {
+		flags += "i"
+	}
+	if e.Dotall {
+		flags += "s"
+	}
+	if e.Multiline {
+		flags += "m"
+	}
+	if flags == "" {
+		return ""
+	}
+	return "(?" + flags + ")"
+}
+
+// extractGroups replaces every value with the requested group of the first regex of the extractor matching it,
+// values without the group are dropped
+func extractGroups(values []string, e Extractor) []string {
+	var res []string
+	for _, v := range values {
+		if g, ok := extractRegex(v, e); ok {
+			res = append(res, g)
+		}
+	}
+	return res
+}
+
+// filterByRegex keeps the values matching any of the patterns, all values are kept when there are no patterns
+func filterByRegex(values []string, patterns []string) []string {
+	if len(patterns) == 0 {
+		return values
+	}
+	var res []*regexp.Regexp
+	for _, pattern := range patterns {
+		if re, err := getCompiledRegex(pattern); err == nil {
+			res = append(res, re)
+		}
+	}
+	var filtered []string
+	for _, v := range values {
+		for _, re := range res {
+			if re.MatchString(v) {
+				filtered = append(filtered, v)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+// extractJSON returns the value at the dotted JSON path, objects and arrays are returned as JSON
+func extractJSON(body []byte, path string) (string, bool) {
+	val := getJSONValue(body, path)
+	switch v := val.(type) {
+	case nil:
+		return "", false
+	case string:
+		return v, true
+	case map[string]any, []any:
+		bs, err := json.Marshal(v)
+		if err != nil {
+			return "", false
+		}
+		return string(bs), true
+	default:
+		return fmt.Sprint(v), true
+	}
+}
+
+// extractXPath returns the text of the first node matched by any of the expressions
+func extractXPath(body []byte, exprs []string) (string, bool) {
+	doc, err := htmlquery.Parse(bytes.NewReader(body))
+	if err != nil {
+		return "", false
+	}
+	for _, expr := range exprs {
+		compiled, err := getCompiledXPath(expr)
+		if err != nil {
+			continue
+		}
+		if node := htmlquery.QuerySelector(doc, compiled); node != nil {
+			return strings.TrimSpace(htmlquery.InnerText(node)), true
+		}
+	}
+	return "", false
+}
+
+// extractXMLPath returns the text of the first XML node matched by any of the expressions.
+// Unlike extractXPath it keeps namespace prefixes and CDATA sections
+func extractXMLPath(body []byte, exprs []string) (string, bool) {
+	doc, err := xmlquery.Parse(bytes.NewReader(body))
+	if err != nil {
+		return "", false
+	}
+	for _, expr := range exprs {
+		compiled, err := getCompiledXPath(expr)
+		if err != nil {
+			continue
+		}
+		if node := xmlquery.QuerySelector(doc, compiled); node != nil {
+			return strings.TrimSpace(node.InnerText()), true
+		}
+	}
+	return "", false
+}
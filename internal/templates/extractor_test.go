@@ -0,0 +1,332 @@
+package templates
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMatchTemplateReturnsExtractedValues(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/version":
+			io.WriteString(w, "app version: 4.2.1")
+		case "/release/4.2.1":
+			io.WriteString(w, "release notes")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name     string
+		template string
+		chained  bool
+	}{
+		{name: "single request", template: `id: version-single
+info:
+  name: Version
+  author: test
+  severity: info
+http:
+  - path:
+      - "{{BaseURL}}/version"
+    matchers:
+      - type: status
+        status: [200]
+    extractors:
+      - type: regex
+        name: version
+        group: 1
+        regex:
+          - 'version: ([0-9.]+)'
+`},
+		{name: "chained requests", chained: true, template: `id: version-chained
+info:
+  name: Version chained
+  author: test
+  severity: info
+http:
+  - path:
+      - "{{BaseURL}}/version"
+    matchers:
+      - type: status
+        status: [500]
+    extractors:
+      - type: regex
+        name: version
+        group: 1
+        regex:
+          - 'version: ([0-9.]+)'
+  - path:
+      - "{{BaseURL}}/release/{{version}}"
+    matchers:
+      - type: status
+        status: [200]
+`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl := loadTestTemplate(t, tt.template)
+			if want := map[bool]int{false: 1, true: 2}[tt.chained]; len(tmpl.Requests) != want {
+				t.Fatalf("template has %d requests, want %d", len(tmpl.Requests), want)
+			}
+			matched, extracted, err := MatchTemplate(context.Background(), srv.URL, "", tmpl, testSettings(), testLogger())
+			if err != nil {
+				t.Fatalf("MatchTemplate: %v", err)
+			}
+			if !matched {
+				t.Fatal("template didn't match")
+			}
+			if extracted["version"] != "4.2.1" {
+				t.Errorf("extracted = %v, want version 4.2.1", extracted)
+			}
+		})
+	}
+}
+
+func TestMatchAndExtract(t *testing.T) {
+	req := &Request{
+		Matchers:   []Matcher{{Type: "word", Words: []string{"token"}}},
+		Extractors: []Extractor{{Type: "regex", Name: "token", Group: "1", Regex: []string{`token=(\w+)`}}},
+	}
+	resp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}}
+	for _, chained := range []bool{false, true} {
+		matched, values := matchAndExtract(req, MatchContext{Resp: resp, Body: []byte("token=abc123")}, chained)
+		if !matched || values["token"] != "abc123" {
+			t.Errorf("chained %v: matchAndExtract() = %v, %v", chained, matched, values)
+		}
+		matched, values = matchAndExtract(req, MatchContext{Resp: resp, Body: []byte("nothing")}, chained)
+		if matched || len(values) != 0 {
+			t.Errorf("chained %v: no match returned %v, %v", chained, matched, values)
+		}
+	}
+
+	// chained templates keep the values of responses that didn't match for the next request
+	req.Matchers = []Matcher{{Type: "word", Words: []string{"missing"}}}
+	if matched, values := matchAndExtract(req, MatchContext{Resp: resp, Body: []byte("token=abc123")}, true); matched || values["token"] != "abc123" {
+		t.Errorf("chained extraction without a match = %v, %v", matched, values)
+	}
+}
+
+func TestMatchTemplateExtractorKeys(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"version":"1.0","token":"t0k3n"}`)
+	}))
+	defer srv.Close()
+
+	tmpl := loadTestTemplate(t, `id: extractor-keys
+info:
+  name: Extractor keys
+  author: test
+  severity: info
+http:
+  - path:
+      - "{{BaseURL}}/api"
+    matchers:
+      - type: status
+        status: [200]
+    extractors:
+      - type: regex
+        name: api_version
+        group: 1
+        regex:
+          - '"version":"([^"]+)"'
+      - type: regex
+        group: 1
+        regex:
+          - '"token":"([^"]+)"'
+`)
+	matched, extracted, err := MatchTemplate(context.Background(), srv.URL, "", tmpl, testSettings(), testLogger())
+	if err != nil || !matched {
+		t.Fatalf("MatchTemplate = %v, %v", matched, err)
+	}
+	if extracted["api_version"] != "1.0" || extracted["extractor_1"] != "t0k3n" || len(extracted) != 2 {
+		t.Errorf("extracted = %v, want api_version and extractor_1", extracted)
+	}
+}
+
+const redirectChainTemplate = `id: redirect-chain
+info:
+  name: Redirect chain
+  author: test
+  severity: info
+http:
+  - path:
+      - "{{BaseURL}}/start"
+    matchers:
+      - type: status
+        status: [200]
+    extractors:
+      - type: redirect-chain
+        name: chain
+      - type: redirect-chain
+        name: first
+        index: 1
+      - type: redirect-chain
+        name: last
+        index: -1
+      - type: redirect-chain
+        name: missing
+        index: 4
+`
+
+func TestRedirectChainExtractor(t *testing.T) {
+	hops := map[string]string{"/start": "/hop1", "/hop1": "hop2", "/hop2": "/final?done=1"}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if next, ok := hops[r.URL.Path]; ok {
+			http.Redirect(w, r, next, http.StatusFound)
+			return
+		}
+		if r.URL.Path != "/final" {
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	tmpl := loadTestTemplate(t, redirectChainTemplate)
+	matched, extracted, err := matchTemplate(context.Background(), srv.URL, "", tmpl, nil, testSettings(), testLogger())
+	if err != nil || !matched {
+		t.Fatalf("matchTemplate = %v, %v, want a match", matched, err)
+	}
+	want := map[string]string{
+		"chain": srv.URL + "/hop1," + srv.URL + "/hop2," + srv.URL + "/final?done=1",
+		"first": srv.URL + "/hop1",
+		"last":  srv.URL + "/final?done=1",
+	}
+	for name, value := range want {
+		if extracted[name] != value {
+			t.Errorf("%s = %q, want %q", name, extracted[name], value)
+		}
+	}
+	if v, ok := extracted["missing"]; ok {
+		t.Errorf("hop past the end of the chain extracted: %q", v)
+	}
+}
+
+func TestExtractRedirectHop(t *testing.T) {
+	chain := []string{"http://a.test/1", "http://a.test/2", "http://a.test/3"}
+	for _, tt := range []struct {
+		index int
+		want  string
+		ok    bool
+	}{
+		{index: 0, want: "http://a.test/1,http://a.test/2,http://a.test/3", ok: true},
+		{index: 2, want: "http://a.test/2", ok: true},
+		{index: -3, want: "http://a.test/1", ok: true},
+		{index: -4},
+		{index: 4},
+	} {
+		if got, ok := extractRedirectHop(chain, tt.index); got != tt.want || ok != tt.ok {
+			t.Errorf("extractRedirectHop(%d) = %q, %v, want %q, %v", tt.index, got, ok, tt.want, tt.ok)
+		}
+	}
+	if _, ok := extractRedirectHop(nil, 0); ok {
+		t.Error("hop extracted from a response without redirects")
+	}
+}
+
+const soapFault = `<?xml version="1.0" encoding="UTF-8"?>
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+  <soap:Body>
+    <soap:Fault>
+      <faultcode>soap:Client</faultcode>
+      <faultstring>Access Denied</faultstring>
+      <detail><![CDATA[user <guest> lacks role admin]]></detail>
+    </soap:Fault>
+  </soap:Body>
+</soap:Envelope>`
+
+func TestXMLPathExtractor(t *testing.T) {
+	ctx := MatchContext{Resp: &http.Response{StatusCode: http.StatusInternalServerError, Header: http.Header{}}, Body: []byte(soapFault)}
+	tests := []struct {
+		xpath []string
+		want  string
+	}{
+		{xpath: []string{"//soap:Fault/faultstring"}, want: "Access Denied"},
+		{xpath: []string{"//soap:Fault/detail"}, want: "user <guest> lacks role admin"},
+		{xpath: []string{"//soap:Fault/missing", "//faultcode"}, want: "soap:Client"},
+		{xpath: []string{"//soap:Fault/missing"}},
+	}
+	for _, tt := range tests {
+		values := processExtractors([]Extractor{{Type: "xmlpath", Name: "fault", XPath: tt.xpath}}, ctx)
+		if got, _ := values["fault"].(string); got != tt.want {
+			t.Errorf("xmlpath %v = %q, want %q", tt.xpath, got, tt.want)
+		}
+	}
+
+	if values := processExtractors([]Extractor{{Type: "xmlpath", Name: "fault", XPath: []string{"//faultstring"}}},
+		MatchContext{Body: []byte("<html><body>not xml")}); len(values) != 0 {
+		t.Errorf("xmlpath on a malformed body = %v, want no value", values)
+	}
+}
+
+const namedGroupTemplate = `id: named-group
+info:
+  name: Named group
+  author: test
+  severity: info
+http:
+  - method: GET
+    path:
+      - "{{BaseURL}}/status"
+    matchers:
+      - type: status
+        status:
+          - 200
+    extractors:
+      - type: regex
+        name: version
+        named-group: version
+        regex:
+          - 'server (?P<version>v\d+\.\d+)'
+      - type: regex
+        name: build
+        multiline: true
+        group: "1"
+        regex:
+          - '^build: (\S+)$'
+      - type: regex
+        name: block
+        dotall: true
+        group: "1"
+        regex:
+          - '<pre>(.*?)</pre>'
+      - type: regex
+        name: unanchored
+        group: "1"
+        regex:
+          - '^build: (\S+)$'
+      - type: regex
+        name: unknown-group
+        named-group: release
+        regex:
+          - 'server (?P<version>v\d+\.\d+)'
+`
+
+func TestRegexNamedGroupAndFlags(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "status: ok\nserver v2.14 (stable)\nbuild: 4f2a9c1\n<pre>line one\nline two</pre>\n")
+	}))
+	t.Cleanup(srv.Close)
+
+	tmpl := loadTestTemplate(t, namedGroupTemplate)
+	matched, extracted, err := matchTemplate(context.Background(), srv.URL, "", tmpl, nil, testSettings(), testLogger())
+	if err != nil || !matched {
+		t.Fatalf("matchTemplate = %v, %v, want a match", matched, err)
+	}
+	want := map[string]string{"version": "v2.14", "build": "4f2a9c1", "block": "line one\nline two"}
+	for name, value := range want {
+		if extracted[name] != value {
+			t.Errorf("%s = %q, want %q", name, extracted[name], value)
+		}
+	}
+	for _, name := range []string{"unanchored", "unknown-group"} {
+		if v, ok := extracted[name]; ok {
+			t.Errorf("%s extracted %q, want no value", name, v)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+// package templates - favicon hash fingerprinting
+package templates
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/bits"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// faviconHashes caches the favicon hash of every scanned base URL
+var faviconHashes sync.Map
+
+// FetchFaviconHash downloads /favicon.ico of the target and returns its Shodan compatible MurmurHash3
+func FetchFaviconHash(ctx context.Context, baseURL string, client *http.Client) (int32, error) {
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return 0, err
+	}
+	faviconURL := buildFullURL(&url.URL{Scheme: base.Scheme, Host: base.Host}, "/favicon.ico")
+	if cached, ok := faviconHashes.Load(faviconURL); ok {
+		return cached.(int32), nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, faviconURL, nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("favicon request returned status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, err
+	}
+
+	hash := faviconHash(data)
+	faviconHashes.Store(faviconURL, hash)
+	return hash, nil
+}
+
+// faviconHash encodes data as MIME base64 with a newline every 76 characters, as Shodan does,
+// and returns the signed 32-bit MurmurHash3 of the encoding
+func faviconHash(data []byte) int32 {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	var sb strings.Builder
+	for len(encoded) > 76 {
+		sb.WriteString(encoded[:76])
+		sb.WriteByte('\n')
+		encoded = encoded[76:]
+	}
+	sb.WriteString(encoded)
+	sb.WriteByte('\n')
+	return int32(murmur3(sb.String(), 0))
+}
+
+// murmur3 computes the 32-bit MurmurHash3 of s
+func murmur3(s string, seed uint32) uint32 {
+	const (
+		c1 = 0xcc9e2d51
+		c2 = 0x1b873593
+	)
+	data := []byte(s)
+	h := seed
+	n := len(data) / 4
+	for i := 0; i < n; i++ {
+		k := binary.LittleEndian.Uint32(data[i*4:])
+		k *= c1
+		k = bits.RotateLeft32(k, 15)
+		k *= c2
+		h ^= k
+		h = bits.RotateLeft32(h, 13)
+		h = h*5 + 0xe6546b64
+	}
+
+	var k uint32
+	tail := data[n*4:]
+	switch len(tail) {
+	case 3:
+		k ^= uint32(tail[2]) << 16
+		fallthrough
+	case 2:
+		k ^= uint32(tail[1]) << 8
+		fallthrough
+	case 1:
+		k ^= uint32(tail[0])
+		k *= c1
+		k = bits.RotateLeft32(k, 15)
+		k *= c2
+		h ^= k
+	}
+
+	h ^= uint32(len(data))
+	h ^= h >> 16
+	h *= 0x85ebca6b
+	h ^= h >> 13
+	h *= 0xc2b2ae35
+	h ^= h >> 16
+	return h
+}
+
+// hasFaviconMatcher reports whether any matcher needs the favicon hash
+func hasFaviconMatcher(matchers []Matcher) bool {
+	for _, m := range matchers {
+		if m.Type == "favicon" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+package templates
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+// faviconFixture is a 768 byte icon whose base64 encoding spans several 76 character lines
+var faviconFixture = func() []byte {
+	data := make([]byte, 0, 768)
+	for i := 0; i < 3; i++ {
+		for b := 0; b < 256; b++ {
+			data = append(data, byte(b))
+		}
+	}
+	return data
+}()
+
+// faviconFixtureHash is mmh3.hash(codecs.encode(faviconFixture, "base64")), the value Shodan reports
+const faviconFixtureHash int32 = 1836528006
+
+func TestMurmur3(t *testing.T) {
+	tests := []struct {
+		in   string
+		want uint32
+	}{
+		{in: "", want: 0},
+		{in: "hello", want: 0x248bfa47},
+		{in: "The quick brown fox jumps over the lazy dog", want: 0x2e4ff723},
+	}
+	for _, tt := range tests {
+		if got := murmur3(tt.in, 0); got != tt.want {
+			t.Errorf("murmur3(%q) = %#x, want %#x", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFaviconHash(t *testing.T) {
+	if got := faviconHash(faviconFixture); got != faviconFixtureHash {
+		t.Errorf("faviconHash(fixture) = %d, want %d", got, faviconFixtureHash)
+	}
+	if got, want := faviconHash([]byte{0, 0, 1, 0, 1, 0}), int32(-584014218); got != want {
+		t.Errorf("faviconHash(short icon) = %d, want %d", got, want)
+	}
+}
+
+// newFaviconServer serves the fixture as /favicon.ico and counts how often it was fetched
+func newFaviconServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var fetches atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/favicon.ico" {
+			fetches.Add(1)
+			w.Write(faviconFixture)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &fetches
+}
+
+func TestFetchFaviconHash(t *testing.T) {
+	srv, fetches := newFaviconServer(t)
+	for i := 0; i < 2; i++ {
+		hash, err := FetchFaviconHash(context.Background(), srv.URL+"/some/page", srv.Client())
+		if err != nil {
+			t.Fatalf("FetchFaviconHash: %v", err)
+		}
+		if hash != faviconFixtureHash {
+			t.Errorf("FetchFaviconHash = %d, want %d", hash, faviconFixtureHash)
+		}
+	}
+	if n := fetches.Load(); n != 1 {
+		t.Errorf("favicon fetched %d times, want 1", n)
+	}
+
+	missing := httptest.NewServer(http.NotFoundHandler())
+	defer missing.Close()
+	if _, err := FetchFaviconHash(context.Background(), missing.URL, missing.Client()); err == nil {
+		t.Error("FetchFaviconHash succeeded on a missing favicon")
+	}
+}
+
+const faviconTemplate = `id: favicon-%s
+info:
+  name: Favicon fingerprint
+  author: test
+  severity: info
+http:
+  - method: GET
+    path:
+      - "{{BaseURL}}/"
+    matchers:
+      - type: favicon
+        favicon-hash: %d
+`
+
+func TestFaviconMatcher(t *testing.T) {
+	srv, _ := newFaviconServer(t)
+	tests := []struct {
+		name string
+		hash int32
+		want bool
+	}{
+		{name: "match", hash: faviconFixtureHash, want: true},
+		{name: "other", hash: faviconFixtureHash + 1, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl := loadTemplateWithFiles(t, fmt.Sprintf(faviconTemplate, tt.name, tt.hash), nil)
+			if got := runRequests(t, srv.URL, tmpl, DefaultAdvancedSettings()); got != tt.want {
+				t.Errorf("matched = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
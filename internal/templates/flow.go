@@ -0,0 +1,554 @@
+// package templates - flow expressions controlling the order and conditions of template requests
+package templates
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// A flow has the form
+//
+//	flow    = stmt { [";"] stmt }
+//	stmt    = if | expr
+//	if      = "if" "(" expr ")" block [ "else" ( if | block ) ]
+//	block   = "{" [ flow ] "}"
+//	expr    = and { "||" and }
+//	and     = primary { "&&" primary }
+//	primary = request | "foreach" "(" request "," expr ")" | "(" expr ")"
+//	request = protocol "(" index ")" [ "[" "timeout=" duration "]" ]
+//
+// where protocol is http, dns, network, headless, imap, pop3, redis, mysql, memcached, grpc, websocket or graphql
+// and index is the 1-based position of the request among the template requests of that protocol. A sequence evaluates to its last
+// statement, && and || short-circuit.
+// foreach runs the request, splits the first value it extracted into items and evaluates the expression once
+// per item with the item in the {{item}} variable and in the variable of the extractor. A request with a timeout
+// runs with its own deadline, AdvancedSettingsChecker.FlowTimeoutBehavior decides what a timed out request does
+
+// Values of AdvancedSettingsChecker.FlowTimeoutBehavior
+const (
+	// FlowTimeoutSkip treats a timed out flow request as not matched and goes on with the flow
+	FlowTimeoutSkip = "skip"
+	// FlowTimeoutFail stops the flow as not matched
+	FlowTimeoutFail = "fail"
+)
+
+// errFlowStepTimeout stops the flow when a request times out with FlowTimeoutFail
+var errFlowStepTimeout = errors.New("flow request timed out")
+
+// flowNode is a node of a parsed flow
+type flowNode interface {
+	eval(ctx context.Context, r *requestRunner, reqs map[string][]*Request) (bool, error)
+}
+
+// ReqNode runs a single request, within Timeout if it is set
+type ReqNode struct {
+	Protocol string
+	Index    int
+	Timeout  time.Duration
+}
+
+// IfNode runs Then or Else depending on Cond, a false Cond without Else evaluates to false
+type IfNode struct {
+	Cond flowNode
+	Then flowNode
+	Else flowNode
+}
+
+// SeqNode runs the nodes one after another and evaluates to the last one
+type SeqNode struct {
+	Nodes []flowNode
+}
+
+// OrNode evaluates to true at the first true node
+type OrNode struct {
+	Nodes []flowNode
+}
+
+// AndNode evaluates to false at the first false node
+type AndNode struct {
+	Nodes []flowNode
+}
+
+// ForEachNode runs Body once per item extracted by Source and evaluates to true if any run was true
+type ForEachNode struct {
+	Source *ReqNode
+	Body   flowNode
+}
+
+func (n *ReqNode) eval(ctx context.Context, r *requestRunner, reqs map[string][]*Request) (bool, error) {
+	list := reqs[n.Protocol]
+	if n.Index < 1 || n.Index > len(list) {
+		return false, fmt.Errorf("flow references %s(%d), the template has %d %s requests", n.Protocol, n.Index, len(list), n.Protocol)
+	}
+	if n.Timeout <= 0 {
+		return r.run(ctx, list[n.Index-1])
+	}
+
+	stepCtx, cancel := context.WithTimeout(ctx, n.Timeout)
+	defer cancel()
+	matched, err := r.run(stepCtx, list[n.Index-1])
+	// failed requests are mostly logged and reported as not matched, so the deadline is checked on the context
+	if ctx.Err() == nil && errors.Is(stepCtx.Err(), context.DeadlineExceeded) {
+		r.logger.Info("Flow request timed out",
+			slog.String("template_id", r.tmpl.ID),
+			slog.String("request", fmt.Sprintf("%s(%d)", n.Protocol, n.Index)),
+			slog.Duration("timeout", n.Timeout),
+		)
+		if r.advanced.FlowTimeoutBehavior == FlowTimeoutFail {
+			return false, errFlowStepTimeout
+		}
+		return false, nil
+	}
+	return matched, err
+}
+
+func (n *IfNode) eval(ctx context.Context, r *requestRunner, reqs map[string][]*Request) (bool, error) {
+	cond, err := n.Cond.eval(ctx, r, reqs)
+	if err != nil {
+		return false, err
+	}
+	if cond {
+		return n.Then.eval(ctx, r, reqs)
+	}
+	if n.Else == nil {
+		return false, nil
+	}
+	return n.Else.eval(ctx, r, reqs)
+}
+
+func (n *SeqNode) eval(ctx context.Context, r *requestRunner, reqs map[string][]*Request) (bool, error) {
+	var res bool
+	for _, node := range n.Nodes {
+		var err error
+		if res, err = node.eval(ctx, r, reqs); err != nil {
+			return false, err
+		}
+	}
+	return res, nil
+}
+
+func (n *OrNode) eval(ctx context.Context, r *requestRunner, reqs map[string][]*Request) (bool, error) {
+	for _, node := range n.Nodes {
+		ok, err := node.eval(ctx, r, reqs)
+		if err != nil || ok {
+			return ok, err
+		}
+	}
+	return false, nil
+}
+
+func (n *AndNode) eval(ctx context.Context, r *requestRunner, reqs map[string][]*Request) (bool, error) {
+	for _, node := range n.Nodes {
+		ok, err := node.eval(ctx, r, reqs)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (n *ForEachNode) eval(ctx context.Context, r *requestRunner, reqs map[string][]*Request) (bool, error) {
+	if _, err := n.Source.eval(ctx, r, reqs); err != nil {
+		return false, err
+	}
+	name, value, ok := firstExtracted(reqs[n.Source.Protocol][n.Source.Index-1], r.lastExtracted)
+	if !ok {
+		return false, nil
+	}
+	items, isList := value.([]string)
+	if !isList {
+		items = splitFlowItems(extractedString(value))
+	}
+	if limit := r.advanced.MaxFlowIterations; limit > 0 && len(items) > limit {
+		items = items[:limit]
+	}
+
+	defer func() {
+		r.vars[name] = value
+		delete(r.vars, "item")
+	}()
+	var matched bool
+	for _, item := range items {
+		r.vars[name] = item
+		r.vars["item"] = item
+		ok, err := n.Body.eval(ctx, r, reqs)
+		if err != nil {
+			return false, err
+		}
+		matched = matched || ok
+	}
+	return matched, nil
+}
+
+// firstExtracted returns the name and value of the first extractor of req, in template order, that produced a value
+func firstExtracted(req *Request, values map[string]interface{}) (string, interface{}, bool) {
+	for i, e := range req.Extractors {
+		name := e.Name
+		if name == "" {
+			name = fmt.Sprintf("extractor_%d", i)
+		}
+		if v, ok := values[name]; ok {
+			return name, v, true
+		}
+	}
+	return "", nil, false
+}
+
+// splitFlowItems splits an extracted string into loop items: a JSON array gives its elements, anything else
+// is split on commas. The values of list extractors are looped over as they are
+func splitFlowItems(value string) []string {
+	var arr []any
+	if err := json.Unmarshal([]byte(value), &arr); err == nil {
+		items := make([]string, 0, len(arr))
+		for _, v := range arr {
+			if s, ok := v.(string); ok {
+				items = append(items, s)
+			} else {
+				items = append(items, fmt.Sprint(v))
+			}
+		}
+		return items
+	}
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// runFlow parses the flow and runs the template requests it references
+func runFlow(ctx context.Context, flow string, r *requestRunner) (bool, error) {
+	root, err := parseFlow(flow)
+	if err != nil {
+		return false, err
+	}
+	r.inFlow = true
+	matched, err := root.eval(ctx, r, requestsByProtocol(r.tmpl.Requests))
+	if errors.Is(err, errFlowStepTimeout) {
+		return false, nil
+	}
+	return matched, err
+}
+
+// requestsByProtocol groups the requests by the protocol name used in flows
+func requestsByProtocol(requests []*Request) map[string][]*Request {
+	groups := make(map[string][]*Request)
+	for _, req := range requests {
+		protocol := req.Type
+		switch req.Type {
+		case "", "http":
+			protocol = "http"
+		case "dns", "CNAME", "NS", "TXT", "A":
+			protocol = "dns"
+		}
+		groups[protocol] = append(groups[protocol], req)
+	}
+	return groups
+}
+
+// flowProtocols lists the request protocols that can be called from a flow
+var flowProtocols = map[string]bool{
+	"http":      true,
+	"dns":       true,
+	"network":   true,
+	"headless":  true,
+	"imap":      true,
+	"pop3":      true,
+	"redis":     true,
+	"mysql":     true,
+	"memcached": true,
+	"grpc":      true,
+	"websocket": true,
+	"graphql":   true,
+}
+
+// flowParser is a recursive-descent parser over the tokens of a flow
+type flowParser struct {
+	tokens []string
+	pos    int
+}
+
+// parseFlow parses the flow into its syntax tree
+func parseFlow(flow string) (flowNode, error) {
+	tokens, err := tokenizeFlow(flow)
+	if err != nil {
+		return nil, err
+	}
+	p := &flowParser{tokens: tokens}
+	root, err := p.parseSeq()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("flow: unexpected %q", p.tokens[p.pos])
+	}
+	if len(root.Nodes) == 0 {
+		return nil, fmt.Errorf("flow is empty")
+	}
+	return root, nil
+}
+
+// tokenizeFlow splits the flow into identifiers, numbers, operators and brackets
+func tokenizeFlow(flow string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(flow); {
+		c := rune(flow[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case strings.ContainsRune("(){};,", c):
+			tokens = append(tokens, string(c))
+			i++
+		case c == '[':
+			end := strings.IndexByte(flow[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("flow: unclosed [ at %d", i)
+			}
+			tokens = append(tokens, flow[i:i+end+1])
+			i += end + 1
+		case strings.HasPrefix(flow[i:], "&&"), strings.HasPrefix(flow[i:], "||"):
+			tokens = append(tokens, flow[i:i+2])
+			i += 2
+		case unicode.IsLetter(c) || unicode.IsDigit(c):
+			j := i
+			for j < len(flow) && (unicode.IsLetter(rune(flow[j])) || unicode.IsDigit(rune(flow[j]))) {
+				j++
+			}
+			tokens = append(tokens, flow[i:j])
+			i = j
+		default:
+			return nil, fmt.Errorf("flow: unexpected character %q at %d", c, i)
+		}
+	}
+	return tokens, nil
+}
+
+// peek returns the current token or "" at the end
+func (p *flowParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+// expect consumes the token or fails
+func (p *flowParser) expect(tok string) error {
+	if p.peek() != tok {
+		if p.pos >= len(p.tokens) {
+			return fmt.Errorf("flow: expected %q at the end", tok)
+		}
+		return fmt.Errorf("flow: expected %q, got %q", tok, p.peek())
+	}
+	p.pos++
+	return nil
+}
+
+// parseSeq parses statements until the end of the flow or a closing brace
+func (p *flowParser) parseSeq() (*SeqNode, error) {
+	seq := &SeqNode{}
+	for p.pos < len(p.tokens) && p.peek() != "}" {
+		if p.peek() == ";" {
+			p.pos++
+			continue
+		}
+		node, err := p.parseStmt()
+		if err != nil {
+			return nil, err
+		}
+		seq.Nodes = append(seq.Nodes, node)
+	}
+	return seq, nil
+}
+
+// parseStmt parses an if statement or an expression
+func (p *flowParser) parseStmt() (flowNode, error) {
+	if p.peek() != "if" {
+		return p.parseOr()
+	}
+	p.pos++
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	cond, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	then, err := p.parseBlock()
+	if err != nil {
+		return nil, err
+	}
+	node := &IfNode{Cond: cond, Then: then}
+	if p.peek() == "else" {
+		p.pos++
+		if p.peek() == "if" {
+			node.Else, err = p.parseStmt()
+		} else {
+			node.Else, err = p.parseBlock()
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return node, nil
+}
+
+// parseBlock parses a braced sequence
+func (p *flowParser) parseBlock() (flowNode, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	seq, err := p.parseSeq()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect("}"); err != nil {
+		return nil, err
+	}
+	return seq, nil
+}
+
+// parseOr parses a || chain
+func (p *flowParser) parseOr() (flowNode, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	nodes := []flowNode{first}
+	for p.peek() == "||" {
+		p.pos++
+		next, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, next)
+	}
+	if len(nodes) == 1 {
+		return first, nil
+	}
+	return &OrNode{Nodes: nodes}, nil
+}
+
+// parseAnd parses a && chain
+func (p *flowParser) parseAnd() (flowNode, error) {
+	first, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	nodes := []flowNode{first}
+	for p.peek() == "&&" {
+		p.pos++
+		next, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, next)
+	}
+	if len(nodes) == 1 {
+		return first, nil
+	}
+	return &AndNode{Nodes: nodes}, nil
+}
+
+// parsePrimary parses a request call, a foreach loop or a parenthesized expression
+func (p *flowParser) parsePrimary() (flowNode, error) {
+	switch p.peek() {
+	case "(":
+		p.pos++
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return node, p.expect(")")
+	case "foreach":
+		return p.parseForEach()
+	default:
+		return p.parseRequest()
+	}
+}
+
+// parseForEach parses foreach(request, expr)
+func (p *flowParser) parseForEach() (flowNode, error) {
+	p.pos++
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	source, err := p.parseRequest()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(","); err != nil {
+		return nil, err
+	}
+	body, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	return &ForEachNode{Source: source, Body: body}, nil
+}
+
+// parseRequest parses protocol(index)
+func (p *flowParser) parseRequest() (*ReqNode, error) {
+	tok := p.peek()
+	if !flowProtocols[tok] {
+		if tok == "" {
+			return nil, fmt.Errorf("flow: unexpected end")
+		}
+		return nil, fmt.Errorf("flow: unknown request protocol %q", tok)
+	}
+	p.pos++
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	index, err := strconv.Atoi(p.peek())
+	if err != nil {
+		return nil, fmt.Errorf("flow: invalid request index %q for %s", p.peek(), tok)
+	}
+	p.pos++
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	node := &ReqNode{Protocol: tok, Index: index}
+	if strings.HasPrefix(p.peek(), "[") {
+		if err := parseRequestOptions(node, p.peek()); err != nil {
+			return nil, err
+		}
+		p.pos++
+	}
+	return node, nil
+}
+
+// parseRequestOptions applies the [key=value,...] annotation of a flow request
+func parseRequestOptions(node *ReqNode, annotation string) error {
+	for _, opt := range strings.Split(strings.Trim(annotation, "[]"), ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(opt), "=")
+		if !ok {
+			return fmt.Errorf("flow: invalid request option %q", opt)
+		}
+		switch strings.TrimSpace(key) {
+		case "timeout":
+			d, err := time.ParseDuration(strings.TrimSpace(value))
+			if err != nil || d <= 0 {
+				return fmt.Errorf("flow: invalid timeout %q", value)
+			}
+			node.Timeout = d
+		default:
+			return fmt.Errorf("flow: unknown request option %q", key)
+		}
+	}
+	return nil
+}
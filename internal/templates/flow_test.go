@@ -0,0 +1,214 @@
+package templates
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// flowTemplate returns a template with the flow and n HTTP requests, request i requests /ri and matches on 200
+func flowTemplate(flow string, n int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "id: flow-test\ninfo:\n  name: Flow test\n  author: test\n  severity: info\nflow: %q\nhttp:\n", flow)
+	for i := 1; i <= n; i++ {
+		fmt.Fprintf(&b, "  - path:\n      - \"{{BaseURL}}/r%d\"\n    matchers:\n      - type: status\n        status: [200]\n", i)
+	}
+	return b.String()
+}
+
+func TestParseFlow(t *testing.T) {
+	tests := []struct {
+		flow    string
+		wantErr string
+	}{
+		{flow: "http(1) && http(2) || http(3)"},
+		{flow: "if(http(1)) { http(2) } else if (http(3)) { http(4) } else { http(5) }"},
+		{flow: "if (http(1) || dns(1)) { http(2); http(3) }"},
+		{flow: "foreach(http(1), http(2) && http(3))"},
+		{flow: "http(1)[timeout=100ms]; (http(2) || http(3))"},
+		{flow: "", wantErr: "empty"},
+		{flow: "if(http(1)) { http(2)", wantErr: "flow"},
+		{flow: "http(1) &&", wantErr: "flow"},
+		{flow: "ftp(1)", wantErr: "ftp"},
+		{flow: "http(1)[timeout=soon]", wantErr: "timeout"},
+		{flow: "http(1) )", wantErr: "flow"},
+	}
+	for _, tt := range tests {
+		_, err := parseFlow(tt.flow)
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("parseFlow(%q): %v", tt.flow, err)
+		case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+			t.Errorf("parseFlow(%q) error = %v, want it to mention %q", tt.flow, err, tt.wantErr)
+		}
+	}
+}
+
+func TestFlowBranching(t *testing.T) {
+	tests := []struct {
+		name     string
+		flow     string
+		matching []string
+		want     bool
+		sent     string
+	}{
+		{name: "if true", flow: "if(http(1)) { http(2) } else { http(3) }", matching: []string{"/r1", "/r2"}, want: true, sent: "/r1,/r2"},
+		{name: "if false runs else", flow: "if(http(1)) { http(2) } else { http(3) }", matching: []string{"/r3"}, want: true, sent: "/r1,/r3"},
+		{name: "if false without else", flow: "if(http(1)) { http(2) }", matching: []string{"/r2"}, want: false, sent: "/r1"},
+		{name: "result of the last node", flow: "if(http(1)) { http(2) } else { http(3) }", matching: []string{"/r1"}, want: false, sent: "/r1,/r2"},
+		{name: "nested", flow: "if(http(1)) { if(http(2)) { http(3) } else { http(4) } } else { http(5) }", matching: []string{"/r1", "/r4"}, want: true, sent: "/r1,/r2,/r4"},
+		{name: "else if", flow: "if(http(1)) { http(2) } else if(http(3)) { http(4) } else { http(5) }", matching: []string{"/r3", "/r4"}, want: true, sent: "/r1,/r3,/r4"},
+		{name: "or stops at the first match", flow: "http(1) || http(2) || http(3)", matching: []string{"/r2", "/r3"}, want: true, sent: "/r1,/r2"},
+		{name: "or without match", flow: "http(1) || http(2)", want: false, sent: "/r1,/r2"},
+		{name: "and short-circuits on false", flow: "http(1) && http(2) && http(3)", matching: []string{"/r2", "/r3"}, want: false, sent: "/r1"},
+		{name: "false condition skips the block", flow: "if(http(1) && http(2)) { http(3) }; http(4)", matching: []string{"/r2", "/r3", "/r4"}, want: true, sent: "/r1,/r4"},
+		{name: "and binds tighter than or", flow: "http(1) && http(2) || http(3)", matching: []string{"/r3"}, want: true, sent: "/r1,/r3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, srv := newRequestRecorder(t, tt.matching...)
+			tmpl := loadTestTemplate(t, flowTemplate(tt.flow, 5))
+			matched, _, err := matchTemplate(context.Background(), srv.URL, "", tmpl, nil, testSettings(), testLogger())
+			if err != nil {
+				t.Fatalf("matchTemplate: %v", err)
+			}
+			if matched != tt.want {
+				t.Errorf("flow %q matched = %v, want %v", tt.flow, matched, tt.want)
+			}
+			if got := strings.Join(rec.requests(), ","); got != tt.sent {
+				t.Errorf("flow %q sent %s, want %s", tt.flow, got, tt.sent)
+			}
+		})
+	}
+
+	_, srv := newRequestRecorder(t)
+	tmpl := loadTestTemplate(t, flowTemplate("http(1) || http(3)", 2))
+	if _, _, err := matchTemplate(context.Background(), srv.URL, "", tmpl, nil, testSettings(), testLogger()); err == nil {
+		t.Error("flow referencing a missing request succeeded")
+	}
+}
+
+const foreachTemplate = `id: flow-foreach
+info:
+  name: Flow foreach
+  author: test
+  severity: info
+flow: foreach(http(1), http(2))
+http:
+  - path:
+      - "{{BaseURL}}/users"
+    extractors:
+      - type: regex
+        name: user
+        regex:
+          - '\[.*\]'
+  - path:
+      - "{{BaseURL}}/check/{{item}}?user={{user}}"
+    matchers:
+      - type: status
+        status: [200]
+`
+
+func TestFlowForEach(t *testing.T) {
+	tests := []struct {
+		name       string
+		iterations int
+		sent       string
+		want       bool
+	}{
+		{name: "every item", sent: "/check/id1?user=id1,/check/id2?user=id2,/check/id3?user=id3", want: true},
+		{name: "capped", iterations: 2, sent: "/check/id1?user=id1,/check/id2?user=id2", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var sub []string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if strings.HasPrefix(r.URL.Path, "/check/") {
+					mu.Lock()
+					sub = append(sub, r.URL.RequestURI())
+					mu.Unlock()
+				}
+				switch {
+				case r.URL.Path == "/users":
+					io.WriteString(w, `["id1","id2","id3"]`)
+				case r.URL.Path == "/check/id3":
+				case strings.HasPrefix(r.URL.Path, "/check/"):
+					w.WriteHeader(http.StatusNotFound)
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			t.Cleanup(srv.Close)
+
+			advanced := testSettings()
+			advanced.MaxFlowIterations = tt.iterations
+			tmpl := loadTestTemplate(t, foreachTemplate)
+			matched, _, err := matchTemplate(context.Background(), srv.URL, "", tmpl, nil, advanced, testLogger())
+			if err != nil {
+				t.Fatalf("matchTemplate: %v", err)
+			}
+			if matched != tt.want {
+				t.Errorf("foreach matched = %v, want %v", matched, tt.want)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if got := strings.Join(sub, ","); got != tt.sent {
+				t.Errorf("sub-requests = %s, want %s", got, tt.sent)
+			}
+		})
+	}
+}
+
+func TestFlowStepTimeout(t *testing.T) {
+	tests := []struct {
+		behavior string
+		want     bool
+		step2    bool
+	}{
+		{behavior: "", want: true, step2: true},
+		{behavior: FlowTimeoutSkip, want: true, step2: true},
+		{behavior: FlowTimeoutFail, want: false, step2: false},
+	}
+	for _, tt := range tests {
+		t.Run("behavior="+tt.behavior, func(t *testing.T) {
+			var step2 atomic.Bool
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/r1":
+					select {
+					case <-time.After(200 * time.Millisecond):
+					case <-r.Context().Done():
+					}
+				case "/r2":
+					step2.Store(true)
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			t.Cleanup(srv.Close)
+
+			advanced := testSettings()
+			advanced.FlowTimeoutBehavior = tt.behavior
+			tmpl := loadTestTemplate(t, flowTemplate("http(1)[timeout=100ms]; http(2)", 2))
+			start := time.Now()
+			matched, _, err := matchTemplate(context.Background(), srv.URL, "", tmpl, nil, advanced, testLogger())
+			if err != nil {
+				t.Fatalf("matchTemplate: %v", err)
+			}
+			if matched != tt.want || step2.Load() != tt.step2 {
+				t.Errorf("matched = %v, step 2 ran = %v, want %v and %v", matched, step2.Load(), tt.want, tt.step2)
+			}
+			if elapsed := time.Since(start); elapsed >= 200*time.Millisecond {
+				t.Errorf("flow took %v, step 1 should have been cut at 100ms", elapsed)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+// package templates - GraphQL introspection requests
+package templates
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/artnikel/nuclei/internal/logging"
+)
+
+const (
+	// defaultGraphQLPath is the endpoint of GraphQL requests without a path
+	defaultGraphQLPath = "/graphql"
+	// introspectionQuery is the body of GraphQL requests without a body, it only succeeds with introspection enabled
+	introspectionQuery = `{"query":"{__schema{queryType{name}}}"}`
+)
+
+// matchGraphQLRequest posts the request body, the introspection query by default, as JSON to the GraphQL endpoint
+// and matches the response like an HTTP request
+func matchGraphQLRequest(ctx context.Context, baseURL string, req *Request, tmpl *Template, templateVars map[string]interface{}, advanced *AdvancedSettingsChecker, logger *logging.Logger) (bool, map[string]interface{}, error) {
+	return matchHTTPRequest(ctx, baseURL, graphQLHTTPRequest(req), tmpl, templateVars, advanced, logger)
+}
+
+// graphQLHTTPRequest returns a copy of the GraphQL request as an HTTP POST with the defaults filled in
+func graphQLHTTPRequest(req *Request) *Request {
+	httpReq := *req
+	httpReq.Type = "http"
+	httpReq.Method = http.MethodPost
+	if len(httpReq.Path) == 0 {
+		httpReq.Path = []string{defaultGraphQLPath}
+	}
+	if httpReq.Body == "" {
+		httpReq.Body = introspectionQuery
+	}
+	httpReq.Headers = make(map[string]string, len(req.Headers)+1)
+	httpReq.Headers["Content-Type"] = "application/json"
+	for k, v := range req.Headers {
+		httpReq.Headers[k] = v
+	}
+	return &httpReq
+}
+
+// introspectionEnabled reports whether the body is a GraphQL response holding data.__schema
+func introspectionEnabled(body []byte) bool {
+	var resp struct {
+		Data struct {
+			Schema json.RawMessage `json:"__schema"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return false
+	}
+	return len(resp.Data.Schema) > 0 && string(resp.Data.Schema) != "null"
+}
@@ -0,0 +1,108 @@
+package templates
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newGraphQLServer answers POST /graphql with a minimal schema, or an introspection error when disabled
+func newGraphQLServer(t *testing.T, introspection bool) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.URL.Path != "/graphql" || r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" ||
+			string(body) != introspectionQuery {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if !introspection {
+			io.WriteString(w, `{"errors":[{"message":"GraphQL introspection is not allowed"}],"data":null}`)
+			return
+		}
+		io.WriteString(w, `{"data":{"__schema":{"queryType":{"name":"Query"}}}}`)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// matchGraphQL runs the template against target and reports whether it matched
+func matchGraphQL(t *testing.T, target string, tmpl *Template) bool {
+	t.Helper()
+	matched, _, err := matchTemplate(context.Background(), target, "", tmpl, nil, testSettings(), testLogger())
+	if err != nil {
+		t.Fatalf("matchTemplate: %v", err)
+	}
+	return matched
+}
+
+const graphQLTemplate = `id: graphql-introspection-%d
+info:
+  name: GraphQL introspection
+  author: test
+  severity: medium
+http:
+  - type: graphql
+    matchers:
+      - type: graphql
+        introspection-enabled: %v
+`
+
+const graphQLJSONTemplate = `id: graphql-query-type
+info:
+  name: GraphQL query type
+  author: test
+  severity: info
+http:
+  - type: graphql
+    matchers:
+      - type: json
+        jsonpath: data.__schema.queryType.name
+`
+
+func TestGraphQLRequest(t *testing.T) {
+	enabled := newGraphQLServer(t, true)
+	disabled := newGraphQLServer(t, false)
+
+	tests := []struct {
+		name   string
+		target *httptest.Server
+		expect bool
+		want   bool
+	}{
+		{name: "introspection enabled", target: enabled, expect: true, want: true},
+		{name: "introspection disabled", target: disabled, expect: true, want: false},
+		{name: "expect disabled", target: disabled, expect: false, want: true},
+		{name: "expect disabled on enabled server", target: enabled, expect: false, want: false},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl := loadTestTemplate(t, fmt.Sprintf(graphQLTemplate, i, tt.expect))
+			if got := matchGraphQL(t, tt.target.URL, tmpl); got != tt.want {
+				t.Errorf("graphql matcher = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	tmpl := loadTestTemplate(t, graphQLJSONTemplate)
+	if !matchGraphQL(t, enabled.URL, tmpl) {
+		t.Error("json matcher did not find data.__schema.queryType.name in the graphql response")
+	}
+}
+
+func TestIntrospectionEnabled(t *testing.T) {
+	for body, want := range map[string]bool{
+		`{"data":{"__schema":{"queryType":{"name":"Query"}}}}`: true,
+		`{"data":{"__schema":null}}`:                           false,
+		`{"errors":[{"message":"not allowed"}],"data":null}`:   false,
+		`<html>not json</html>`:                                false,
+	} {
+		if got := introspectionEnabled([]byte(body)); got != want {
+			t.Errorf("introspectionEnabled(%s) = %v, want %v", body, got, want)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+// package templates - gRPC server reflection requests
+package templates
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+
+	"github.com/artnikel/nuclei/internal/constants"
+	"github.com/artnikel/nuclei/internal/logging"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+)
+
+// matchGRPCRequest lists the services of the gRPC server of the host through server reflection and matches
+// the newline-separated service names with the network matchers. Options: port overrides the target port,
+// tls false connects without TLS
+func matchGRPCRequest(ctx context.Context, host, defaultPort string, req *Request, tmpl *Template, advanced *AdvancedSettingsChecker, logger *logging.Logger) (bool, error) {
+	if req.Type != "grpc" {
+		return false, fmt.Errorf("request type is not grpc: %s", req.Type)
+	}
+
+	useTLS := true
+	if tlsVal, ok := req.Options["tls"]; ok {
+		useTLS = fmt.Sprint(tlsVal) != "false"
+	}
+	port := defaultPort
+	if portVal, ok := req.Options["port"]; ok {
+		port = fmt.Sprint(portVal)
+	}
+	addr := net.JoinHostPort(host, port)
+
+	if advanced.DryRun {
+		logDryRun(advanced, logger, "Would connect: grpc "+addr)
+		return false, nil
+	}
+
+	services, err := listGRPCServices(ctx, addr, host, useTLS)
+	if err != nil {
+		return false, err
+	}
+
+	matchCtx := MatchContext{
+		Network: &NetworkResponse{
+			Data: []byte(strings.Join(services, "\n")),
+		},
+	}
+	matched := checkMatchers(req.Matchers, req.MatchersCondition, matchCtx)
+
+	logger.Info("gRPC request matched",
+		slog.String("template_id", tmpl.ID),
+		slog.String("host", host),
+		slog.Int("services", len(services)),
+		slog.Bool("matched", matched),
+	)
+	return matched, nil
+}
+
+// listGRPCServices returns the service names the reflection service of the server at addr reports
+func listGRPCServices(ctx context.Context, addr, host string, useTLS bool) ([]string, error) {
+	creds := insecure.NewCredentials()
+	if useTLS {
+		creds = credentials.NewTLS(&tls.Config{InsecureSkipVerify: true, ServerName: host})
+	}
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, constants.TenSecTimeout)
+	defer cancel()
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = stream.Send(&reflectionpb.ServerReflectionRequest{
+		Host:           host,
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+	stream.CloseSend()
+
+	if errResp := resp.GetErrorResponse(); errResp != nil {
+		return nil, fmt.Errorf("grpc reflection error: %s", errResp.GetErrorMessage())
+	}
+	list := resp.GetListServicesResponse()
+	if list == nil {
+		return nil, errors.New("grpc reflection returned no service list")
+	}
+	services := make([]string, 0, len(list.GetService()))
+	for _, svc := range list.GetService() {
+		services = append(services, svc.GetName())
+	}
+	return services, nil
+}
@@ -0,0 +1,97 @@
+package templates
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// newGRPCServer starts a gRPC server with the health service and reflection registered and returns its port
+func newGRPCServer(t *testing.T, opts ...grpc.ServerOption) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := grpc.NewServer(opts...)
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	reflection.Register(srv)
+	go srv.Serve(l)
+	t.Cleanup(srv.Stop)
+	_, port, _ := net.SplitHostPort(l.Addr().String())
+	return port
+}
+
+const grpcTemplate = `id: grpc-reflection-%d
+info:
+  name: gRPC reflection
+  author: test
+  severity: low
+http:
+  - type: grpc
+    options:
+      port: %s
+      tls: %v
+    matchers:
+      - type: word
+        words:
+          - "%s"
+`
+
+func TestGRPCRequest(t *testing.T) {
+	insecurePort := newGRPCServer(t)
+	tlsPort := newGRPCServer(t, grpc.Creds(credentials.NewTLS(&tls.Config{Certificates: []tls.Certificate{newTestCert(t)}})))
+
+	tests := []struct {
+		name string
+		port string
+		tls  bool
+		word string
+		want bool
+	}{
+		{name: "insecure health service", port: insecurePort, word: "grpc.health.v1.Health", want: true},
+		{name: "insecure reflection service", port: insecurePort, word: "grpc.reflection.v1alpha.ServerReflection", want: true},
+		{name: "insecure unknown service", port: insecurePort, word: "helloworld.Greeter", want: false},
+		{name: "tls health service", port: tlsPort, tls: true, word: "grpc.health.v1.Health", want: true},
+		{name: "tls against plaintext server", port: insecurePort, tls: true, word: "grpc.health.v1.Health", want: false},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl := loadTestTemplate(t, fmt.Sprintf(grpcTemplate, i, tt.port, tt.tls, tt.word))
+			matched, _, err := matchTemplate(context.Background(), "http://127.0.0.1", "", tmpl, nil, testSettings(), testLogger())
+			if err != nil {
+				t.Fatalf("matchTemplate: %v", err)
+			}
+			if matched != tt.want {
+				t.Errorf("grpc matched %q = %v, want %v", tt.word, matched, tt.want)
+			}
+		})
+	}
+}
+
+func TestListGRPCServices(t *testing.T) {
+	port := newGRPCServer(t)
+	services, err := listGRPCServices(context.Background(), net.JoinHostPort("127.0.0.1", port), "127.0.0.1", false)
+	if err != nil {
+		t.Fatalf("listGRPCServices: %v", err)
+	}
+	want := map[string]bool{"grpc.health.v1.Health": false, "grpc.reflection.v1.ServerReflection": false, "grpc.reflection.v1alpha.ServerReflection": false}
+	for _, svc := range services {
+		if _, ok := want[svc]; ok {
+			want[svc] = true
+		}
+	}
+	for svc, found := range want {
+		if !found {
+			t.Errorf("service %s missing from %v", svc, services)
+		}
+	}
+}
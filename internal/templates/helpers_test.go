@@ -0,0 +1,47 @@
+package templates
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/artnikel/nuclei/internal/logging"
+)
+
+// testLogger returns a logger discarding every record
+func testLogger() *logging.Logger {
+	return &logging.Logger{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+}
+
+// testSettings returns the default settings without the template cache and the headless browser
+func testSettings() *AdvancedSettingsChecker {
+	advanced := DefaultAdvancedSettings()
+	advanced.CacheDir = ""
+	advanced.DisableHeadless = true
+	return advanced
+}
+
+// writeTestFile writes content to name in dir and returns its path
+func writeTestFile(t testing.TB, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// loadTestTemplate loads the template YAML the way templates are loaded from disk
+func loadTestTemplate(t *testing.T, content string) *Template {
+	t.Helper()
+	tmpl, err := LoadTemplate(writeTestFile(t, t.TempDir(), "template.yaml", content))
+	if err != nil {
+		t.Fatalf("LoadTemplate: %v", err)
+	}
+	return tmpl
+}
@@ -0,0 +1,124 @@
+// package templates - per-host limit of the templates run at once
+package templates
+
+import (
+	"context"
+	"net/url"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+var (
+	hostScansMu sync.Mutex                    // hostScansMu guards hostScans
+	hostScans   = map[string]*hostScanState{} // hostScans holds the state shared by the running scans of a host, keyed by host
+)
+
+// hostScanState is shared by the concurrent scans of a host so targets on the same host share its limits. It's
+// dropped when the last scan of the host ends
+type hostScanState struct {
+	scans            int
+	semaphores       map[int]chan struct{}    // semaphores limit the templates run at once, keyed by limit
+	templateLimiters map[string]*rate.Limiter // templateLimiters limit the requests overriding the rate, keyed by template ID
+}
+
+// DefaultMaxConcurrentTemplatesPerHost returns the number of templates run at once against a host for the given
+// number of workers
+func DefaultMaxConcurrentTemplatesPerHost(workers int) int {
+	return max(1, workers/10)
+}
+
+// templatesPerHost returns the limit of templates run at once against a host, 0 means no limit
+func (a *AdvancedSettingsChecker) templatesPerHost() int {
+	if a.MaxConcurrentTemplatesPerHost > 0 {
+		return a.MaxConcurrentTemplatesPerHost
+	}
+	if a.Workers > 0 {
+		return DefaultMaxConcurrentTemplatesPerHost(a.Workers)
+	}
+	return 0
+}
+
+// startHostScan registers a scan of the host until end is called and returns the semaphore of the host for the
+// limit, nil if limit is 0
+func startHostScan(host string, limit int) (sem chan struct{}, end func()) {
+	hostScansMu.Lock()
+	defer hostScansMu.Unlock()
+	state, ok := hostScans[host]
+	if !ok {
+		state = &hostScanState{semaphores: map[int]chan struct{}{}, templateLimiters: map[string]*rate.Limiter{}}
+		hostScans[host] = state
+	}
+	state.scans++
+	if limit > 0 {
+		sem = state.semaphores[limit]
+		if sem == nil {
+			sem = make(chan struct{}, limit)
+			state.semaphores[limit] = sem
+		}
+	}
+	return sem, sync.OnceFunc(func() {
+		hostScansMu.Lock()
+		defer hostScansMu.Unlock()
+		if state.scans--; state.scans == 0 {
+			delete(hostScans, host)
+		}
+	})
+}
+
+// templateHostLimiter returns the limiter of the template requests overriding the rate on the host, shared by
+// the running scans of the host. Callers register the host with startHostScan first, without it the limiter only
+// covers the calling request
+func templateHostLimiter(host, tmplID string, frequency, burst int) *rate.Limiter {
+	hostScansMu.Lock()
+	defer hostScansMu.Unlock()
+	state := hostScans[host]
+	if state == nil {
+		return rate.NewLimiter(rate.Every(time.Duration(frequency)*time.Millisecond), burst)
+	}
+	limiter := state.templateLimiters[tmplID]
+	if limiter == nil {
+		limiter = rate.NewLimiter(rate.Every(time.Duration(frequency)*time.Millisecond), burst)
+		state.templateLimiters[tmplID] = limiter
+	}
+	return limiter
+}
+
+// requestHost returns the host the template requests to the target are limited by, the IDN normalized one when
+// the settings normalize IDN targets
+func requestHost(targetURL string, advanced *AdvancedSettingsChecker) string {
+	if advanced.IDNNormalize {
+		targetURL = NormalizeIDN(targetURL)
+	}
+	u, err := url.Parse(targetURL)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
+
+// acquire takes a slot of every non-nil semaphore in order, releasing the taken slots if ctx is done first
+func acquire(ctx context.Context, sems ...chan struct{}) bool {
+	for i, sem := range sems {
+		if sem == nil {
+			continue
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			release(sems[:i]...)
+			return false
+		}
+	}
+	return true
+}
+
+// release frees a slot of every non-nil semaphore
+func release(sems ...chan struct{}) {
+	for _, sem := range sems {
+		if sem != nil {
+			<-sem
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package templates
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const hostLimitTemplate = `id: host-limit-%d
+info:
+  name: Host limit
+  author: test
+  severity: info
+http:
+  - method: GET
+    path:
+      - "{{BaseURL}}/t%d"
+    matchers:
+      - type: status
+        status:
+          - 200
+`
+
+// newConcurrencyServer answers slowly and records the most template requests it was serving at once, the page
+// fetched by a scan before running the templates isn't counted
+func newConcurrencyServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var inFlight, peak atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/t") {
+			return
+		}
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &peak
+}
+
+// writeHostLimitTemplates writes n templates requesting a path each
+func writeHostLimitTemplates(t *testing.T, n int) string {
+	t.Helper()
+	dir := t.TempDir()
+	for i := 0; i < n; i++ {
+		path := filepath.Join(dir, fmt.Sprintf("t%d.yaml", i))
+		if err := os.WriteFile(path, []byte(fmt.Sprintf(hostLimitTemplate, i, i)), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestMaxConcurrentTemplatesPerHost(t *testing.T) {
+	const templates = 30
+	dir := writeHostLimitTemplates(t, templates)
+	tests := []struct {
+		name      string
+		workers   int
+		perHost   int
+		scans     int
+		wantLimit int
+	}{
+		{name: "configured limit", workers: 30, perHost: 3, scans: 1, wantLimit: 3},
+		{name: "default limit of the workers", workers: 40, scans: 1, wantLimit: 4},
+		{name: "limit shared by scans of the host", workers: 30, perHost: 2, scans: 3, wantLimit: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, peak := newConcurrencyServer(t)
+			var wg sync.WaitGroup
+			for s := 0; s < tt.scans; s++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					advanced := testSettings()
+					advanced.Workers = tt.workers
+					advanced.MaxConcurrentTemplatesPerHost = tt.perHost
+					findings, err := FindMatchingTemplates(context.Background(), srv.URL, dir, nil, nil, time.Minute,
+						advanced, testLogger(), func(i, total int) {})
+					if err != nil || len(findings) != templates {
+						t.Errorf("FindMatchingTemplates = %d findings, %v, want %d", len(findings), err, templates)
+					}
+				}()
+			}
+			wg.Wait()
+			if got := int(peak.Load()); got > tt.wantLimit || got < 2 {
+				t.Errorf("%d templates ran at once against the host, want at most %d and more than one", got, tt.wantLimit)
+			}
+		})
+	}
+}
+
+func TestHostScanStateDropped(t *testing.T) {
+	const host = "host-scan.test"
+	sem, endFirst := startHostScan(host, 2)
+	shared, endSecond := startHostScan(host, 2)
+	if sem == nil || shared != sem {
+		t.Fatal("scans of the host don't share its semaphore")
+	}
+	limiter := templateHostLimiter(host, "t", 100, 1)
+	if templateHostLimiter(host, "t", 100, 1) != limiter {
+		t.Error("template limiter not shared while the host is scanned")
+	}
+
+	endFirst()
+	endFirst()
+	hostScansMu.Lock()
+	_, running := hostScans[host]
+	hostScansMu.Unlock()
+	if !running {
+		t.Fatal("host state dropped while a scan of the host is running")
+	}
+
+	endSecond()
+	hostScansMu.Lock()
+	left := len(hostScans)
+	hostScansMu.Unlock()
+	if left != 0 {
+		t.Errorf("%d host states left after the scans ended, want 0", left)
+	}
+	if next, end := startHostScan(host, 2); next == sem {
+		t.Error("semaphore of the ended scans reused")
+	} else {
+		end()
+	}
+
+	// scans through FindMatchingTemplates leave nothing behind either
+	dir := writeHostLimitTemplates(t, 5)
+	srv, _ := newConcurrencyServer(t)
+	advanced := testSettings()
+	advanced.Workers = 20
+	if _, err := FindMatchingTemplates(context.Background(), srv.URL, dir, nil, nil, time.Minute, advanced, testLogger(),
+		func(i, total int) {}); err != nil {
+		t.Fatalf("FindMatchingTemplates: %v", err)
+	}
+	hostScansMu.Lock()
+	left = len(hostScans)
+	hostScansMu.Unlock()
+	if left != 0 {
+		t.Errorf("%d host states left after FindMatchingTemplates, want 0", left)
+	}
+}
+
+func TestDefaultMaxConcurrentTemplatesPerHost(t *testing.T) {
+	tests := []struct{ workers, want int }{{0, 1}, {5, 1}, {10, 1}, {25, 2}, {300, 30}}
+	for _, tt := range tests {
+		if got := DefaultMaxConcurrentTemplatesPerHost(tt.workers); got != tt.want {
+			t.Errorf("DefaultMaxConcurrentTemplatesPerHost(%d) = %d, want %d", tt.workers, got, tt.want)
+		}
+	}
+	if got := (&AdvancedSettingsChecker{}).templatesPerHost(); got != 0 {
+		t.Errorf("templatesPerHost without workers = %d, want no limit", got)
+	}
+}
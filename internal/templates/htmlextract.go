@@ -0,0 +1,90 @@
+// package templates - extraction of URLs from HTML documents
+package templates
+
+import (
+	"bytes"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// ExtractFormActions returns the action URLs of all forms in body resolved against baseURL.
+// Forms without an action attribute are skipped
+func ExtractFormActions(body []byte, baseURL string) ([]string, error) {
+	return extractAttrURLs(body, baseURL, "form", "action")
+}
+
+// ExtractHTMLLinks returns the deduplicated href URLs of all links in body resolved against baseURL
+func ExtractHTMLLinks(body []byte, baseURL string) ([]string, error) {
+	links, err := extractAttrURLs(body, baseURL, "a", "href")
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]struct{}, len(links))
+	unique := links[:0]
+	for _, l := range links {
+		if _, ok := seen[l]; ok {
+			continue
+		}
+		seen[l] = struct{}{}
+		unique = append(unique, l)
+	}
+	return unique, nil
+}
+
+// extractAttrURLs collects attr of every tag element in body and resolves the values against baseURL
+func extractAttrURLs(body []byte, baseURL, tag, attr string) ([]string, error) {
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := html.Parse(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	var urls []string
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == tag {
+			for _, a := range n.Attr {
+				if a.Key != attr {
+					continue
+				}
+				ref, err := url.Parse(strings.TrimSpace(a.Val))
+				if err == nil {
+					urls = append(urls, base.ResolveReference(ref).String())
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	return urls, nil
+}
+
+// ExtractHTMLComments returns the trimmed text of all non-empty comments in body in document order
+func ExtractHTMLComments(body []byte) ([]string, error) {
+	doc, err := html.Parse(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	var comments []string
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.CommentNode {
+			if text := strings.TrimSpace(n.Data); text != "" {
+				comments = append(comments, text)
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	return comments, nil
+}
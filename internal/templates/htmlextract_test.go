@@ -0,0 +1,196 @@
+package templates
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+const formsPage = `<html><body>
+<form action="/login" method="post"><input name="user"></form>
+<form action="https://accounts.example.com/reset,confirm"><input name="email"></form>
+<form method="get"><input name="q"></form>
+</body></html>`
+
+// htmlResponse returns a response to a request for pageURL
+func htmlResponse(t *testing.T, pageURL string) *http.Response {
+	t.Helper()
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Request: &http.Request{URL: u}}
+}
+
+func TestExtractFormActions(t *testing.T) {
+	actions, err := ExtractFormActions([]byte(formsPage), "http://example.com/account/")
+	if err != nil {
+		t.Fatalf("ExtractFormActions: %v", err)
+	}
+	want := []string{"http://example.com/login", "https://accounts.example.com/reset,confirm"}
+	if !reflect.DeepEqual(actions, want) {
+		t.Errorf("ExtractFormActions = %v, want %v", actions, want)
+	}
+}
+
+func TestFormActionExtractor(t *testing.T) {
+	ctx := MatchContext{Resp: htmlResponse(t, "http://example.com/account/"), Body: []byte(formsPage)}
+	values := processExtractors([]Extractor{{Type: "form-action", Name: "forms"}}, ctx)
+	want := []string{"http://example.com/login", "https://accounts.example.com/reset,confirm"}
+	if got, ok := values["forms"].([]string); !ok || !reflect.DeepEqual(got, want) {
+		t.Fatalf("form-action value = %#v, want %v", values["forms"], want)
+	}
+
+	ctx.Body = []byte(`<form method="get"></form>`)
+	if values := processExtractors([]Extractor{{Type: "form-action", Name: "forms"}}, ctx); len(values) != 0 {
+		t.Errorf("form-action without actions = %v", values)
+	}
+}
+
+func TestFormActionInNextRequest(t *testing.T) {
+	var posted bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			io.WriteString(w, `<form action="session/new"><input name="user"></form>`)
+		case "/session/new":
+			posted = r.Method == http.MethodPost
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	tmpl := loadTestTemplate(t, `id: form-action-chain
+info:
+  name: Login form
+  author: test
+  severity: info
+http:
+  - path:
+      - "{{BaseURL}}/"
+    extractors:
+      - type: form-action
+        name: login
+  - method: POST
+    path:
+      - "{{login}}"
+    matchers:
+      - type: status
+        status: [200]
+`)
+	matched, extracted, err := MatchTemplate(context.Background(), srv.URL, "", tmpl, testSettings(), testLogger())
+	if err != nil {
+		t.Fatalf("MatchTemplate: %v", err)
+	}
+	if !matched || !posted {
+		t.Fatalf("matched = %v, form action requested = %v", matched, posted)
+	}
+	if want := srv.URL + "/session/new"; extracted["login"] != want {
+		t.Errorf("extracted login = %q, want %q", extracted["login"], want)
+	}
+}
+
+const linksPage = `<html><body>
+<a href="/docs">Docs</a>
+<a href="../pricing?plan=pro,team">Pricing</a>
+<a href="https://example.org/about">About</a>
+<a href="//cdn.example.net/app.js">CDN</a>
+<a href="contact">Contact</a>
+<a href="/docs">Docs again</a>
+<a name="top">No href</a>
+</body></html>`
+
+func TestExtractHTMLLinks(t *testing.T) {
+	links, err := ExtractHTMLLinks([]byte(linksPage), "https://example.com/products/list")
+	if err != nil {
+		t.Fatalf("ExtractHTMLLinks: %v", err)
+	}
+	want := []string{
+		"https://example.com/docs",
+		"https://example.com/pricing?plan=pro,team",
+		"https://example.org/about",
+		"https://cdn.example.net/app.js",
+		"https://example.com/products/contact",
+	}
+	if !reflect.DeepEqual(links, want) {
+		t.Errorf("ExtractHTMLLinks = %v, want %v", links, want)
+	}
+}
+
+func TestHTMLLinksExtractor(t *testing.T) {
+	ctx := MatchContext{Resp: htmlResponse(t, "https://example.com/products/list"), Body: []byte(linksPage)}
+	tests := []struct {
+		name  string
+		regex []string
+		want  []string
+	}{
+		{name: "all links", want: []string{
+			"https://example.com/docs",
+			"https://example.com/pricing?plan=pro,team",
+			"https://example.org/about",
+			"https://cdn.example.net/app.js",
+			"https://example.com/products/contact",
+		}},
+		{name: "regex filter", regex: []string{`^https://example\.com/`}, want: []string{
+			"https://example.com/docs",
+			"https://example.com/pricing?plan=pro,team",
+			"https://example.com/products/contact",
+		}},
+		{name: "no link matches", regex: []string{`\.pdf$`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := processExtractors([]Extractor{{Type: "html-links", Name: "links", Regex: tt.regex}}, ctx)
+			if tt.want == nil {
+				if len(values) != 0 {
+					t.Fatalf("html-links = %v, want no value", values)
+				}
+				return
+			}
+			if got, ok := values["links"].([]string); !ok || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("html-links = %#v, want %v", values["links"], tt.want)
+			}
+		})
+	}
+}
+
+const commentsPage = `<html><head><!-- version: 2.3.1 --></head>
+<body><!-- debug mode --><p>Welcome</p><!--   --></body></html>`
+
+func TestExtractHTMLComments(t *testing.T) {
+	comments, err := ExtractHTMLComments([]byte(commentsPage))
+	if err != nil {
+		t.Fatalf("ExtractHTMLComments: %v", err)
+	}
+	if want := []string{"version: 2.3.1", "debug mode"}; !reflect.DeepEqual(comments, want) {
+		t.Errorf("ExtractHTMLComments = %q, want %q", comments, want)
+	}
+}
+
+func TestHTMLCommentExtractor(t *testing.T) {
+	ctx := MatchContext{Resp: htmlResponse(t, "https://example.com/"), Body: []byte(commentsPage)}
+	tests := []struct {
+		name      string
+		extractor Extractor
+		want      string
+	}{
+		{name: "all comments", extractor: Extractor{Type: "html-comment"}, want: "version: 2.3.1,debug mode"},
+		{name: "version filter", extractor: Extractor{Type: "html-comment", Regex: []string{"version"}}, want: "version: 2.3.1"},
+		{name: "version group", extractor: Extractor{Type: "html-comment", Regex: []string{`version: ([\d.]+)`}, Group: "1"}, want: "2.3.1"},
+		{name: "no comment matches", extractor: Extractor{Type: "html-comment", Regex: []string{"password"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.extractor.Name = "comments"
+			values := processExtractors([]Extractor{tt.extractor}, ctx)
+			if got, _ := values["comments"].(string); got != tt.want {
+				t.Errorf("html-comment = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+// package templates - shared HTTP client for template requests
+package templates
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/artnikel/nuclei/internal/constants"
+)
+
+// transportSettings are the advanced settings the shared HTTP client is built from
+type transportSettings struct {
+	proxy               string
+	maxIdleConnsPerHost int
+	maxConnsPerHost     int
+	disableKeepAlives   bool
+}
+
+var (
+	httpClientMu   sync.Mutex                             // httpClientMu guards httpClients
+	httpClients    = map[transportSettings]*http.Client{} // httpClients holds a client per connection settings so template requests reuse connections
+	activeRequests sync.WaitGroup                         // activeRequests counts the callers of getHTTPClient not released yet
+)
+
+// dialContext opens the TCP connections of the HTTP transports and network requests, it matches the dialer
+// of http.DefaultTransport
+var dialContext = (&net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}).DialContext
+
+// DefaultMaxHTTPRedirects is the number of redirects followed per HTTP request by default
+const DefaultMaxHTTPRedirects = 5
+
+// DefaultMaxIdleConnsPerHost returns the idle connection pool size per host for the given number of workers
+func DefaultMaxIdleConnsPerHost(workers int) int {
+	return max(4, workers/50)
+}
+
+// ResetHTTPClient waits for the requests using the shared HTTP clients to finish and drops the clients, they're
+// rebuilt from the current settings on the next request. New requests wait until the reset is done
+func ResetHTTPClient() {
+	httpClientMu.Lock()
+	defer httpClientMu.Unlock()
+	activeRequests.Wait()
+	for settings, client := range httpClients {
+		client.CloseIdleConnections()
+		delete(httpClients, settings)
+	}
+}
+
+// getHTTPClient returns the shared HTTP client of the connection settings, building it on first use. Scans with
+// different settings get different clients and never wait for each other.
+// The caller must call release once it's done with the client
+func getHTTPClient(advanced *AdvancedSettingsChecker) (client *http.Client, release func(), err error) {
+	settings := transportSettings{
+		proxy:               advanced.Proxy,
+		maxIdleConnsPerHost: advanced.MaxIdleConnsPerHost,
+		maxConnsPerHost:     advanced.MaxConnsPerHost,
+		disableKeepAlives:   advanced.DisableKeepAlives,
+	}
+
+	httpClientMu.Lock()
+	defer httpClientMu.Unlock()
+	client, ok := httpClients[settings]
+	if !ok {
+		client, err = newSharedHTTPClient(settings)
+		if err != nil {
+			return nil, nil, err
+		}
+		httpClients[settings] = client
+	}
+	activeRequests.Add(1)
+	return client, sync.OnceFunc(activeRequests.Done), nil
+}
+
+// newSharedHTTPClient builds the shared HTTP client from the connection settings
+func newSharedHTTPClient(settings transportSettings) (*http.Client, error) {
+	tr := newInsecureTransport(settings)
+	if settings.proxy != "" {
+		proxyURL, err := url.Parse(settings.proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy url: %w", err)
+		}
+		tr.Proxy = http.ProxyURL(proxyURL)
+	}
+	return &http.Client{Transport: tr, Timeout: constants.TenSecTimeout}, nil
+}
+
+// withRedirectLimit returns a copy of the client sharing its transport that follows at most maxRedirects redirects,
+// with 0 the redirect response itself is returned
+func withRedirectLimit(client *http.Client, maxRedirects int) *http.Client {
+	limited := *client
+	limited.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) > maxRedirects {
+			return http.ErrUseLastResponse
+		}
+		return nil
+	}
+	return &limited
+}
+
+// newInsecureTransport returns a transport with TLS-certificate checking disabled and the given pool limits
+func newInsecureTransport(settings transportSettings) *http.Transport {
+	return &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return dialContext(ctx, network, addr)
+		},
+		DisableKeepAlives:   settings.disableKeepAlives,
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: settings.maxIdleConnsPerHost,
+		MaxConnsPerHost:     settings.maxConnsPerHost,
+	}
+}
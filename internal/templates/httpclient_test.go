@@ -0,0 +1,327 @@
+package templates
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetHTTPClientSettings(t *testing.T) {
+	t.Cleanup(ResetHTTPClient)
+	advanced := &AdvancedSettingsChecker{MaxIdleConnsPerHost: 8, MaxConnsPerHost: 16}
+
+	first, release, err := getHTTPClient(advanced)
+	if err != nil {
+		t.Fatalf("getHTTPClient: %v", err)
+	}
+	release()
+	tr := first.Transport.(*http.Transport)
+	if tr.MaxIdleConnsPerHost != 8 || tr.MaxConnsPerHost != 16 || tr.DisableKeepAlives {
+		t.Errorf("transport pool = %d idle, %d per host, keep-alives disabled %v", tr.MaxIdleConnsPerHost, tr.MaxConnsPerHost, tr.DisableKeepAlives)
+	}
+
+	same, release, _ := getHTTPClient(&AdvancedSettingsChecker{MaxIdleConnsPerHost: 8, MaxConnsPerHost: 16})
+	release()
+	if same != first {
+		t.Error("client rebuilt although the settings are unchanged")
+	}
+
+	advanced.DisableKeepAlives = true
+	changed, release, _ := getHTTPClient(advanced)
+	release()
+	if changed == first || !changed.Transport.(*http.Transport).DisableKeepAlives {
+		t.Error("client not rebuilt after the settings changed")
+	}
+	again, release, _ := getHTTPClient(&AdvancedSettingsChecker{MaxIdleConnsPerHost: 8, MaxConnsPerHost: 16})
+	release()
+	if again != first {
+		t.Error("client of the first settings rebuilt after a scan used other settings")
+	}
+
+	if _, _, err := getHTTPClient(&AdvancedSettingsChecker{Proxy: "://bad"}); err == nil {
+		t.Error("invalid proxy accepted")
+	}
+}
+
+func TestGetHTTPClientOtherSettingsDoNotWait(t *testing.T) {
+	t.Cleanup(ResetHTTPClient)
+	old, release, err := getHTTPClient(&AdvancedSettingsChecker{MaxIdleConnsPerHost: 4})
+	if err != nil {
+		t.Fatalf("getHTTPClient: %v", err)
+	}
+
+	got := make(chan *http.Client)
+	go func() {
+		client, release, _ := getHTTPClient(&AdvancedSettingsChecker{MaxIdleConnsPerHost: 32})
+		release()
+		got <- client
+	}()
+	select {
+	case client := <-got:
+		if client == old || client.Transport.(*http.Transport).MaxIdleConnsPerHost != 32 {
+			t.Error("other settings got the client of the running request")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("request with other settings waited for the running request")
+	}
+	release()
+}
+
+func TestResetHTTPClientWaitsForRequests(t *testing.T) {
+	t.Cleanup(ResetHTTPClient)
+	advanced := &AdvancedSettingsChecker{MaxIdleConnsPerHost: 4}
+	old, release, err := getHTTPClient(advanced)
+	if err != nil {
+		t.Fatalf("getHTTPClient: %v", err)
+	}
+
+	reset := make(chan struct{})
+	go func() {
+		ResetHTTPClient()
+		close(reset)
+	}()
+	select {
+	case <-reset:
+		t.Fatal("client reset while a request was still using it")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	release()
+	select {
+	case <-reset:
+	case <-time.After(5 * time.Second):
+		t.Fatal("reset still waiting after the request was released")
+	}
+	client, release, _ := getHTTPClient(advanced)
+	release()
+	if client == old {
+		t.Error("client not rebuilt after the reset")
+	}
+}
+
+const settingsChangeTemplate = `id: settings-change
+info:
+  name: Settings change
+  author: test
+  severity: info
+http:
+  - method: GET
+    path:
+      - "{{BaseURL}}/"
+    matchers:
+      - type: status
+        status:
+          - 200
+`
+
+// TestHTTPClientSettingsChangeDuringScans changes the connection settings and resets the shared client, as the
+// settings window does, while scans are sending requests. Run with -race
+func TestHTTPClientSettingsChangeDuringScans(t *testing.T) {
+	t.Cleanup(ResetHTTPClient)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(time.Millisecond)
+		io.WriteString(w, "ok")
+	}))
+	t.Cleanup(srv.Close)
+	tmpl := loadTestTemplate(t, settingsChangeTemplate)
+
+	var current atomic.Pointer[AdvancedSettingsChecker]
+	current.Store(testSettings())
+	applySettings := func(i int) {
+		advanced := testSettings()
+		advanced.MaxIdleConnsPerHost = 2 + i%3
+		advanced.DisableKeepAlives = i%2 == 0
+		current.Store(advanced)
+		ResetHTTPClient()
+	}
+
+	const scans, requests, changes = 8, 25, 20
+	var failed atomic.Int32
+	var wg sync.WaitGroup
+	for s := 0; s < scans; s++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < requests; i++ {
+				matched, _, err := matchTemplate(context.Background(), srv.URL, "", tmpl, nil, current.Load(), testLogger())
+				if err != nil || !matched {
+					failed.Add(1)
+				}
+			}
+		}()
+	}
+	for c := 0; c < 2; c++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < changes; i++ {
+				applySettings(c*changes + i)
+				time.Sleep(time.Millisecond)
+			}
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Minute):
+		t.Fatal("scans and settings changes deadlocked")
+	}
+	if n := failed.Load(); n > 0 {
+		t.Errorf("%d of %d requests failed while the settings changed", n, scans*requests)
+	}
+}
+
+const redirectLimitTemplate = `id: redirect-limit-%d
+info:
+  name: Redirect limit
+  author: test
+  severity: info
+http:
+  - method: GET
+%s    path:
+      - "{{BaseURL}}/hop/0"
+    matchers:
+      - type: status
+        status:
+          - %d
+    extractors:
+      - type: redirect-chain
+        name: chain
+`
+
+func TestRedirectLimit(t *testing.T) {
+	const hops = 10
+	var mu sync.Mutex
+	var sent int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		sent++
+		mu.Unlock()
+		var n int
+		fmt.Sscanf(r.URL.Path, "/hop/%d", &n)
+		if n < hops {
+			http.Redirect(w, r, fmt.Sprintf("/hop/%d", n+1), http.StatusFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	tests := []struct {
+		name       string
+		global     int
+		request    string
+		wantStatus int
+		wantHops   int
+	}{
+		{name: "global limit of 3", global: 3, wantStatus: http.StatusFound, wantHops: 3},
+		{name: "default limit", global: DefaultMaxHTTPRedirects, wantStatus: http.StatusFound, wantHops: DefaultMaxHTTPRedirects},
+		{name: "whole chain", global: 20, wantStatus: http.StatusOK, wantHops: hops},
+		{name: "request limit overrides", global: 20, request: "    max-redirects: 3\n", wantStatus: http.StatusFound, wantHops: 3},
+		{name: "request disables following", global: 20, request: "    max-redirects: 0\n", wantStatus: http.StatusFound},
+		{name: "global disables following", global: 0, wantStatus: http.StatusFound},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mu.Lock()
+			sent = 0
+			mu.Unlock()
+			advanced := testSettings()
+			advanced.MaxHTTPRedirects = tt.global
+			tmpl := loadTestTemplate(t, fmt.Sprintf(redirectLimitTemplate, i, tt.request, tt.wantStatus))
+			matched, extracted, err := matchTemplate(context.Background(), srv.URL, "", tmpl, nil, advanced, testLogger())
+			if err != nil || !matched {
+				t.Fatalf("matchTemplate = %v, %v, want status %d", matched, err, tt.wantStatus)
+			}
+			var chain []string
+			if extracted["chain"] != "" {
+				chain = strings.Split(extracted["chain"], ",")
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if len(chain) != tt.wantHops || sent != tt.wantHops+1 {
+				t.Errorf("followed %d redirects with %d requests, want %d with %d", len(chain), sent, tt.wantHops, tt.wantHops+1)
+			}
+		})
+	}
+}
+
+// BenchmarkHTTPClientPoolP99 sends requests from 200 goroutines to a local server through the shared client and
+// reports the 99th percentile latency with the Go default idle pool and with a pool sized for the workers
+func BenchmarkHTTPClientPoolP99(b *testing.B) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok")
+	}))
+	defer srv.Close()
+	b.Cleanup(ResetHTTPClient)
+
+	const goroutines = 200
+	for _, bc := range []struct {
+		name     string
+		advanced *AdvancedSettingsChecker
+	}{
+		{name: "default-pool", advanced: &AdvancedSettingsChecker{MaxIdleConnsPerHost: http.DefaultMaxIdleConnsPerHost}},
+		{name: "tuned-pool", advanced: &AdvancedSettingsChecker{MaxIdleConnsPerHost: goroutines}},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			var mu sync.Mutex
+			latencies := make([]time.Duration, 0, b.N)
+			work := make(chan struct{})
+			var wg sync.WaitGroup
+			for g := 0; g < goroutines; g++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for range work {
+						client, release, err := getHTTPClient(bc.advanced)
+						if err != nil {
+							b.Error(err)
+							return
+						}
+						start := time.Now()
+						resp, err := client.Get(srv.URL)
+						if err == nil {
+							io.Copy(io.Discard, resp.Body)
+							resp.Body.Close()
+						}
+						elapsed := time.Since(start)
+						release()
+						if err != nil {
+							b.Error(err)
+							return
+						}
+						mu.Lock()
+						latencies = append(latencies, elapsed)
+						mu.Unlock()
+					}
+				}()
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				work <- struct{}{}
+			}
+			close(work)
+			wg.Wait()
+			b.StopTimer()
+
+			if len(latencies) == 0 {
+				return
+			}
+			sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+			p99 := latencies[len(latencies)*99/100]
+			b.ReportMetric(float64(p99.Microseconds()), "p99-us")
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package templates
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// serveDNS starts a mock DNS server answering A queries for the names in records and installs it as the
+// resolver of the process until the test ends. The returned function lists the received "TYPE name" questions
+func serveDNS(t *testing.T, records map[string]net.IP) func() []string {
+	t.Helper()
+	var mu sync.Mutex
+	var questions []string
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			var msg dnsmessage.Message
+			if err := msg.Unpack(buf[:n]); err != nil || len(msg.Questions) == 0 {
+				continue
+			}
+			q := msg.Questions[0]
+			mu.Lock()
+			questions = append(questions, strings.TrimPrefix(q.Type.String(), "Type")+" "+strings.TrimSuffix(q.Name.String(), "."))
+			mu.Unlock()
+			msg.Header.Response = true
+			msg.Header.Authoritative = true
+			ip, ok := records[strings.TrimSuffix(q.Name.String(), ".")]
+			switch {
+			case !ok:
+				msg.Header.RCode = dnsmessage.RCodeNameError
+			case q.Type == dnsmessage.TypeA:
+				var a dnsmessage.AResource
+				copy(a.A[:], ip.To4())
+				msg.Answers = []dnsmessage.Resource{{
+					Header: dnsmessage.ResourceHeader{Name: q.Name, Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET, TTL: 60},
+					Body:   &a,
+				}}
+			}
+			resp, err := msg.Pack()
+			if err != nil {
+				continue
+			}
+			conn.WriteTo(resp, addr)
+		}
+	}()
+
+	resolver := net.DefaultResolver
+	net.DefaultResolver = &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "udp", conn.LocalAddr().String())
+		},
+	}
+	t.Cleanup(func() { net.DefaultResolver = resolver })
+	return func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), questions...)
+	}
+}
+
+func TestNormalizeIDN(t *testing.T) {
+	tests := map[string]string{
+		"http://hébergement.fr":           "http://xn--hbergement-b7a.fr",
+		"https://hébergement.fr:8443/a?b": "https://xn--hbergement-b7a.fr:8443/a?b",
+		"http://example.com/é":            "http://example.com/é",
+		"not a url":                       "not a url",
+	}
+	for in, want := range tests {
+		if got := NormalizeIDN(in); got != want {
+			t.Errorf("NormalizeIDN(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestIDNTargetResolves(t *testing.T) {
+	serveDNS(t, map[string]net.IP{"xn--hbergement-b7a.fr": net.IPv4(127, 0, 0, 1)})
+	var host string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host = r.Host
+		io.WriteString(w, "ok")
+	}))
+	defer srv.Close()
+	u, _ := url.Parse(srv.URL)
+	target := "http://hébergement.fr:" + u.Port()
+
+	tmpl := loadTestTemplate(t, `id: idn-target
+info:
+  name: IDN target
+  author: test
+  severity: info
+http:
+  - path:
+      - "/"
+    matchers:
+      - type: status
+        status: [200]
+`)
+
+	advanced := testSettings()
+	matched, _, err := MatchTemplate(context.Background(), target, "", tmpl, advanced, testLogger())
+	if err != nil || !matched {
+		t.Fatalf("MatchTemplate(%s) = %v, %v", target, matched, err)
+	}
+	if want := "xn--hbergement-b7a.fr:" + u.Port(); host != want {
+		t.Errorf("Host header = %q, want %q", host, want)
+	}
+}
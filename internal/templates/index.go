@@ -0,0 +1,167 @@
+// package templates - index of loaded templates
+package templates
+
+import (
+	"regexp"
+	"strings"
+)
+
+// cveIDRe matches CVE IDs mentioned in template tags and descriptions
+var cveIDRe = regexp.MustCompile(`(?i)CVE-\d{4}-\d+`)
+
+// TemplateIndex looks up loaded templates by ID, tag, severity and CVE ID
+type TemplateIndex struct {
+	templates  []*Template
+	position   map[*Template]int
+	byID       map[string]*Template
+	byTag      map[string][]*Template
+	bySeverity map[string][]*Template
+	byCVE      map[string][]*Template
+}
+
+// NewTemplateIndex indexes templates, lookups return templates in the order given here
+func NewTemplateIndex(templates []*Template) *TemplateIndex {
+	idx := &TemplateIndex{
+		templates:  templates,
+		position:   make(map[*Template]int, len(templates)),
+		byID:       make(map[string]*Template, len(templates)),
+		byTag:      make(map[string][]*Template),
+		bySeverity: make(map[string][]*Template),
+		byCVE:      make(map[string][]*Template),
+	}
+	for i, t := range templates {
+		idx.position[t] = i
+		idx.byID[t.ID] = t
+		seen := make(map[string]struct{})
+		for _, tag := range t.AllTags() {
+			key := indexKey(tag)
+			if _, ok := seen[key]; ok || key == "" {
+				continue
+			}
+			seen[key] = struct{}{}
+			idx.byTag[key] = append(idx.byTag[key], t)
+		}
+		sev := indexKey(t.SeverityLevel())
+		idx.bySeverity[sev] = append(idx.bySeverity[sev], t)
+		for _, cve := range templateCVEs(t) {
+			idx.byCVE[cve] = append(idx.byCVE[cve], t)
+		}
+	}
+	return idx
+}
+
+// templateCVEs returns the lowercased CVE IDs of the template from info cve-id, tags and description
+func templateCVEs(t *Template) []string {
+	seen := make(map[string]struct{})
+	var cves []string
+	add := func(id string) {
+		key := indexKey(id)
+		if _, ok := seen[key]; ok || key == "" {
+			return
+		}
+		seen[key] = struct{}{}
+		cves = append(cves, key)
+	}
+	add(t.Info.CVEID)
+	for _, tag := range t.AllTags() {
+		for _, id := range cveIDRe.FindAllString(tag, -1) {
+			add(id)
+		}
+	}
+	for _, id := range cveIDRe.FindAllString(t.DescriptionText(), -1) {
+		add(id)
+	}
+	return cves
+}
+
+// All returns every indexed template
+func (i *TemplateIndex) All() []*Template {
+	return i.templates
+}
+
+// ByID returns the template with the given ID or nil
+func (i *TemplateIndex) ByID(id string) *Template {
+	return i.byID[id]
+}
+
+// ByTag returns the templates tagged with tag, case-insensitively
+func (i *TemplateIndex) ByTag(tag string) []*Template {
+	return i.byTag[indexKey(tag)]
+}
+
+// BySeverity returns the templates with the given severity, case-insensitively
+func (i *TemplateIndex) BySeverity(sev string) []*Template {
+	return i.bySeverity[indexKey(sev)]
+}
+
+// ByCVE returns the first template for the CVE ID, case-insensitively, or nil
+func (i *TemplateIndex) ByCVE(cveID string) *Template {
+	if found := i.byCVE[indexKey(cveID)]; len(found) > 0 {
+		return found[0]
+	}
+	return nil
+}
+
+// AllByCVE returns every template for the CVE ID, case-insensitively
+func (i *TemplateIndex) AllByCVE(cveID string) []*Template {
+	return i.byCVE[indexKey(cveID)]
+}
+
+// ByTags returns the templates tagged with any of tags, an empty list returns every template
+func (i *TemplateIndex) ByTags(tags []string) []*Template {
+	if len(tags) == 0 {
+		return i.templates
+	}
+	var lists [][]*Template
+	for _, tag := range tags {
+		lists = append(lists, i.ByTag(tag))
+	}
+	return i.union(lists)
+}
+
+// BySeverities returns the templates with any of severities, an empty list returns every template
+func (i *TemplateIndex) BySeverities(severities []string) []*Template {
+	if len(severities) == 0 {
+		return i.templates
+	}
+	var lists [][]*Template
+	for _, sev := range severities {
+		lists = append(lists, i.BySeverity(sev))
+	}
+	return i.union(lists)
+}
+
+// Search returns the templates whose name or description contains query, case-insensitively
+func (i *TemplateIndex) Search(query string) []*Template {
+	query = strings.ToLower(strings.TrimSpace(query))
+	var found []*Template
+	for _, t := range i.templates {
+		if strings.Contains(strings.ToLower(t.Info.Name), query) ||
+			strings.Contains(strings.ToLower(t.DescriptionText()), query) {
+			found = append(found, t)
+		}
+	}
+	return found
+}
+
+// union merges the lists without duplicates, keeping the index order
+func (i *TemplateIndex) union(lists [][]*Template) []*Template {
+	selected := make([]bool, len(i.templates))
+	for _, list := range lists {
+		for _, t := range list {
+			selected[i.position[t]] = true
+		}
+	}
+	var merged []*Template
+	for pos, ok := range selected {
+		if ok {
+			merged = append(merged, i.templates[pos])
+		}
+	}
+	return merged
+}
+
+// indexKey normalizes tags and severities for lookups
+func indexKey(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
@@ -0,0 +1,319 @@
+package templates
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeTaggedTemplates writes one template per entry, named after its index, with the given info fields
+func writeTaggedTemplates(t *testing.T, infos []string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for i, info := range infos {
+		writeTestFile(t, dir, fmt.Sprintf("t%02d.yaml", i), fmt.Sprintf(`id: t%02d
+info:
+  name: Template %d
+  author: test
+%s
+http:
+  - path:
+      - "{{BaseURL}}/%d"
+    matchers:
+      - type: status
+        status: [200]
+`, i, i, info, i))
+	}
+	return dir
+}
+
+func templateIDs(tmpls []*Template) string {
+	ids := make([]string, 0, len(tmpls))
+	for _, tmpl := range tmpls {
+		ids = append(ids, tmpl.ID)
+	}
+	sort.Strings(ids)
+	return strings.Join(ids, ",")
+}
+
+func TestLoadTemplatesTagFilter(t *testing.T) {
+	infos := []string{
+		"  severity: high\n  tags: cve,rce",
+		"  severity: high\n  tags: [cve, sqli]",
+		"  severity: low\n  tags: CVE",
+		"  severity: info\n  tags: tech",
+		"  severity: info\n  tags: [misconfig]",
+		"  severity: info",
+		"  severity: low\n  tags: exposure",
+		"  severity: low\n  tags: rce",
+		"  severity: info\n  tags: panel,login",
+		"  severity: info\n  tags: cves",
+	}
+	dir := writeTaggedTemplates(t, infos)
+
+	tests := []struct {
+		name   string
+		filter []string
+		want   string
+	}{
+		{name: "no filter", filter: nil, want: "t00,t01,t02,t03,t04,t05,t06,t07,t08,t09"},
+		{name: "cve", filter: []string{"cve"}, want: "t00,t01,t02"},
+		{name: "any of several tags", filter: []string{"rce", "panel"}, want: "t00,t07,t08"},
+		{name: "unknown tag", filter: []string{"xss"}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpls, _, err := LoadTemplates(dir, tt.filter, false, testSettings(), nil)
+			if err != nil {
+				t.Fatalf("LoadTemplates: %v", err)
+			}
+			if got := templateIDs(tmpls); got != tt.want {
+				t.Errorf("LoadTemplates(tags %v) = %s, want %s", tt.filter, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindMatchingTemplatesSeverityFilter(t *testing.T) {
+	_, srv := newRequestRecorder(t, "/0", "/1", "/2")
+	dir := writeTaggedTemplates(t, []string{"  severity: critical", "  severity: high", "  severity: low"})
+
+	tests := []struct {
+		name   string
+		filter []string
+		want   string
+	}{
+		{name: "critical", filter: []string{"critical"}, want: "t00"},
+		{name: "case insensitive", filter: []string{"CRITICAL", "High"}, want: "t00,t01"},
+		{name: "no filter", filter: nil, want: "t00,t01,t02"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings, err := FindMatchingTemplates(context.Background(), srv.URL, dir, nil, tt.filter, 10*time.Second,
+				testSettings(), testLogger(), func(i, total int) {})
+			if err != nil {
+				t.Fatalf("FindMatchingTemplates: %v", err)
+			}
+			ids := make([]string, 0, len(findings))
+			for _, f := range findings {
+				ids = append(ids, f.TemplateID)
+			}
+			sort.Strings(ids)
+			if got := strings.Join(ids, ","); got != tt.want {
+				t.Errorf("findings with severity filter %v = %s, want %s", tt.filter, got, tt.want)
+			}
+		})
+	}
+}
+
+// writeHostTemplates writes n templates, every tenth without a hosts list and the others restricted to another host.
+// Their matchers are checked against the page HTML, loading them dominates the cost of a scan
+func writeHostTemplates(tb testing.TB, n int) string {
+	tb.Helper()
+	dir := tb.TempDir()
+	for i := 0; i < n; i++ {
+		hosts := "hosts:\n  - intranet.example.com\n"
+		if i%10 == 0 {
+			hosts = ""
+		}
+		writeTestFile(tb, dir, fmt.Sprintf("h%04d.yaml", i), fmt.Sprintf(`id: h%04d
+%sinfo:
+  name: Host template %d
+  author: test
+  severity: low
+  tags: exposure,config
+http:
+  - method: GET
+    path:
+      - "{{BaseURL}}/h%04d"
+    headers:
+      User-Agent: scanner
+    matchers-condition: and
+    matchers:
+      - type: word
+        part: body
+        words: ["secret", "password"]
+      - type: regex
+        regex:
+          - 'key=([a-z0-9]+)'
+`, i, hosts, i, i))
+	}
+	return dir
+}
+
+func TestQuickLoadTemplate(t *testing.T) {
+	dir := writeHostTemplates(t, 2)
+	restricted, err := quickLoadTemplate(dir + "/h0001.yaml")
+	if err != nil {
+		t.Fatalf("quickLoadTemplate: %v", err)
+	}
+	if restricted.ID != "h0001" || len(restricted.Hosts) != 1 || restricted.Hosts[0] != "intranet.example.com" {
+		t.Errorf("quickLoadTemplate = %+v", restricted)
+	}
+	if _, err := quickLoadTemplate(writeTestFile(t, dir, "broken.yaml", "id: [")); err == nil {
+		t.Error("quickLoadTemplate parsed an invalid template")
+	}
+}
+
+func TestLoadTemplatesForHost(t *testing.T) {
+	dir := writeHostTemplates(t, 20)
+	tests := []struct {
+		host string
+		want string
+	}{
+		{host: "", want: "all"},
+		{host: "www.example.org", want: "h0000,h0010"},
+		{host: "intranet.example.com", want: "all"},
+	}
+	for _, tt := range tests {
+		tmpls, err := loadTemplatesForHost(dir, tt.host, nil, false, nil, nil)
+		if err != nil {
+			t.Fatalf("loadTemplatesForHost(%q): %v", tt.host, err)
+		}
+		if tt.want == "all" {
+			if len(tmpls) != 20 {
+				t.Errorf("loadTemplatesForHost(%q) loaded %d templates, want 20", tt.host, len(tmpls))
+			}
+			continue
+		}
+		if got := templateIDs(tmpls); got != tt.want {
+			t.Errorf("loadTemplatesForHost(%q) = %s, want %s", tt.host, got, tt.want)
+		}
+	}
+}
+
+// BenchmarkFindMatchingTemplates scans a local target with 1000 templates of which 900 are restricted to another
+// host. The host prefilter parses only the id and hosts of those, the full parse loads every template first
+func BenchmarkFindMatchingTemplates(b *testing.B) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+	dir := writeHostTemplates(b, 1000)
+	advanced := testSettings()
+	advanced.PortCheckTimeout = 0
+	advanced.EnableDeduplication = false
+
+	b.Run("host-prefilter", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := FindMatchingTemplates(context.Background(), srv.URL, dir, nil, nil, 5*time.Second,
+				advanced, testLogger(), func(i, total int) {}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("full-parse", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			tmpls, _, err := LoadTemplates(dir, nil, false, advanced, nil)
+			if err != nil {
+				b.Fatal(err)
+			}
+			var kept []*Template
+			for _, tmpl := range tmpls {
+				if tmpl.MatchesHost("127.0.0.1") {
+					kept = append(kept, tmpl)
+				}
+			}
+			if _, err := ScanTemplates(context.Background(), srv.URL, kept, nil, 5*time.Second, advanced, testLogger(), func(i, total int) {}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// indexCorpus returns 20 templates cycling through the severities, with tags and descriptions on every few
+func indexCorpus() []*Template {
+	severities := []string{"critical", "high", "medium", "low", "info"}
+	tmpls := make([]*Template, 20)
+	for i := range tmpls {
+		tmpl := &Template{ID: fmt.Sprintf("idx%02d", i)}
+		tmpl.Info.Name = fmt.Sprintf("Template %02d", i)
+		tmpl.Info.Severity = severities[i%len(severities)]
+		tmpl.Info.Tags = Tags{"web"}
+		if i%4 == 0 {
+			tmpl.Info.Tags = append(tmpl.Info.Tags, "sqli")
+		}
+		if i%6 == 0 {
+			tmpl.Info.Tags = append(tmpl.Info.Tags, "RCE")
+		}
+		if i%7 == 0 {
+			tmpl.Info.Description = "Exposed Admin Panel"
+		}
+		tmpls[i] = tmpl
+	}
+	return tmpls
+}
+
+// orderedIDs joins the template IDs in the order returned
+func orderedIDs(tmpls []*Template) string {
+	ids := make([]string, 0, len(tmpls))
+	for _, tmpl := range tmpls {
+		ids = append(ids, tmpl.ID)
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestTemplateIndex(t *testing.T) {
+	idx := NewTemplateIndex(indexCorpus())
+
+	if got := idx.ByID("idx07"); got == nil || got.Info.Name != "Template 07" {
+		t.Errorf("ByID(idx07) = %+v", got)
+	}
+	if got := idx.ByID("missing"); got != nil {
+		t.Errorf("ByID(missing) = %s, want nil", got.ID)
+	}
+	if got := len(idx.All()); got != 20 {
+		t.Errorf("All() = %d templates, want 20", got)
+	}
+
+	tests := []struct {
+		name string
+		got  []*Template
+		want string
+	}{
+		{name: "ByTag", got: idx.ByTag("sqli"), want: "idx00,idx04,idx08,idx12,idx16"},
+		{name: "ByTag case insensitive", got: idx.ByTag(" rce "), want: "idx00,idx06,idx12,idx18"},
+		{name: "ByTag unknown", got: idx.ByTag("xss"), want: ""},
+		{name: "BySeverity", got: idx.BySeverity("CRITICAL"), want: "idx00,idx05,idx10,idx15"},
+		{name: "BySeverity unknown", got: idx.BySeverity("urgent"), want: ""},
+		{name: "ByTags union", got: idx.ByTags([]string{"rce", "sqli"}), want: "idx00,idx04,idx06,idx08,idx12,idx16,idx18"},
+		{name: "BySeverities union", got: idx.BySeverities([]string{"low", "critical"}), want: "idx00,idx03,idx05,idx08,idx10,idx13,idx15,idx18"},
+		{name: "Search description", got: idx.Search("admin PANEL"), want: "idx00,idx07,idx14"},
+		{name: "Search name", got: idx.Search("template 1"), want: "idx10,idx11,idx12,idx13,idx14,idx15,idx16,idx17,idx18,idx19"},
+		{name: "Search no match", got: idx.Search("wordpress"), want: ""},
+	}
+	for _, tt := range tests {
+		if got := orderedIDs(tt.got); got != tt.want {
+			t.Errorf("%s = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTemplateIndexByCVE(t *testing.T) {
+	dir := writeTaggedTemplates(t, []string{
+		"  severity: critical\n  cve-id: CVE-2021-44228\n  cvss-score: 10.0",
+		"  severity: critical\n  tags: cve,cve-2021-44228,log4j",
+		"  severity: high\n  description: Detects CVE-2022-22965 (Spring4Shell) and CVE-2021-44228 probes",
+		"  severity: info\n  tags: cve2021",
+	})
+	tmpls, idx, err := LoadTemplates(dir, nil, false, testSettings(), nil)
+	if err != nil || len(tmpls) != 4 {
+		t.Fatalf("LoadTemplates = %d templates, %v", len(tmpls), err)
+	}
+
+	if got := idx.ByCVE("cve-2021-44228"); got == nil || got.ID != "t00" {
+		t.Errorf("ByCVE(cve-2021-44228) = %v, want t00", got)
+	}
+	if got := orderedIDs(idx.AllByCVE("CVE-2021-44228")); got != "t00,t01,t02" {
+		t.Errorf("AllByCVE(CVE-2021-44228) = %s, want t00,t01,t02", got)
+	}
+	if got := idx.ByCVE("CVE-2022-22965"); got == nil || got.ID != "t02" {
+		t.Errorf("ByCVE(CVE-2022-22965) = %v, want t02", got)
+	}
+	if got := idx.ByCVE("CVE-2020-0001"); got != nil {
+		t.Errorf("ByCVE of an unknown CVE = %s, want nil", got.ID)
+	}
+}
@@ -0,0 +1,46 @@
+// package templates - internal IP address disclosure detection
+package templates
+
+import (
+	"net/http"
+	"regexp"
+)
+
+// internalIPRe matches RFC 1918 private IPv4 addresses
+var internalIPRe = regexp.MustCompile(`\b(10\.\d+\.\d+\.\d+|172\.(1[6-9]|2\d|3[0-1])\.\d+\.\d+|192\.168\.\d+\.\d+)\b`)
+
+// matchInternalIP reports whether the response body or header values disclose a private IP address.
+// With excludeRequestIPs, addresses sent in the request headers are ignored
+func matchInternalIP(resp *http.Response, body []byte, excludeRequestIPs bool) bool {
+	excluded := make(map[string]struct{})
+	if excludeRequestIPs && resp.Request != nil {
+		for _, values := range resp.Request.Header {
+			for _, v := range values {
+				for _, ip := range internalIPRe.FindAllString(v, -1) {
+					excluded[ip] = struct{}{}
+				}
+			}
+		}
+	}
+
+	found := func(text string) bool {
+		for _, ip := range internalIPRe.FindAllString(text, -1) {
+			if _, ok := excluded[ip]; !ok {
+				return true
+			}
+		}
+		return false
+	}
+
+	if found(string(body)) {
+		return true
+	}
+	for _, values := range resp.Header {
+		for _, v := range values {
+			if found(v) {
+				return true
+			}
+		}
+	}
+	return false
+}
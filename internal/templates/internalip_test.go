@@ -0,0 +1,45 @@
+package templates
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestMatchInternalIP(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		header  http.Header
+		exclude bool
+		sent    string
+		want    bool
+	}{
+		{name: "10/8 in body", body: `{"upstream":"10.0.12.7:8080"}`, want: true},
+		{name: "172.16/12 in body", body: "backend 172.31.255.1 unreachable", want: true},
+		{name: "192.168/16 in body", body: "<!-- built on 192.168.1.20 -->", want: true},
+		{name: "private ip in header", header: http.Header{"X-Backend-Server": {"192.168.0.5"}}, want: true},
+		{name: "public addresses", body: "client 8.8.8.8, proxy 172.15.0.1, cdn 172.32.0.1", want: false},
+		{name: "version numbers", body: "release 110.0.0.1 build 210.10.0.0", want: false},
+		{name: "no address", body: "<html><body>Welcome</body></html>", want: false},
+		{name: "scanner header reflected", body: "forwarded for 10.1.2.3", exclude: true, sent: "10.1.2.3", want: false},
+		{name: "reflected header kept without exclusion", body: "forwarded for 10.1.2.3", sent: "10.1.2.3", want: true},
+		{name: "other address with exclusion", body: "forwarded for 10.1.2.3 via 10.9.9.9", exclude: true, sent: "10.1.2.3", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, "http://example.com/", nil)
+			if tt.sent != "" {
+				req.Header.Set("X-Forwarded-For", tt.sent)
+			}
+			header := tt.header
+			if header == nil {
+				header = http.Header{}
+			}
+			resp := &http.Response{StatusCode: http.StatusOK, Header: header, Request: req}
+			m := Matcher{Type: "internal-ip", ExcludeRequestIPs: tt.exclude}
+			if got := checkSingleMatcher(m, MatchContext{Resp: resp, Body: []byte(tt.body)}); got != tt.want {
+				t.Errorf("internal-ip matcher = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
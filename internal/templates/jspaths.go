@@ -0,0 +1,124 @@
+// package templates - discovery of API paths referenced from page JavaScript
+package templates
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// jsPathRe matches absolute paths inside JavaScript source
+var jsPathRe = regexp.MustCompile(`/[a-zA-Z0-9_/-]{3,60}`)
+
+// hasJSPathsExtractor reports whether any extractor needs the paths found in page scripts
+func hasJSPathsExtractor(extractors []Extractor) bool {
+	for _, e := range extractors {
+		if e.Type == "js-paths" {
+			return true
+		}
+	}
+	return false
+}
+
+// DiscoverJSPaths collects paths from inline scripts of the page and from up to maxFiles external scripts
+func DiscoverJSPaths(ctx context.Context, pageURL, pageHTML string, maxFiles int, client *http.Client) []string {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return nil
+	}
+	inline, srcs := collectScripts(pageHTML)
+
+	seen := make(map[string]struct{})
+	for _, code := range inline {
+		addJSPaths(code, seen)
+	}
+	for i, src := range srcs {
+		if maxFiles > 0 && i >= maxFiles {
+			break
+		}
+		ref, err := url.Parse(src)
+		if err != nil {
+			continue
+		}
+		code, err := fetchScript(ctx, base.ResolveReference(ref).String(), client)
+		if err != nil {
+			continue
+		}
+		addJSPaths(code, seen)
+	}
+
+	paths := make([]string, 0, len(seen))
+	for p := range seen {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// collectScripts returns the bodies of inline scripts and the src attributes of external ones
+func collectScripts(pageHTML string) (inline []string, srcs []string) {
+	doc, err := html.Parse(strings.NewReader(pageHTML))
+	if err != nil {
+		return nil, nil
+	}
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == "script" {
+			src := ""
+			for _, attr := range n.Attr {
+				if attr.Key == "src" {
+					src = strings.TrimSpace(attr.Val)
+				}
+			}
+			if src != "" {
+				srcs = append(srcs, src)
+			} else if n.FirstChild != nil && n.FirstChild.Type == html.TextNode {
+				inline = append(inline, n.FirstChild.Data)
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	return inline, srcs
+}
+
+// fetchScript downloads a JavaScript file
+func fetchScript(ctx context.Context, scriptURL string, client *http.Client) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, scriptURL, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("script request returned status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// addJSPaths adds the paths found in code to seen, skipping protocol-relative URLs and comments starting with //
+func addJSPaths(code string, seen map[string]struct{}) {
+	for _, loc := range jsPathRe.FindAllStringIndex(code, -1) {
+		match := code[loc[0]:loc[1]]
+		if strings.HasPrefix(match, "//") || (loc[0] > 0 && code[loc[0]-1] == '/') {
+			continue
+		}
+		seen[match] = struct{}{}
+	}
+}
@@ -0,0 +1,74 @@
+package templates
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+const appJS = `// api client, assets come from //static-cdn
+const api = {
+  users: "/api/v1/users",
+  orders: '/api/v1/orders/list',
+};
+axios.post(` + "`/internal/admin-tools`" + `);
+const half = total / 2;
+fetch("/a");
+`
+
+func TestDiscoverJSPaths(t *testing.T) {
+	var lateFetched atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/static/app.js":
+			fmt.Fprint(w, appJS)
+		case "/static/late.js":
+			lateFetched.Store(true)
+			fmt.Fprint(w, `fetch("/api/late")`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	page := `<html><head>
+<script src="static/app.js"></script>
+<script src="/missing.js"></script>
+<script src="` + srv.URL + `/static/late.js"></script>
+</head><body><script>const status = "/api/v2/status";</script></body></html>`
+
+	paths := DiscoverJSPaths(context.Background(), srv.URL+"/", page, 2, srv.Client())
+	want := []string{"/api/v1/orders/list", "/api/v1/users", "/api/v2/status", "/internal/admin-tools"}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("DiscoverJSPaths = %v, want %v", paths, want)
+	}
+	if lateFetched.Load() {
+		t.Error("script beyond MaxJSFiles was fetched")
+	}
+
+	paths = DiscoverJSPaths(context.Background(), srv.URL+"/", page, 0, srv.Client())
+	if len(paths) != len(want)+1 || !lateFetched.Load() {
+		t.Errorf("without a limit DiscoverJSPaths = %v, want every script fetched", paths)
+	}
+}
+
+func TestJSPathsExtractor(t *testing.T) {
+	paths := []string{"/api/v1/users", "/api/v2/status"}
+	extractors := []Extractor{{Type: "js-paths", Name: "endpoints"}}
+
+	values := processExtractors(extractors, MatchContext{Headless: &HeadlessResponse{DiscoveredPaths: paths}})
+	if got, ok := values["endpoints"].([]string); !ok || !reflect.DeepEqual(got, paths) {
+		t.Fatalf("js-paths value = %#v, want %v", values["endpoints"], paths)
+	}
+	if got := extractedString(values["endpoints"]); got != "/api/v1/users,/api/v2/status" {
+		t.Errorf("reported value = %q", got)
+	}
+
+	if values := processExtractors(extractors, MatchContext{Headless: &HeadlessResponse{}}); len(values) != 0 {
+		t.Errorf("js-paths without discovered paths = %v", values)
+	}
+}
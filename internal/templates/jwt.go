@@ -0,0 +1,62 @@
+// package templates - extraction of JWT claims from responses
+package templates
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// jwtRe matches the header, payload and optional signature segments of a compact JWT
+var jwtRe = regexp.MustCompile(`[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)
+
+// extractJWTClaim returns the claim at claimPath (header.alg, payload.sub, ...) of the first JWT in text.
+// Dotted strings whose segments don't decode to JSON objects are skipped
+func extractJWTClaim(text, claimPath string) (string, bool) {
+	section, path, ok := strings.Cut(claimPath, ".")
+	if !ok || path == "" {
+		return "", false
+	}
+	var idx int
+	switch section {
+	case "header":
+		idx = 0
+	case "payload":
+		idx = 1
+	default:
+		return "", false
+	}
+	for _, token := range jwtRe.FindAllString(text, -1) {
+		segments := strings.Split(token, ".")
+		header, ok := decodeJWTSegment(segments[0])
+		if !ok {
+			continue
+		}
+		payload, ok := decodeJWTSegment(segments[1])
+		if !ok {
+			continue
+		}
+		segment := [][]byte{header, payload}[idx]
+		// numeric dates like exp and iat would otherwise be formatted in exponent notation
+		if v, ok := getJSONValue(segment, path).(float64); ok {
+			return strconv.FormatFloat(v, 'f', -1, 64), true
+		}
+		return extractJSON(segment, path)
+	}
+	return "", false
+}
+
+// decodeJWTSegment decodes a base64url segment and reports whether it holds a JSON object
+func decodeJWTSegment(segment string) ([]byte, bool) {
+	bs, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(segment, "="))
+	if err != nil {
+		return nil, false
+	}
+	var obj map[string]any
+	if err := json.Unmarshal(bs, &obj); err != nil {
+		return nil, false
+	}
+	return bs, true
+}
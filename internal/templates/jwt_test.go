@@ -0,0 +1,51 @@
+package templates
+
+import (
+	"encoding/base64"
+	"net/http"
+	"testing"
+)
+
+// noneJWT is an unsigned token with the header {"alg":"none","typ":"JWT"}
+var noneJWT = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","typ":"JWT"}`)) + "." +
+	base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"admin","exp":1893456000}`)) + "."
+
+func TestExtractJWTClaim(t *testing.T) {
+	tests := []struct {
+		text      string
+		claimPath string
+		want      string
+		wantOK    bool
+	}{
+		{text: "token=" + noneJWT, claimPath: "header.alg", want: "none", wantOK: true},
+		{text: noneJWT, claimPath: "payload.sub", want: "admin", wantOK: true},
+		{text: noneJWT, claimPath: "payload.exp", want: "1893456000", wantOK: true},
+		{text: "version 1.2.3 then " + noneJWT, claimPath: "header.alg", want: "none", wantOK: true},
+		{text: noneJWT, claimPath: "payload.role"},
+		{text: noneJWT, claimPath: "signature.alg"},
+		{text: "no token here", claimPath: "header.alg"},
+	}
+	for _, tt := range tests {
+		got, ok := extractJWTClaim(tt.text, tt.claimPath)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("extractJWTClaim(%q, %q) = %q, %v, want %q, %v", tt.text, tt.claimPath, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestJWTExtractor(t *testing.T) {
+	resp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{"Authorization": {"Bearer " + noneJWT}}}
+	ctx := MatchContext{Resp: resp, Body: []byte(`{"access_token":"` + noneJWT + `"}`)}
+
+	values := processExtractors([]Extractor{
+		{Type: "jwt", Name: "body_alg", ClaimPath: "header.alg"},
+		{Type: "jwt", Name: "header_alg", Part: "header", ClaimPath: "header.alg"},
+		{Type: "jwt", Name: "missing", ClaimPath: "payload.role"},
+	}, ctx)
+	if values["body_alg"] != "none" || values["header_alg"] != "none" {
+		t.Errorf("jwt extractors = %v, want alg none from the body and the header", values)
+	}
+	if _, ok := values["missing"]; ok {
+		t.Errorf("missing claim extracted as %v", values["missing"])
+	}
+}
@@ -0,0 +1,11 @@
+// package templates - lint checks for templates that load but are likely mistaken
+package templates
+
+// LintTemplate returns warnings about the template that don't prevent it from loading
+func LintTemplate(tmpl *Template) []string {
+	var warnings []string
+	if tmpl.Info.CVEID != "" && tmpl.Info.CVSSScore == 0 {
+		warnings = append(warnings, "cve-id "+tmpl.Info.CVEID+" is set but cvss-score is zero")
+	}
+	return warnings
+}
@@ -0,0 +1,41 @@
+package templates
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const lintTemplate = `id: cve-fixture
+info:
+  name: CVE fixture
+  author: test
+  severity: critical
+%s
+http:
+  - path:
+      - "{{BaseURL}}/"
+    matchers:
+      - type: status
+        status: [200]
+`
+
+func TestLintTemplate(t *testing.T) {
+	tests := []struct {
+		name string
+		info string
+		want string
+	}{
+		{name: "cve without cvss", info: "  cve-id: CVE-2021-44228", want: "cve-id CVE-2021-44228 is set but cvss-score is zero"},
+		{name: "cve with cvss", info: "  cve-id: CVE-2021-44228\n  cvss-score: 10.0"},
+		{name: "no cve", info: "  tags: misconfig"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl := loadTestTemplate(t, fmt.Sprintf(lintTemplate, tt.info))
+			if got := strings.Join(LintTemplate(tmpl), "; "); got != tt.want {
+				t.Errorf("LintTemplate = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
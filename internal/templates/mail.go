@@ -0,0 +1,103 @@
+// package templates - IMAP and POP3 banner grabbing
+package templates
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/artnikel/nuclei/internal/constants"
+	"github.com/artnikel/nuclei/internal/logging"
+)
+
+// mailPorts holds the default plain and TLS ports of the mail protocols
+var mailPorts = map[string][2]string{
+	"imap": {"143", "993"},
+	"pop3": {"110", "995"},
+}
+
+// mailGreetings holds the prefix of a successful greeting of the mail protocols
+var mailGreetings = map[string]string{
+	"imap": "* OK",
+	"pop3": "+OK",
+}
+
+// matchMailRequest connects to the IMAP or POP3 server of the host, reads its greeting and matches it with
+// the network matchers. Options: tls (bool) switches to implicit TLS, port overrides the default port
+func matchMailRequest(ctx context.Context, host string, req *Request, tmpl *Template, advanced *AdvancedSettingsChecker, logger *logging.Logger) (bool, error) {
+	ports, ok := mailPorts[req.Type]
+	if !ok {
+		return false, fmt.Errorf("request type is not a mail protocol: %s", req.Type)
+	}
+
+	useTLS := fmt.Sprint(req.Options["tls"]) == "true"
+	port := ports[0]
+	if useTLS {
+		port = ports[1]
+	}
+	if portVal, ok := req.Options["port"]; ok {
+		port = fmt.Sprint(portVal)
+	}
+	addr := net.JoinHostPort(host, port)
+
+	if advanced.DryRun {
+		logDryRun(advanced, logger, "Would connect: "+req.Type+" "+addr)
+		return false, nil
+	}
+
+	banner, err := readMailGreeting(ctx, addr, host, useTLS)
+	if err != nil {
+		return false, err
+	}
+	if !strings.HasPrefix(banner, mailGreetings[req.Type]) {
+		logger.Info("Unexpected mail server greeting",
+			slog.String("template_id", tmpl.ID),
+			slog.String("address", addr),
+			slog.String("greeting", banner),
+		)
+	}
+
+	matchCtx := MatchContext{
+		Network: &NetworkResponse{
+			Data: []byte(banner),
+		},
+	}
+	matched := checkMatchers(req.Matchers, req.MatchersCondition, matchCtx)
+
+	logger.Info("Mail request matched",
+		slog.String("template_id", tmpl.ID),
+		slog.String("host", host),
+		slog.String("protocol", req.Type),
+		slog.Bool("matched", matched),
+	)
+	return matched, nil
+}
+
+// readMailGreeting dials addr, over TLS if useTLS, and returns the first line sent by the server
+func readMailGreeting(ctx context.Context, addr, host string, useTLS bool) (string, error) {
+	var conn net.Conn
+	var err error
+	if useTLS {
+		dialer := &tls.Dialer{Config: &tls.Config{InsecureSkipVerify: true, ServerName: host}}
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	} else {
+		dialer := &net.Dialer{}
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	conn.SetReadDeadline(time.Now().Add(constants.FiveSecTimeout))
+	line, err := bufio.NewReaderSize(conn, 4096).ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
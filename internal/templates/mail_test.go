@@ -0,0 +1,91 @@
+package templates
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+)
+
+// serveGreeting accepts connections on l and sends greeting to each of them
+func serveGreeting(t *testing.T, l net.Listener, greeting string) string {
+	t.Helper()
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			io.WriteString(conn, greeting)
+			conn.Close()
+		}
+	}()
+	_, port, _ := net.SplitHostPort(l.Addr().String())
+	return port
+}
+
+const mailTemplate = `id: mail-banner-%d
+info:
+  name: Mail banner
+  author: test
+  severity: info
+http:
+  - type: %s
+    options:
+      port: %s
+      tls: %v
+    matchers:
+      - type: word
+        words:
+          - "%s"
+`
+
+func TestMailBannerRequests(t *testing.T) {
+	plain := func(t *testing.T) net.Listener {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return l
+	}
+	withTLS := func(t *testing.T) net.Listener {
+		l, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{newTestCert(t)}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return l
+	}
+
+	tests := []struct {
+		name     string
+		protocol string
+		listen   func(t *testing.T) net.Listener
+		tls      bool
+		greeting string
+		word     string
+		want     bool
+	}{
+		{name: "imap dovecot", protocol: "imap", listen: plain, greeting: "* OK [CAPABILITY IMAP4rev1 STARTTLS AUTH=PLAIN] Dovecot (Ubuntu) ready.\r\n", word: "Dovecot", want: true},
+		{name: "imap other server", protocol: "imap", listen: plain, greeting: "* OK Courier-IMAP ready.\r\n", word: "Dovecot", want: false},
+		{name: "imaps", protocol: "imap", listen: withTLS, tls: true, greeting: "* OK The Microsoft Exchange IMAP4 service is ready.\r\n", word: "Microsoft Exchange", want: true},
+		{name: "pop3 exchange", protocol: "pop3", listen: plain, greeting: "+OK The Microsoft Exchange POP3 service is ready.\r\n", word: "Microsoft Exchange", want: true},
+		{name: "pop3 dovecot", protocol: "pop3", listen: plain, greeting: "+OK Dovecot ready.\r\n", word: "Dovecot", want: true},
+		{name: "pop3s", protocol: "pop3", listen: withTLS, tls: true, greeting: "+OK Dovecot ready.\r\n", word: "Exchange", want: false},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			port := serveGreeting(t, tt.listen(t), tt.greeting)
+			tmpl := loadTestTemplate(t, fmt.Sprintf(mailTemplate, i, tt.protocol, port, tt.tls, tt.word))
+			matched, _, err := matchTemplate(context.Background(), "http://127.0.0.1", "", tmpl, nil, testSettings(), testLogger())
+			if err != nil {
+				t.Fatalf("matchTemplate: %v", err)
+			}
+			if matched != tt.want {
+				t.Errorf("%s banner matched = %v, want %v", tt.protocol, matched, tt.want)
+			}
+		})
+	}
+}
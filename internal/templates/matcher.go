@@ -6,7 +6,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"regexp"
 	"strconv"
 	"strings"
 
@@ -94,8 +93,11 @@ func matchXPathByPart(body []byte, xpathExpr string) bool {
 	if err != nil {
 		return false
 	}
-	nodes := htmlquery.Find(doc, xpathExpr)
-	return len(nodes) > 0
+	expr, err := getCompiledXPath(xpathExpr)
+	if err != nil {
+		return false
+	}
+	return htmlquery.QuerySelector(doc, expr) != nil
 }
 
 // getJSONValue retrieves a value from JSON at path
@@ -209,7 +211,7 @@ func matchRegexListByPart(resp *http.Response, body []byte, regexList []string,
 		if noCase {
 			prefix = "(?i)"
 		}
-		re, err := regexp.Compile(prefix + regexStr)
+		re, err := getCompiledRegex(prefix + regexStr)
 		if err != nil {
 			continue 
 		}
@@ -281,7 +283,7 @@ func matchHeadlessByPattern(resp *HeadlessResponse, m Matcher) bool {
 	// check if any regex pattern from matcher matches HTML
 	if len(m.Regex) > 0 {
 		for _, pattern := range m.Regex {
-			re, err := regexp.Compile(pattern)
+			re, err := getCompiledRegex(pattern)
 			if err != nil {
 				continue
 			}
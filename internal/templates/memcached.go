@@ -0,0 +1,144 @@
+// package templates - Memcached stats requests
+package templates
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/artnikel/nuclei/internal/constants"
+	"github.com/artnikel/nuclei/internal/logging"
+)
+
+const (
+	// defaultMemcachedPort is the port of Memcached requests without a port option
+	defaultMemcachedPort = "11211"
+	// memcachedUDPHeaderLen is the size of the frame header prefixing every Memcached UDP datagram
+	memcachedUDPHeaderLen = 8
+	// maxMemcachedStatsLines limits the number of read stat lines
+	maxMemcachedStatsLines = 1000
+	// memcachedEnd terminates the reply to stats
+	memcachedEnd = "END"
+)
+
+// matchMemcachedRequest sends stats to the Memcached server of the host and matches the stat lines with the
+// network matchers. Options: port overrides the default port, protocol udp uses the UDP framing instead of TCP
+func matchMemcachedRequest(ctx context.Context, host string, req *Request, tmpl *Template, advanced *AdvancedSettingsChecker, logger *logging.Logger) (bool, error) {
+	if req.Type != "memcached" {
+		return false, fmt.Errorf("request type is not memcached: %s", req.Type)
+	}
+
+	protocol := "tcp"
+	if protoVal, ok := req.Options["protocol"]; ok && fmt.Sprint(protoVal) == "udp" {
+		protocol = "udp"
+	}
+	port := defaultMemcachedPort
+	if portVal, ok := req.Options["port"]; ok {
+		port = fmt.Sprint(portVal)
+	}
+	addr := net.JoinHostPort(host, port)
+
+	if advanced.DryRun {
+		logDryRun(advanced, logger, "Would connect: memcached "+protocol+" "+addr)
+		return false, nil
+	}
+
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(ctx, protocol, addr)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(constants.FiveSecTimeout))
+
+	var stats []byte
+	if protocol == "udp" {
+		stats, err = memcachedStatsUDP(conn)
+	} else {
+		stats, err = memcachedStatsTCP(conn)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	matchCtx := MatchContext{
+		Network: &NetworkResponse{
+			Data: stats,
+		},
+	}
+	matched := checkMatchers(req.Matchers, req.MatchersCondition, matchCtx)
+
+	logger.Info("Memcached request matched",
+		slog.String("template_id", tmpl.ID),
+		slog.String("host", host),
+		slog.String("protocol", protocol),
+		slog.Bool("matched", matched),
+	)
+	return matched, nil
+}
+
+// memcachedStatsTCP sends stats over the stream and returns the reply lines up to END
+func memcachedStatsTCP(conn net.Conn) ([]byte, error) {
+	if _, err := conn.Write([]byte("stats\r\n")); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	scanner := bufio.NewScanner(conn)
+	for i := 0; i < maxMemcachedStatsLines && scanner.Scan(); i++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == memcachedEnd {
+			return buf.Bytes(), nil
+		}
+		if strings.HasPrefix(line, "ERROR") {
+			return nil, fmt.Errorf("memcached error: %s", line)
+		}
+		buf.WriteString(line)
+		buf.WriteString("\n")
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if buf.Len() == 0 {
+		return nil, errors.New("empty memcached stats reply")
+	}
+	// a truncated reply still holds the stats read so far
+	return buf.Bytes(), nil
+}
+
+// memcachedStatsUDP sends stats in a single datagram and returns the reply lines up to END, the reply may span
+// several datagrams each starting with the frame header (request id, sequence number, datagram count, reserved)
+func memcachedStatsUDP(conn net.Conn) ([]byte, error) {
+	frame := make([]byte, memcachedUDPHeaderLen)
+	binary.BigEndian.PutUint16(frame[0:], 1)
+	binary.BigEndian.PutUint16(frame[4:], 1)
+	if _, err := conn.Write(append(frame, "stats\r\n"...)); err != nil {
+		return nil, err
+	}
+
+	var reply bytes.Buffer
+	packet := make([]byte, 65535)
+	for received, total := 0, 1; received < total; received++ {
+		n, err := conn.Read(packet)
+		if err != nil {
+			return nil, err
+		}
+		if n < memcachedUDPHeaderLen {
+			return nil, errors.New("short memcached udp datagram")
+		}
+		total = int(binary.BigEndian.Uint16(packet[4:6]))
+		reply.Write(packet[memcachedUDPHeaderLen:n])
+		if bytes.Contains(reply.Bytes(), []byte(memcachedEnd+"\r\n")) {
+			break
+		}
+	}
+
+	stats, _, _ := bytes.Cut(reply.Bytes(), []byte(memcachedEnd+"\r\n"))
+	return bytes.ReplaceAll(stats, []byte("\r\n"), []byte("\n")), nil
+}
@@ -0,0 +1,141 @@
+package templates
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+const memcachedStats = "STAT pid 1162\r\nSTAT uptime 5022\r\nSTAT version 1.4.25\r\nSTAT curr_connections 10\r\nEND\r\n"
+
+// serveMemcachedTCP answers stats with memcachedStats and keeps the connection open afterwards
+func serveMemcachedTCP(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	t.Cleanup(func() {
+		close(done)
+		l.Close()
+	})
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				if line, err := r.ReadString('\n'); err != nil || line != "stats\r\n" {
+					fmt.Fprint(conn, "ERROR\r\n")
+					return
+				}
+				fmt.Fprint(conn, memcachedStats+"STAT after_end 1\r\n")
+				<-done
+			}()
+		}
+	}()
+	_, port, _ := net.SplitHostPort(l.Addr().String())
+	return port
+}
+
+// serveMemcachedUDP answers stats datagrams with memcachedStats split over two framed datagrams
+func serveMemcachedUDP(t *testing.T) string {
+	t.Helper()
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { pc.Close() })
+	go func() {
+		buf := make([]byte, 1500)
+		for {
+			n, addr, err := pc.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if n < memcachedUDPHeaderLen || string(buf[memcachedUDPHeaderLen:n]) != "stats\r\n" {
+				continue
+			}
+			half := len(memcachedStats) / 2
+			for seq, part := range []string{memcachedStats[:half], memcachedStats[half:]} {
+				frame := make([]byte, memcachedUDPHeaderLen)
+				copy(frame[0:2], buf[0:2])
+				binary.BigEndian.PutUint16(frame[2:], uint16(seq))
+				binary.BigEndian.PutUint16(frame[4:], 2)
+				pc.WriteTo(append(frame, part...), addr)
+			}
+		}
+	}()
+	_, port, _ := net.SplitHostPort(pc.LocalAddr().String())
+	return port
+}
+
+func TestMemcachedStatsTCP(t *testing.T) {
+	port := serveMemcachedTCP(t)
+	conn, err := net.Dial("tcp", net.JoinHostPort("127.0.0.1", port))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(2 * time.Second))
+
+	stats, err := memcachedStatsTCP(conn)
+	if err != nil {
+		t.Fatalf("memcachedStatsTCP: %v (END did not stop the read)", err)
+	}
+	want := strings.ReplaceAll(strings.TrimSuffix(memcachedStats, "END\r\n"), "\r\n", "\n")
+	if string(stats) != want {
+		t.Errorf("memcachedStatsTCP = %q, want %q", stats, want)
+	}
+}
+
+const memcachedTemplate = `id: memcached-stats-%d
+info:
+  name: Exposed Memcached
+  author: test
+  severity: medium
+http:
+  - type: memcached
+    options:
+      port: %s
+      protocol: %s
+    matchers-condition: and
+    matchers:
+      - type: word
+        words:
+          - "STAT version 1.4"
+      - type: regex
+        regex:
+          - 'STAT curr_connections \d+'
+`
+
+func TestMemcachedRequest(t *testing.T) {
+	for i, protocol := range []string{"tcp", "udp"} {
+		t.Run(protocol, func(t *testing.T) {
+			var port string
+			if protocol == "udp" {
+				port = serveMemcachedUDP(t)
+			} else {
+				port = serveMemcachedTCP(t)
+			}
+			tmpl := loadTestTemplate(t, fmt.Sprintf(memcachedTemplate, i, port, protocol))
+			start := time.Now()
+			matched, _, err := matchTemplate(context.Background(), "http://127.0.0.1", "", tmpl, nil, testSettings(), testLogger())
+			if err != nil || !matched {
+				t.Fatalf("matchTemplate = %v, %v, want a match", matched, err)
+			}
+			if elapsed := time.Since(start); elapsed > 2*time.Second {
+				t.Errorf("stats took %v, want the read to stop at END", elapsed)
+			}
+		})
+	}
+}
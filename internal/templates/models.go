@@ -4,6 +4,7 @@ package templates
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -21,6 +22,10 @@ type Template struct {
 	Variables        map[string]interface{} `yaml:"variables,omitempty"`
 	StopAtFirstMatch bool                   `yaml:"stop-at-first-match,omitempty"`
 	RequestCondition string                 `yaml:"req-condition,omitempty"`
+	Version          string                 `yaml:"version,omitempty"`
+	Requires         []string               `yaml:"requires,omitempty"`
+	Extends          string                 `yaml:"extends,omitempty"`
+	Flow             string                 `yaml:"flow,omitempty"`
 
 	RequestsRaw []*Request `yaml:"requests,omitempty"`
 	HTTPRaw     []*Request `yaml:"http,omitempty"`
@@ -31,6 +36,9 @@ type Template struct {
 	Requests []*Request `yaml:"-"`
 
 	Hosts []string `yaml:"hosts,omitempty"`
+
+	// FilePath is the file the template was loaded from
+	FilePath string `yaml:"-"`
 }
 
 type Info struct {
@@ -39,6 +47,11 @@ type Info struct {
 	Severity    string `yaml:"severity"`
 	Description string `yaml:"description,omitempty"`
 	Tags        Tags   `yaml:"tags,omitempty"`
+
+	CVSSScore float64 `yaml:"cvss-score,omitempty"`
+	CVEID     string  `yaml:"cve-id,omitempty"`
+
+	MinToolVersion string `yaml:"min-tool-version,omitempty"`
 }
 
 type Request struct {
@@ -46,14 +59,20 @@ type Request struct {
 	Method            string                 `yaml:"method"`
 	Path              []string               `yaml:"path"`
 	Headers           map[string]string      `yaml:"headers,omitempty"`
+	Body              string                 `yaml:"body,omitempty"`
 	Matchers          []Matcher              `yaml:"matchers,omitempty"`
 	MatchersCondition string                 `yaml:"matchers-condition,omitempty"`
 	Extractors        []Extractor            `yaml:"extractors,omitempty"`
 	Attack            string                 `yaml:"attack,omitempty"`
 	Payloads          map[string]interface{} `yaml:"payloads,omitempty"`
+	SniperDefault     string                 `yaml:"sniper-default,omitempty"`
 	Pipeline          bool                   `yaml:"pipeline,omitempty"`
 	Options           map[string]interface{} `yaml:"options,omitempty"`
 	Preconditions     []Condition            `yaml:"pre-condition,omitempty"`
+	RateLimit         int                    `yaml:"rate-limit,omitempty"`
+	RateLimitBurst    int                    `yaml:"rate-limit-burst,omitempty"`
+	// MaxRedirects overrides AdvancedSettingsChecker.MaxHTTPRedirects when set, 0 disables following redirects
+	MaxRedirects *int `yaml:"max-redirects,omitempty"`
 }
 
 type Matcher struct {
@@ -66,10 +85,36 @@ type Matcher struct {
 	Regex     []string `yaml:"regex,omitempty"`
 	Size      int      `yaml:"size,omitempty"`
 	Dlength   int      `yaml:"dlength,omitempty"`
-	Binary    []string   `yaml:"binary,omitempty"`
-	XPath     []string   `yaml:"xpath,omitempty"`
+	Binary    []string `yaml:"binary,omitempty"`
+	XPath     []string `yaml:"xpath,omitempty"`
 	JSONPath  string   `yaml:"jsonpath,omitempty"`
 	NoCase    bool     `yaml:"nocase,omitempty"`
+
+	FaviconHash int32 `yaml:"favicon-hash,omitempty"`
+	// Confidence selects the sqlerror patterns to check: low (default), medium or high
+	Confidence string `yaml:"confidence,omitempty"`
+
+	EntropyMin     float64 `yaml:"entropy-min,omitempty"`
+	EntropyMax     float64 `yaml:"entropy-max,omitempty"`
+	MinTokenLength int     `yaml:"min-token-length,omitempty"`
+
+	MinDuration time.Duration `yaml:"min-duration,omitempty"`
+	MaxDuration time.Duration `yaml:"max-duration,omitempty"`
+
+	ReflectOrigin    bool `yaml:"reflect-origin,omitempty"`
+	AllowNull        bool `yaml:"allow-null,omitempty"`
+	AllowCredentials bool `yaml:"allow-credentials,omitempty"`
+
+	ExcludeRequestIPs bool `yaml:"exclude-request-ips,omitempty"`
+
+	// CookieFlags are httponly, secure, samesite-none, samesite-strict and samesite-lax,
+	// Negate matches cookies missing any of them
+	CookieName  string   `yaml:"cookie-name,omitempty"`
+	CookieFlags []string `yaml:"cookie-flags,omitempty"`
+	Negate      bool     `yaml:"negate,omitempty"`
+
+	// IntrospectionEnabled is the expected state of GraphQL introspection for graphql matchers
+	IntrospectionEnabled bool `yaml:"introspection-enabled,omitempty"`
 }
 
 type Extractor struct {
@@ -79,9 +124,21 @@ type Extractor struct {
 	Regex    []string `yaml:"regex,omitempty"`
 	Name     string   `yaml:"name,omitempty"`
 	NoCase   bool     `yaml:"nocase,omitempty"`
-	XPath    []string   `yaml:"xpath,omitempty"`
+	XPath    []string `yaml:"xpath,omitempty"`
 	JSONPath string   `yaml:"jsonpath,omitempty"`
 	Base64   bool     `yaml:"base64,omitempty"`
+	Encoding string   `yaml:"encoding,omitempty"`
+	// Index picks one hop of a redirect-chain extractor: 1-based, negative counts from the last hop, 0 takes all hops
+	Index int `yaml:"index,omitempty"`
+	// Field is the certificate field of an ssl extractor: subject-cn, issuer-cn, san, fingerprint-sha256 or not-after
+	Field string `yaml:"field,omitempty"`
+	// ClaimPath is the dot-notation claim of a jwt extractor, e.g. header.alg or payload.sub
+	ClaimPath string `yaml:"claim-path,omitempty"`
+	// NamedGroup selects a named capture group of the regex instead of Group
+	NamedGroup string `yaml:"named-group,omitempty"`
+	// Dotall and Multiline set the s and m flags of the regex
+	Dotall    bool `yaml:"dotall,omitempty"`
+	Multiline bool `yaml:"multiline,omitempty"`
 }
 
 type Condition struct {
@@ -91,6 +148,60 @@ type Condition struct {
 
 type Tags []string
 
+// Finding describes a single template match against a target
+type Finding struct {
+	Timestamp       time.Time         `json:"timestamp"`
+	Target          string            `json:"target"`
+	TemplateID      string            `json:"template_id"`
+	Severity        string            `json:"severity"`
+	Name            string            `json:"name"`
+	Description     string            `json:"description,omitempty"`
+	CVSSScore       float64           `json:"cvss_score,omitempty"`
+	CVEID           string            `json:"cve_id,omitempty"`
+	MatchedURL      string            `json:"matched_url"`
+	ExtractedValues map[string]string `json:"extracted_values,omitempty"`
+	// Duration is the time the template took to run against the target
+	Duration time.Duration `json:"duration,omitempty"`
+	// Tags are the tags of the template
+	Tags []string `json:"tags,omitempty"`
+	// TemplatePath is the file the template was loaded from
+	TemplatePath string `json:"template_path,omitempty"`
+}
+
+// SeverityLevel returns the severity from the info block, falling back to the top level severity
+func (t *Template) SeverityLevel() string {
+	if t.Info.Severity != "" {
+		return t.Info.Severity
+	}
+	return t.Severity
+}
+
+// DescriptionText returns the description from the info block, falling back to the top level description
+func (t *Template) DescriptionText() string {
+	if t.Info.Description != "" {
+		return t.Info.Description
+	}
+	return t.Description
+}
+
+// NewFinding creates a finding for the template matched on the target
+func NewFinding(target string, tmpl *Template) *Finding {
+	return &Finding{
+		Timestamp:   time.Now(),
+		Target:      target,
+		TemplateID:  tmpl.ID,
+		Severity:    tmpl.SeverityLevel(),
+		Name:        tmpl.Info.Name,
+		Description: tmpl.DescriptionText(),
+		CVSSScore:   tmpl.Info.CVSSScore,
+		CVEID:       tmpl.Info.CVEID,
+		MatchedURL:  target,
+		Tags:        tmpl.Info.Tags,
+
+		TemplatePath: tmpl.FilePath,
+	}
+}
+
 // UnmarshalYAML implements custom parsing of the Tags field from YAML (string or list)
 func (t *Tags) UnmarshalYAML(value *yaml.Node) error {
 	switch value.Kind {
@@ -0,0 +1,107 @@
+// package templates - MySQL server version detection
+package templates
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"time"
+
+	"github.com/artnikel/nuclei/internal/constants"
+	"github.com/artnikel/nuclei/internal/logging"
+)
+
+const (
+	// defaultMySQLPort is the port of MySQL requests without a port option
+	defaultMySQLPort = "3306"
+	// mysqlProtocolV10 is the protocol version of the initial handshake packet sent by MySQL 3.21 and later
+	mysqlProtocolV10 = 10
+	// mysqlErrPacket marks an error packet, sent e.g. when the client host is not allowed to connect
+	mysqlErrPacket = 0xff
+)
+
+// matchMySQLRequest reads the initial handshake of the MySQL server of the host and matches its server version
+// with the network matchers. No authentication is done. Options: port overrides the default port
+func matchMySQLRequest(ctx context.Context, host string, req *Request, tmpl *Template, advanced *AdvancedSettingsChecker, logger *logging.Logger) (bool, error) {
+	if req.Type != "mysql" {
+		return false, fmt.Errorf("request type is not mysql: %s", req.Type)
+	}
+
+	port := defaultMySQLPort
+	if portVal, ok := req.Options["port"]; ok {
+		port = fmt.Sprint(portVal)
+	}
+	addr := net.JoinHostPort(host, port)
+
+	if advanced.DryRun {
+		logDryRun(advanced, logger, "Would connect: mysql "+addr)
+		return false, nil
+	}
+
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(constants.FiveSecTimeout))
+
+	version, err := readMySQLVersion(conn)
+	if err != nil {
+		return false, err
+	}
+
+	matchCtx := MatchContext{
+		Network: &NetworkResponse{
+			Data: []byte(version),
+		},
+	}
+	matched := checkMatchers(req.Matchers, req.MatchersCondition, matchCtx)
+
+	logger.Info("MySQL request matched",
+		slog.String("template_id", tmpl.ID),
+		slog.String("host", host),
+		slog.String("version", version),
+		slog.Bool("matched", matched),
+	)
+	return matched, nil
+}
+
+// readMySQLVersion reads the first packet (3-byte little-endian payload length, sequence id, payload) and returns
+// the null-terminated server version following the protocol version byte of the handshake
+func readMySQLVersion(r io.Reader) (string, error) {
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return "", err
+	}
+	length := int(header[0]) | int(header[1])<<8 | int(header[2])<<16
+	if length == 0 {
+		return "", errors.New("empty mysql handshake packet")
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return "", err
+	}
+
+	switch payload[0] {
+	case mysqlProtocolV10:
+	case mysqlErrPacket:
+		// error code (2 bytes) and message
+		if len(payload) > 3 {
+			return "", fmt.Errorf("mysql error: %s", payload[3:])
+		}
+		return "", errors.New("mysql error packet")
+	default:
+		return "", fmt.Errorf("unsupported mysql protocol version: %d", payload[0])
+	}
+
+	version, _, ok := bytes.Cut(payload[1:], []byte{0})
+	if !ok {
+		return "", errors.New("mysql server version is not terminated")
+	}
+	return string(version), nil
+}
@@ -0,0 +1,100 @@
+package templates
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"testing"
+)
+
+// mysqlHandshake returns an Initial Handshake Packet v10 as sent by a MySQL server of the given version
+func mysqlHandshake(version string) []byte {
+	var payload bytes.Buffer
+	payload.WriteByte(mysqlProtocolV10)
+	payload.WriteString(version)
+	payload.WriteByte(0)
+	payload.Write([]byte{0x2a, 0x00, 0x00, 0x00})                         // connection id
+	payload.Write([]byte{0x3b, 0x2f, 0x5c, 0x14, 0x61, 0x0b, 0x6c, 0x7e}) // auth-plugin-data part 1
+	payload.WriteByte(0)                                                  // filler
+	payload.Write([]byte{0xff, 0xf7})                                     // capability flags, lower bytes
+	payload.WriteByte(0x21)                                               // utf8_general_ci
+	payload.Write([]byte{0x02, 0x00})                                     // status flags
+	payload.Write([]byte{0xff, 0x81})                                     // capability flags, upper bytes
+	payload.WriteByte(21)                                                 // auth-plugin-data length
+	payload.Write(make([]byte, 10))                                       // reserved
+	payload.Write([]byte{0x1d, 0x4c, 0x06, 0x52, 0x3e, 0x68, 0x2d, 0x17, 0x47, 0x5a, 0x17, 0x72, 0x00})
+	payload.WriteString("mysql_native_password\x00")
+	return mysqlPacket(payload.Bytes())
+}
+
+// mysqlPacket frames payload with the 3-byte length and the sequence id 0
+func mysqlPacket(payload []byte) []byte {
+	n := len(payload)
+	return append([]byte{byte(n), byte(n >> 8), byte(n >> 16), 0}, payload...)
+}
+
+func TestReadMySQLVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		packet  []byte
+		want    string
+		wantErr bool
+	}{
+		{name: "5.7 handshake", packet: mysqlHandshake("5.7.33-0ubuntu0.18.04.1"), want: "5.7.33-0ubuntu0.18.04.1"},
+		{name: "8.0 handshake", packet: mysqlHandshake("8.0.35"), want: "8.0.35"},
+		{name: "host not allowed", packet: mysqlPacket(append([]byte{mysqlErrPacket, 0x6a, 0x04}, "Host '10.0.0.5' is not allowed to connect"...)), wantErr: true},
+		{name: "protocol v9", packet: mysqlPacket([]byte("\x095.0.0\x00")), wantErr: true},
+		{name: "unterminated version", packet: mysqlPacket([]byte("\x0a5.7.33")), wantErr: true},
+		{name: "truncated packet", packet: mysqlHandshake("5.7.33")[:20], wantErr: true},
+		{name: "empty packet", packet: mysqlPacket(nil), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readMySQLVersion(bytes.NewReader(tt.packet))
+			if got != tt.want || (err != nil) != tt.wantErr {
+				t.Errorf("readMySQLVersion = %q, %v, want %q, error %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+const mysqlTemplate = `id: mysql-version-%d
+info:
+  name: MySQL version
+  author: test
+  severity: info
+http:
+  - type: mysql
+    options:
+      port: %s
+    matchers:
+      - type: word
+        words:
+          - "%s"
+`
+
+func TestMySQLRequest(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := serveGreeting(t, l, string(mysqlHandshake("5.7.33-0ubuntu0.18.04.1")))
+
+	for i, tt := range []struct {
+		word string
+		want bool
+	}{
+		{word: "5.7.", want: true},
+		{word: "8.0.", want: false},
+	} {
+		tmpl := loadTestTemplate(t, fmt.Sprintf(mysqlTemplate, i, port, tt.word))
+		matched, _, err := matchTemplate(context.Background(), "http://127.0.0.1", "", tmpl, nil, testSettings(), testLogger())
+		if err != nil {
+			t.Fatalf("matchTemplate: %v", err)
+		}
+		if matched != tt.want {
+			t.Errorf("mysql version matched %q = %v, want %v", tt.word, matched, tt.want)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+// package templates - payload wordlists and attack modes
+package templates
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DefaultMaxPayloadCombinations is the number of payload sets generated per request by default
+const DefaultMaxPayloadCombinations = 1000
+
+// Supported payload attack modes
+const (
+	AttackClusterBomb = "clusterbomb"
+	AttackPitchfork   = "pitchfork"
+	AttackSniper      = "sniper"
+)
+
+// loadPayloadLists converts the raw payloads of a request into named wordlists and their encodings.
+// A value is an inline list, a path to a file with one payload per line or a map with
+// "values" or "file" and an optional "encoding" key. Files are resolved relative to dir, the directory of the template
+func loadPayloadLists(payloads map[string]interface{}, dir string) (map[string][]string, map[string]string, error) {
+	lists := make(map[string][]string, len(payloads))
+	encodings := make(map[string]string)
+	for name, raw := range payloads {
+		if def, ok := raw.(map[string]interface{}); ok {
+			if enc, ok := def["encoding"].(string); ok && enc != "" {
+				if err := validateEncoding(enc); err != nil {
+					return nil, nil, fmt.Errorf("payload %s: %w", name, err)
+				}
+				encodings[name] = enc
+			}
+			switch {
+			case def["values"] != nil:
+				raw = def["values"]
+			case def["file"] != nil:
+				raw = def["file"]
+			default:
+				return nil, nil, fmt.Errorf("payload %s has neither values nor file", name)
+			}
+		}
+
+		words, err := payloadWords(name, raw, dir)
+		if err != nil {
+			return nil, nil, err
+		}
+		lists[name] = words
+	}
+	return lists, encodings, nil
+}
+
+// payloadWords reads a single wordlist from an inline list or a file path relative to dir
+func payloadWords(name string, raw interface{}, dir string) ([]string, error) {
+	switch v := raw.(type) {
+	case []interface{}:
+		words := make([]string, 0, len(v))
+		for _, item := range v {
+			words = append(words, fmt.Sprint(item))
+		}
+		return words, nil
+	case []string:
+		return v, nil
+	case string:
+		path, err := payloadFilePath(v, dir)
+		if err != nil {
+			return nil, fmt.Errorf("payload %s: %w", name, err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read payload file %s: %w", v, err)
+		}
+		var words []string
+		for _, line := range strings.Split(string(data), "\n") {
+			if line = strings.TrimRight(line, "\r"); line != "" {
+				words = append(words, line)
+			}
+		}
+		return words, nil
+	default:
+		return nil, fmt.Errorf("unsupported payload %s of type %T", name, raw)
+	}
+}
+
+// payloadFilePath resolves a payload file against the template directory. Absolute paths and paths
+// leaving the directory are rejected so a template can't read arbitrary files of the scanning host
+func payloadFilePath(file, dir string) (string, error) {
+	if !filepath.IsLocal(file) {
+		return "", fmt.Errorf("payload file %s must be a relative path inside the template directory", file)
+	}
+	return filepath.Join(dir, file), nil
+}
+
+// buildPayloadSets returns the variable sets to substitute for each request of tmpl according to the attack mode.
+// A request without payloads produces a single empty set
+func buildPayloadSets(req *Request, tmpl *Template, advanced *AdvancedSettingsChecker) ([]map[string]interface{}, error) {
+	if len(req.Payloads) == 0 {
+		return []map[string]interface{}{{}}, nil
+	}
+	dir := "."
+	if tmpl != nil && tmpl.FilePath != "" {
+		dir = filepath.Dir(tmpl.FilePath)
+	}
+	lists, encodings, err := loadPayloadLists(req.Payloads, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(lists))
+	for name := range lists {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sets []map[string]string
+	switch strings.ToLower(req.Attack) {
+	case AttackClusterBomb, "":
+		sets = clusterBombSets(names, lists, advanced.MaxPayloadCombinations)
+	case AttackPitchfork:
+		sets = pitchforkSets(names, lists, advanced.MaxPayloadCombinations)
+	case AttackSniper:
+		sets = sniperSets(names, lists, req.SniperDefault, advanced.MaxPayloadCombinations)
+	default:
+		return nil, fmt.Errorf("unsupported attack mode: %s", req.Attack)
+	}
+
+	return withEncodings(sets, encodings), nil
+}
+
+// withEncodings converts payload sets into variable sets, wrapping values of encoded payloads
+func withEncodings(sets []map[string]string, encodings map[string]string) []map[string]interface{} {
+	result := make([]map[string]interface{}, 0, len(sets))
+	for _, set := range sets {
+		vars := make(map[string]interface{}, len(set))
+		for name, value := range set {
+			if enc, ok := encodings[name]; ok {
+				vars[name] = encodedValue{Value: value, Encoding: enc}
+			} else {
+				vars[name] = value
+			}
+		}
+		result = append(result, vars)
+	}
+	return result
+}
+
+// clusterBombSets computes the cartesian product of all wordlists, stopping after limit sets when limit > 0
+func clusterBombSets(names []string, lists map[string][]string, limit int) []map[string]string {
+	sets := []map[string]string{{}}
+	for _, name := range names {
+		var next []map[string]string
+		for _, set := range sets {
+			for _, word := range lists[name] {
+				if limit > 0 && len(next) >= limit {
+					break
+				}
+				combined := make(map[string]string, len(set)+1)
+				for k, v := range set {
+					combined[k] = v
+				}
+				combined[name] = word
+				next = append(next, combined)
+			}
+		}
+		sets = next
+	}
+	return sets
+}
+
+// pitchforkSets iterates all wordlists in lockstep, producing as many sets as the shortest list has entries
+func pitchforkSets(names []string, lists map[string][]string, limit int) []map[string]string {
+	size := -1
+	for _, name := range names {
+		if size < 0 || len(lists[name]) < size {
+			size = len(lists[name])
+		}
+	}
+	if limit > 0 && size > limit {
+		size = limit
+	}
+
+	sets := make([]map[string]string, 0, size)
+	for i := 0; i < size; i++ {
+		set := make(map[string]string, len(names))
+		for _, name := range names {
+			set[name] = lists[name][i]
+		}
+		sets = append(sets, set)
+	}
+	return sets
+}
+
+// sniperSets fuzzes one position at a time, every other position is set to the default value
+func sniperSets(names []string, lists map[string][]string, defaultValue string, limit int) []map[string]string {
+	var sets []map[string]string
+	for _, name := range names {
+		for _, word := range lists[name] {
+			if limit > 0 && len(sets) >= limit {
+				return sets
+			}
+			set := make(map[string]string, len(names))
+			for _, other := range names {
+				set[other] = defaultValue
+			}
+			set[name] = word
+			sets = append(sets, set)
+		}
+	}
+	return sets
+}
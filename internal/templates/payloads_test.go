@@ -0,0 +1,303 @@
+package templates
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// requestRecorder is a mock server answering 200 for the request URIs in match and 404 otherwise
+type requestRecorder struct {
+	mu    sync.Mutex
+	uris  []string
+	match map[string]bool
+}
+
+func newRequestRecorder(t *testing.T, match ...string) (*requestRecorder, *httptest.Server) {
+	t.Helper()
+	rec := &requestRecorder{match: make(map[string]bool)}
+	for _, uri := range match {
+		rec.match[uri] = true
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec.mu.Lock()
+		rec.uris = append(rec.uris, r.URL.RequestURI())
+		rec.mu.Unlock()
+		if rec.match[r.URL.RequestURI()] {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	t.Cleanup(srv.Close)
+	return rec, srv
+}
+
+func (r *requestRecorder) requests() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.uris...)
+}
+
+// loadTemplateWithFiles writes the template and the extra files, e.g. wordlists, into one directory and loads it
+func loadTemplateWithFiles(t *testing.T, content string, files map[string]string) *Template {
+	t.Helper()
+	dir := t.TempDir()
+	for name, data := range files {
+		writeTestFile(t, dir, name, data)
+	}
+	tmpl, err := LoadTemplate(writeTestFile(t, dir, "template.yaml", content))
+	if err != nil {
+		t.Fatalf("LoadTemplate: %v", err)
+	}
+	return tmpl
+}
+
+// runRequests runs every request of tmpl against target the way the engine does, stopping at the first match
+func runRequests(t *testing.T, target string, tmpl *Template, advanced *AdvancedSettingsChecker) bool {
+	t.Helper()
+	for _, req := range tmpl.Requests {
+		matched, _, err := matchHTTPRequest(context.Background(), target, req, tmpl, tmpl.Variables, advanced, testLogger())
+		if err != nil {
+			t.Fatalf("matchHTTPRequest: %v", err)
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+const clusterBombTemplate = `id: clusterbomb-login
+info:
+  name: Cluster bomb login
+  author: test
+  severity: high
+http:
+  - method: GET
+    path:
+      - "{{BaseURL}}/login?user={{user}}&pass={{pass}}"
+    attack: clusterbomb
+    payloads:
+      user: lists/users.txt
+      pass:
+        - a
+        - b
+        - c
+    matchers:
+      - type: status
+        status: [200]
+`
+
+func TestClusterBombAttack(t *testing.T) {
+	files := map[string]string{"lists/users.txt": "admin\nroot\n"}
+
+	t.Run("every combination", func(t *testing.T) {
+		rec, srv := newRequestRecorder(t)
+		tmpl := loadTemplateWithFiles(t, clusterBombTemplate, files)
+		if runRequests(t, srv.URL, tmpl, testSettings()) {
+			t.Fatal("template matched a server without a matching response")
+		}
+		unique := make(map[string]bool)
+		for _, uri := range rec.requests() {
+			unique[uri] = true
+		}
+		if got := rec.requests(); len(got) != 6 || len(unique) != 6 {
+			t.Fatalf("requests = %v, want 6 unique combinations", got)
+		}
+		for _, user := range []string{"admin", "root"} {
+			for _, pass := range []string{"a", "b", "c"} {
+				if uri := "/login?user=" + user + "&pass=" + pass; !unique[uri] {
+					t.Errorf("combination %s not requested", uri)
+				}
+			}
+		}
+	})
+
+	t.Run("first match aborts", func(t *testing.T) {
+		rec, srv := newRequestRecorder(t, "/login?user=admin&pass=b")
+		tmpl := loadTemplateWithFiles(t, clusterBombTemplate, files)
+		if !runRequests(t, srv.URL, tmpl, testSettings()) {
+			t.Fatal("template didn't match")
+		}
+		// payloads are combined in name order, pass=b with user=admin is the third combination
+		got := rec.requests()
+		if len(got) != 3 || got[2] != "/login?user=admin&pass=b" {
+			t.Fatalf("requests = %v, want the scan to stop at the matching combination", got)
+		}
+	})
+
+	t.Run("combination cap", func(t *testing.T) {
+		rec, srv := newRequestRecorder(t)
+		tmpl := loadTemplateWithFiles(t, clusterBombTemplate, files)
+		advanced := testSettings()
+		advanced.MaxPayloadCombinations = 4
+		runRequests(t, srv.URL, tmpl, advanced)
+		if got := rec.requests(); len(got) != 4 {
+			t.Fatalf("requests = %v, want 4 with the combination cap", got)
+		}
+	})
+}
+
+func TestPayloadFilePath(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		file    string
+		want    string
+		wantErr bool
+	}{
+		{name: "relative to template", file: "users.txt", want: filepath.Join(dir, "users.txt")},
+		{name: "subdirectory", file: "lists/users.txt", want: filepath.Join(dir, "lists", "users.txt")},
+		{name: "absolute path", file: "/etc/passwd", wantErr: true},
+		{name: "parent directory", file: "../../etc/passwd", wantErr: true},
+		{name: "escape after subdirectory", file: "lists/../../secret.txt", wantErr: true},
+		{name: "empty", file: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := payloadFilePath(tt.file, dir)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("payloadFilePath(%q) error = %v, wantErr %v", tt.file, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("payloadFilePath(%q) = %q, want %q", tt.file, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPayloadFileOutsideTemplateDir(t *testing.T) {
+	tmpl := loadTemplateWithFiles(t, `id: payload-escape
+info:
+  name: Payload escape
+  author: test
+  severity: info
+http:
+  - path:
+      - "{{BaseURL}}/{{word}}"
+    payloads:
+      word: ../../../../etc/passwd
+    matchers:
+      - type: status
+        status: [200]
+`, nil)
+	if _, err := buildPayloadSets(tmpl.Requests[0], tmpl, testSettings()); err == nil {
+		t.Fatal("payload file outside the template directory was read")
+	}
+}
+
+func TestPitchforkAttack(t *testing.T) {
+	tests := []struct {
+		name      string
+		users     string
+		passwords string
+		want      []string
+	}{
+		{
+			name:      "equal length",
+			users:     "admin\nroot\nguest\n",
+			passwords: "hunter2\ntoor\nguest\n",
+			want:      []string{"/login?user=admin&pass=hunter2", "/login?user=root&pass=toor", "/login?user=guest&pass=guest"},
+		},
+		{
+			name:      "shortest list wins",
+			users:     "admin\nroot\nguest\n",
+			passwords: "hunter2\ntoor\n",
+			want:      []string{"/login?user=admin&pass=hunter2", "/login?user=root&pass=toor"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, srv := newRequestRecorder(t)
+			tmpl := loadTemplateWithFiles(t, `id: pitchfork-login
+info:
+  name: Pitchfork login
+  author: test
+  severity: high
+http:
+  - method: GET
+    path:
+      - "{{BaseURL}}/login?user={{user}}&pass={{pass}}"
+    attack: pitchfork
+    payloads:
+      user: users.txt
+      pass: passwords.txt
+    matchers:
+      - type: status
+        status: [200]
+`, map[string]string{"users.txt": tt.users, "passwords.txt": tt.passwords})
+			runRequests(t, srv.URL, tmpl, testSettings())
+
+			got := rec.requests()
+			if len(got) != len(tt.want) {
+				t.Fatalf("requests = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("request %d = %s, want %s", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSniperAttack(t *testing.T) {
+	tests := []struct {
+		name          string
+		sniperDefault string
+		want          []string
+	}{
+		{
+			name: "empty default",
+			want: []string{
+				"/search?p1=x&p2=", "/search?p1=y&p2=", "/search?p1=z&p2=",
+				"/search?p1=&p2=x", "/search?p1=&p2=y", "/search?p1=&p2=z",
+			},
+		},
+		{
+			name:          "custom default",
+			sniperDefault: "\n    sniper-default: keep",
+			want: []string{
+				"/search?p1=x&p2=keep", "/search?p1=y&p2=keep", "/search?p1=z&p2=keep",
+				"/search?p1=keep&p2=x", "/search?p1=keep&p2=y", "/search?p1=keep&p2=z",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, srv := newRequestRecorder(t)
+			tmpl := loadTestTemplate(t, `id: sniper-search
+info:
+  name: Sniper search
+  author: test
+  severity: low
+http:
+  - method: GET
+    path:
+      - "{{BaseURL}}/search?p1={{p1}}&p2={{p2}}"
+    attack: sniper`+tt.sniperDefault+`
+    payloads:
+      p1: [x, y, z]
+      p2: [x, y, z]
+    matchers:
+      - type: status
+        status: [200]
+`)
+			runRequests(t, srv.URL, tmpl, testSettings())
+
+			got := rec.requests()
+			if len(got) != len(tt.want) {
+				t.Fatalf("requests = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("request %d = %s, want %s", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+// package templates - scan profiles selecting the templates to run
+package templates
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Profile limits a scan to templates of the listed severities and request types, excluding the listed IDs.
+// Empty lists don't filter
+type Profile struct {
+	Severity  []string `yaml:"severity,omitempty"`
+	Type      []string `yaml:"type,omitempty"`
+	ExcludeID []string `yaml:"exclude-id,omitempty"`
+}
+
+// isProfileFile reports whether the YAML file at path is a profile rather than a template:
+// it has no id and sets at least one of the profile fields
+func isProfileFile(path string) bool {
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	var fields map[string]any
+	if err := yaml.Unmarshal(bs, &fields); err != nil {
+		return false
+	}
+	if _, ok := fields["id"]; ok {
+		return false
+	}
+	for _, key := range []string{"severity", "type", "exclude-id"} {
+		if _, ok := fields[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadProfile loads the profile from the YAML file at path
+func LoadProfile(path string) (*Profile, error) {
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	p := &Profile{}
+	if err := yaml.Unmarshal(bs, p); err != nil {
+		return nil, fmt.Errorf("failed to parse profile %s: %w", path, err)
+	}
+	return p, nil
+}
+
+// ApplyProfile returns the templates allowed by the profile in their original order, a nil profile keeps all of them
+func ApplyProfile(templates []*Template, p *Profile) []*Template {
+	if p == nil {
+		return templates
+	}
+	excluded := make(map[string]struct{}, len(p.ExcludeID))
+	for _, id := range p.ExcludeID {
+		excluded[strings.TrimSpace(id)] = struct{}{}
+	}
+
+	var res []*Template
+	for _, tmpl := range templates {
+		if _, ok := excluded[tmpl.ID]; ok {
+			continue
+		}
+		if !tmpl.MatchesSeverity(p.Severity) || !requestTypesAllowed(tmpl, p.Type) {
+			continue
+		}
+		res = append(res, tmpl)
+	}
+	return res
+}
+
+// requestTypesAllowed reports whether every request of the template has one of types, requests without
+// a type are http requests. An empty list allows every type
+func requestTypesAllowed(tmpl *Template, types []string) bool {
+	if len(types) == 0 {
+		return true
+	}
+	for _, req := range tmpl.Requests {
+		reqType := req.Type
+		if reqType == "" {
+			reqType = "http"
+		}
+		allowed := false
+		for _, t := range types {
+			if strings.EqualFold(strings.TrimSpace(t), reqType) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,92 @@
+package templates
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+const noInfoProfile = `severity: [low, medium, high, critical]
+exclude-id: []
+`
+
+func TestLoadProfile(t *testing.T) {
+	path := writeTestFile(t, t.TempDir(), "profile.yaml", "severity: [high, critical]\ntype: [http, dns]\nexclude-id: [noisy-check]\n")
+	p, err := LoadProfile(path)
+	if err != nil {
+		t.Fatalf("LoadProfile: %v", err)
+	}
+	if strings.Join(p.Severity, ",") != "high,critical" || strings.Join(p.Type, ",") != "http,dns" || strings.Join(p.ExcludeID, ",") != "noisy-check" {
+		t.Errorf("LoadProfile = %+v", p)
+	}
+	if !isProfileFile(path) {
+		t.Error("profile not detected as a profile file")
+	}
+
+	bad := writeTestFile(t, t.TempDir(), "bad.yaml", "severity: [unclosed\n")
+	if _, err := LoadProfile(bad); err == nil {
+		t.Error("LoadProfile accepted invalid YAML")
+	}
+}
+
+func TestApplyProfile(t *testing.T) {
+	corpus := indexCorpus()
+	corpus[1].Requests = []*Request{{Type: "dns"}}
+	corpus[2].Requests = []*Request{{}, {Type: "network"}}
+
+	tests := []struct {
+		name    string
+		profile *Profile
+		removed string
+	}{
+		{name: "nil profile"},
+		{name: "excluding info", profile: &Profile{Severity: []string{"low", "medium", "high", "critical"}}, removed: "idx04,idx09,idx14,idx19"},
+		{name: "http only", profile: &Profile{Type: []string{"HTTP"}}, removed: "idx01,idx02"},
+		{name: "excluded ids", profile: &Profile{ExcludeID: []string{"idx03", " idx07 "}}, removed: "idx03,idx07"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kept := make(map[*Template]bool)
+			for _, tmpl := range ApplyProfile(corpus, tt.profile) {
+				kept[tmpl] = true
+			}
+			var removed []string
+			for _, tmpl := range corpus {
+				if !kept[tmpl] {
+					removed = append(removed, tmpl.ID)
+				}
+			}
+			if got := strings.Join(removed, ","); got != tt.removed {
+				t.Errorf("ApplyProfile removed %s, want %s", got, tt.removed)
+			}
+		})
+	}
+}
+
+func TestFindMatchingTemplatesProfile(t *testing.T) {
+	_, srv := newRequestRecorder(t, "/0", "/1", "/2", "/3")
+	dir := writeTaggedTemplates(t, []string{"  severity: info", "  severity: high", "  severity: info", "  severity: low"})
+	profilePath := writeTestFile(t, dir, "no-info.yaml", noInfoProfile)
+	profile, err := LoadProfile(profilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	advanced := testSettings()
+	advanced.Profile = profile
+
+	findings, err := FindMatchingTemplates(context.Background(), srv.URL, dir, nil, nil, 10*time.Second,
+		advanced, testLogger(), func(i, total int) {})
+	if err != nil {
+		t.Fatalf("FindMatchingTemplates: %v", err)
+	}
+	ids := make([]string, 0, len(findings))
+	for _, f := range findings {
+		ids = append(ids, f.TemplateID)
+	}
+	sort.Strings(ids)
+	if got := strings.Join(ids, ","); got != "t01,t03" {
+		t.Errorf("findings with the profile = %s, want t01,t03", got)
+	}
+}
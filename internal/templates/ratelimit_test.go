@@ -0,0 +1,192 @@
+package templates
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// requestLog is a mock server middleware recording when each request arrived
+type requestLog struct {
+	mu    sync.Mutex
+	times map[string][]time.Time
+}
+
+func (l *requestLog) handler(w http.ResponseWriter, r *http.Request) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.times == nil {
+		l.times = make(map[string][]time.Time)
+	}
+	l.times[r.URL.Query().Get("t")] = append(l.times[r.URL.Query().Get("t")], time.Now())
+	w.WriteHeader(http.StatusNotFound)
+}
+
+func (l *requestLog) arrivals(template string) []time.Time {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]time.Time(nil), l.times[template]...)
+}
+
+func rateLimitedRequest(template string, rateLimit int) *Request {
+	return &Request{
+		Method:    http.MethodGet,
+		Path:      []string{"{{BaseURL}}/a?t=" + template, "{{BaseURL}}/b?t=" + template, "{{BaseURL}}/c?t=" + template},
+		Matchers:  []Matcher{{Type: "status", Status: []int{http.StatusOK}}},
+		RateLimit: rateLimit,
+	}
+}
+
+func TestPerTemplateRateLimit(t *testing.T) {
+	log := &requestLog{}
+	srv := httptest.NewServer(http.HandlerFunc(log.handler))
+	defer srv.Close()
+	advanced := testSettings()
+
+	var wg sync.WaitGroup
+	for id, rateLimit := range map[string]int{"login-rate-limited": 500, "static-global-rate": 0} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tmpl := &Template{ID: id}
+			if _, _, err := matchHTTPRequest(context.Background(), srv.URL, rateLimitedRequest(id, rateLimit), tmpl, nil, advanced, testLogger()); err != nil {
+				t.Errorf("matchHTTPRequest(%s): %v", id, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	limited := log.arrivals("login-rate-limited")
+	if len(limited) != 3 {
+		t.Fatalf("rate-limited template sent %d requests, want 3", len(limited))
+	}
+	// 500ms between requests is at most 2 requests per second
+	for i := 1; i < len(limited); i++ {
+		if gap := limited[i].Sub(limited[i-1]); gap < 450*time.Millisecond {
+			t.Errorf("gap between rate-limited requests %d and %d = %v, want >= 500ms", i-1, i, gap)
+		}
+	}
+
+	global := log.arrivals("static-global-rate")
+	if len(global) != 3 {
+		t.Fatalf("global-rate template sent %d requests, want 3", len(global))
+	}
+	if spread := global[len(global)-1].Sub(global[0]); spread > 400*time.Millisecond {
+		t.Errorf("global-rate template took %v for 3 requests, it's slowed down by the per-template limit", spread)
+	}
+}
+
+func TestGetHostLimiter(t *testing.T) {
+	advanced := &AdvancedSettingsChecker{RateLimiterFrequency: 10, RateLimiterBurstSize: 100}
+	host := "limiter.test"
+	_, endHostScan := startHostScan(host, 0)
+	defer endHostScan()
+
+	global := getHostLimiter(host, &Request{}, "a", advanced)
+	if getHostLimiter(host, &Request{}, "b", advanced) != global {
+		t.Error("requests without overrides don't share the host limiter")
+	}
+	if getHostLimiter(host, &Request{RateLimit: 10, RateLimitBurst: 100}, "c", advanced) != global {
+		t.Error("overrides equal to the global rate got a separate limiter")
+	}
+
+	override := getHostLimiter(host, &Request{RateLimit: 1000}, "d", advanced)
+	if override == global {
+		t.Fatal("request with a rate-limit override uses the global host limiter")
+	}
+	if override.Burst() != 1 {
+		t.Errorf("override burst = %d, want 1", override.Burst())
+	}
+	if getHostLimiter(host, &Request{RateLimit: 1000}, "d", advanced) != override {
+		t.Error("override limiter is not reused for the same host and template")
+	}
+	if getHostLimiter(host, &Request{RateLimit: 1000}, "e", advanced) == override {
+		t.Error("override limiter is shared between templates")
+	}
+}
+
+func TestGetHostLimiterConcurrent(t *testing.T) {
+	advanced := &AdvancedSettingsChecker{RateLimiterFrequency: 10, RateLimiterBurstSize: 100}
+	limiters := make([]*rate.Limiter, 100)
+	var wg sync.WaitGroup
+	for i := range limiters {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			limiters[i] = getHostLimiter("concurrent-limiter.test", &Request{}, "a", advanced)
+		}(i)
+	}
+	wg.Wait()
+	for _, l := range limiters {
+		if l != limiters[0] {
+			t.Fatal("concurrent callers got different limiters for the same host")
+		}
+	}
+}
+
+// mutexHostLimiters is the mutex guarded map getHostLimiter used before, kept as the benchmark baseline
+type mutexHostLimiters struct {
+	mu       sync.Mutex
+	limiters map[string]*rate.Limiter
+}
+
+func (m *mutexHostLimiters) get(host string, advanced *AdvancedSettingsChecker) *rate.Limiter {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if l, ok := m.limiters[host]; ok {
+		return l
+	}
+	l := rate.NewLimiter(rate.Every(time.Duration(advanced.RateLimiterFrequency)*time.Millisecond), advanced.RateLimiterBurstSize)
+	m.limiters[host] = l
+	return l
+}
+
+// BenchmarkGetHostLimiter looks up the limiter of one host from 100 goroutines and reports the 99th percentile
+// latency of the lookup with the sync.Map and with the mutex guarded map
+func BenchmarkGetHostLimiter(b *testing.B) {
+	advanced := &AdvancedSettingsChecker{RateLimiterFrequency: 10, RateLimiterBurstSize: 100}
+	baseline := &mutexHostLimiters{limiters: make(map[string]*rate.Limiter)}
+	for _, bc := range []struct {
+		name string
+		get  func() *rate.Limiter
+	}{
+		{name: "sync.Map", get: func() *rate.Limiter { return getHostLimiter("bench-limiter.test", &Request{}, "a", advanced) }},
+		{name: "mutex", get: func() *rate.Limiter { return baseline.get("bench-limiter.test", advanced) }},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			const goroutines = 100
+			perGoroutine := b.N/goroutines + 1
+			latencies := make([][]time.Duration, goroutines)
+			var wg sync.WaitGroup
+			b.ResetTimer()
+			for g := 0; g < goroutines; g++ {
+				wg.Add(1)
+				go func(g int) {
+					defer wg.Done()
+					own := make([]time.Duration, perGoroutine)
+					for i := range own {
+						start := time.Now()
+						bc.get()
+						own[i] = time.Since(start)
+					}
+					latencies[g] = own
+				}(g)
+			}
+			wg.Wait()
+			b.StopTimer()
+
+			var all []time.Duration
+			for _, l := range latencies {
+				all = append(all, l...)
+			}
+			sort.Slice(all, func(i, j int) bool { return all[i] < all[j] })
+			b.ReportMetric(float64(all[len(all)*99/100].Nanoseconds()), "p99-ns")
+		})
+	}
+}
@@ -0,0 +1,131 @@
+// package templates - Redis INFO requests
+package templates
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/artnikel/nuclei/internal/constants"
+	"github.com/artnikel/nuclei/internal/logging"
+)
+
+const (
+	// defaultRedisPort is the port of Redis requests without a port option
+	defaultRedisPort = "6379"
+	// maxRedisReplySize limits the size of a read INFO reply
+	maxRedisReplySize = 1 << 20
+)
+
+// matchRedisRequest sends INFO to the Redis server of the host and matches the reply with the network matchers.
+// Options: port overrides the default port, password authenticates with AUTH first. An error reply such as
+// NOAUTH is matched as is
+func matchRedisRequest(ctx context.Context, host string, req *Request, tmpl *Template, advanced *AdvancedSettingsChecker, logger *logging.Logger) (bool, error) {
+	if req.Type != "redis" {
+		return false, fmt.Errorf("request type is not redis: %s", req.Type)
+	}
+
+	port := defaultRedisPort
+	if portVal, ok := req.Options["port"]; ok {
+		port = fmt.Sprint(portVal)
+	}
+	addr := net.JoinHostPort(host, port)
+
+	if advanced.DryRun {
+		logDryRun(advanced, logger, "Would connect: redis "+addr)
+		return false, nil
+	}
+
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(constants.FiveSecTimeout))
+	r := bufio.NewReader(conn)
+
+	if password, ok := req.Options["password"]; ok {
+		if _, err := conn.Write(redisCommand("AUTH", fmt.Sprint(password))); err != nil {
+			return false, err
+		}
+		reply, err := readRedisReply(r)
+		if err != nil {
+			return false, err
+		}
+		if strings.HasPrefix(reply, "ERR") || strings.HasPrefix(reply, "WRONGPASS") {
+			return false, fmt.Errorf("redis auth failed: %s", reply)
+		}
+	}
+
+	if _, err := conn.Write(redisCommand("INFO")); err != nil {
+		return false, err
+	}
+	reply, err := readRedisReply(r)
+	if err != nil {
+		return false, err
+	}
+
+	matchCtx := MatchContext{
+		Network: &NetworkResponse{
+			Data: []byte(reply),
+		},
+	}
+	matched := checkMatchers(req.Matchers, req.MatchersCondition, matchCtx)
+
+	logger.Info("Redis request matched",
+		slog.String("template_id", tmpl.ID),
+		slog.String("host", host),
+		slog.Bool("matched", matched),
+	)
+	return matched, nil
+}
+
+// redisCommand encodes the command as a RESP array of bulk strings
+func redisCommand(args ...string) []byte {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&sb, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	return []byte(sb.String())
+}
+
+// readRedisReply reads a simple string, error or bulk string reply, bulk strings are cut at maxRedisReplySize
+func readRedisReply(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	line = strings.TrimRight(line, "\r\n")
+	if line == "" {
+		return "", errors.New("empty redis reply")
+	}
+
+	switch line[0] {
+	case '+', '-', ':':
+		return line[1:], nil
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return "", fmt.Errorf("invalid redis bulk length: %s", line)
+		}
+		if n < 0 {
+			return "", nil
+		}
+		bs, err := io.ReadAll(io.LimitReader(r, int64(min(n, maxRedisReplySize))))
+		if err != nil {
+			return "", err
+		}
+		return string(bs), nil
+	default:
+		return "", fmt.Errorf("unexpected redis reply: %s", line)
+	}
+}
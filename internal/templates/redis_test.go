@@ -0,0 +1,157 @@
+package templates
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const redisInfo = "# Server\r\nredis_version:2.8.4\r\nredis_mode:standalone\r\nos:Linux 3.13.0-24-generic x86_64\r\n" +
+	"# Replication\r\nrole:master\r\nconnected_slaves:0\r\n"
+
+// serveRedis answers AUTH and INFO commands in the Redis protocol, INFO requires AUTH when password is set
+func serveRedis(t *testing.T, password string) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go handleRedisConn(conn, password)
+		}
+	}()
+	_, port, _ := net.SplitHostPort(l.Addr().String())
+	return port
+}
+
+func handleRedisConn(conn net.Conn, password string) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	authed := password == ""
+	for {
+		args, err := readRedisCommand(r)
+		if err != nil {
+			return
+		}
+		switch strings.ToUpper(args[0]) {
+		case "AUTH":
+			if len(args) == 2 && args[1] == password {
+				authed = true
+				fmt.Fprint(conn, "+OK\r\n")
+			} else {
+				fmt.Fprint(conn, "-WRONGPASS invalid username-password pair\r\n")
+			}
+		case "INFO":
+			if !authed {
+				fmt.Fprint(conn, "-NOAUTH Authentication required.\r\n")
+				continue
+			}
+			fmt.Fprintf(conn, "$%d\r\n%s\r\n", len(redisInfo), redisInfo)
+		default:
+			fmt.Fprintf(conn, "-ERR unknown command '%s'\r\n", args[0])
+		}
+	}
+}
+
+// readRedisCommand reads a RESP array of bulk strings
+func readRedisCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(strings.TrimRight(line, "\r\n"), "*"))
+	if err != nil || n < 1 {
+		return nil, fmt.Errorf("invalid command: %q", line)
+	}
+	args := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		if _, err := r.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		arg, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, strings.TrimRight(arg, "\r\n"))
+	}
+	return args, nil
+}
+
+const redisTemplate = `id: redis-exposed-%d
+info:
+  name: Exposed Redis
+  author: test
+  severity: high
+http:
+  - type: redis
+    options:
+      port: %s
+%s    matchers-condition: and
+    matchers:
+      - type: word
+        words:
+          - "redis_version:2"
+      - type: regex
+        regex:
+          - 'role:master'
+`
+
+func TestRedisRequest(t *testing.T) {
+	open := serveRedis(t, "")
+	protected := serveRedis(t, "s3cret")
+
+	tests := []struct {
+		name    string
+		port    string
+		options string
+		want    bool
+	}{
+		{name: "no auth", port: open, want: true},
+		{name: "auth required", port: protected, want: false},
+		{name: "password", port: protected, options: "      password: s3cret\n", want: true},
+		{name: "wrong password", port: protected, options: "      password: guess\n", want: false},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl := loadTestTemplate(t, fmt.Sprintf(redisTemplate, i, tt.port, tt.options))
+			matched, _, err := matchTemplate(context.Background(), "http://127.0.0.1", "", tmpl, nil, testSettings(), testLogger())
+			if err != nil {
+				t.Fatalf("matchTemplate: %v", err)
+			}
+			if matched != tt.want {
+				t.Errorf("redis matched = %v, want %v", matched, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadRedisReply(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "+OK\r\n", want: "OK"},
+		{in: "-NOAUTH Authentication required.\r\n", want: "NOAUTH Authentication required."},
+		{in: "$5\r\nhello\r\n", want: "hello"},
+		{in: "$-1\r\n", want: ""},
+		{in: "*1\r\n$4\r\nINFO\r\n", wantErr: true},
+		{in: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := readRedisReply(bufio.NewReader(strings.NewReader(tt.in)))
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("readRedisReply(%q) = %q, %v, want %q, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
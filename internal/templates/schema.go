@@ -0,0 +1,55 @@
+// package templates - template schema validation
+package templates
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed schema.json
+var templateSchemaJSON []byte
+
+// templateSchema is the compiled template JSON schema
+var templateSchema = gojsonschema.NewBytesLoader(templateSchemaJSON)
+
+// ValidationError lists all schema violations found in a template file
+type ValidationError struct {
+	Path       string
+	Violations []string
+}
+
+// Error implements the error interface
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("template %s failed schema validation: %s", e.Path, strings.Join(e.Violations, "; "))
+}
+
+// validateTemplateSchema validates the raw YAML template against the embedded JSON schema
+func validateTemplateSchema(path string, bs []byte) error {
+	var doc interface{}
+	if err := yaml.Unmarshal(bs, &doc); err != nil {
+		return fmt.Errorf("failed to parse template %s: %w", path, err)
+	}
+	jsonDoc, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("failed to convert template %s to JSON: %w", path, err)
+	}
+
+	result, err := gojsonschema.Validate(templateSchema, gojsonschema.NewBytesLoader(jsonDoc))
+	if err != nil {
+		return fmt.Errorf("failed to validate template %s: %w", path, err)
+	}
+	if result.Valid() {
+		return nil
+	}
+
+	vErr := &ValidationError{Path: path}
+	for _, desc := range result.Errors() {
+		vErr.Violations = append(vErr.Violations, desc.String())
+	}
+	return vErr
+}
@@ -0,0 +1,97 @@
+package templates
+
+import (
+	"errors"
+	"testing"
+)
+
+const validSchemaTemplate = `id: valid-template
+info:
+  name: Valid
+  author: test
+  severity: high
+http:
+  - path:
+      - "{{BaseURL}}/"
+    matchers:
+      - type: status
+        status: [200]
+`
+
+const missingIDTemplate = `info:
+  name: Missing id
+  author: test
+  severity: high
+http:
+  - path:
+      - "{{BaseURL}}/"
+    matchers:
+      - type: status
+        status: [200]
+`
+
+const unknownMatcherTemplate = `id: unknown-matcher
+info:
+  name: Unknown matcher
+  author: test
+  severity: high
+http:
+  - path:
+      - "{{BaseURL}}/"
+    matchers:
+      - type: stats
+        status: [200]
+`
+
+func TestLoadTemplateSchemaValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{name: "missing id", content: missingIDTemplate, wantErr: true},
+		{name: "unknown matcher type", content: unknownMatcherTemplate, wantErr: true},
+		{name: "valid template", content: validSchemaTemplate},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadTemplate(writeTestFile(t, t.TempDir(), "template.yaml", tt.content))
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("LoadTemplate: %v", err)
+				}
+				return
+			}
+			var vErr *ValidationError
+			if !errors.As(err, &vErr) {
+				t.Fatalf("error = %v, want *ValidationError", err)
+			}
+			if len(vErr.Violations) == 0 {
+				t.Error("validation error lists no violations")
+			}
+		})
+	}
+}
+
+func TestLoadTemplatesStrictSchema(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "valid.yaml", validSchemaTemplate)
+	writeTestFile(t, dir, "missing-id.yaml", missingIDTemplate)
+	writeTestFile(t, dir, "unknown-matcher.yaml", unknownMatcherTemplate)
+	advanced := testSettings()
+
+	var warnings int
+	tmpls, _, err := LoadTemplates(dir, nil, false, advanced, func(err error) { warnings++ })
+	if err != nil {
+		t.Fatalf("LoadTemplates: %v", err)
+	}
+	if len(tmpls) != 1 || tmpls[0].ID != "valid-template" || warnings != 2 {
+		t.Fatalf("loaded %d templates with %d warnings, want only the valid one and 2 warnings", len(tmpls), warnings)
+	}
+
+	_, _, err = LoadTemplates(dir, nil, true, advanced, nil)
+	var vErr *ValidationError
+	if !errors.As(err, &vErr) {
+		t.Fatalf("strict LoadTemplates error = %v, want *ValidationError", err)
+	}
+}
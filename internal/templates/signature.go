@@ -0,0 +1,80 @@
+// package templates - HMAC signing of template files
+package templates
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// SignaturePrefix starts the comment line carrying the template signature
+const SignaturePrefix = "# nuclei-signature:"
+
+// SignatureError reports a template whose signature is missing or doesn't match its content
+type SignatureError struct {
+	Path   string
+	Reason string
+}
+
+func (e *SignatureError) Error() string {
+	return fmt.Sprintf("template %s: %s", e.Path, e.Reason)
+}
+
+// SignTemplate returns the template content with its signature appended as the last line,
+// replacing an existing signature
+func SignTemplate(data, key []byte) []byte {
+	body, _ := splitSignature(data)
+	signed := append(append([]byte{}, body...), '\n')
+	return append(signed, []byte(SignaturePrefix+" "+templateSignature(body, key)+"\n")...)
+}
+
+// VerifyTemplateFile checks the signature of the template at path against key
+func VerifyTemplateFile(path string, key []byte) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if len(key) == 0 {
+		return errors.New("template signing key is not configured")
+	}
+	body, signature := splitSignature(data)
+	if signature == "" {
+		return &SignatureError{Path: path, Reason: "signature is missing"}
+	}
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return &SignatureError{Path: path, Reason: "signature is malformed"}
+	}
+	actual, _ := hex.DecodeString(templateSignature(body, key))
+	if !hmac.Equal(expected, actual) {
+		return &SignatureError{Path: path, Reason: "signature mismatch"}
+	}
+	return nil
+}
+
+// splitSignature separates the canonical template content, without trailing blank lines, from the hex signature
+// on its last line. The signature is empty if the last line isn't a signature
+func splitSignature(data []byte) ([]byte, string) {
+	body := bytes.TrimRight(data, "\r\n\t ")
+	idx := bytes.LastIndexByte(body, '\n')
+	last := body[idx+1:]
+	if !bytes.HasPrefix(last, []byte(SignaturePrefix)) {
+		return body, ""
+	}
+	signature := string(bytes.TrimSpace(last[len(SignaturePrefix):]))
+	if idx < 0 {
+		return nil, signature
+	}
+	return bytes.TrimRight(body[:idx], "\r\n\t "), signature
+}
+
+// templateSignature returns the hex HMAC-SHA256 of the canonical template content
+func templateSignature(body, key []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,155 @@
+package templates
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const signingKey = "test-signing-key"
+
+// signedTemplate returns a template with the given ID and extends, signed with key unless key is empty
+func signedTemplate(id, extends, key string) string {
+	var block string
+	if extends != "" {
+		block = "extends: " + extends + "\n"
+	}
+	content := fmt.Sprintf(`id: %s
+%sinfo:
+  name: Template %s
+  author: test
+  severity: info
+http:
+  - path:
+      - "{{BaseURL}}/%s"
+    matchers:
+      - type: status
+        status: [200]
+`, id, block, id, id)
+	if key == "" {
+		return content
+	}
+	return string(SignTemplate([]byte(content), []byte(key)))
+}
+
+func TestVerifyTemplateFile(t *testing.T) {
+	dir := t.TempDir()
+	signed := signedTemplate("signed", "", signingKey)
+	tests := []struct {
+		name    string
+		content string
+		key     string
+		reason  string
+	}{
+		{name: "valid", content: signed, key: signingKey},
+		{name: "trailing blank lines ignored", content: signed + "\n\n", key: signingKey},
+		{name: "resigned", content: string(SignTemplate([]byte(signed), []byte(signingKey))), key: signingKey},
+		{name: "tampered content", content: strings.Replace(signed, "severity: info", "severity: critical", 1), key: signingKey, reason: "signature mismatch"},
+		{name: "other key", content: signed, key: "other-key", reason: "signature mismatch"},
+		{name: "missing", content: signedTemplate("unsigned", "", ""), key: signingKey, reason: "signature is missing"},
+		{name: "malformed", content: signedTemplate("unsigned", "", "") + SignaturePrefix + " not-hex\n", key: signingKey, reason: "signature is malformed"},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeTestFile(t, dir, fmt.Sprintf("t%d.yaml", i), tt.content)
+			err := VerifyTemplateFile(path, []byte(tt.key))
+			if tt.reason == "" {
+				if err != nil {
+					t.Errorf("VerifyTemplateFile: %v", err)
+				}
+				return
+			}
+			var sErr *SignatureError
+			if !errors.As(err, &sErr) || sErr.Reason != tt.reason {
+				t.Errorf("VerifyTemplateFile error = %v, want %q", err, tt.reason)
+			}
+		})
+	}
+
+	path := writeTestFile(t, dir, "nokey.yaml", signed)
+	if err := VerifyTemplateFile(path, nil); err == nil {
+		t.Error("template verified without a signing key")
+	}
+}
+
+func TestLoadTemplatesVerifySignatures(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "valid.yaml", signedTemplate("valid", "", signingKey))
+	writeTestFile(t, dir, "invalid.yaml", strings.Replace(signedTemplate("invalid", "", signingKey), "/invalid", "/admin", 1))
+	writeTestFile(t, dir, "missing.yaml", signedTemplate("missing", "", ""))
+	advanced := testSettings()
+	advanced.VerifySignatures = true
+	advanced.TemplateSigningKey = signingKey
+
+	var warnings []error
+	tmpls, _, err := LoadTemplates(dir, nil, false, advanced, func(err error) { warnings = append(warnings, err) })
+	if err != nil {
+		t.Fatalf("LoadTemplates: %v", err)
+	}
+	if got := templateIDs(tmpls); got != "valid" {
+		t.Errorf("loaded templates = %s, want valid", got)
+	}
+	if len(warnings) != 2 {
+		t.Errorf("warnings = %v, want the invalid and missing signatures", warnings)
+	}
+
+	if _, _, err := LoadTemplates(dir, nil, true, advanced, nil); err == nil {
+		t.Error("strict LoadTemplates accepted templates with bad signatures")
+	}
+
+	advanced.VerifySignatures = false
+	tmpls, _, err = LoadTemplates(dir, nil, false, advanced, nil)
+	if err != nil || len(tmpls) != 3 {
+		t.Errorf("LoadTemplates without verification = %d templates, %v, want 3", len(tmpls), err)
+	}
+}
+
+func TestLoadTemplatesVerifiesBaseSignatures(t *testing.T) {
+	tests := []struct {
+		name    string
+		baseKey string
+		want    string
+	}{
+		{name: "signed base", baseKey: signingKey, want: "base,child"},
+		{name: "base signed with another key", baseKey: "other-key", want: ""},
+		{name: "unsigned base", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeTestFile(t, dir, "base.yaml", signedTemplate("base", "", tt.baseKey))
+			writeTestFile(t, dir, "child.yaml", signedTemplate("child", "base.yaml", signingKey))
+			advanced := testSettings()
+			advanced.VerifySignatures = true
+			advanced.TemplateSigningKey = signingKey
+
+			tmpls, _, err := LoadTemplates(dir, nil, false, advanced, func(error) {})
+			if err != nil {
+				t.Fatalf("LoadTemplates: %v", err)
+			}
+			if got := templateIDs(tmpls); got != tt.want {
+				t.Errorf("loaded templates = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtendsOutsideTemplateDir(t *testing.T) {
+	root := t.TempDir()
+	base := writeTestFile(t, root, "base.yaml", signedTemplate("base", "", ""))
+	dir := filepath.Join(root, "templates")
+	for _, extends := range []string{"../base.yaml", base, "sub/../../base.yaml"} {
+		path := writeTestFile(t, dir, "child.yaml", signedTemplate("child", extends, ""))
+		if _, err := LoadTemplate(path); err == nil || !strings.Contains(err.Error(), "outside the template directory") {
+			t.Errorf("extends %s: LoadTemplate error = %v, want it rejected", extends, err)
+		}
+	}
+
+	writeTestFile(t, dir, "base/http.yaml", signedTemplate("http-base", "", ""))
+	path := writeTestFile(t, dir, "child.yaml", signedTemplate("child", "base/http.yaml", ""))
+	if _, err := LoadTemplate(path); err != nil {
+		t.Errorf("extends of a base in a subdirectory: %v", err)
+	}
+}
@@ -0,0 +1,68 @@
+// package templates - built-in SQL error detection
+package templates
+
+import (
+	"regexp"
+)
+
+// Confidence levels of the sqlerror matcher, a higher level checks fewer, more specific patterns
+const (
+	ConfidenceLow    = "low"
+	ConfidenceMedium = "medium"
+	ConfidenceHigh   = "high"
+)
+
+// sqlErrorPattern is a database error message with the confidence that it indicates an injection
+type sqlErrorPattern struct {
+	Database   string
+	Confidence int
+	Re         *regexp.Regexp
+}
+
+// sqlErrorPatterns are the error messages checked by the sqlerror matcher, compiled once in init
+var sqlErrorPatterns []sqlErrorPattern
+
+func init() {
+	for _, p := range []struct {
+		database   string
+		confidence string
+		pattern    string
+	}{
+		{"mysql", ConfidenceHigh, `You have an error in your SQL syntax`},
+		{"mysql", ConfidenceMedium, `(?i)warning: mysqli?_`},
+		{"mssql", ConfidenceHigh, `Unclosed quotation mark after the character string`},
+		{"mssql", ConfidenceMedium, `(?i)Unclosed quotation mark`},
+		{"postgresql", ConfidenceHigh, `ERROR:\s+unterminated quoted string`},
+		{"oracle", ConfidenceMedium, `ORA-\d{5}`},
+		{"sqlite", ConfidenceLow, `(?i)SQLite(3::|\.Exception| error)`},
+	} {
+		sqlErrorPatterns = append(sqlErrorPatterns, sqlErrorPattern{
+			Database:   p.database,
+			Confidence: confidenceRank(p.confidence),
+			Re:         regexp.MustCompile(p.pattern),
+		})
+	}
+}
+
+// confidenceRank orders the confidence levels, unknown and empty levels are treated as low
+func confidenceRank(confidence string) int {
+	switch confidence {
+	case ConfidenceHigh:
+		return 2
+	case ConfidenceMedium:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// matchSQLError reports whether body contains a database error message with at least the given confidence
+func matchSQLError(body []byte, confidence string) bool {
+	minRank := confidenceRank(confidence)
+	for _, p := range sqlErrorPatterns {
+		if p.Confidence >= minRank && p.Re.Match(body) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+package templates
+
+import (
+	"net/http"
+	"testing"
+)
+
+// sqlErrorBodies holds a synthetic error page of every database family
+var sqlErrorBodies = map[string]string{
+	"mysql":      `<b>Error:</b> You have an error in your SQL syntax; check the manual that corresponds to your MySQL server version`,
+	"mssql":      `Microsoft OLE DB Provider for SQL Server error '80040e14' Unclosed quotation mark after the character string ''.`,
+	"postgresql": `pq: ERROR:  unterminated quoted string at or near "'1" LINE 1: SELECT * FROM users WHERE id='1`,
+	"oracle":     `java.sql.SQLException: ORA-01756: quoted string not properly terminated`,
+	"sqlite":     `Warning: SQLite3::query(): Unable to prepare statement: 1, unrecognized token`,
+}
+
+func TestMatchSQLError(t *testing.T) {
+	tests := []struct {
+		database   string
+		confidence string
+		want       bool
+	}{
+		{database: "mysql", confidence: ConfidenceHigh, want: true},
+		{database: "mssql", confidence: ConfidenceHigh, want: true},
+		{database: "postgresql", confidence: ConfidenceHigh, want: true},
+		{database: "oracle", confidence: ConfidenceMedium, want: true},
+		{database: "oracle", confidence: ConfidenceHigh, want: false},
+		{database: "sqlite", confidence: ConfidenceLow, want: true},
+		{database: "sqlite", confidence: "", want: true},
+		{database: "sqlite", confidence: ConfidenceMedium, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.database+"/"+tt.confidence, func(t *testing.T) {
+			if got := matchSQLError([]byte(sqlErrorBodies[tt.database]), tt.confidence); got != tt.want {
+				t.Errorf("matchSQLError(%s, %q) = %v, want %v", tt.database, tt.confidence, got, tt.want)
+			}
+		})
+	}
+
+	if matchSQLError([]byte("<html><body>Welcome back</body></html>"), ConfidenceLow) {
+		t.Error("sqlerror matched a page without database errors")
+	}
+}
+
+func TestSQLErrorMatcher(t *testing.T) {
+	resp := &http.Response{StatusCode: http.StatusInternalServerError, Header: http.Header{}}
+	for database, body := range sqlErrorBodies {
+		ctx := MatchContext{Resp: resp, Body: []byte(body)}
+		if !checkSingleMatcher(Matcher{Type: "sqlerror"}, ctx) {
+			t.Errorf("sqlerror matcher missed the %s error", database)
+		}
+	}
+	if checkSingleMatcher(Matcher{Type: "sqlerror"}, MatchContext{Resp: resp}) {
+		t.Error("sqlerror matcher matched a response without body")
+	}
+}
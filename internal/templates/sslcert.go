@@ -0,0 +1,43 @@
+// package templates - extraction of TLS certificate fields
+package templates
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// extractCertField returns the field of the leaf certificate presented by the server of resp
+func extractCertField(resp *http.Response, field string) (string, bool) {
+	if resp == nil || resp.TLS == nil || len(resp.TLS.PeerCertificates) == 0 {
+		return "", false
+	}
+	return certField(resp.TLS.PeerCertificates[0], field)
+}
+
+// certField returns subject-cn, issuer-cn, san (DNS names and IP addresses joined with commas),
+// fingerprint-sha256 (hex of the DER certificate) or not-after (RFC 3339) of the certificate
+func certField(cert *x509.Certificate, field string) (string, bool) {
+	var value string
+	switch strings.ToLower(strings.TrimSpace(field)) {
+	case "subject-cn":
+		value = cert.Subject.CommonName
+	case "issuer-cn":
+		value = cert.Issuer.CommonName
+	case "san":
+		sans := append([]string{}, cert.DNSNames...)
+		for _, ip := range cert.IPAddresses {
+			sans = append(sans, ip.String())
+		}
+		value = strings.Join(sans, ",")
+	case "fingerprint-sha256":
+		sum := sha256.Sum256(cert.Raw)
+		value = hex.EncodeToString(sum[:])
+	case "not-after":
+		value = cert.NotAfter.UTC().Format(time.RFC3339)
+	}
+	return value, value != ""
+}
@@ -0,0 +1,158 @@
+package templates
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// testCertNotAfter is the expiry of the certificates made by newTestCert
+var testCertNotAfter = time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// newTestCert returns a leaf certificate for app.example.test signed by a throwaway CA
+func newTestCert(t *testing.T) tls.Certificate {
+	t.Helper()
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test Root CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              testCertNotAfter,
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, ca, ca, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, _ = x509.ParseCertificate(caDER)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "app.example.test"},
+		DNSNames:     []string{"app.example.test", "www.example.test"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     testCertNotAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, leaf, ca, &key.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der, caDER}, PrivateKey: key}
+}
+
+// certFingerprint returns the hex SHA-256 of the DER certificate
+func certFingerprint(der []byte) string {
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestCertField(t *testing.T) {
+	pair := newTestCert(t)
+	cert, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		field string
+		want  string
+	}{
+		{field: "subject-cn", want: "app.example.test"},
+		{field: "issuer-cn", want: "Test Root CA"},
+		{field: "san", want: "app.example.test,www.example.test,127.0.0.1"},
+		{field: "fingerprint-sha256", want: certFingerprint(pair.Certificate[0])},
+		{field: "NOT-AFTER", want: "2030-01-02T03:04:05Z"},
+		{field: "serial"},
+	}
+	for _, tt := range tests {
+		got, ok := certField(cert, tt.field)
+		if got != tt.want || ok != (tt.want != "") {
+			t.Errorf("certField(%s) = %q, %v, want %q", tt.field, got, ok, tt.want)
+		}
+	}
+}
+
+const sslExtractorTemplate = `id: ssl-fields
+info:
+  name: TLS certificate fields
+  author: test
+  severity: info
+http:
+  - path:
+      - "{{BaseURL}}/"
+    matchers:
+      - type: status
+        status: [200]
+    extractors:
+      - type: ssl
+        name: cn
+        field: subject-cn
+      - type: ssl
+        name: issuer
+        field: issuer-cn
+      - type: ssl
+        name: san
+        field: san
+      - type: ssl
+        name: fingerprint
+        field: fingerprint-sha256
+      - type: ssl
+        name: expires
+        field: not-after
+`
+
+func TestSSLExtractor(t *testing.T) {
+	pair := newTestCert(t)
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv.TLS = &tls.Config{Certificates: []tls.Certificate{pair}}
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+
+	tmpl := loadTestTemplate(t, sslExtractorTemplate)
+	matched, extracted, err := matchTemplate(context.Background(), srv.URL, "", tmpl, nil, testSettings(), testLogger())
+	if err != nil || !matched {
+		t.Fatalf("matchTemplate = %v, %v, want a match", matched, err)
+	}
+	want := map[string]string{
+		"cn":          "app.example.test",
+		"issuer":      "Test Root CA",
+		"san":         "app.example.test,www.example.test,127.0.0.1",
+		"fingerprint": certFingerprint(pair.Certificate[0]),
+		"expires":     "2030-01-02T03:04:05Z",
+	}
+	for name, value := range want {
+		if extracted[name] != value {
+			t.Errorf("%s = %q, want %q", name, extracted[name], value)
+		}
+	}
+
+	plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(plain.Close)
+	_, extracted, err = matchTemplate(context.Background(), plain.URL, "", tmpl, nil, testSettings(), testLogger())
+	if err != nil || len(extracted) != 0 {
+		t.Errorf("plain HTTP extracted %v, %v, want nothing", extracted, err)
+	}
+}
@@ -0,0 +1,146 @@
+// package templates - gob cache of parsed templates
+package templates
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+)
+
+// templateCacheDirName is the directory inside the user cache dir holding cached templates by default
+var templateCacheDirName = filepath.Join("nuclei", "templates")
+
+// templateCacheDirPerm keeps other users from planting entries that would be decoded as templates
+const templateCacheDirPerm = 0o700
+
+// templateCacheVersion is part of the cache file names, bump it when the Template struct changes
+// so entries written by older builds are not decoded with missing fields
+const templateCacheVersion = "12"
+
+func init() {
+	// YAML decodes nested variables, payloads and options into these types
+	gob.Register(map[string]interface{}{})
+	gob.Register([]interface{}{})
+}
+
+// cachedTemplate is a cache entry, SourceHash is the sha256 of the YAML the template was parsed from
+type cachedTemplate struct {
+	SourceHash string
+	Template   *Template
+}
+
+// DefaultTemplateCacheDir returns the directory parsed templates are cached in when none is configured,
+// empty when the OS has no user cache dir
+func DefaultTemplateCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, templateCacheDirName)
+}
+
+// ClearTemplateCache removes all cached templates from cacheDir
+func ClearTemplateCache(cacheDir string) error {
+	return os.RemoveAll(cacheDir)
+}
+
+// loadTemplateCached returns the template at path from the cache in cacheDir when the cache entry was parsed
+// from the current YAML, otherwise it parses the YAML and refreshes the cache. An empty cacheDir or one other
+// users could write to disables caching. A non-nil verify checks the signatures of the base templates
+func loadTemplateCached(path, cacheDir string, verify func(path string) error) (*Template, error) {
+	if cacheDir == "" || !prepareCacheDir(cacheDir) {
+		return loadTemplate(path, make(map[string]bool), verify)
+	}
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return loadTemplate(path, make(map[string]bool), verify)
+	}
+	sum := sha256.Sum256(source)
+	sourceHash := hex.EncodeToString(sum[:])
+	cachePath, err := templateCachePath(path, cacheDir)
+	if err != nil {
+		return loadTemplate(path, make(map[string]bool), verify)
+	}
+
+	if tmpl, ok := readCachedTemplate(cachePath, sourceHash); ok {
+		if err := checkToolVersion(tmpl); err != nil {
+			return nil, err
+		}
+		tmpl.FilePath = path
+		return tmpl, nil
+	}
+
+	tmpl, err := loadTemplate(path, make(map[string]bool), verify)
+	if err != nil {
+		return nil, err
+	}
+	// the cache entry is only checked against the template file, so a change of the base would go unnoticed
+	if tmpl.Extends == "" {
+		writeCachedTemplate(cachePath, sourceHash, tmpl)
+	}
+	return tmpl, nil
+}
+
+// prepareCacheDir creates cacheDir and reports whether its entries can be trusted: it must be a real directory
+// owned by the current user and not writable by group or others
+func prepareCacheDir(cacheDir string) bool {
+	if err := os.MkdirAll(cacheDir, templateCacheDirPerm); err != nil {
+		return false
+	}
+	info, err := os.Lstat(cacheDir)
+	if err != nil || !info.IsDir() {
+		return false
+	}
+	return info.Mode().Perm()&0o022 == 0 && ownedByCurrentUser(info)
+}
+
+// templateCachePath returns the cache file of the template, named after its basename and a hash of its absolute path
+// and the cache version
+func templateCachePath(path, cacheDir string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(templateCacheVersion + ":" + abs))
+	return filepath.Join(cacheDir, hex.EncodeToString(sum[:8])+"-"+filepath.Base(path)+".gob"), nil
+}
+
+// readCachedTemplate decodes the cached template if it was parsed from YAML with sourceHash
+func readCachedTemplate(cachePath, sourceHash string) (*Template, bool) {
+	data, err := os.ReadFile(cachePath)
+	if err != nil {
+		return nil, false
+	}
+	var entry cachedTemplate
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&entry); err != nil {
+		return nil, false
+	}
+	if entry.SourceHash != sourceHash || entry.Template == nil {
+		return nil, false
+	}
+	return entry.Template, true
+}
+
+// writeCachedTemplate stores the parsed template, failures only cost a YAML parse on the next load
+func writeCachedTemplate(cachePath, sourceHash string, tmpl *Template) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(cachedTemplate{SourceHash: sourceHash, Template: tmpl}); err != nil {
+		return
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(cachePath), ".cache-*")
+	if err != nil {
+		return
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		return
+	}
+	if err := tmp.Close(); err != nil {
+		return
+	}
+	_ = os.Rename(tmp.Name(), cachePath)
+}
@@ -0,0 +1,191 @@
+package templates
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+const cachedTemplateYAML = `id: cached
+info:
+  name: %s
+  author: test
+  severity: info
+http:
+  - path:
+      - "{{BaseURL}}/"
+    matchers:
+      - type: status
+        status: [200]
+`
+
+func TestDefaultTemplateCacheDir(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("XDG_CACHE_HOME is only honoured on linux")
+	}
+	base := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", base)
+	if got, want := DefaultTemplateCacheDir(), filepath.Join(base, "nuclei", "templates"); got != want {
+		t.Errorf("DefaultTemplateCacheDir = %q, want %q", got, want)
+	}
+}
+
+// plantCachedTemplate overwrites the cache entry of path with a template named name recorded for sourceHash
+func plantCachedTemplate(t *testing.T, path, cacheDir, sourceHash, name string) {
+	t.Helper()
+	cachePath, err := templateCachePath(path, cacheDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	entry := cachedTemplate{SourceHash: sourceHash, Template: &Template{ID: "cached", Info: Info{Name: name}}}
+	if err := gob.NewEncoder(&buf).Encode(entry); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(cachePath, buf.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// cachedSourceHash returns the source hash stored in the cache entry of path
+func cachedSourceHash(t *testing.T, path, cacheDir string) string {
+	t.Helper()
+	cachePath, _ := templateCachePath(path, cacheDir)
+	data, err := os.ReadFile(cachePath)
+	if err != nil {
+		t.Fatalf("cache entry not written: %v", err)
+	}
+	var entry cachedTemplate
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&entry); err != nil {
+		t.Fatal(err)
+	}
+	return entry.SourceHash
+}
+
+func TestLoadTemplateCached(t *testing.T) {
+	dir := t.TempDir()
+	cacheDir := filepath.Join(t.TempDir(), "cache")
+	path := writeTestFile(t, dir, "cached.yaml", fmt.Sprintf(cachedTemplateYAML, "Original"))
+
+	tmpl, err := loadTemplateCached(path, cacheDir, nil)
+	if err != nil {
+		t.Fatalf("loadTemplateCached: %v", err)
+	}
+	if tmpl.Info.Name != "Original" {
+		t.Fatalf("name = %q, want Original", tmpl.Info.Name)
+	}
+	info, err := os.Stat(cacheDir)
+	if err != nil {
+		t.Fatalf("cache dir not created: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != templateCacheDirPerm {
+		t.Errorf("cache dir permissions = %o, want %o", perm, templateCacheDirPerm)
+	}
+	sourceHash := cachedSourceHash(t, path, cacheDir)
+
+	t.Run("entry of the current yaml is used", func(t *testing.T) {
+		plantCachedTemplate(t, path, cacheDir, sourceHash, "From cache")
+		tmpl, err := loadTemplateCached(path, cacheDir, nil)
+		if err != nil || tmpl.Info.Name != "From cache" || tmpl.FilePath != path {
+			t.Errorf("loadTemplateCached = %+v, %v, want the cache entry", tmpl, err)
+		}
+	})
+
+	t.Run("entry of other yaml is ignored", func(t *testing.T) {
+		plantCachedTemplate(t, path, cacheDir, "0000", "Poisoned")
+		tmpl, err := loadTemplateCached(path, cacheDir, nil)
+		if err != nil || tmpl.Info.Name != "Original" {
+			t.Errorf("loadTemplateCached = %+v, %v, want the parsed yaml", tmpl, err)
+		}
+		if cachedSourceHash(t, path, cacheDir) != sourceHash {
+			t.Error("cache entry not refreshed from the yaml")
+		}
+	})
+
+	t.Run("edited yaml is parsed again", func(t *testing.T) {
+		writeTestFile(t, dir, "cached.yaml", fmt.Sprintf(cachedTemplateYAML, "Edited"))
+		tmpl, err := loadTemplateCached(path, cacheDir, nil)
+		if err != nil || tmpl.Info.Name != "Edited" {
+			t.Errorf("loadTemplateCached = %+v, %v, want the edited yaml", tmpl, err)
+		}
+	})
+
+	t.Run("corrupt entry is ignored", func(t *testing.T) {
+		cachePath, _ := templateCachePath(path, cacheDir)
+		if err := os.WriteFile(cachePath, []byte("not gob"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := loadTemplateCached(path, cacheDir, nil); err != nil {
+			t.Errorf("loadTemplateCached: %v", err)
+		}
+	})
+}
+
+func TestLoadTemplateCachedUntrustedDir(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix permission bits")
+	}
+	dir := t.TempDir()
+	path := writeTestFile(t, dir, "cached.yaml", fmt.Sprintf(cachedTemplateYAML, "Original"))
+	sum := sha256.Sum256([]byte(fmt.Sprintf(cachedTemplateYAML, "Original")))
+
+	shared := filepath.Join(t.TempDir(), "shared")
+	if err := os.Mkdir(shared, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	plantCachedTemplate(t, path, shared, hex.EncodeToString(sum[:]), "Poisoned")
+	if err := os.Chmod(shared, 0o777); err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl, err := loadTemplateCached(path, shared, nil)
+	if err != nil || tmpl.Info.Name != "Original" {
+		t.Errorf("loadTemplateCached = %+v, %v, want the parsed yaml", tmpl, err)
+	}
+
+	link := filepath.Join(t.TempDir(), "link")
+	if err := os.Symlink(shared, link); err != nil {
+		t.Skip("symlinks unsupported")
+	}
+	if prepareCacheDir(link) {
+		t.Error("symlinked cache dir trusted")
+	}
+}
+
+// BenchmarkLoadTemplatesCache loads 1000 templates with an empty cache, which parses the YAML and writes the
+// entries, and with a warm cache
+func BenchmarkLoadTemplatesCache(b *testing.B) {
+	dir := writeHostTemplates(b, 1000)
+	cacheDir := filepath.Join(b.TempDir(), "cache")
+	advanced := &AdvancedSettingsChecker{CacheDir: cacheDir}
+
+	b.Run("cold", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			if err := ClearTemplateCache(cacheDir); err != nil {
+				b.Fatal(err)
+			}
+			b.StartTimer()
+			if _, _, err := LoadTemplates(dir, nil, false, advanced, nil); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("warm", func(b *testing.B) {
+		if _, _, err := LoadTemplates(dir, nil, false, advanced, nil); err != nil {
+			b.Fatal(err)
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, _, err := LoadTemplates(dir, nil, false, advanced, nil); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
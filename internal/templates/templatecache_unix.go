@@ -0,0 +1,15 @@
+//go:build !windows
+// +build !windows
+
+package templates
+
+import (
+	"os"
+	"syscall"
+)
+
+// ownedByCurrentUser reports whether the file belongs to the user running the scanner
+func ownedByCurrentUser(info os.FileInfo) bool {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	return ok && int(st.Uid) == os.Getuid()
+}
@@ -3,11 +3,15 @@ package templates
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io/fs"
+	"log/slog"
+	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -16,19 +20,117 @@ import (
 	"slices"
 
 	"github.com/artnikel/nuclei/internal/constants"
+	"github.com/artnikel/nuclei/internal/dedup"
+	"github.com/artnikel/nuclei/internal/discover"
 	"github.com/artnikel/nuclei/internal/logging"
+	"github.com/artnikel/nuclei/internal/metrics"
 	"github.com/artnikel/nuclei/internal/templates/headless"
+	"github.com/artnikel/nuclei/internal/waf"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"gopkg.in/yaml.v3"
 )
 
 type AdvancedSettingsChecker struct {
-	HeadlessTabs         int
-	RateLimiterFrequency int
-	RateLimiterBurstSize int
+	HeadlessTabs         int           `json:"headlessTabs"`
+	RateLimiterFrequency int           `json:"rateLimiterFrequency"`
+	RateLimiterBurstSize int           `json:"rateLimiterBurstSize"`
+	Proxy                string        `json:"proxy,omitempty"`
+	Retries              int           `json:"retries,omitempty"`
+	RetryDelay           time.Duration `json:"retryDelay,omitempty"`
+	DisableHeadless      bool          `json:"disableHeadless,omitempty"`
+	// Respect429 retries 429 responses, independently of Retries, after the delay of their Retry-After header capped
+	// at RetryMaxDelay if set
+	Respect429    bool          `json:"respect429,omitempty"`
+	RetryMaxDelay time.Duration `json:"retryMaxDelay,omitempty"`
+	// MaxHTTPRedirects is the number of redirects followed per HTTP request, 0 disables following them
+	MaxHTTPRedirects int `json:"maxHTTPRedirects"`
+	// MaxPayloadCombinations caps the number of payload sets generated per request, 0 means no limit
+	MaxPayloadCombinations int `json:"maxPayloadCombinations,omitempty"`
+	// IDNNormalize converts internationalized target hostnames to punycode before sending requests
+	IDNNormalize bool `json:"idnNormalize,omitempty"`
+	// SkipOnWAF lists template tags that are not run when a WAF is detected in front of the target, the target is
+	// only probed for a WAF when it is set
+	SkipOnWAF []string `json:"skipOnWAF,omitempty"`
+	// EnablePathDiscovery collects paths from robots.txt and sitemap.xml into the {{DiscoveredPaths}} variable
+	EnablePathDiscovery bool `json:"enablePathDiscovery,omitempty"`
+	// RescanInterval skips target and template pairs checked within the interval, requires TargetCache
+	RescanInterval time.Duration      `json:"rescanInterval,omitempty"`
+	TargetCache    *dedup.TargetCache `json:"-"`
+	// MaxJSFiles caps the number of external scripts fetched for js-paths extractors, 0 means no limit
+	MaxJSFiles int `json:"maxJSFiles,omitempty"`
+	// MaxIdleConnsPerHost, MaxConnsPerHost and DisableKeepAlives tune the connection pool of the shared HTTP client
+	MaxIdleConnsPerHost int  `json:"maxIdleConnsPerHost,omitempty"`
+	MaxConnsPerHost     int  `json:"maxConnsPerHost,omitempty"`
+	DisableKeepAlives   bool `json:"disableKeepAlives,omitempty"`
+	// CacheDir stores parsed templates as gob files to skip YAML parsing on later loads, empty disables the cache
+	CacheDir string `json:"cacheDir,omitempty"`
+	// PreScanPortCheck skips the target when its port doesn't accept connections within PortCheckTimeout
+	PreScanPortCheck bool          `json:"preScanPortCheck,omitempty"`
+	PortCheckTimeout time.Duration `json:"portCheckTimeout,omitempty"`
+	// StopOnFirstHostMatch stops scanning a host with the remaining templates once any template matched
+	StopOnFirstHostMatch bool `json:"stopOnFirstHostMatch,omitempty"`
+	// EnableDeduplication removes targets that are the same URL after normalization before scanning
+	EnableDeduplication bool `json:"enableDeduplication,omitempty"`
+	// Paused holds back workers from starting new targets while set
+	Paused *atomic.Bool `json:"-"`
+	// VerifySignatures rejects templates without a valid signature made with TemplateSigningKey
+	VerifySignatures   bool   `json:"verifySignatures,omitempty"`
+	TemplateSigningKey string `json:"-"`
+	// DryRun logs the requests the templates would send instead of sending them, OnDryRun receives every logged line
+	DryRun   bool              `json:"dryRun,omitempty"`
+	OnDryRun func(line string) `json:"-"`
+	// MaxFlowIterations caps the runs of the body of a flow foreach loop, 0 means no limit
+	MaxFlowIterations int `json:"maxFlowIterations,omitempty"`
+	// FlowTimeoutBehavior is FlowTimeoutSkip (default) or FlowTimeoutFail for flow requests exceeding their timeout
+	FlowTimeoutBehavior string `json:"flowTimeoutBehavior,omitempty"`
+	// FailOnNoMatchers makes running a template without matchers an error instead of a logged non-match
+	FailOnNoMatchers bool `json:"failOnNoMatchers,omitempty"`
+	// NotificationsEnabled sends a desktop notification when a GUI scan completes
+	NotificationsEnabled bool `json:"notificationsEnabled,omitempty"`
+	// Profile drops the templates it doesn't allow before the scan, profile files in the templates folder are not loaded as templates
+	Profile *Profile `json:"-"`
+	// Workers caps the templates FindMatchingTemplates runs at once, 0 means no limit
+	Workers int `json:"workers,omitempty"`
+	// MaxConcurrentTemplatesPerHost caps the templates run at once against a host across all targets on it,
+	// 0 defaults to DefaultMaxConcurrentTemplatesPerHost of Workers
+	MaxConcurrentTemplatesPerHost int `json:"maxConcurrentTemplatesPerHost,omitempty"`
+	// OnFinding receives every finding of FindMatchingTemplates as soon as its template matched, OnPass every
+	// template that ran against a target without matching
+	OnFinding func(f *Finding)                                        `json:"-"`
+	OnPass    func(target, templateID string, duration time.Duration) `json:"-"`
 }
 
-// LoadTemplate loads and parses YAML template from the specified path
+// DefaultAdvancedSettings returns the settings used when none are configured explicitly
+func DefaultAdvancedSettings() *AdvancedSettingsChecker {
+	return &AdvancedSettingsChecker{
+		HeadlessTabs:         10,
+		RateLimiterFrequency: 10,
+		RateLimiterBurstSize: 100,
+
+		MaxPayloadCombinations: DefaultMaxPayloadCombinations,
+		IDNNormalize:           true,
+		MaxJSFiles:             20,
+		MaxIdleConnsPerHost:    DefaultMaxIdleConnsPerHost(0),
+		CacheDir:               DefaultTemplateCacheDir(),
+		PortCheckTimeout:       constants.FiveSecTimeout,
+		EnableDeduplication:    true,
+		MaxFlowIterations:      100,
+		Respect429:             true,
+		MaxHTTPRedirects:       DefaultMaxHTTPRedirects,
+		RetryMaxDelay:          constants.OneMinTimeout,
+	}
+}
+
+// LoadTemplate loads and parses YAML template from the specified path, validating it against the template schema.
+// A template with extends is merged with the base template it names
 func LoadTemplate(path string) (*Template, error) {
+	return loadTemplate(path, make(map[string]bool), nil)
+}
+
+// loadTemplate loads the template at path, seen holds the absolute paths of the templates extending it.
+// A non-nil verify checks the signatures of the base templates
+func loadTemplate(path string, seen map[string]bool, verify func(path string) error) (*Template, error) {
 	if !(strings.HasSuffix(path, constants.YamlFileFormat) || strings.HasSuffix(path, constants.YmlFileFormat)) {
 		return nil, fmt.Errorf("file is not a YAML template: %s", path)
 	}
@@ -42,16 +144,74 @@ func LoadTemplate(path string) (*Template, error) {
 	if err := yaml.Unmarshal(bs, tmpl); err != nil {
 		return nil, fmt.Errorf("failed to parse template %s: %w", path, err)
 	}
+	if err := validateTemplateSchema(path, bs); err != nil {
+		return nil, err
+	}
+	if err := checkToolVersion(tmpl); err != nil {
+		return nil, err
+	}
 	tmpl.NormalizeRequests()
 
 	tmpl.Requests = append(tmpl.Requests, tmpl.RequestsRaw...)
-	tmpl.Requests = append(tmpl.Requests, tmpl.HTTPRaw...)
+	tmpl.FilePath = path
+
+	if tmpl.Extends != "" {
+		if err := extendTemplate(tmpl, path, seen, verify); err != nil {
+			return nil, err
+		}
+	}
+	if tmpl.Flow != "" {
+		if _, err := parseFlow(tmpl.Flow); err != nil {
+			return nil, fmt.Errorf("template %s: %w", tmpl.ID, err)
+		}
+	}
+	return tmpl, nil
+}
 
+// MinimalTemplate holds the fields of a template needed to decide whether it applies to a target
+type MinimalTemplate struct {
+	ID    string   `yaml:"id"`
+	Hosts []string `yaml:"hosts,omitempty"`
+}
+
+// quickLoadTemplate parses only the id and hosts of the template at path
+func quickLoadTemplate(path string) (*MinimalTemplate, error) {
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	tmpl := &MinimalTemplate{}
+	if err := yaml.Unmarshal(bs, tmpl); err != nil {
+		return nil, fmt.Errorf("failed to parse template %s: %w", path, err)
+	}
 	return tmpl, nil
 }
 
-// LoadTemplates loads and parses YAML templates from the specified directory
-func LoadTemplates(dir string) ([]*Template, error) {
+// LoadTemplates loads and parses YAML templates from the specified directory, keeping only templates
+// tagged with one of tagFilter when it is non-empty. Parsed templates are cached in advanced.CacheDir and
+// signatures are checked if advanced.VerifySignatures is set, a nil advanced disables both.
+// Templates failing schema or signature validation are skipped and passed to warn, unless strict is set.
+// The returned index covers the returned templates
+func LoadTemplates(dir string, tagFilter []string, strict bool, advanced *AdvancedSettingsChecker, warn func(err error)) ([]*Template, *TemplateIndex, error) {
+	tmpls, err := loadTemplatesForHost(dir, "", tagFilter, strict, advanced, warn)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tmpls, NewTemplateIndex(tmpls), nil
+}
+
+// loadTemplatesForHost works like LoadTemplates, and when targetHost is set it skips the full parse
+// of templates whose hosts list excludes the target
+func loadTemplatesForHost(dir, targetHost string, tagFilter []string, strict bool, advanced *AdvancedSettingsChecker, warn func(err error)) ([]*Template, error) {
+	if advanced == nil {
+		advanced = &AdvancedSettingsChecker{}
+	}
+	var verify func(path string) error
+	if advanced.VerifySignatures {
+		verify = func(path string) error {
+			return VerifyTemplateFile(path, []byte(advanced.TemplateSigningKey))
+		}
+	}
 	var templates []*Template
 	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
@@ -63,52 +223,199 @@ func LoadTemplates(dir string) ([]*Template, error) {
 		if !(strings.HasSuffix(d.Name(), constants.YamlFileFormat) || strings.HasSuffix(d.Name(), constants.YmlFileFormat)) {
 			return nil
 		}
-		bs, err := os.ReadFile(path)
+		if targetHost != "" {
+			minimal, err := quickLoadTemplate(path)
+			if err == nil && !hostsMatch(minimal.Hosts, targetHost) {
+				return nil
+			}
+		}
+		if verify != nil {
+			if err := verify(path); err != nil {
+				if isProfileFile(path) {
+					return nil
+				}
+				var sErr *SignatureError
+				if errors.As(err, &sErr) && !strict {
+					if warn != nil {
+						warn(err)
+					}
+					return nil
+				}
+				return err
+			}
+		}
+		tmpl, err := loadTemplateCached(path, advanced.CacheDir, verify)
 		if err != nil {
+			if isProfileFile(path) {
+				return nil
+			}
+			var vErr *ValidationError
+			var sErr *SignatureError
+			if (errors.As(err, &vErr) || errors.As(err, &sErr)) && !strict {
+				if warn != nil {
+					warn(err)
+				}
+				return nil
+			}
 			return err
 		}
-		tmpl := &Template{}
-		if err := yaml.Unmarshal(bs, tmpl); err != nil {
-			return fmt.Errorf("failed to parse template %s: %w", path, err)
-		}
-		tmpl.NormalizeRequests()
-		tmpl.Requests = append(tmpl.Requests, tmpl.RequestsRaw...)
-		tmpl.Requests = append(tmpl.Requests, tmpl.HTTPRaw...)
-
 		templates = append(templates, tmpl)
 		return nil
 	})
 	if err != nil {
 		return nil, err
 	}
-	return templates, nil
+	return NewTemplateIndex(templates).ByTags(tagFilter), nil
 }
 
-// FindMatchingTemplates searches for matching templates for the specified URL, executing them in parallel
+// FindMatchingTemplates searches for matching templates for the specified URL, executing them in parallel,
+// and returns a finding with the extracted values for every match
 func FindMatchingTemplates(ctx context.Context,
 	targetURL string,
 	templatesDir string,
+	tagFilter []string,
+	severityFilter []string,
 	timeout time.Duration,
 	advanced *AdvancedSettingsChecker,
 	logger *logging.Logger,
-	progressCallback func(i, total int)) ([]*Template, error) {
-	templates, err := LoadTemplates(templatesDir)
+	progressCallback func(i, total int)) ([]*Finding, error) {
+	metrics.TargetsTotal.Inc()
+	start := time.Now()
+	defer func() {
+		metrics.ScanDuration.Observe(time.Since(start).Seconds())
+		metrics.TargetsProcessed.Inc()
+	}()
+
+	parsedURL, err := url.Parse(targetURL)
 	if err != nil {
+		metrics.ErrorsTotal.Inc()
 		return nil, err
 	}
 
+	templates, err := loadTemplatesForHost(templatesDir, parsedURL.Hostname(), tagFilter, false, advanced, func(err error) {
+		logger.Warn("Skipping invalid template", slog.Any("error", err))
+	})
+	if err != nil {
+		metrics.ErrorsTotal.Inc()
+		return nil, err
+	}
+
+	findings, err := ScanTemplates(ctx, targetURL, templates, severityFilter, timeout, advanced, logger, progressCallback)
+	if err != nil {
+		metrics.ErrorsTotal.Inc()
+	}
+	return findings, err
+}
+
+// detectWAF sends the WAF probe to the target through the shared HTTP client, taking a slot of the host semaphore
+// and waiting for the host rate limiter like template requests do. It returns nil when no WAF is detected or the
+// probe fails
+func detectWAF(ctx context.Context, targetURL, host string, hostSem chan struct{}, timeout time.Duration, advanced *AdvancedSettingsChecker, logger *logging.Logger) *waf.WAFInfo {
+	client, releaseClient, err := getHTTPClient(advanced)
+	if err != nil {
+		logger.Info("WAF detection failed", slog.String("target", targetURL), slog.Any("error", err))
+		return nil
+	}
+	defer releaseClient()
+
+	if !acquire(ctx, hostSem) {
+		return nil
+	}
+	defer release(hostSem)
+	if err := getHostLimiter(host, &Request{}, "", advanced).Wait(ctx); err != nil {
+		return nil
+	}
+
+	probeClient := *client
+	probeClient.Timeout = timeout
+	wafInfo, err := waf.NewDetector().Detect(ctx, targetURL, &probeClient)
+	if err != nil {
+		logger.Info("WAF detection failed", slog.String("target", targetURL), slog.Any("error", err))
+		return nil
+	}
+	if wafInfo != nil {
+		logger.Warn("WAF detected",
+			slog.String("target", targetURL),
+			slog.String("waf", wafInfo.Name),
+			slog.Float64("confidence", wafInfo.Confidence),
+		)
+	}
+	return wafInfo
+}
+
+// ScanTemplates runs the loaded templates against the target the way FindMatchingTemplates does: the templates
+// are filtered by the profile and run in dependency order, with the port check, WAF detection, path discovery and
+// rescan cache of the settings applied. Callers scanning many targets load the templates once and count the
+// targets themselves
+func ScanTemplates(ctx context.Context,
+	targetURL string,
+	templates []*Template,
+	severityFilter []string,
+	timeout time.Duration,
+	advanced *AdvancedSettingsChecker,
+	logger *logging.Logger,
+	progressCallback func(i, total int)) ([]*Finding, error) {
 	parsedURL, err := url.Parse(targetURL)
 	if err != nil {
 		return nil, err
 	}
 	targetHost := parsedURL.Hostname()
 
-	htmlContent, err := headless.DoHeadlessRequest(ctx, targetURL, advanced.HeadlessTabs)
+	templates = ApplyProfile(templates, advanced.Profile)
+	metrics.TemplatesLoaded.Set(float64(len(templates)))
+
+	templates, err = sortByDependencies(templates)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch HTML for %s: %w", targetURL, err)
+		return nil, err
+	}
+
+	if advanced.DryRun {
+		return dryRunTemplates(ctx, targetURL, templates, severityFilter, targetHost, advanced, logger, progressCallback)
+	}
+
+	if recentlyScanned(targetURL, templates, advanced) {
+		logger.Info("Target scanned within the rescan interval, skipping", slog.String("target", targetURL))
+		progressCallback(len(templates), len(templates))
+		return nil, nil
+	}
+
+	if advanced.PreScanPortCheck && !isPortOpen(ctx, parsedURL, advanced.PortCheckTimeout) {
+		logger.Info("Port closed, skipping target", slog.String("target", targetURL), slog.String("port", targetPort(parsedURL)))
+		progressCallback(len(templates), len(templates))
+		return nil, nil
+	}
+
+	var htmlContent string
+	if advanced.DisableHeadless {
+		htmlContent, err = FetchHTML(ctx, targetURL, timeout)
+		if err != nil {
+			logger.Info("Failed to fetch HTML", slog.String("target", targetURL), slog.Any("error", err))
+		}
+	} else {
+		htmlContent, err = headless.DoHeadlessRequest(ctx, targetURL, advanced.HeadlessTabs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch HTML for %s: %w", targetURL, err)
+		}
+	}
+
+	// templates take a slot before they start, required templates come first so they never wait for a slot
+	// held by a template waiting for them
+	limitHost := requestHost(targetURL, advanced)
+	hostSem, endHostScan := startHostScan(limitHost, advanced.templatesPerHost())
+	defer endHostScan()
+
+	// the WAF probe is only sent when its result decides which templates run
+	var wafInfo *waf.WAFInfo
+	if len(advanced.SkipOnWAF) > 0 {
+		wafInfo = detectWAF(ctx, targetURL, limitHost, hostSem, timeout, advanced, logger)
+	}
+
+	var targetVars map[string]interface{}
+	if advanced.EnablePathDiscovery {
+		targetVars = map[string]interface{}{"DiscoveredPaths": discoverPaths(ctx, targetURL, timeout, logger)}
 	}
 
-	var matchedTemplates []*Template
+	var findings []*Finding
 
 	var mu sync.Mutex
 	var wg sync.WaitGroup
@@ -116,22 +423,90 @@ func FindMatchingTemplates(ctx context.Context,
 	total := len(templates)
 	var counter atomic.Int32
 
+	// matchedHosts records the hosts with a finding, with StopOnFirstHostMatch the remaining templates
+	// are skipped and the running ones are cancelled through scanCtx
+	var matchedHosts sync.Map
+	scanCtx, cancelScan := context.WithCancel(ctx)
+	defer cancelScan()
+
+	// runs let templates with requirements wait for the templates they require to run and match
+	runs := newTemplateRuns(templates)
+
+	var workerSem chan struct{}
+	if advanced.Workers > 0 {
+		workerSem = make(chan struct{}, advanced.Workers)
+	}
+
 	for _, tmpl := range templates {
-		if !templateMatchesHost(tmpl, targetHost) {
+		if !templateMatchesHost(tmpl, targetHost) || !tmpl.MatchesSeverity(severityFilter) ||
+			(wafInfo != nil && tmpl.HasAnyTag(advanced.SkipOnWAF)) ||
+			(advanced.TargetCache != nil && advanced.TargetCache.ShouldSkip(targetURL, tmpl.ID, advanced.RescanInterval)) {
+			closeRun(runs, tmpl, false)
 			current := int(counter.Add(1))
 			progressCallback(current, total)
 			continue
 		}
 
+		if !acquire(scanCtx, hostSem, workerSem) {
+			closeRun(runs, tmpl, false)
+			progressCallback(int(counter.Add(1)), total)
+			continue
+		}
+
 		wg.Add(1)
 		go func(t *Template) {
 			defer wg.Done()
+			defer release(hostSem, workerSem)
+			matched := false
+			defer func() { closeRun(runs, t, matched) }()
+
+			if !requirementsMatched(scanCtx, t, runs) {
+				progressCallback(int(counter.Add(1)), total)
+				return
+			}
+
+			if advanced.StopOnFirstHostMatch {
+				if _, done := matchedHosts.Load(targetHost); done {
+					progressCallback(int(counter.Add(1)), total)
+					return
+				}
+			}
 
-			matches, err := MatchTemplate(ctx, targetURL, htmlContent, t, advanced, logger)
-			if err == nil && matches {
+			started := time.Now()
+			matches, extracted, err := matchTemplate(scanCtx, targetURL, htmlContent, t, targetVars, advanced, logger)
+			duration := time.Since(started)
+			if !matches && advanced.StopOnFirstHostMatch && scanCtx.Err() != nil && ctx.Err() == nil {
+				progressCallback(int(counter.Add(1)), total)
+				return
+			}
+			if advanced.TargetCache != nil {
+				if err := advanced.TargetCache.Record(targetURL, t.ID); err != nil {
+					logger.Warn("Failed to record scanned target", slog.String("target", targetURL), slog.Any("error", err))
+				}
+			}
+			switch {
+			case err != nil:
+				logger.Info("Error matching template", slog.String("template_id", t.ID), slog.String("target", targetURL), slog.Any("error", err))
+			case !matches:
+				if advanced.OnPass != nil {
+					advanced.OnPass(targetURL, t.ID, duration)
+				}
+			default:
+				matched = true
+				metrics.RecordMatch(t.Info.Severity)
+				finding := NewFinding(targetURL, t)
+				finding.ExtractedValues = extracted
+				finding.Duration = duration
 				mu.Lock()
-				matchedTemplates = append(matchedTemplates, t)
+				findings = append(findings, finding)
 				mu.Unlock()
+				if advanced.OnFinding != nil {
+					advanced.OnFinding(finding)
+				}
+				if advanced.StopOnFirstHostMatch {
+					matchedHosts.Store(targetHost, struct{}{})
+					cancelScan()
+				}
 			}
 			current := int(counter.Add(1))
 			progressCallback(current, total)
@@ -139,82 +514,243 @@ func FindMatchingTemplates(ctx context.Context,
 	}
 
 	wg.Wait()
-	return matchedTemplates, nil
+	return findings, nil
+}
+
+// recentlyScanned reports whether the rescan cache skips every template for the target, the target isn't
+// contacted at all then
+func recentlyScanned(targetURL string, templates []*Template, advanced *AdvancedSettingsChecker) bool {
+	if advanced.TargetCache == nil || advanced.RescanInterval <= 0 || len(templates) == 0 {
+		return false
+	}
+	for _, tmpl := range templates {
+		if !advanced.TargetCache.ShouldSkip(targetURL, tmpl.ID, advanced.RescanInterval) {
+			return false
+		}
+	}
+	return true
 }
 
-// MatchTemplate executes HTTP requests from the template and checks if the response matches the matchers conditions
-func MatchTemplate(ctx context.Context, baseURL string, htmlContent string, tmpl *Template, advanced *AdvancedSettingsChecker, logger *logging.Logger) (bool, error) {
+// discoverPaths returns the paths disclosed by the target robots.txt and sitemap.xml
+func discoverPaths(ctx context.Context, targetURL string, timeout time.Duration, logger *logging.Logger) []string {
+	client := newInsecureHTTPClient(timeout)
+	paths, err := discover.FetchRobotsTxt(ctx, targetURL, client)
+	if err != nil {
+		logger.Info("robots.txt discovery failed", slog.String("target", targetURL), slog.Any("error", err))
+	}
+	locs, err := discover.FetchSitemap(ctx, targetURL, client)
+	if err != nil {
+		logger.Info("sitemap.xml discovery failed", slog.String("target", targetURL), slog.Any("error", err))
+	}
+	return append(paths, locs...)
+}
+
+// MatchTemplate executes HTTP requests from the template and checks if the response matches the matchers conditions.
+// On a match it also returns the values extracted by the template extractors
+func MatchTemplate(ctx context.Context, baseURL string, htmlContent string, tmpl *Template, advanced *AdvancedSettingsChecker, logger *logging.Logger) (bool, map[string]string, error) {
+	return matchTemplate(ctx, baseURL, htmlContent, tmpl, nil, advanced, logger)
+}
+
+// matchTemplate runs the template with targetVars added to the template variables
+func matchTemplate(ctx context.Context, baseURL string, htmlContent string, tmpl *Template, targetVars map[string]interface{}, advanced *AdvancedSettingsChecker, logger *logging.Logger) (bool, map[string]string, error) {
+	ctx, span := tracer.Start(ctx, "template.match", trace.WithAttributes(
+		attribute.String("template.id", tmpl.ID),
+		attribute.String("target.url", baseURL),
+	))
+	defer span.End()
+
 	if len(tmpl.Requests) == 0 {
-		return false, fmt.Errorf("template %s has no requests", tmpl.ID)
+		return false, nil, fmt.Errorf("template %s has no requests", tmpl.ID)
+	}
+	if !tmpl.HasMatchers() {
+		if advanced.FailOnNoMatchers {
+			return false, nil, fmt.Errorf("template %s has no matchers", tmpl.ID)
+		}
+		logger.Info("Template has no matchers defined, skipping match", slog.String("template_id", tmpl.ID))
+		return false, nil, nil
 	}
 
 	parsedURL, err := url.Parse(baseURL)
 	if err != nil {
-		return false, err
+		return false, nil, err
 	}
 	host := parsedURL.Hostname()
 
+	// vars is local to this invocation, tmpl is shared between concurrent scans
+	vars := copyVariables(tmpl.Variables)
+	for k, v := range targetVars {
+		vars[k] = v
+	}
+	runner := &requestRunner{
+		baseURL:     baseURL,
+		htmlContent: htmlContent,
+		host:        host,
+		port:        targetPort(parsedURL),
+		tmpl:        tmpl,
+		vars:        vars,
+		extracted:   make(map[string]string),
+		advanced:    advanced,
+		logger:      logger,
+	}
+
+	if tmpl.Flow != "" {
+		matched, err := runFlow(ctx, tmpl.Flow, runner)
+		if err != nil || !matched {
+			return false, nil, err
+		}
+		return true, runner.extracted, nil
+	}
+	if tmpl.RequestCondition != "" {
+		matched, err := matchRequestCondition(ctx, tmpl.RequestCondition, runner)
+		if err != nil || !matched {
+			return false, nil, err
+		}
+		return true, runner.extracted, nil
+	}
+
 	for _, req := range tmpl.Requests {
-		var matched bool
-		var err error
-
-		switch req.Type {
-		case "http", "":
-			if canOfflineMatchRequest(req) {
-				matched := matchOfflineHTML(htmlContent, req, tmpl, logger)
-				if matched {
-					return true, nil
-				}
-			} else {
-				matched, err := matchHTTPRequest(ctx, baseURL, req, tmpl, advanced, logger)
-				if err != nil {
-					return false, err
-				}
-				if matched {
-					return true, nil
-				}
-			}
-		case "dns", "CNAME", "NS", "TXT", "A":
-			matched, err = matchDNSRequest(host, req, tmpl, logger)
-		case "network":
-			matched, err = matchNetworkRequest(ctx, host, req, tmpl, logger)
-		case "headless":
-			if canOfflineMatchRequest(req) {
-				matched := matchOfflineHTML(htmlContent, req, tmpl, logger)
-				if matched {
-					return true, nil
-				}
-			} else {
-				matched, err := matchHeadlessRequest(ctx, baseURL, req, tmpl, advanced, logger)
-				if err != nil {
-					return false, err
-				}
-				if matched {
-					return true, nil
-				}
-			}
-		default:
-			logger.Info.Printf("Unsupported request type: %s\n", req.Type)
-			continue
+		matched, err := runner.run(ctx, req)
+		if err != nil {
+			return false, nil, err
+		}
+		if matched {
+			return true, runner.extracted, nil
 		}
+	}
+
+	return false, nil, nil
+}
 
+// matchRequestCondition runs every request of the template, then evaluates the req-condition expression with
+// the result of the n-th request as the boolean parameter rn (e.g. "r1 && !r2")
+func matchRequestCondition(ctx context.Context, condition string, runner *requestRunner) (bool, error) {
+	results := make(map[int]bool, len(runner.tmpl.Requests))
+	for i, req := range runner.tmpl.Requests {
+		matched, err := runner.run(ctx, req)
 		if err != nil {
-			logger.Info.Printf("Request failed: %v", err)
-			continue
+			return false, err
 		}
+		results[i+1] = matched
+	}
 
-		if matched {
-			return true, nil
+	params := copyVariables(runner.vars)
+	for n, matched := range results {
+		params["r"+strconv.Itoa(n)] = matched
+	}
+	result, err := evaluateDSL(condition, params)
+	if err != nil {
+		return false, fmt.Errorf("req-condition of template %s: %w", runner.tmpl.ID, err)
+	}
+	matched, ok := result.(bool)
+	if !ok {
+		return false, fmt.Errorf("req-condition of template %s is not a boolean expression: %s", runner.tmpl.ID, condition)
+	}
+	return matched, nil
+}
+
+// requestRunner runs the requests of one template invocation, later requests see the values extracted by earlier ones
+type requestRunner struct {
+	baseURL     string
+	htmlContent string
+	host        string
+	port        string
+	tmpl        *Template
+	vars        map[string]interface{}
+	extracted   map[string]string
+	// lastExtracted holds the values extracted by the last run request
+	lastExtracted map[string]interface{}
+	// inFlow sends every request, the page HTML doesn't stand in for the responses of flow requests
+	inFlow   bool
+	advanced *AdvancedSettingsChecker
+	logger   *logging.Logger
+}
+
+// run executes req and reports whether it matched. Failed DNS and network requests are logged and don't match,
+// HTTP and headless errors are returned
+func (r *requestRunner) run(ctx context.Context, req *Request) (bool, error) {
+	tmpl, advanced, logger := r.tmpl, r.advanced, r.logger
+	r.lastExtracted = nil
+	var matched bool
+	var err error
+
+	switch req.Type {
+	case "http", "":
+		if r.offline(req) && !advanced.DryRun {
+			return matchOfflineHTML(r.htmlContent, req, tmpl, logger), nil
+		}
+		matched, values, err := matchHTTPRequest(ctx, r.baseURL, req, tmpl, r.vars, advanced, logger)
+		if err != nil {
+			return false, err
+		}
+		r.addExtracted(values)
+		return matched, nil
+	case "dns", "CNAME", "NS", "TXT", "A":
+		matched, err = matchDNSRequest(ctx, r.host, req, tmpl, advanced, logger)
+	case "network":
+		matched, err = matchNetworkRequest(ctx, r.host, r.port, req, tmpl, advanced, logger)
+	case "imap", "pop3":
+		matched, err = matchMailRequest(ctx, r.host, req, tmpl, advanced, logger)
+	case "redis":
+		matched, err = matchRedisRequest(ctx, r.host, req, tmpl, advanced, logger)
+	case "mysql":
+		matched, err = matchMySQLRequest(ctx, r.host, req, tmpl, advanced, logger)
+	case "memcached":
+		matched, err = matchMemcachedRequest(ctx, r.host, req, tmpl, advanced, logger)
+	case "grpc":
+		matched, err = matchGRPCRequest(ctx, r.host, r.port, req, tmpl, advanced, logger)
+	case "websocket":
+		matched, err = matchWebSocketRequest(ctx, r.baseURL, req, tmpl, advanced, logger)
+	case "graphql":
+		matched, values, err := matchGraphQLRequest(ctx, r.baseURL, req, tmpl, r.vars, advanced, logger)
+		if err != nil {
+			return false, err
 		}
+		r.addExtracted(values)
+		return matched, nil
+	case "headless":
+		if advanced.DisableHeadless && !r.offline(req) {
+			logger.Info("Headless is disabled, skipping headless request", slog.String("template_id", tmpl.ID))
+			return false, nil
+		}
+		if r.offline(req) {
+			return matchOfflineHTML(r.htmlContent, req, tmpl, logger), nil
+		}
+		matched, values, err := matchHeadlessRequest(ctx, r.baseURL, req, tmpl, advanced, logger)
+		if err != nil {
+			return false, err
+		}
+		r.addExtracted(values)
+		return matched, nil
+	default:
+		logger.Info("Unsupported request type", slog.String("template_id", tmpl.ID), slog.String("type", req.Type))
+		return false, nil
 	}
 
-	return false, nil
+	if err != nil {
+		logger.Info("Request failed", slog.String("template_id", tmpl.ID), slog.Any("error", err))
+		return false, nil
+	}
+	return matched, nil
+}
+
+// offline reports whether req is matched against the page HTML instead of being sent
+func (r *requestRunner) offline(req *Request) bool {
+	return !r.inFlow && canOfflineMatchRequest(req)
+}
+
+// addExtracted makes the extracted values available to the following requests and the finding
+func (r *requestRunner) addExtracted(values map[string]interface{}) {
+	r.lastExtracted = values
+	for k, v := range values {
+		r.vars[k] = v
+		r.extracted[k] = extractedString(v)
+	}
 }
 
-// checkMatchers checks the list of matchers according to the given condition (and/or)
+// checkMatchers checks the list of matchers according to the given condition (and/or), an empty list never matches
 func checkMatchers(matchers []Matcher, condition string, ctx MatchContext) bool {
 	if len(matchers) == 0 {
-		return true
+		return false
 	}
 
 	condition = strings.ToLower(condition)
@@ -255,12 +791,19 @@ func checkSingleMatcher(m Matcher, ctx MatchContext) bool {
 
 	case "word":
 		if ctx.Resp == nil {
+			// banners and replies of the network protocols are matched as a body
+			if ctx.Network != nil {
+				return matchWordsByPart(&http.Response{}, ctx.Network.Data, m.Words, "body", m.Condition, m.NoCase)
+			}
 			return false
 		}
 		return matchWordsByPart(ctx.Resp, ctx.Body, m.Words, m.Part, m.Condition, m.NoCase)
 
 	case "regex":
 		if ctx.Resp == nil {
+			if ctx.Network != nil {
+				return matchRegexListByPart(&http.Response{}, ctx.Network.Data, m.Regex, "body", m.NoCase)
+			}
 			return false
 		}
 		return matchRegexListByPart(ctx.Resp, ctx.Body, m.Regex, m.Part, m.NoCase)
@@ -318,6 +861,46 @@ func checkSingleMatcher(m Matcher, ctx MatchContext) bool {
 			return false
 		}
 		return matchHeadlessByPattern(ctx.Headless, m)
+	case "favicon":
+		if ctx.FaviconHash == nil {
+			return false
+		}
+		return *ctx.FaviconHash == m.FaviconHash
+	case "sqlerror":
+		if ctx.Body == nil {
+			return false
+		}
+		return matchSQLError(ctx.Body, m.Confidence)
+	case "entropy":
+		if ctx.Body == nil {
+			return false
+		}
+		return matchEntropy(ctx.Body, m.EntropyMin, m.EntropyMax, m.MinTokenLength)
+	case "duration":
+		if ctx.Resp == nil {
+			return false
+		}
+		return ctx.Duration >= m.MinDuration && (m.MaxDuration == 0 || ctx.Duration <= m.MaxDuration)
+	case "cors":
+		if ctx.CORSInfo == nil {
+			return false
+		}
+		return matchCORS(ctx.CORSInfo, m)
+	case "internal-ip":
+		if ctx.Resp == nil {
+			return false
+		}
+		return matchInternalIP(ctx.Resp, ctx.Body, m.ExcludeRequestIPs)
+	case "cookie":
+		if ctx.Resp == nil {
+			return false
+		}
+		return matchCookie(ctx.Resp, m.CookieName, m.CookieFlags, m.Negate)
+	case "graphql":
+		if ctx.Body == nil {
+			return false
+		}
+		return introspectionEnabled(ctx.Body) == m.IntrospectionEnabled
 	default:
 		return false
 	}
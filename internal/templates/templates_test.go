@@ -0,0 +1,481 @@
+package templates
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/artnikel/nuclei/internal/dedup"
+	"github.com/artnikel/nuclei/internal/logging"
+)
+
+func TestMatchTemplateConcurrentChainedRequests(t *testing.T) {
+	// every client gets its own session id, the second request must carry the id from the first response
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/login":
+			fmt.Fprintf(w, "session=%s", r.URL.Query().Get("client"))
+		case strings.HasPrefix(r.URL.Path, "/profile/"):
+			if r.Header.Get("X-Session") != strings.TrimPrefix(r.URL.Path, "/profile/") {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			io.WriteString(w, "profile of "+r.Header.Get("X-Session"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	tmpl := loadTestTemplate(t, `id: chained-session
+info:
+  name: Chained session
+  author: test
+  severity: info
+variables:
+  client: ""
+http:
+  - path:
+      - "{{BaseURL}}/login?client={{client}}"
+    matchers:
+      - type: status
+        status: [500]
+    extractors:
+      - type: regex
+        name: session
+        group: 1
+        regex:
+          - 'session=(\w+)'
+  - path:
+      - "{{BaseURL}}/profile/{{session}}"
+    headers:
+      X-Session: "{{session}}"
+    matchers:
+      - type: status
+        status: [200]
+`)
+	advanced := testSettings()
+
+	const workers = 50
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			client := fmt.Sprintf("c%d", i)
+			matched, extracted, err := matchTemplate(context.Background(), srv.URL, "", tmpl,
+				map[string]interface{}{"client": client}, advanced, testLogger())
+			switch {
+			case err != nil:
+				errs <- err
+			case !matched:
+				errs <- fmt.Errorf("client %s: template didn't match", client)
+			case extracted["session"] != client:
+				errs <- fmt.Errorf("client %s got session %q", client, extracted["session"])
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+	if _, ok := tmpl.Variables["session"]; ok {
+		t.Error("extracted values leaked into the shared template variables")
+	}
+}
+
+func TestRescanIntervalSkipsProcessedTargets(t *testing.T) {
+	var mu sync.Mutex
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		mu.Unlock()
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+	countRequests := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		n := requests
+		requests = 0
+		return n
+	}
+
+	dir := writeTaggedTemplates(t, []string{"  severity: info", "  severity: low"})
+	cache, err := dedup.NewTargetCache(filepath.Join(t.TempDir(), "targets.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cache.Close()
+	advanced := testSettings()
+	advanced.TargetCache = cache
+	advanced.RescanInterval = 24 * time.Hour
+
+	scan := func() {
+		t.Helper()
+		if _, err := FindMatchingTemplates(context.Background(), srv.URL, dir, nil, nil, 10*time.Second,
+			advanced, testLogger(), func(i, total int) {}); err != nil {
+			t.Fatalf("FindMatchingTemplates: %v", err)
+		}
+	}
+	scan()
+	if n := countRequests(); n == 0 {
+		t.Fatal("first scan sent no requests")
+	}
+	scan()
+	if n := countRequests(); n != 0 {
+		t.Errorf("second scan sent %d requests to the processed target, want 0", n)
+	}
+}
+
+func TestPreScanPortCheck(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closedURL := "http://" + ln.Addr().String()
+	ln.Close()
+
+	dir := writeTaggedTemplates(t, []string{"  severity: info", "  severity: low", "  severity: high"})
+	advanced := testSettings()
+	advanced.PreScanPortCheck = true
+	advanced.PortCheckTimeout = time.Second
+
+	var logs bytes.Buffer
+	logger := &logging.Logger{Logger: slog.New(slog.NewTextHandler(&logs, nil))}
+	findings, err := FindMatchingTemplates(context.Background(), closedURL, dir, nil, nil, 10*time.Second,
+		advanced, logger, func(i, total int) {})
+	if err != nil || len(findings) != 0 {
+		t.Fatalf("FindMatchingTemplates = %d findings, %v", len(findings), err)
+	}
+	if n := strings.Count(logs.String(), "Port closed"); n != 1 {
+		t.Errorf("%d port closed messages, want 1:\n%s", n, logs.String())
+	}
+	if n := strings.Count(logs.String(), "level=ERROR"); n != 0 {
+		t.Errorf("%d template errors logged for the closed port:\n%s", n, logs.String())
+	}
+
+	_, srv := newRequestRecorder(t, "/0", "/1", "/2")
+	findings, err = FindMatchingTemplates(context.Background(), srv.URL, dir, nil, nil, 10*time.Second,
+		advanced, testLogger(), func(i, total int) {})
+	if err != nil || len(findings) != 3 {
+		t.Errorf("open port: FindMatchingTemplates = %d findings, %v, want 3", len(findings), err)
+	}
+}
+
+// newWAFServer answers the WAF probe like Cloudflare blocking it and every other request with 200, it counts the
+// probes it received
+func newWAFServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var probes atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Has("file") {
+			probes.Add(1)
+			w.Header().Set("CF-RAY", "8a1b2c3d4e5f-AMS")
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &probes
+}
+
+func TestWAFDetection(t *testing.T) {
+	t.Cleanup(ResetHTTPClient)
+	dir := writeTaggedTemplates(t, []string{"  severity: high\n  tags: sqli", "  severity: info\n  tags: tech"})
+
+	target, targetProbes := newWAFServer(t)
+	advanced := testSettings()
+	findings, err := FindMatchingTemplates(context.Background(), target.URL, dir, nil, nil, 10*time.Second,
+		advanced, testLogger(), func(i, total int) {})
+	if err != nil || len(findings) != 2 {
+		t.Errorf("without skip-on-waf: FindMatchingTemplates = %d findings, %v, want 2", len(findings), err)
+	}
+	if n := targetProbes.Load(); n != 0 {
+		t.Errorf("without skip-on-waf: %d WAF probes sent, want 0", n)
+	}
+
+	// the probe goes through the proxy like the template requests
+	proxy, proxyProbes := newWAFServer(t)
+	advanced.SkipOnWAF = []string{"sqli"}
+	advanced.Proxy = proxy.URL
+	findings, err = FindMatchingTemplates(context.Background(), target.URL, dir, nil, nil, 10*time.Second,
+		advanced, testLogger(), func(i, total int) {})
+	if err != nil || len(findings) != 1 || findings[0].TemplateID != "t01" {
+		t.Errorf("with skip-on-waf: FindMatchingTemplates = %+v, %v, want the t01 finding", findings, err)
+	}
+	if target, proxy := targetProbes.Load(), proxyProbes.Load(); target != 0 || proxy != 1 {
+		t.Errorf("WAF probes sent = %d direct, %d through the proxy, want 0 and 1", target, proxy)
+	}
+}
+
+func TestStopOnFirstHostMatch(t *testing.T) {
+	dir := writeTaggedTemplates(t, []string{"  severity: critical", "  severity: high", "  severity: medium", "  severity: low", "  severity: info"})
+	templatePaths := map[string]bool{"/0": true, "/1": true, "/2": true, "/3": true, "/4": true}
+
+	for _, stop := range []bool{true, false} {
+		t.Run(fmt.Sprintf("stop=%v", stop), func(t *testing.T) {
+			rec, srv := newRequestRecorder(t, "/0")
+			advanced := testSettings()
+			advanced.Workers = 1
+			advanced.StopOnFirstHostMatch = stop
+
+			findings, err := FindMatchingTemplates(context.Background(), srv.URL, dir, nil, nil, 10*time.Second,
+				advanced, testLogger(), func(i, total int) {})
+			if err != nil || len(findings) != 1 || findings[0].TemplateID != "t00" {
+				t.Fatalf("FindMatchingTemplates = %+v, %v, want the t00 finding", findings, err)
+			}
+			executed := 0
+			for _, uri := range rec.requests() {
+				if templatePaths[uri] {
+					executed++
+				}
+			}
+			want := 5
+			if stop {
+				want = 1
+			}
+			if executed != want {
+				t.Errorf("%d templates sent requests, want %d: %v", executed, want, rec.requests())
+			}
+		})
+	}
+}
+
+func TestMatchTemplateConcurrentRandomExtraction(t *testing.T) {
+	// every /token response carries a fresh random token, /check only accepts tokens that were handed out
+	var mu sync.Mutex
+	issued := make(map[string]bool)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/token":
+			buf := make([]byte, 16)
+			if _, err := rand.Read(buf); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			token := hex.EncodeToString(buf)
+			mu.Lock()
+			issued[token] = true
+			mu.Unlock()
+			fmt.Fprintf(w, "token=%s", token)
+		case strings.HasPrefix(r.URL.Path, "/check/"):
+			token := strings.TrimPrefix(r.URL.Path, "/check/")
+			mu.Lock()
+			ok := issued[token] && r.Header.Get("X-Token") == token
+			delete(issued, token)
+			mu.Unlock()
+			if !ok {
+				w.WriteHeader(http.StatusForbidden)
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	tmpl := loadTestTemplate(t, `id: random-token
+info:
+  name: Random token
+  author: test
+  severity: info
+variables:
+  token: ""
+  labels:
+    - one
+    - two
+  nested:
+    key: value
+http:
+  - path:
+      - "{{BaseURL}}/token"
+    matchers:
+      - type: status
+        status: [500]
+    extractors:
+      - type: regex
+        name: token
+        group: 1
+        regex:
+          - 'token=([0-9a-f]{32})'
+  - path:
+      - "{{BaseURL}}/check/{{token}}"
+    headers:
+      X-Token: "{{token}}"
+    matchers:
+      - type: status
+        status: [200]
+`)
+	advanced := testSettings()
+
+	const invocations = 100
+	var wg sync.WaitGroup
+	errs := make(chan error, invocations)
+	tokens := make(chan string, invocations)
+	for i := 0; i < invocations; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			matched, extracted, err := matchTemplate(context.Background(), srv.URL, "", tmpl, nil, advanced, testLogger())
+			switch {
+			case err != nil:
+				errs <- err
+			case !matched:
+				errs <- fmt.Errorf("token %v was not accepted by the chained request", extracted["token"])
+			default:
+				tokens <- extracted["token"]
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	close(tokens)
+	for err := range errs {
+		t.Error(err)
+	}
+	seen := make(map[string]bool)
+	for token := range tokens {
+		if seen[token] {
+			t.Errorf("token %s extracted by two invocations", token)
+		}
+		seen[token] = true
+	}
+	if tmpl.Variables["token"] != "" {
+		t.Errorf("shared template variable token = %v, want it untouched", tmpl.Variables["token"])
+	}
+}
+
+const noMatchersTemplate = `id: no-matchers
+info:
+  name: No matchers
+  author: test
+  severity: info
+http:
+  - method: GET
+    path:
+      - "{{BaseURL}}/"
+`
+
+const statusMatcherTemplate = `id: with-matchers
+info:
+  name: With matchers
+  author: test
+  severity: info
+http:
+  - method: GET
+    path:
+      - "{{BaseURL}}/"
+    matchers:
+      - type: status
+        status:
+          - 200
+`
+
+func TestTemplateWithoutMatchers(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "<html><body>anything</body></html>")
+	}))
+	t.Cleanup(srv.Close)
+
+	empty := loadTestTemplate(t, noMatchersTemplate)
+	if empty.HasMatchers() {
+		t.Fatal("HasMatchers = true for a template without matchers")
+	}
+	matched, _, err := matchTemplate(context.Background(), srv.URL, "", empty, nil, testSettings(), testLogger())
+	if err != nil || matched {
+		t.Errorf("template without matchers = %v, %v, want no match and no error", matched, err)
+	}
+
+	strict := testSettings()
+	strict.FailOnNoMatchers = true
+	if _, _, err := matchTemplate(context.Background(), srv.URL, "", empty, nil, strict, testLogger()); err == nil ||
+		!strings.Contains(err.Error(), "no matchers") {
+		t.Errorf("FailOnNoMatchers error = %v, want a no matchers error", err)
+	}
+
+	if checkMatchers(nil, "or", MatchContext{}) || checkMatchers(nil, "and", MatchContext{}) {
+		t.Error("checkMatchers matched an empty matcher list")
+	}
+	if matchOfflineHTML("<html><body>anything</body></html>", empty.Requests[0], empty, testLogger()) {
+		t.Error("matchOfflineHTML matched a request without matchers")
+	}
+
+	withMatchers := loadTestTemplate(t, statusMatcherTemplate)
+	for _, advanced := range []*AdvancedSettingsChecker{testSettings(), strict} {
+		matched, _, err := matchTemplate(context.Background(), srv.URL, "", withMatchers, nil, advanced, testLogger())
+		if err != nil || !matched {
+			t.Errorf("template with a status matcher = %v, %v, want a match", matched, err)
+		}
+	}
+}
+
+const requestConditionTemplate = `id: req-condition-%d
+info:
+  name: Request condition
+  author: test
+  severity: info
+req-condition: "%s"
+http:
+  - method: GET
+    path:
+      - "{{BaseURL}}/one"
+    matchers:
+      - type: status
+        status:
+          - 200
+  - method: GET
+    path:
+      - "{{BaseURL}}/two"
+    matchers:
+      - type: status
+        status:
+          - 200
+`
+
+func TestRequestCondition(t *testing.T) {
+	tests := []struct {
+		condition string
+		want      bool
+	}{
+		{condition: "r1 && r2", want: false},
+		{condition: "r1 || r2", want: true},
+		{condition: "r1 && !r2", want: true},
+		{condition: "r2", want: false},
+	}
+	for i, tt := range tests {
+		t.Run(tt.condition, func(t *testing.T) {
+			// only the first request matches
+			rec, srv := newRequestRecorder(t, "/one")
+			tmpl := loadTestTemplate(t, fmt.Sprintf(requestConditionTemplate, i, tt.condition))
+			matched, _, err := matchTemplate(context.Background(), srv.URL, "", tmpl, nil, testSettings(), testLogger())
+			if err != nil || matched != tt.want {
+				t.Errorf("req-condition %q = %v, %v, want %v", tt.condition, matched, err, tt.want)
+			}
+			// both requests run once whatever the first one returned
+			if got := strings.Join(rec.requests(), ","); got != "/one,/two" {
+				t.Errorf("requests = %s, want /one,/two", got)
+			}
+		})
+	}
+
+	rec, srv := newRequestRecorder(t, "/one")
+	tmpl := loadTestTemplate(t, fmt.Sprintf(requestConditionTemplate, len(tests), "r1 + 1"))
+	if _, _, err := matchTemplate(context.Background(), srv.URL, "", tmpl, nil, testSettings(), testLogger()); err == nil {
+		t.Errorf("non-boolean req-condition accepted after requests %v", rec.requests())
+	}
+}
@@ -0,0 +1,115 @@
+package templates
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+var (
+	spanRecorderOnce sync.Once
+	spanRecorder     *tracetest.SpanRecorder
+)
+
+// recordSpans installs an in-memory span recorder as the global tracer provider, once per test binary since
+// tracers created before the first provider only delegate to that one
+func recordSpans() *tracetest.SpanRecorder {
+	spanRecorderOnce.Do(func() {
+		spanRecorder = tracetest.NewSpanRecorder()
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spanRecorder)))
+	})
+	return spanRecorder
+}
+
+// spanAttrs returns the attributes of the ended spans with the name, keyed by attribute key
+func spanAttrs(rec *tracetest.SpanRecorder, name, templateID string) []map[attribute.Key]attribute.Value {
+	var res []map[attribute.Key]attribute.Value
+	for _, span := range rec.Ended() {
+		if span.Name() != name {
+			continue
+		}
+		attrs := make(map[attribute.Key]attribute.Value)
+		for _, kv := range span.Attributes() {
+			attrs[kv.Key] = kv.Value
+		}
+		if templateID != "" && attrs["template.id"].AsString() != templateID {
+			continue
+		}
+		res = append(res, attrs)
+	}
+	return res
+}
+
+func TestTracingSpans(t *testing.T) {
+	rec := recordSpans()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer srv.Close()
+
+	tmpl := loadTestTemplate(t, `id: traced
+info:
+  name: Traced
+  author: test
+  severity: info
+http:
+  - method: GET
+    path:
+      - "{{BaseURL}}/teapot"
+    matchers:
+      - type: status
+        status: [418]
+`)
+	matched, _, err := MatchTemplate(context.Background(), srv.URL, "", tmpl, testSettings(), testLogger())
+	if err != nil || !matched {
+		t.Fatalf("MatchTemplate = %v, %v", matched, err)
+	}
+
+	spans := spanAttrs(rec, "template.match", "traced")
+	if len(spans) != 1 {
+		t.Fatalf("got %d template.match spans, want 1", len(spans))
+	}
+	if got := spans[0]["target.url"].AsString(); got != srv.URL {
+		t.Errorf("template.match target.url = %q, want %q", got, srv.URL)
+	}
+
+	var found bool
+	for _, attrs := range spanAttrs(rec, "http.request", "") {
+		if attrs["target.url"].AsString() != srv.URL+"/teapot" {
+			continue
+		}
+		found = true
+		if got := attrs["http.status_code"].AsInt64(); got != http.StatusTeapot {
+			t.Errorf("http.status_code = %d, want %d", got, http.StatusTeapot)
+		}
+		if _, ok := attrs["retry.count"]; !ok {
+			t.Error("http.request span lacks retry.count")
+		}
+	}
+	if !found {
+		t.Error("no http.request span for the template request")
+	}
+}
+
+func TestTracingDNSSpan(t *testing.T) {
+	rec := recordSpans()
+	tmpl := &Template{ID: "traced-dns"}
+	req := &Request{Type: "A", Matchers: []Matcher{{Type: "word", Words: []string{"127.0.0.1"}}}}
+	if _, err := matchDNSRequest(context.Background(), "localhost", req, tmpl, testSettings(), testLogger()); err != nil {
+		t.Logf("DNS lookup failed: %v", err)
+	}
+	spans := spanAttrs(rec, "dns.lookup", "traced-dns")
+	if len(spans) == 0 {
+		t.Fatal("no dns.lookup span recorded")
+	}
+	if got := spans[0]["target.url"].AsString(); got != "localhost" {
+		t.Errorf("dns.lookup target.url = %q, want localhost", got)
+	}
+}
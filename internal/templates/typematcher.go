@@ -2,14 +2,19 @@
 package templates
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net"
 	"net/http"
 	"net/url"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -17,15 +22,34 @@ import (
 	"github.com/artnikel/nuclei/internal/constants"
 	"github.com/artnikel/nuclei/internal/logging"
 	"github.com/artnikel/nuclei/internal/templates/headless"
+	"github.com/klauspost/compress/zstd"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/time/rate"
 )
 
 type MatchContext struct {
-	Resp     *http.Response
-	Body     []byte
-	DNS      *DNSResponse
-	Network  *NetworkResponse
-	Headless *HeadlessResponse
+	Resp        *http.Response
+	Body        []byte
+	DNS         *DNSResponse
+	Network     *NetworkResponse
+	Headless    *HeadlessResponse
+	FaviconHash *int32
+	Duration    time.Duration
+	CORSInfo    *CORSInfo
+	// RedirectChain lists the URLs the request was redirected to, in order
+	RedirectChain []string
+}
+
+// HTTPResult is a received HTTP response with its body, the time it took to receive it and the redirects
+// followed on the way
+type HTTPResult struct {
+	Resp          *http.Response
+	Body          []byte
+	Duration      time.Duration
+	RedirectChain []string
 }
 
 type DNSResponse struct {
@@ -43,120 +67,508 @@ type HeadlessResponse struct {
 	Screenshot []byte
 	StatusCode int
 	Err        error
+	// DiscoveredPaths lists the paths referenced from the page scripts
+	DiscoveredPaths []string
 }
 
-var (
-	hostLimitersMu sync.Mutex                       // hostLimitersMu guards access to hostLimiters map
-	hostLimiters   = make(map[string]*rate.Limiter) // hostLimiters stores rate limiters per hostname
-)
+var tracer = otel.Tracer("github.com/artnikel/nuclei/internal/templates") // tracer creates spans for template execution
 
-// getHostLimiter returns or creates a rate limiter for a given host
-func getHostLimiter(host string, advanced *AdvancedSettingsChecker) *rate.Limiter {
-	hostLimitersMu.Lock()
-	defer hostLimitersMu.Unlock()
+var hostLimiters sync.Map // hostLimiters stores rate limiters per hostname
+
+// getHostLimiter returns or creates a rate limiter for a given host.
+// Requests with their own rate-limit settings get a separate limiter per host and template, see templateHostLimiter
+func getHostLimiter(host string, req *Request, tmplID string, advanced *AdvancedSettingsChecker) *rate.Limiter {
+	if req.RateLimit > 0 || req.RateLimitBurst > 0 {
+		frequency := req.RateLimit
+		if frequency == 0 {
+			frequency = advanced.RateLimiterFrequency
+		}
+		burst := req.RateLimitBurst
+		if burst == 0 {
+			burst = 1
+		}
+		if frequency != advanced.RateLimiterFrequency || burst != advanced.RateLimiterBurstSize {
+			return templateHostLimiter(host, tmplID, frequency, burst)
+		}
+	}
 
-	limiter, ok := hostLimiters[host]
-	if !ok {
-		limiter = rate.NewLimiter(rate.Every(time.Duration(advanced.RateLimiterFrequency)*time.Millisecond), advanced.RateLimiterBurstSize)
-		hostLimiters[host] = limiter
+	if limiter, ok := hostLimiters.Load(host); ok {
+		return limiter.(*rate.Limiter)
 	}
-	return limiter
+	limiter, _ := hostLimiters.LoadOrStore(host,
+		rate.NewLimiter(rate.Every(time.Duration(advanced.RateLimiterFrequency)*time.Millisecond), advanced.RateLimiterBurstSize))
+	return limiter.(*rate.Limiter)
 }
 
-// matchHTTPRequest performs HTTP requests and matches responses
-func matchHTTPRequest(ctx context.Context, baseURL string, req *Request, tmpl *Template, advanced *AdvancedSettingsChecker, logger *logging.Logger) (bool, error) {
-	client := newInsecureHTTPClient(constants.TenSecTimeout)
+// matchHTTPRequest performs HTTP requests, matches responses and returns the values extracted from them.
+// templateVars holds the template variables together with the values extracted by previous requests
+func matchHTTPRequest(ctx context.Context, baseURL string, req *Request, tmpl *Template, templateVars map[string]interface{}, advanced *AdvancedSettingsChecker, logger *logging.Logger) (bool, map[string]interface{}, error) {
+	client, release, err := getHTTPClient(advanced)
+	if err != nil {
+		return false, nil, err
+	}
+	defer release()
+	maxRedirects := advanced.MaxHTTPRedirects
+	if req.MaxRedirects != nil {
+		maxRedirects = *req.MaxRedirects
+	}
+	client = withRedirectLimit(client, maxRedirects)
 
 	method := req.Method
 	if method == "" {
 		method = http.MethodGet
 	}
 
+	displayURL := baseURL
+	if advanced.IDNNormalize {
+		baseURL = NormalizeIDN(baseURL)
+	}
+
 	parsedBaseURL, err := url.Parse(baseURL)
 	if err != nil {
-		return false, fmt.Errorf("invalid base url: %w", err)
+		return false, nil, fmt.Errorf("invalid base url: %w", err)
 	}
-	baseURLForVars := fmt.Sprintf("%s://%s", parsedBaseURL.Scheme, parsedBaseURL.Host)
+	vars := baseRequestVars(parsedBaseURL, templateVars)
 
-	vars := make(map[string]interface{})
-	for k, v := range tmpl.Variables {
-		vars[k] = v
-	}
-	vars["BaseURL"] = baseURLForVars
-	vars["Host"] = parsedBaseURL.Host
-	vars["Hostname"] = parsedBaseURL.Hostname()
+	// the rate-limit overrides of the template are shared with the running scans of the host
+	_, endHostScan := startHostScan(parsedBaseURL.Hostname(), 0)
+	defer endHostScan()
 
-	for _, p := range req.Path {
-		pathWithVars := substituteVariables(p, vars)
-		fullURL := buildFullURL(parsedBaseURL, pathWithVars)
+	payloadSets, err := buildPayloadSets(req, tmpl, advanced)
+	if err != nil {
+		return false, nil, err
+	}
+	extracted := make(map[string]interface{})
 
-		httpReq, err := http.NewRequestWithContext(ctx, method, fullURL, nil)
+	var faviconHash *int32
+	if hasFaviconMatcher(req.Matchers) && !advanced.DryRun {
+		hash, err := FetchFaviconHash(ctx, baseURL, client)
 		if err != nil {
-			return false, err
+			logger.Info("Favicon fetch error", slog.String("target", displayURL), slog.Any("error", err))
+		} else {
+			faviconHash = &hash
 		}
+	}
 
-		for k, v := range req.Headers {
-			httpReq.Header.Set(k, substituteVariables(v, vars))
+	for _, p := range req.Path {
+		for _, payload := range payloadSets {
+			payload, err := expandPayloadValues(payload, vars)
+			if err != nil {
+				return false, nil, err
+			}
+			reqVars := withPayload(vars, payload)
+			pathWithVars, err := expandDynamicPayloads(substitutePathVariables(p, reqVars), reqVars)
+			if err != nil {
+				return false, nil, err
+			}
+			fullURL := buildFullURL(parsedBaseURL, pathWithVars)
+
+			var reqBody io.Reader
+			if req.Body != "" {
+				bodyWithVars, err := expandDynamicPayloads(substituteVariables(req.Body, reqVars), reqVars)
+				if err != nil {
+					return false, nil, err
+				}
+				reqBody = strings.NewReader(bodyWithVars)
+			}
+
+			httpReq, err := http.NewRequestWithContext(ctx, method, fullURL, reqBody)
+			if err != nil {
+				return false, nil, err
+			}
+			if strings.Contains(p, "{{"+rawPrefix) {
+				setRawRequestPath(httpReq.URL, parsedBaseURL, pathWithVars)
+			}
+
+			for k, v := range req.Headers {
+				headerValue, err := expandDynamicPayloads(substituteVariables(v, reqVars), reqVars)
+				if err != nil {
+					return false, nil, err
+				}
+				httpReq.Header.Set(k, headerValue)
+			}
+			if httpReq.Header.Get("Accept-Encoding") == "" {
+				httpReq.Header.Set("Accept-Encoding", acceptEncoding)
+			}
+
+			if advanced.DryRun {
+				logDryRun(advanced, logger, "Would request: "+method+" "+fullURL)
+				continue
+			}
+
+			limiter := getHostLimiter(parsedBaseURL.Hostname(), req, tmpl.ID, advanced)
+			for {
+				err := limiter.Wait(ctx)
+				if err != nil {
+					if errors.Is(err, context.DeadlineExceeded) {
+						logger.Info("Rate limiter wait error", slog.String("host", parsedBaseURL.Host), slog.Any("error", err))
+
+						return false, nil, nil
+					}
+					return false, nil, err
+				}
+				break
+			}
+
+			result, err := doHTTPRequestWithRetry(ctx, client, httpReq, advanced)
+			if err != nil {
+				logger.Info("HTTP request error", slog.String("target", displayURL), slog.String("url", fullURL), slog.Any("error", err))
+				continue
+			}
+
+			resp := result.Resp
+			matchCtx := MatchContext{
+				Resp:          resp,
+				Body:          result.Body,
+				FaviconHash:   faviconHash,
+				Duration:      result.Duration,
+				RedirectChain: result.RedirectChain,
+			}
+			if hasCORSMatcher(req.Matchers) {
+				info, err := probeCORS(ctx, client, fullURL)
+				if err != nil {
+					logger.Info("CORS probe error", slog.String("target", displayURL), slog.String("url", fullURL), slog.Any("error", err))
+				} else {
+					matchCtx.CORSInfo = info
+				}
+			}
+
+			matched, values := matchAndExtract(req, matchCtx, len(tmpl.Requests) > 1)
+			for k, v := range values {
+				extracted[k] = v
+			}
+
+			logger.Info("HTTP request matched",
+				slog.String("template_id", tmpl.ID),
+				slog.String("target", displayURL),
+				slog.String("url", fullURL),
+				slog.Bool("matched", matched),
+				slog.Int("status", resp.StatusCode),
+			)
+			if matched {
+				return true, extracted, nil
+			}
 		}
+	}
+
+	return false, extracted, nil
+}
+
+// matchAndExtract checks the matchers and runs the extractors of req against the response.
+// Chained templates need the values of every response for the next request, so they are extracted inline;
+// otherwise extraction runs alongside the matchers and its values are only kept on a match
+func matchAndExtract(req *Request, matchCtx MatchContext, chained bool) (bool, map[string]interface{}) {
+	if chained {
+		values := processExtractors(req.Extractors, matchCtx)
+		return checkMatchers(req.Matchers, req.MatchersCondition, matchCtx), values
+	}
+
+	var wg sync.WaitGroup
+	var values map[string]interface{}
+	if len(req.Extractors) > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			values = processExtractors(req.Extractors, matchCtx)
+		}()
+	}
+	matched := checkMatchers(req.Matchers, req.MatchersCondition, matchCtx)
+	wg.Wait()
+	if !matched {
+		return false, nil
+	}
+	return true, values
+}
 
-		limiter := getHostLimiter(parsedBaseURL.Hostname(), advanced)
-		for {
-			err := limiter.Wait(ctx)
+// baseRequestVars copies the template variables and adds the BaseURL, Host, Hostname and IPv6 of the target
+func baseRequestVars(baseURL *url.URL, templateVars map[string]interface{}) map[string]interface{} {
+	vars := make(map[string]interface{}, len(templateVars)+3)
+	for k, v := range templateVars {
+		vars[k] = v
+	}
+	vars["BaseURL"] = fmt.Sprintf("%s://%s", baseURL.Scheme, baseURL.Host)
+	vars["Host"] = baseURL.Host
+	vars["Hostname"] = baseURL.Hostname()
+	vars["IPv6"] = ""
+	if isIPv6(baseURL.Hostname()) {
+		vars["IPv6"] = baseURL.Hostname()
+	}
+	return vars
+}
+
+// withPayload returns a copy of vars extended with the payload values
+func withPayload(vars, payload map[string]interface{}) map[string]interface{} {
+	if len(payload) == 0 {
+		return vars
+	}
+	merged := make(map[string]interface{}, len(vars)+len(payload))
+	for k, v := range vars {
+		merged[k] = v
+	}
+	for k, v := range payload {
+		merged[k] = v
+	}
+	return merged
+}
+
+// dynamicPayloadRe matches {{expr(...)}} placeholders evaluated at request time
+var dynamicPayloadRe = regexp.MustCompile(`\{\{expr\((.*?)\)\}\}`)
+
+// evaluateDynamicPayload evaluates a DSL expression and formats the result as a payload value
+func evaluateDynamicPayload(expr string, vars map[string]interface{}) (string, error) {
+	result, err := evaluateDSL(expr, vars)
+	if err != nil {
+		return "", err
+	}
+	if f, ok := result.(float64); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64), nil
+	}
+	return fmt.Sprint(result), nil
+}
+
+// expandPayloadValues evaluates the {{expr(...)}} placeholders of the payload values, so computed values are
+// substituted and path-escaped like static ones
+func expandPayloadValues(payload, vars map[string]interface{}) (map[string]interface{}, error) {
+	expanded := make(map[string]interface{}, len(payload))
+	for name, value := range payload {
+		switch v := value.(type) {
+		case string:
+			s, err := expandDynamicPayloads(v, vars)
+			if err != nil {
+				return nil, fmt.Errorf("payload %s: %w", name, err)
+			}
+			expanded[name] = s
+		case encodedValue:
+			s, err := expandDynamicPayloads(v.Value, vars)
 			if err != nil {
-				if errors.Is(err, context.DeadlineExceeded) {
-					logger.Info.Printf("Rate limiter wait error for host %s: %v", parsedBaseURL.Host, err)
+				return nil, fmt.Errorf("payload %s: %w", name, err)
+			}
+			v.Value = s
+			expanded[name] = v
+		default:
+			expanded[name] = value
+		}
+	}
+	return expanded, nil
+}
 
-					return false, nil
+// expandDynamicPayloads replaces every {{expr(...)}} placeholder in s with the evaluated expression
+func expandDynamicPayloads(s string, vars map[string]interface{}) (string, error) {
+	var evalErr error
+	expanded := dynamicPayloadRe.ReplaceAllStringFunc(s, func(m string) string {
+		value, err := evaluateDynamicPayload(dynamicPayloadRe.FindStringSubmatch(m)[1], vars)
+		if err != nil {
+			evalErr = err
+			return m
+		}
+		return value
+	})
+	return expanded, evalErr
+}
+
+// doHTTPRequestWithRetry sends the request and reads the body, retrying network errors and 5xx responses
+// up to advanced.Retries times with advanced.RetryDelay between attempts.
+// The duration of the returned result covers only the last attempt
+func doHTTPRequestWithRetry(ctx context.Context, client *http.Client, req *http.Request, advanced *AdvancedSettingsChecker) (*HTTPResult, error) {
+	ctx, span := tracer.Start(ctx, "http.request", trace.WithAttributes(
+		attribute.String("target.url", req.URL.String()),
+	))
+	defer span.End()
+
+	var lastErr error
+	delay := advanced.RetryDelay
+	attempt, rateLimited := 0, 0
+	for ; attempt <= advanced.Retries; attempt++ {
+		if attempt > 0 || rateLimited > 0 {
+			select {
+			case <-ctx.Done():
+				recordSpanError(span, ctx.Err())
+				return nil, ctx.Err()
+			case <-time.After(delay):
+			}
+			delay = advanced.RetryDelay
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					recordSpanError(span, err)
+					return nil, err
 				}
-				return false, err
+				req.Body = body
 			}
-			break
 		}
 
-		resp, err := client.Do(httpReq)
+		start := time.Now()
+		resp, err := client.Do(req.WithContext(ctx))
 		if err != nil {
-			logger.Info.Printf("HTTP request error for %s: %v", fullURL, err)
+			lastErr = err
 			continue
 		}
-
-		body, err := io.ReadAll(resp.Body)
+		body, err := readEncodedBody(resp)
 		resp.Body.Close()
+		duration := time.Since(start)
 		if err != nil {
-			logger.Info.Printf("Failed to read body for %s: %v", fullURL, err)
+			lastErr = fmt.Errorf("failed to read body: %w", err)
+			continue
+		}
+		if resp.StatusCode >= http.StatusInternalServerError && attempt < advanced.Retries {
+			lastErr = fmt.Errorf("server returned status %d", resp.StatusCode)
 			continue
 		}
+		if resp.StatusCode == http.StatusTooManyRequests && advanced.Respect429 && rateLimited < max429Retries {
+			// rate limited retries don't count against Retries, which defaults to 0
+			rateLimited++
+			attempt--
+			delay = retryAfterDelay(resp.Header.Get("Retry-After"), advanced.RetryDelay, advanced.RetryMaxDelay, time.Now())
+			continue
+		}
+
+		span.SetAttributes(
+			attribute.Int("http.status_code", resp.StatusCode),
+			attribute.Int("retry.count", attempt),
+		)
+		return &HTTPResult{Resp: resp, Body: body, Duration: duration, RedirectChain: redirectChain(resp)}, nil
+	}
+
+	span.SetAttributes(attribute.Int("retry.count", attempt-1))
+	recordSpanError(span, lastErr)
+	return nil, lastErr
+}
+
+// max429Retries limits the retries of a request answered with 429 when Respect429 is set
+const max429Retries = 3
+
+// retryAfterDelay returns the wait before retrying a 429 response: the Retry-After value, given in seconds or as
+// an HTTP date, but at least minDelay and at most maxDelay unless maxDelay is 0
+func retryAfterDelay(retryAfter string, minDelay, maxDelay time.Duration, now time.Time) time.Duration {
+	var wait time.Duration
+	retryAfter = strings.TrimSpace(retryAfter)
+	if secs, err := strconv.Atoi(retryAfter); err == nil {
+		wait = time.Duration(secs) * time.Second
+	} else if at, err := http.ParseTime(retryAfter); err == nil {
+		wait = at.Sub(now)
+	}
+	wait = max(wait, minDelay)
+	if maxDelay > 0 {
+		wait = min(wait, maxDelay)
+	}
+	return wait
+}
+
+// redirectChain returns the locations of the redirects followed to get resp, resolved against the redirecting URL.
+// The client records the redirect response on every request it creates for a Location header
+func redirectChain(resp *http.Response) []string {
+	var chain []string
+	for req := resp.Request; req != nil && req.Response != nil; req = req.Response.Request {
+		chain = append(chain, req.URL.String())
+	}
+	slices.Reverse(chain)
+	return chain
+}
+
+// bodyBufPool reuses the buffers response bodies are read into
+var bodyBufPool = sync.Pool{
+	New: func() interface{} {
+		return bytes.NewBuffer(make([]byte, 0, 64*1024))
+	},
+}
 
-		matchCtx := MatchContext{
-			Resp: resp,
-			Body: body,
+// acceptEncoding lists the content encodings readEncodedBody decodes. Setting it disables the transparent gzip
+// decoding of the transport
+const acceptEncoding = "gzip, zstd"
+
+// readEncodedBody reads the response body decoded according to its gzip or zstd Content-Encoding,
+// bodies in other encodings are returned as sent
+func readEncodedBody(resp *http.Response) ([]byte, error) {
+	switch strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding"))) {
+	case "gzip":
+		zr, err := gzip.NewReader(resp.Body)
+		if errors.Is(err, io.EOF) {
+			// HEAD and 204 responses keep the header without a body
+			return []byte{}, nil
 		}
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		return readBody(zr)
+	case "zstd":
+		zr, err := zstd.NewReader(resp.Body, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		return readBody(zr)
+	default:
+		return readBody(resp.Body)
+	}
+}
+
+// readBody reads r into a pooled buffer and returns a copy of exactly the bytes read
+func readBody(r io.Reader) ([]byte, error) {
+	buf := bodyBufPool.Get().(*bytes.Buffer)
+	defer func() {
+		buf.Reset()
+		bodyBufPool.Put(buf)
+	}()
+	if _, err := buf.ReadFrom(r); err != nil {
+		return nil, err
+	}
+	body := make([]byte, buf.Len())
+	copy(body, buf.Bytes())
+	return body, nil
+}
 
-		matched := checkMatchers(req.Matchers, req.MatchersCondition, matchCtx)
+// recordSpanError marks the span as failed with err
+func recordSpanError(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
 
-		logger.Info.Printf("Template %s, request %s: matched=%v, status=%d", tmpl.ID, fullURL, matched, resp.StatusCode)
+// matchDNSRequest queries the record types listed in the request path, A without any, and matches the results
+// of each. It returns on the first matching type without querying the remaining ones
+func matchDNSRequest(ctx context.Context, host string, req *Request, tmpl *Template, advanced *AdvancedSettingsChecker, logger *logging.Logger) (bool, error) {
+	queryTypes := req.Path
+	if len(queryTypes) == 0 {
+		queryTypes = []string{"A"}
+	}
+
+	var lastErr error
+	for _, queryType := range queryTypes {
+		matched, err := matchDNSQuery(ctx, host, strings.ToUpper(queryType), req, tmpl, advanced, logger)
+		if err != nil {
+			lastErr = err
+			continue
+		}
 		if matched {
 			return true, nil
 		}
 	}
-
-	return false, nil
+	return false, lastErr
 }
 
-// matchDNSRequest performs DNS queries and matches the results
-func matchDNSRequest(host string, req *Request, tmpl *Template, logger *logging.Logger) (bool, error) {
-	queryType := "A"
-	if len(req.Path) > 0 {
-		queryType = strings.ToUpper(req.Path[0])
+// matchDNSQuery performs a DNS query of a single record type and matches the results
+func matchDNSQuery(ctx context.Context, host, queryType string, req *Request, tmpl *Template, advanced *AdvancedSettingsChecker, logger *logging.Logger) (bool, error) {
+	if advanced.DryRun {
+		logDryRun(advanced, logger, "Would query: "+queryType+" "+host)
+		return false, nil
 	}
 
+	ctx, span := tracer.Start(ctx, "dns.lookup", trace.WithAttributes(
+		attribute.String("template.id", tmpl.ID),
+		attribute.String("target.url", host),
+		attribute.String("dns.query_type", queryType),
+	))
+	defer span.End()
+	resolver := net.DefaultResolver
+
 	var records []string
 	var err error
 
 	switch queryType {
 	case "A":
-		records, err = net.LookupHost(host)
+		records, err = resolver.LookupHost(ctx, host)
 	case "AAAA":
-		ips, e := net.LookupIP(host)
+		ips, e := resolver.LookupIP(ctx, "ip", host)
 		if e != nil {
 			err = e
 		} else {
@@ -167,16 +579,16 @@ func matchDNSRequest(host string, req *Request, tmpl *Template, logger *logging.
 			}
 		}
 	case "TXT":
-		records, err = net.LookupTXT(host)
+		records, err = resolver.LookupTXT(ctx, host)
 	case "CNAME":
-		cname, e := net.LookupCNAME(host)
+		cname, e := resolver.LookupCNAME(ctx, host)
 		if e != nil {
 			err = e
 		} else {
 			records = []string{cname}
 		}
 	case "NS":
-		nsRecords, e := net.LookupNS(host)
+		nsRecords, e := resolver.LookupNS(ctx, host)
 		if e != nil {
 			err = e
 		} else {
@@ -185,7 +597,7 @@ func matchDNSRequest(host string, req *Request, tmpl *Template, logger *logging.
 			}
 		}
 	case "MX":
-		mxRecords, e := net.LookupMX(host)
+		mxRecords, e := resolver.LookupMX(ctx, host)
 		if e != nil {
 			err = e
 		} else {
@@ -194,12 +606,13 @@ func matchDNSRequest(host string, req *Request, tmpl *Template, logger *logging.
 			}
 		}
 	default:
-		logger.Info.Printf("Unsupported DNS query type: %s\n", queryType)
+		logger.Info("Unsupported DNS query type", slog.String("query_type", queryType))
 		return false, nil
 	}
 
 	if err != nil {
-		logger.Info.Printf("DNS lookup error for host %s: %v\n", host, err)
+		logger.Info("DNS lookup error", slog.String("host", host), slog.Any("error", err))
+		recordSpanError(span, err)
 		return false, err
 	}
 
@@ -213,14 +626,21 @@ func matchDNSRequest(host string, req *Request, tmpl *Template, logger *logging.
 	}
 
 	matched := checkMatchers(req.Matchers, req.MatchersCondition, matchCtx)
-	logger.Info.Printf("Template %s, DNS request for host %s, query type %s: matched=%v, records=%v",
-		tmpl.ID, host, queryType, matched, records)
+	logger.Info("DNS request matched",
+		slog.String("template_id", tmpl.ID),
+		slog.String("host", host),
+		slog.String("query_type", queryType),
+		slog.Bool("matched", matched),
+		slog.Any("records", records),
+	)
 
 	return matched, nil
 }
 
-// matchNetworkRequest sends data over network connection and matches response
-func matchNetworkRequest(ctx context.Context, host string, req *Request, tmpl *Template, logger *logging.Logger) (bool, error) {
+// matchNetworkRequest sends every value of the default payload, or nothing without one, over its own network
+// connection and matches the response. It returns on the first matching payload without sending the remaining
+// ones. The port option of the request overrides defaultPort
+func matchNetworkRequest(ctx context.Context, host, defaultPort string, req *Request, tmpl *Template, advanced *AdvancedSettingsChecker, logger *logging.Logger) (bool, error) {
 	if req.Type != "network" {
 		return false, fmt.Errorf("request type is not network: %s", req.Type)
 	}
@@ -232,42 +652,49 @@ func matchNetworkRequest(ctx context.Context, host string, req *Request, tmpl *T
 		}
 	}
 
-	dialer := &net.Dialer{}
-	conn, err := dialer.DialContext(ctx, protocol, host)
-	if err != nil {
-		return false, err
+	port := defaultPort
+	if portVal, ok := req.Options["port"]; ok {
+		port = fmt.Sprint(portVal)
 	}
-	defer conn.Close()
 
-	var toSend []byte
-	if raw, ok := req.Payloads["default"]; ok {
-		switch v := raw.(type) {
-		case []interface{}:
-			if len(v) > 0 {
-				if s, ok := v[0].(string); ok {
-					toSend = []byte(s)
-				}
-			}
-		case []string:
-			if len(v) > 0 {
-				toSend = []byte(v[0])
-			}
-		case map[string]interface{}:
+	if advanced.DryRun {
+		logDryRun(advanced, logger, "Would connect: "+protocol+" "+net.JoinHostPort(host, port))
+		return false, nil
+	}
 
-			for _, inner := range v {
-				if arr, ok := inner.([]interface{}); ok && len(arr) > 0 {
-					if s, ok := arr[0].(string); ok {
-						toSend = []byte(s)
-						break
-					}
-				}
-			}
+	payloads := defaultPayloads(req)
+	if len(payloads) == 0 {
+		payloads = [][]byte{nil}
+	}
+	var lastErr error
+	for _, payload := range payloads {
+		matched, err := matchNetworkPayload(ctx, protocol, net.JoinHostPort(host, port), payload, req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		logger.Info("Network request matched",
+			slog.String("template_id", tmpl.ID),
+			slog.String("host", host),
+			slog.Bool("matched", matched),
+		)
+		if matched {
+			return true, nil
 		}
 	}
+	return false, lastErr
+}
 
-	if len(toSend) > 0 {
-		_, err = conn.Write(toSend)
-		if err != nil {
+// matchNetworkPayload sends the payload, if not empty, over a new connection to addr and matches the response
+func matchNetworkPayload(ctx context.Context, protocol, addr string, payload []byte, req *Request) (bool, error) {
+	conn, err := dialContext(ctx, protocol, addr)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	if len(payload) > 0 {
+		if _, err := conn.Write(payload); err != nil {
 			return false, err
 		}
 	}
@@ -280,23 +707,60 @@ func matchNetworkRequest(ctx context.Context, host string, req *Request, tmpl *T
 		return false, err
 	}
 
-	response := buf[:n]
-
 	matchCtx := MatchContext{
 		Network: &NetworkResponse{
-			Data: response,
+			Data: buf[:n],
 		},
 	}
+	return checkMatchers(req.Matchers, req.MatchersCondition, matchCtx), nil
+}
 
-	matched := checkMatchers(req.Matchers, req.MatchersCondition, matchCtx)
+// defaultPayload returns the first value of the default payload of the request, nil without one
+func defaultPayload(req *Request) []byte {
+	if payloads := defaultPayloads(req); len(payloads) > 0 {
+		return payloads[0]
+	}
+	return nil
+}
 
-	logger.Info.Printf("Template %s, network request to %s: matched=%v", tmpl.ID, host, matched)
+// defaultPayloads returns the string values of the default payload of the request, a map payload gives the
+// values of its lists in key order
+func defaultPayloads(req *Request) [][]byte {
+	var payloads [][]byte
+	switch v := req.Payloads["default"].(type) {
+	case []interface{}:
+		payloads = appendStringPayloads(payloads, v)
+	case []string:
+		for _, s := range v {
+			payloads = append(payloads, []byte(s))
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		for _, k := range keys {
+			if arr, ok := v[k].([]interface{}); ok {
+				payloads = appendStringPayloads(payloads, arr)
+			}
+		}
+	}
+	return payloads
+}
 
-	return matched, nil
+// appendStringPayloads appends the string values to payloads, skipping the others
+func appendStringPayloads(payloads [][]byte, values []interface{}) [][]byte {
+	for _, value := range values {
+		if s, ok := value.(string); ok {
+			payloads = append(payloads, []byte(s))
+		}
+	}
+	return payloads
 }
 
-// matchHeadlessRequest runs headless browser requests and matches output
-func matchHeadlessRequest(ctx context.Context, baseURL string, req *Request, tmpl *Template, advanced *AdvancedSettingsChecker, logger *logging.Logger) (bool, error) {
+// matchHeadlessRequest runs headless browser requests, matches output and returns the extracted values
+func matchHeadlessRequest(ctx context.Context, baseURL string, req *Request, tmpl *Template, advanced *AdvancedSettingsChecker, logger *logging.Logger) (bool, map[string]interface{}, error) {
 	var url string
 	if len(req.Path) > 0 {
 		url = baseURL + req.Path[0]
@@ -304,53 +768,89 @@ func matchHeadlessRequest(ctx context.Context, baseURL string, req *Request, tmp
 		url = baseURL
 	}
 
+	if advanced.DryRun {
+		logDryRun(advanced, logger, "Would open: "+url)
+		return false, nil, nil
+	}
+
+	ctx, span := tracer.Start(ctx, "headless.navigate", trace.WithAttributes(
+		attribute.String("template.id", tmpl.ID),
+		attribute.String("target.url", url),
+	))
+	defer span.End()
+
+	start := time.Now()
 	htmlContent, err := headless.DoHeadlessRequest(ctx, url, advanced.HeadlessTabs)
 	if err != nil {
-		logger.Error.Printf("Headless request failed: %v", err)
-		return false, err
+		logger.Error("Headless request failed", slog.String("url", url), slog.Any("error", err))
+		recordSpanError(span, err)
+		return false, nil, err
+	}
+
+	headlessResp := &HeadlessResponse{
+		RenderTime: time.Since(start),
+		HTML:       htmlContent,
+	}
+	if hasJSPathsExtractor(req.Extractors) {
+		headlessResp.DiscoveredPaths = DiscoverJSPaths(ctx, url, htmlContent, advanced.MaxJSFiles, newInsecureHTTPClient(constants.TenSecTimeout))
 	}
 
 	matchCtx := MatchContext{
-		Body: []byte(htmlContent),
+		Body:     []byte(htmlContent),
+		Headless: headlessResp,
 	}
 
 	matched := checkMatchers(req.Matchers, req.MatchersCondition, matchCtx)
-
-	logger.Info.Printf(
-		"Template %s, headless request to %s: matched=%v, response_len=%d",
-		tmpl.ID, baseURL, matched, len(htmlContent),
+	extracted := processExtractors(req.Extractors, matchCtx)
+
+	logger.Info("Headless request matched",
+		slog.String("template_id", tmpl.ID),
+		slog.String("url", baseURL),
+		slog.Bool("matched", matched),
+		slog.Int("response_len", len(htmlContent)),
+		slog.Int("js_paths", len(headlessResp.DiscoveredPaths)),
 	)
 
-	return matched, nil
+	return matched, extracted, nil
 }
 
 // matchOfflineHTML matches patterns against offline HTML content
 func matchOfflineHTML(html string, req *Request, tmpl *Template, logger *logging.Logger) bool {
+	if len(req.Matchers) == 0 {
+		logger.Info("Request has no matchers defined, skipping offline match", slog.String("template_id", tmpl.ID))
+		return false
+	}
 	for _, matcher := range req.Matchers {
 		switch matcher.Type {
 		case "word":
 			for _, word := range matcher.Words {
 				if strings.Contains(html, word) {
-					logger.Info.Printf(
-						"Template %s, offline matcher type=word matched word=%q", tmpl.ID, word)
+					logger.Info("Offline matcher matched",
+						slog.String("template_id", tmpl.ID),
+						slog.String("matcher_type", "word"),
+						slog.String("word", word),
+					)
 					return true
 				}
 			}
 		case "regex":
 			for _, pattern := range matcher.Regex {
-				re, err := regexp.Compile(pattern)
+				re, err := getCompiledRegex(pattern)
 				if err != nil {
-					logger.Info.Printf("Invalid regex in template %s: %v", tmpl.ID, err)
+					logger.Info("Invalid regex", slog.String("template_id", tmpl.ID), slog.Any("error", err))
 					continue
 				}
 				if re.MatchString(html) {
-					logger.Info.Printf(
-						"Template %s, offline matcher type=regex matched pattern=%q", tmpl.ID, pattern)
+					logger.Info("Offline matcher matched",
+						slog.String("template_id", tmpl.ID),
+						slog.String("matcher_type", "regex"),
+						slog.String("pattern", pattern),
+					)
 					return true
 				}
 			}
 		default:
-			logger.Info.Printf("Unsupported offline matcher type: %s", matcher.Type)
+			logger.Info("Unsupported offline matcher type", slog.String("template_id", tmpl.ID), slog.String("matcher_type", matcher.Type))
 		}
 	}
 	return false
@@ -0,0 +1,446 @@
+package templates
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestEvaluateDynamicPayload(t *testing.T) {
+	vars := map[string]interface{}{"user": "abc"}
+	tests := []struct {
+		name  string
+		expr  string
+		check func(string) bool
+	}{
+		{name: "now_unix", expr: "now_unix()", check: func(v string) bool {
+			ts, err := strconv.ParseInt(v, 10, 64)
+			return err == nil && time.Since(time.Unix(ts, 0)).Abs() < time.Minute
+		}},
+		{name: "rand_string", expr: "rand_string(12)", check: regexp.MustCompile(`^[a-zA-Z0-9]{12}$`).MatchString},
+		{name: "md5", expr: `md5("abc")`, check: func(v string) bool { return v == "900150983cd24fb0d6963f7d28e17f72" }},
+		{name: "sha256", expr: `sha256("abc")`, check: func(v string) bool {
+			return v == "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"
+		}},
+		{name: "variable argument", expr: "md5(user)", check: func(v string) bool { return v == "900150983cd24fb0d6963f7d28e17f72" }},
+		{name: "arithmetic", expr: "1 + 2", check: func(v string) bool { return v == "3" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := evaluateDynamicPayload(tt.expr, vars)
+			if err != nil {
+				t.Fatalf("evaluateDynamicPayload(%q): %v", tt.expr, err)
+			}
+			if !tt.check(got) {
+				t.Errorf("evaluateDynamicPayload(%q) = %q", tt.expr, got)
+			}
+		})
+	}
+
+	if _, err := evaluateDynamicPayload("rand_string()", vars); err == nil {
+		t.Error("rand_string without a length didn't fail")
+	}
+}
+
+func TestDynamicPayloadsInPath(t *testing.T) {
+	rec, srv := newRequestRecorder(t)
+	tmpl := loadTestTemplate(t, `id: dynamic-payloads
+info:
+  name: Dynamic payloads
+  author: test
+  severity: info
+http:
+  - method: GET
+    path:
+      - "{{BaseURL}}/inline/{{expr(now_unix())}}"
+      - "{{BaseURL}}/payload/{{ts}}"
+      - "/relative/{{nonce}}"
+    payloads:
+      ts: ["{{expr(now_unix())}}"]
+      nonce: ["{{expr(rand_string(6))}}"]
+    attack: pitchfork
+    matchers:
+      - type: status
+        status: [200]
+`)
+	runRequests(t, srv.URL, tmpl, testSettings())
+
+	got := rec.requests()
+	if len(got) != 3 {
+		t.Fatalf("requests = %v, want 3", got)
+	}
+	for i, re := range []*regexp.Regexp{
+		regexp.MustCompile(`^/inline/[0-9]+$`),
+		regexp.MustCompile(`^/payload/[0-9]+$`),
+		regexp.MustCompile(`^/relative/[a-zA-Z0-9]{6}$`),
+	} {
+		if !re.MatchString(got[i]) || strings.Contains(got[i], "expr") {
+			t.Errorf("request %d = %s, want it to match %s", i, got[i], re)
+		}
+	}
+}
+
+const durationTemplate = `id: duration-sleep
+info:
+  name: Time based injection
+  author: test
+  severity: high
+http:
+  - method: GET
+    path:
+      - "{{BaseURL}}/search?q=sleep(5)"
+    matchers:
+      - type: duration
+        min-duration: 4s
+        max-duration: 10s
+      - type: duration
+        max-duration: 3s
+`
+
+func TestDurationMatcher(t *testing.T) {
+	if testing.Short() {
+		t.Skip("the mock server sleeps 5s")
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(5 * time.Second)
+	}))
+	defer srv.Close()
+
+	tmpl := loadTestTemplate(t, durationTemplate)
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/search?q=sleep(5)", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := doHTTPRequestWithRetry(context.Background(), srv.Client(), req, testSettings())
+	if err != nil {
+		t.Fatalf("doHTTPRequestWithRetry: %v", err)
+	}
+	if result.Duration < 5*time.Second {
+		t.Fatalf("result duration = %v, want at least 5s", result.Duration)
+	}
+
+	ctx := MatchContext{Resp: result.Resp, Body: result.Body, Duration: result.Duration}
+	matchers := tmpl.Requests[0].Matchers
+	if !checkSingleMatcher(matchers[0], ctx) {
+		t.Errorf("min-duration 4s, max-duration 10s matcher missed a %v response", result.Duration)
+	}
+	if checkSingleMatcher(matchers[1], ctx) {
+		t.Errorf("max-duration 3s matcher fired on a %v response", result.Duration)
+	}
+}
+
+func TestDurationMatcherBounds(t *testing.T) {
+	resp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}}
+	tests := []struct {
+		name     string
+		duration time.Duration
+		matcher  Matcher
+		want     bool
+	}{
+		{name: "at min", duration: 4 * time.Second, matcher: Matcher{MinDuration: 4 * time.Second}, want: true},
+		{name: "below min", duration: 3 * time.Second, matcher: Matcher{MinDuration: 4 * time.Second}, want: false},
+		{name: "at max", duration: 3 * time.Second, matcher: Matcher{MaxDuration: 3 * time.Second}, want: true},
+		{name: "above max", duration: 5 * time.Second, matcher: Matcher{MaxDuration: 3 * time.Second}, want: false},
+		{name: "no max", duration: time.Minute, matcher: Matcher{MinDuration: time.Second}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.matcher.Type = "duration"
+			if got := checkSingleMatcher(tt.matcher, MatchContext{Resp: resp, Duration: tt.duration}); got != tt.want {
+				t.Errorf("duration matcher = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if checkSingleMatcher(Matcher{Type: "duration"}, MatchContext{Duration: time.Second}) {
+		t.Error("duration matcher matched without a response")
+	}
+}
+
+func TestReadBody(t *testing.T) {
+	first, err := readBody(strings.NewReader("first response body"))
+	if err != nil {
+		t.Fatalf("readBody: %v", err)
+	}
+	large := bytes.Repeat([]byte("x"), 200*1024)
+	second, err := readBody(bytes.NewReader(large))
+	if err != nil {
+		t.Fatalf("readBody: %v", err)
+	}
+	if string(first) != "first response body" || cap(first) != len(first) {
+		t.Errorf("first body = %q with capacity %d, want an exact copy", first, cap(first))
+	}
+	if !bytes.Equal(second, large) {
+		t.Errorf("body larger than the pooled buffer read as %d bytes, want %d", len(second), len(large))
+	}
+	if empty, err := readBody(strings.NewReader("")); err != nil || len(empty) != 0 {
+		t.Errorf("empty body = %q, %v", empty, err)
+	}
+}
+
+// BenchmarkBodyReadingAllocsPerOp compares reading a 48KB body with io.ReadAll against the pooled buffer
+func BenchmarkBodyReadingAllocsPerOp(b *testing.B) {
+	body := bytes.Repeat([]byte("<tr><td>row</td></tr>\n"), 48*1024/22)
+	b.Run("io.ReadAll", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := io.ReadAll(bytes.NewReader(body)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("pooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := readBody(bytes.NewReader(body)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// newRateLimitedServer answers the first limited requests with 429 and retryAfter, the later ones with 200, and
+// records the time of every request
+func newRateLimitedServer(t *testing.T, limited int, retryAfter string) (*httptest.Server, func() []time.Time) {
+	t.Helper()
+	var mu sync.Mutex
+	var times []time.Time
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		times = append(times, time.Now())
+		n := len(times)
+		mu.Unlock()
+		if n <= limited {
+			w.Header().Set("Retry-After", retryAfter)
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Clone(times)
+	}
+}
+
+func TestRetry429WaitsForRetryAfter(t *testing.T) {
+	srv, requests := newRateLimitedServer(t, 1, "2")
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	advanced := DefaultAdvancedSettings()
+	if advanced.Retries != 0 || advanced.RetryDelay > time.Second {
+		t.Fatalf("defaults changed: %d retries, %v delay", advanced.Retries, advanced.RetryDelay)
+	}
+
+	result, err := doHTTPRequestWithRetry(context.Background(), srv.Client(), req, advanced)
+	if err != nil {
+		t.Fatalf("doHTTPRequestWithRetry: %v", err)
+	}
+	if result.Resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want 200 after the 429 was retried", result.Resp.StatusCode)
+	}
+	times := requests()
+	if len(times) != 2 {
+		t.Fatalf("%d requests sent, want 2", len(times))
+	}
+	if gap := times[1].Sub(times[0]); gap < 2*time.Second {
+		t.Errorf("retried after %v, want at least the 2s of Retry-After", gap)
+	}
+}
+
+func TestRetry429Limits(t *testing.T) {
+	tests := []struct {
+		name       string
+		respect429 bool
+		wantStatus int
+		wantSent   int
+	}{
+		{name: "retries capped", respect429: true, wantStatus: http.StatusTooManyRequests, wantSent: max429Retries + 1},
+		{name: "respect429 disabled", respect429: false, wantStatus: http.StatusTooManyRequests, wantSent: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, requests := newRateLimitedServer(t, 100, "0")
+			req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+			advanced := &AdvancedSettingsChecker{Respect429: tt.respect429, RetryDelay: time.Millisecond}
+			result, err := doHTTPRequestWithRetry(context.Background(), srv.Client(), req, advanced)
+			if err != nil {
+				t.Fatalf("doHTTPRequestWithRetry: %v", err)
+			}
+			if result.Resp.StatusCode != tt.wantStatus || len(requests()) != tt.wantSent {
+				t.Errorf("status %d after %d requests, want %d after %d", result.Resp.StatusCode, len(requests()), tt.wantStatus, tt.wantSent)
+			}
+		})
+	}
+}
+
+func TestRetryAfterDelay(t *testing.T) {
+	now := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+	tests := []struct {
+		retryAfter string
+		min, max   time.Duration
+		want       time.Duration
+	}{
+		{retryAfter: "2", min: time.Second, want: 2 * time.Second},
+		{retryAfter: "2", min: 5 * time.Second, want: 5 * time.Second},
+		{retryAfter: "120", min: time.Second, max: time.Minute, want: time.Minute},
+		{retryAfter: now.Add(30 * time.Second).Format(http.TimeFormat), want: 30 * time.Second},
+		{retryAfter: now.Add(-time.Minute).Format(time.RFC1123), min: time.Second, want: time.Second},
+		{retryAfter: "soon", min: 3 * time.Second, want: 3 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := retryAfterDelay(tt.retryAfter, tt.min, tt.max, now); got != tt.want {
+			t.Errorf("retryAfterDelay(%q, %v, %v) = %v, want %v", tt.retryAfter, tt.min, tt.max, got, tt.want)
+		}
+	}
+}
+
+const encodedBodyTemplate = `id: encoded-body-%s
+info:
+  name: Encoded body
+  author: test
+  severity: info
+http:
+  - method: GET
+    path:
+      - "{{BaseURL}}/page?encoding=%s"
+    matchers:
+      - type: word
+        words:
+          - "admin panel v3"
+`
+
+func TestEncodedResponseBody(t *testing.T) {
+	const page = "<html><title>admin panel v3</title></html>"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encoding := r.URL.Query().Get("encoding")
+		if encoding != "identity" && !strings.Contains(r.Header.Get("Accept-Encoding"), encoding) {
+			http.Error(w, "encoding not accepted", http.StatusNotAcceptable)
+			return
+		}
+		w.Header().Set("Content-Encoding", encoding)
+		switch encoding {
+		case "zstd":
+			zw, _ := zstd.NewWriter(w)
+			io.WriteString(zw, page)
+			zw.Close()
+		case "gzip":
+			gw := gzip.NewWriter(w)
+			io.WriteString(gw, page)
+			gw.Close()
+		default:
+			w.Header().Del("Content-Encoding")
+			io.WriteString(w, page)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	for _, encoding := range []string{"zstd", "gzip", "identity"} {
+		tmpl := loadTestTemplate(t, fmt.Sprintf(encodedBodyTemplate, encoding, encoding))
+		if !runRequests(t, srv.URL, tmpl, testSettings()) {
+			t.Errorf("word matcher missed the %q encoded body", encoding)
+		}
+	}
+}
+
+func TestDNSRequestStopsAtFirstMatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		pattern     string
+		wantMatch   bool
+		wantQueried bool
+	}{
+		{name: "A matches", pattern: "127.0.0.7", wantMatch: true},
+		{name: "A misses", pattern: "v=spf1", wantQueried: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			questions := serveDNS(t, map[string]net.IP{"scan.test": net.IPv4(127, 0, 0, 7)})
+			req := &Request{
+				Type:     "dns",
+				Path:     []string{"a", "TXT"},
+				Matchers: []Matcher{{Type: "dns", Pattern: tt.pattern}},
+			}
+			matched, _ := matchDNSRequest(context.Background(), "scan.test", req, &Template{ID: "dns-early-exit"}, testSettings(), testLogger())
+			if matched != tt.wantMatch {
+				t.Errorf("matchDNSRequest = %v, want %v", matched, tt.wantMatch)
+			}
+			var queriedTXT bool
+			for _, q := range questions() {
+				if q == "TXT scan.test" {
+					queriedTXT = true
+				}
+			}
+			if queriedTXT != tt.wantQueried {
+				t.Errorf("TXT queried = %v, want %v (questions %v)", queriedTXT, tt.wantQueried, questions())
+			}
+		})
+	}
+}
+
+func TestNetworkRequestStopsAtFirstMatch(t *testing.T) {
+	tests := []struct {
+		name      string
+		pattern   string
+		wantMatch bool
+		wantSent  []string
+	}{
+		{name: "first payload matches", pattern: "+PONG ping", wantMatch: true, wantSent: []string{"ping"}},
+		{name: "second payload matches", pattern: "+PONG info", wantMatch: true, wantSent: []string{"ping", "info"}},
+		{name: "no payload matches", pattern: "+PONG quit", wantSent: []string{"ping", "info"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { l.Close() })
+			var mu sync.Mutex
+			var sent []string
+			go func() {
+				for {
+					conn, err := l.Accept()
+					if err != nil {
+						return
+					}
+					buf := make([]byte, 64)
+					n, _ := conn.Read(buf)
+					mu.Lock()
+					sent = append(sent, string(buf[:n]))
+					mu.Unlock()
+					io.WriteString(conn, "+PONG "+string(buf[:n])+"\r\n")
+					conn.Close()
+				}
+			}()
+
+			req := &Request{
+				Type:     "network",
+				Payloads: map[string]interface{}{"default": []interface{}{"ping", "info"}},
+				Matchers: []Matcher{{Type: "network", Pattern: tt.pattern}},
+			}
+			_, port, _ := net.SplitHostPort(l.Addr().String())
+			matched, err := matchNetworkRequest(context.Background(), "127.0.0.1", port, req, &Template{ID: "network-early-exit"}, testSettings(), testLogger())
+			if err != nil || matched != tt.wantMatch {
+				t.Fatalf("matchNetworkRequest = %v, %v, want %v", matched, err, tt.wantMatch)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if strings.Join(sent, ",") != strings.Join(tt.wantSent, ",") {
+				t.Errorf("payloads sent = %v, want %v", sent, tt.wantSent)
+			}
+		})
+	}
+}
@@ -0,0 +1,214 @@
+// package templates - remote template updates
+package templates
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/artnikel/nuclei/internal/constants"
+)
+
+// ManifestSignatureHeader carries the hex HMAC-SHA256 of the manifest body
+const ManifestSignatureHeader = "X-Manifest-Signature"
+
+// lastUpdateFile stores the time of the last applied update inside the templates directory
+const lastUpdateFile = ".last-update"
+
+// ManifestFile describes a single template file in the update manifest
+type ManifestFile struct {
+	Path   string `json:"path"`
+	SHA256 string `json:"sha256"`
+	URL    string `json:"url,omitempty"`
+}
+
+// Manifest lists the templates published at the update URL
+type Manifest struct {
+	Files []ManifestFile `json:"files"`
+}
+
+// UpdateResult reports the files that differ from the manifest and whether they were written
+type UpdateResult struct {
+	Changed []string
+	Applied bool
+}
+
+// Updater keeps a local templates directory in sync with a remote manifest
+type Updater struct {
+	ManifestURL string
+	Dir         string
+	HMACKey     []byte
+	DryRun      bool
+
+	client *http.Client
+}
+
+// NewUpdater creates an updater for the templates directory dir
+func NewUpdater(manifestURL, dir, hmacKey string) *Updater {
+	return &Updater{
+		ManifestURL: manifestURL,
+		Dir:         dir,
+		HMACKey:     []byte(hmacKey),
+		client:      &http.Client{Timeout: constants.OneMinTimeout},
+	}
+}
+
+// CheckForUpdates fetches and verifies the manifest, then downloads every changed template unless DryRun is set
+func (u *Updater) CheckForUpdates(ctx context.Context) (*UpdateResult, error) {
+	if len(u.HMACKey) == 0 {
+		return nil, errors.New("template update HMAC key is not configured")
+	}
+	manifest, err := u.fetchManifest(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &UpdateResult{}
+	for _, f := range manifest.Files {
+		localPath, err := u.localPath(f.Path)
+		if err != nil {
+			return nil, err
+		}
+		hash, err := fileSHA256(localPath)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		if strings.EqualFold(hash, f.SHA256) {
+			continue
+		}
+		result.Changed = append(result.Changed, f.Path)
+		if u.DryRun {
+			continue
+		}
+		if err := u.download(ctx, f, localPath); err != nil {
+			return nil, err
+		}
+	}
+
+	if u.DryRun {
+		return result, nil
+	}
+	result.Applied = true
+	stamp := []byte(time.Now().UTC().Format(time.RFC3339))
+	if err := os.WriteFile(filepath.Join(u.Dir, lastUpdateFile), stamp, constants.FilePerm); err != nil {
+		return nil, fmt.Errorf("failed to record update time: %w", err)
+	}
+	return result, nil
+}
+
+// fetchManifest downloads the manifest and verifies its HMAC signature
+func (u *Updater) fetchManifest(ctx context.Context) (*Manifest, error) {
+	body, resp, err := u.get(ctx, u.ManifestURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch template manifest: %w", err)
+	}
+
+	signature, err := hex.DecodeString(resp.Header.Get(ManifestSignatureHeader))
+	if err != nil || len(signature) == 0 {
+		return nil, errors.New("template manifest is not signed")
+	}
+	mac := hmac.New(sha256.New, u.HMACKey)
+	mac.Write(body)
+	if !hmac.Equal(mac.Sum(nil), signature) {
+		return nil, errors.New("template manifest signature mismatch")
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(body, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to decode template manifest: %w", err)
+	}
+	return &manifest, nil
+}
+
+// download fetches a template, checks its hash and atomically replaces the local file
+func (u *Updater) download(ctx context.Context, f ManifestFile, localPath string) error {
+	fileURL := f.URL
+	if fileURL == "" {
+		base, err := url.Parse(u.ManifestURL)
+		if err != nil {
+			return err
+		}
+		ref, err := url.Parse(f.Path)
+		if err != nil {
+			return err
+		}
+		fileURL = base.ResolveReference(ref).String()
+	}
+
+	body, _, err := u.get(ctx, fileURL)
+	if err != nil {
+		return fmt.Errorf("failed to download template %s: %w", f.Path, err)
+	}
+	sum := sha256.Sum256(body)
+	if !strings.EqualFold(hex.EncodeToString(sum[:]), f.SHA256) {
+		return fmt.Errorf("template %s does not match the manifest hash", f.Path)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(localPath), constants.DirPerm); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(localPath), ".update-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(body); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), localPath)
+}
+
+// get performs a GET request and returns the body of a successful response
+func (u *Updater) get(ctx context.Context, target string) ([]byte, *http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	return body, resp, nil
+}
+
+// localPath maps a manifest path into the templates directory, rejecting paths that escape it
+func (u *Updater) localPath(p string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(p))
+	if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid template path in manifest: %s", p)
+	}
+	return filepath.Join(u.Dir, clean), nil
+}
+
+// fileSHA256 returns the hex SHA256 of the file at path
+func fileSHA256(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
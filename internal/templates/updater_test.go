@@ -0,0 +1,157 @@
+package templates
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const testHMACKey = "update-key"
+
+// manifestServer serves a signed manifest of files and records which of them were downloaded
+type manifestServer struct {
+	mu         sync.Mutex
+	downloads  []string
+	files      map[string]string
+	signingKey string
+}
+
+func (m *manifestServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/manifest.json" {
+		var manifest Manifest
+		for path, content := range m.files {
+			sum := sha256.Sum256([]byte(content))
+			manifest.Files = append(manifest.Files, ManifestFile{Path: path, SHA256: hex.EncodeToString(sum[:])})
+		}
+		body, _ := json.Marshal(manifest)
+		mac := hmac.New(sha256.New, []byte(m.signingKey))
+		mac.Write(body)
+		w.Header().Set(ManifestSignatureHeader, hex.EncodeToString(mac.Sum(nil)))
+		w.Write(body)
+		return
+	}
+	path := strings.TrimPrefix(r.URL.Path, "/")
+	content, ok := m.files[path]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	m.mu.Lock()
+	m.downloads = append(m.downloads, path)
+	m.mu.Unlock()
+	w.Write([]byte(content))
+}
+
+func (m *manifestServer) downloaded() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	res := append([]string(nil), m.downloads...)
+	sort.Strings(res)
+	return res
+}
+
+func newManifestServer(t *testing.T, signingKey string) (*manifestServer, string) {
+	t.Helper()
+	m := &manifestServer{
+		signingKey: signingKey,
+		files: map[string]string{
+			"unchanged.yaml":  "id: unchanged\n",
+			"changed.yaml":    "id: changed\nversion: 2\n",
+			"http/added.yaml": "id: added\n",
+		},
+	}
+	srv := httptest.NewServer(m)
+	t.Cleanup(srv.Close)
+	return m, srv.URL + "/manifest.json"
+}
+
+// localTemplates writes the local copies: one identical to the manifest, one outdated
+func localTemplates(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	writeTestFile(t, dir, "unchanged.yaml", "id: unchanged\n")
+	writeTestFile(t, dir, "changed.yaml", "id: changed\nversion: 1\n")
+	return dir
+}
+
+func TestUpdaterDownloadsChangedFiles(t *testing.T) {
+	m, manifestURL := newManifestServer(t, testHMACKey)
+	dir := localTemplates(t)
+
+	result, err := NewUpdater(manifestURL, dir, testHMACKey).CheckForUpdates(context.Background())
+	if err != nil {
+		t.Fatalf("CheckForUpdates: %v", err)
+	}
+	sort.Strings(result.Changed)
+	want := []string{"changed.yaml", "http/added.yaml"}
+	if !result.Applied || strings.Join(result.Changed, ",") != strings.Join(want, ",") {
+		t.Fatalf("result = %+v, want %v applied", result, want)
+	}
+	if got := m.downloaded(); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("downloaded %v, want only %v", got, want)
+	}
+	for path, content := range m.files {
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(path)))
+		if err != nil || string(data) != content {
+			t.Errorf("local %s = %q, %v, want %q", path, data, err, content)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, lastUpdateFile)); err != nil {
+		t.Errorf("update time not recorded: %v", err)
+	}
+}
+
+func TestUpdaterDryRun(t *testing.T) {
+	m, manifestURL := newManifestServer(t, testHMACKey)
+	dir := localTemplates(t)
+
+	updater := NewUpdater(manifestURL, dir, testHMACKey)
+	updater.DryRun = true
+	result, err := updater.CheckForUpdates(context.Background())
+	if err != nil {
+		t.Fatalf("CheckForUpdates: %v", err)
+	}
+	if result.Applied || len(result.Changed) != 2 || len(m.downloaded()) != 0 {
+		t.Fatalf("dry run result = %+v, downloads %v", result, m.downloaded())
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "changed.yaml")); string(data) != "id: changed\nversion: 1\n" {
+		t.Errorf("dry run modified changed.yaml: %q", data)
+	}
+}
+
+func TestUpdaterRejectsInvalidSignature(t *testing.T) {
+	m, manifestURL := newManifestServer(t, "attacker-key")
+	dir := localTemplates(t)
+
+	if _, err := NewUpdater(manifestURL, dir, testHMACKey).CheckForUpdates(context.Background()); err == nil {
+		t.Fatal("manifest signed with another key was accepted")
+	}
+	if len(m.downloaded()) != 0 {
+		t.Errorf("files downloaded from an unverified manifest: %v", m.downloaded())
+	}
+	if _, err := NewUpdater(manifestURL, dir, "").CheckForUpdates(context.Background()); err == nil {
+		t.Error("update without an HMAC key didn't fail")
+	}
+}
+
+func TestUpdaterLocalPath(t *testing.T) {
+	u := NewUpdater("", t.TempDir(), testHMACKey)
+	for _, p := range []string{"../escape.yaml", "a/../../escape.yaml", "/etc/passwd", ".."} {
+		if _, err := u.localPath(p); err == nil {
+			t.Errorf("localPath(%q) escaped the templates directory", p)
+		}
+	}
+	if got, err := u.localPath("http/cve.yaml"); err != nil || got != filepath.Join(u.Dir, "http", "cve.yaml") {
+		t.Errorf("localPath(http/cve.yaml) = %q, %v", got, err)
+	}
+}
@@ -2,50 +2,220 @@
 package templates
 
 import (
-	"crypto/tls"
+	"context"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
+	"regexp"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 
+	"github.com/antchfx/xpath"
+	"github.com/artnikel/nuclei/internal/constants"
 	"golang.org/x/net/html"
+	"golang.org/x/net/idna"
 )
 
+// regexCache stores compiled regular expressions keyed by the full pattern
+var regexCache sync.Map
+
+// getCompiledRegex returns the compiled pattern, compiling it only on first use
+func getCompiledRegex(pattern string) (*regexp.Regexp, error) {
+	if re, ok := regexCache.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	regexCache.Store(pattern, re)
+	return re, nil
+}
+
+// xpathCache stores compiled XPath expressions keyed by the expression string
+var xpathCache sync.Map
+
+// getCompiledXPath returns the compiled expression, compiling it only on first use
+func getCompiledXPath(expr string) (*xpath.Expr, error) {
+	if compiled, ok := xpathCache.Load(expr); ok {
+		return compiled.(*xpath.Expr), nil
+	}
+	compiled, err := xpath.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	xpathCache.Store(expr, compiled)
+	return compiled, nil
+}
+
 // newInsecureHTTTPClient returns HTTP client with TLS-certificate checking disabled
 func newInsecureHTTPClient(timeout time.Duration) *http.Client {
-	tr := &http.Transport{
-		TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
-		DisableKeepAlives: true,
-	}
 	return &http.Client{
-		Transport: tr,
+		Transport: newInsecureTransport(transportSettings{disableKeepAlives: true}),
 		Timeout:   timeout,
 	}
 }
 
-// buildFullURL builds a full URL based on the base and relative paths
+// FetchHTML downloads the page at targetURL without a browser, it's used when headless mode is disabled
+func FetchHTML(ctx context.Context, targetURL string, timeout time.Duration) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, targetURL, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := newInsecureHTTPClient(timeout).Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
+
+// buildFullURL builds a full URL based on the base and relative paths. The relative path is taken as escaped,
+// its escapes and query string are kept as they are
 func buildFullURL(base *url.URL, path string) string {
 	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
 		return path
 	}
 	u := *base
+	if isIPv6(u.Hostname()) {
+		if port := u.Port(); port != "" {
+			u.Host = net.JoinHostPort(u.Hostname(), port)
+		} else {
+			u.Host = "[" + u.Hostname() + "]"
+		}
+	}
+	path, query, hasQuery := strings.Cut(path, "?")
 	if !strings.HasPrefix(path, "/") {
 		path = "/" + path
 	}
-	u.Path = strings.TrimRight(u.Path, "/") + path
+	setEscapedPath(&u, strings.TrimRight(u.EscapedPath(), "/")+path)
+	if hasQuery {
+		u.RawQuery = query
+		u.ForceQuery = query == ""
+	}
 	return u.String()
 }
 
-// substituteVariables replaces placeholders of the {{key}} form with values from vars
+// setEscapedPath sets the path of u from its escaped form so the escapes aren't encoded a second time,
+// a path with invalid escapes is set as is
+func setEscapedPath(u *url.URL, escaped string) {
+	if unescaped, err := url.PathUnescape(escaped); err == nil {
+		u.Path, u.RawPath = unescaped, escaped
+	} else {
+		u.Path, u.RawPath = escaped, ""
+	}
+}
+
+// setRawRequestPath makes the request to u send the path of rawURL, a path or a full URL, without escaping:
+// characters such as < and " the URL escaping would encode stay literal. The path is sent in the absolute form
+// of the request line, the query string of u is kept
+func setRawRequestPath(u *url.URL, base *url.URL, rawURL string) {
+	var path string
+	if strings.HasPrefix(rawURL, "http://") || strings.HasPrefix(rawURL, "https://") {
+		_, rest, _ := strings.Cut(rawURL, "://")
+		path = "/"
+		if i := strings.IndexByte(rest, '/'); i >= 0 {
+			path = rest[i:]
+		}
+	} else {
+		path = strings.TrimRight(base.EscapedPath(), "/") + "/" + strings.TrimPrefix(rawURL, "/")
+	}
+	path, _, _ = strings.Cut(path, "?")
+	u.Opaque = "//" + u.Host + path
+}
+
+// NormalizeIDN converts a non-ASCII hostname of the target URL to its punycode form, other targets are returned as is
+func NormalizeIDN(target string) string {
+	u, err := url.Parse(target)
+	if err != nil || u.Host == "" {
+		return target
+	}
+	host := u.Hostname()
+	if isASCII(host) {
+		return target
+	}
+	ascii, err := idna.Lookup.ToASCII(host)
+	if err != nil {
+		return target
+	}
+	if port := u.Port(); port != "" {
+		u.Host = net.JoinHostPort(ascii, port)
+	} else {
+		u.Host = ascii
+	}
+	return u.String()
+}
+
+// isASCII reports whether s contains only ASCII characters
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// isIPv6 reports whether host is a literal IPv6 address
+func isIPv6(host string) bool {
+	ip := net.ParseIP(host)
+	return ip != nil && ip.To4() == nil && len(ip) == net.IPv6len
+}
+
+// targetPort returns the explicit port of the URL or the default port of its scheme
+func targetPort(u *url.URL) string {
+	if port := u.Port(); port != "" {
+		return port
+	}
+	if u.Scheme == "https" {
+		return "443"
+	}
+	return "80"
+}
+
+// isPortOpen reports whether the port of the target accepts TCP connections within timeout
+func isPortOpen(ctx context.Context, u *url.URL, timeout time.Duration) bool {
+	if timeout <= 0 {
+		timeout = constants.FiveSecTimeout
+	}
+	dialer := net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(u.Hostname(), targetPort(u)))
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// substituteVariables replaces placeholders of the {{key}} form with values from vars, {{raw:key}} skips the value encoding
 func substituteVariables(s string, vars map[string]interface{}) string {
 	for k, v := range vars {
 		placeholder := fmt.Sprintf("{{%s}}", k)
 
+		rawPlaceholder := fmt.Sprintf("{{%s%s}}", rawPrefix, k)
+
 		switch val := v.(type) {
 		case string:
+			s = strings.ReplaceAll(s, rawPlaceholder, val)
 			s = strings.ReplaceAll(s, placeholder, val)
+		case encodedValue:
+			s = strings.ReplaceAll(s, rawPlaceholder, val.Value)
+			if strings.Contains(s, placeholder) {
+				encoded, err := applyEncoding(val.Value, val.Encoding)
+				if err != nil {
+					encoded = val.Value
+				}
+				s = strings.ReplaceAll(s, placeholder, encoded)
+			}
 		case []interface{}:
 			var parts []string
 			for _, item := range val {
@@ -61,12 +231,86 @@ func substituteVariables(s string, vars map[string]interface{}) string {
 	return s
 }
 
+// urlVars are the request variables holding URL parts, they are never path-escaped
+var urlVars = map[string]bool{"BaseURL": true, "Host": true, "Hostname": true, "IPv6": true}
+
+// substitutePathVariables is substituteVariables for request paths: plain string values placed in the path part
+// of a URL (starting with /, http://, https:// or {{BaseURL}}) are inserted with escapePathValue.
+// {{raw:key}}, encoded values and the query string are substituted as is
+func substitutePathVariables(p string, vars map[string]interface{}) string {
+	if !isURLPath(p) {
+		return substituteVariables(p, vars)
+	}
+	path, query, hasQuery := strings.Cut(p, "?")
+	for k, v := range vars {
+		if val, ok := v.(string); ok && !urlVars[k] {
+			path = strings.ReplaceAll(path, fmt.Sprintf("{{%s}}", k), escapePathValue(val))
+		}
+	}
+	if hasQuery {
+		path += "?" + query
+	}
+	return substituteVariables(path, vars)
+}
+
+// escapePathValue path-escapes every segment of val and keeps the slashes between them, so multi-segment and
+// traversal payloads such as ../../etc/passwd reach the target as they are
+func escapePathValue(val string) string {
+	segments := strings.Split(val, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// isURLPath reports whether s is a URL or an absolute path
+func isURLPath(s string) bool {
+	for _, prefix := range []string{"/", "http://", "https://", "{{BaseURL}}"} {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// copyVariables returns a deep copy of the template variables so a scan can modify them safely
+func copyVariables(src map[string]interface{}) map[string]interface{} {
+	dst := make(map[string]interface{}, len(src))
+	for k, v := range src {
+		dst[k] = copyValue(v)
+	}
+	return dst
+}
+
+// copyValue deep copies the slices and maps produced by YAML decoding
+func copyValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		return copyVariables(val)
+	case []interface{}:
+		items := make([]interface{}, len(val))
+		for i, item := range val {
+			items[i] = copyValue(item)
+		}
+		return items
+	case []string:
+		return append([]string(nil), val...)
+	default:
+		return v
+	}
+}
+
 // templateMatchesHost checks if the target host matches the list in the template
 func templateMatchesHost(tmpl *Template, targetHost string) bool {
-	if len(tmpl.Hosts) == 0 {
+	return hostsMatch(tmpl.Hosts, targetHost)
+}
+
+// hostsMatch checks if the target host matches one of hosts, an empty list matches every host
+func hostsMatch(hosts []string, targetHost string) bool {
+	if len(hosts) == 0 {
 		return true
 	}
-	for _, h := range tmpl.Hosts {
+	for _, h := range hosts {
 		if strings.Contains(targetHost, h) {
 			return true
 		}
@@ -74,6 +318,59 @@ func templateMatchesHost(tmpl *Template, targetHost string) bool {
 	return false
 }
 
+// MatchesHost reports whether the template applies to the target host
+func (t *Template) MatchesHost(targetHost string) bool {
+	return templateMatchesHost(t, targetHost)
+}
+
+// AllTags returns the tags of the info block together with the top level tags
+func (t *Template) AllTags() []string {
+	tags := make([]string, 0, len(t.Info.Tags)+len(t.Tags))
+	tags = append(tags, t.Info.Tags...)
+	return append(tags, t.Tags...)
+}
+
+// HasAnyTag reports whether the template is tagged with at least one of tags, an empty list matches every template
+func (t *Template) HasAnyTag(tags []string) bool {
+	if len(tags) == 0 {
+		return true
+	}
+	for _, want := range tags {
+		want = strings.TrimSpace(want)
+		for _, tag := range t.AllTags() {
+			if strings.EqualFold(strings.TrimSpace(tag), want) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// MatchesSeverity reports whether the template severity is one of severities (case-insensitive),
+// an empty list matches every template
+func (t *Template) MatchesSeverity(severities []string) bool {
+	if len(severities) == 0 {
+		return true
+	}
+	severity := t.SeverityLevel()
+	for _, s := range severities {
+		if strings.EqualFold(strings.TrimSpace(s), severity) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasMatchers reports whether any request of the template defines a matcher
+func (t *Template) HasMatchers() bool {
+	for _, req := range t.Requests {
+		if len(req.Matchers) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
 // extractHTMLTitle extracts the contents of the <title> tag from the HTML document
 func extractHTMLTitle(r io.Reader) string {
 	doc, err := html.Parse(r)
@@ -112,8 +409,12 @@ func canOfflineMatch(m Matcher) bool {
 	}
 }
 
-// canOfflineMatchRequest returns true if all matchers in the request support offline matching
+// canOfflineMatchRequest returns true if all matchers in the request support offline matching.
+// Requests with extractors need the real response and are never matched offline
 func canOfflineMatchRequest(req *Request) bool {
+	if len(req.Extractors) > 0 {
+		return false
+	}
 	for _, m := range req.Matchers {
 		if !canOfflineMatch(m) {
 			return false
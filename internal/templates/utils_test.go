@@ -0,0 +1,278 @@
+package templates
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/antchfx/htmlquery"
+	"github.com/antchfx/xpath"
+)
+
+func TestBuildFullURL(t *testing.T) {
+	tests := []struct {
+		name string
+		base string
+		path string
+		want string
+	}{
+		{name: "ipv4", base: "http://127.0.0.1:8080", path: "/admin", want: "http://127.0.0.1:8080/admin"},
+		{name: "path without slash", base: "http://example.com", path: "admin", want: "http://example.com/admin"},
+		{name: "base path", base: "http://example.com/app/", path: "/admin", want: "http://example.com/app/admin"},
+		{name: "ipv6 with port", base: "http://[::1]:8080", path: "/admin", want: "http://[::1]:8080/admin"},
+		{name: "ipv6 without port", base: "http://[2001:db8::1]", path: "/admin", want: "http://[2001:db8::1]/admin"},
+		{name: "absolute url", base: "http://example.com", path: "https://other.test/x", want: "https://other.test/x"},
+		{name: "escaped path", base: "http://example.com", path: "/search/%3Cscript%3E", want: "http://example.com/search/%3Cscript%3E"},
+		{name: "escaped slash", base: "http://example.com", path: "/files/a%2Fb", want: "http://example.com/files/a%2Fb"},
+		{name: "query string", base: "http://example.com/app", path: "/search?q=a%20b&x=1", want: "http://example.com/app/search?q=a%20b&x=1"},
+		{name: "empty query", base: "http://example.com", path: "/search?", want: "http://example.com/search?"},
+		{name: "escaped base path", base: "http://example.com/my%20app/", path: "/admin", want: "http://example.com/my%20app/admin"},
+		{name: "invalid escape", base: "http://example.com", path: "/100%zz", want: "http://example.com/100%25zz"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base, err := url.Parse(tt.base)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := buildFullURL(base, tt.path); got != tt.want {
+				t.Errorf("buildFullURL(%s, %s) = %s, want %s", tt.base, tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBaseRequestVarsIPv6(t *testing.T) {
+	for target, want := range map[string]string{"http://[::1]:8080": "::1", "http://127.0.0.1:8080": "", "http://example.com": ""} {
+		u, _ := url.Parse(target)
+		if got := baseRequestVars(u, nil)["IPv6"]; got != want {
+			t.Errorf("IPv6 of %s = %q, want %q", target, got, want)
+		}
+	}
+}
+
+// listenIPv6 listens on the IPv6 loopback, skipping the test where it's unavailable
+func listenIPv6(t *testing.T) net.Listener {
+	t.Helper()
+	l, err := net.Listen("tcp6", "[::1]:0")
+	if err != nil {
+		t.Skipf("IPv6 loopback unavailable: %v", err)
+	}
+	return l
+}
+
+func TestHTTPRequestIPv6Target(t *testing.T) {
+	var host, ipv6Header string
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, ipv6Header = r.Host, r.Header.Get("X-IPv6")
+		io.WriteString(w, "ok")
+	}))
+	srv.Listener = listenIPv6(t)
+	srv.Start()
+	defer srv.Close()
+
+	tmpl := loadTestTemplate(t, `id: ipv6-http
+info:
+  name: IPv6 HTTP
+  author: test
+  severity: info
+http:
+  - path:
+      - "/status"
+    headers:
+      X-IPv6: "{{IPv6}}"
+    matchers:
+      - type: status
+        status: [200]
+`)
+	matched, _, err := MatchTemplate(context.Background(), srv.URL, "", tmpl, testSettings(), testLogger())
+	if err != nil || !matched {
+		t.Fatalf("MatchTemplate = %v, %v", matched, err)
+	}
+	if port := srv.Listener.Addr().(*net.TCPAddr).Port; host != net.JoinHostPort("::1", strconv.Itoa(port)) {
+		t.Errorf("Host header = %q, want the bracketed address", host)
+	}
+	if ipv6Header != "::1" {
+		t.Errorf("{{IPv6}} = %q, want ::1", ipv6Header)
+	}
+}
+
+func TestNetworkRequestIPv6Target(t *testing.T) {
+	l := listenIPv6(t)
+	defer l.Close()
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		io.WriteString(conn, "SSH-2.0-OpenSSH_9.6\r\n")
+	}()
+
+	req := &Request{
+		Type:     "network",
+		Matchers: []Matcher{{Type: "network", Pattern: "OpenSSH"}},
+	}
+	port := strconv.Itoa(l.Addr().(*net.TCPAddr).Port)
+	matched, err := matchNetworkRequest(context.Background(), "::1", port, req, &Template{ID: "ipv6-network"}, testSettings(), testLogger())
+	if err != nil || !matched {
+		t.Fatalf("matchNetworkRequest on [::1]:%s = %v, %v", port, matched, err)
+	}
+}
+
+func TestGetCompiledRegex(t *testing.T) {
+	first, err := getCompiledRegex(`admin-(\d+)`)
+	if err != nil {
+		t.Fatalf("getCompiledRegex: %v", err)
+	}
+	second, _ := getCompiledRegex(`admin-(\d+)`)
+	if first != second {
+		t.Error("the same pattern was compiled twice")
+	}
+	insensitive, _ := getCompiledRegex(`(?i)admin-(\d+)`)
+	if insensitive == first || !insensitive.MatchString("ADMIN-1") || first.MatchString("ADMIN-1") {
+		t.Error("the (?i) prefix is not part of the cache key")
+	}
+	if _, err := getCompiledRegex(`admin-(`); err == nil {
+		t.Error("invalid pattern compiled without an error")
+	}
+	if _, ok := regexCache.Load(`admin-(`); ok {
+		t.Error("invalid pattern was cached")
+	}
+}
+
+func TestGetCompiledRegexConcurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	results := make([]*regexp.Regexp, 50)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			re, err := getCompiledRegex(`concurrent-[a-z]+`)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			results[i] = re
+		}(i)
+	}
+	wg.Wait()
+	cached, _ := regexCache.Load(`concurrent-[a-z]+`)
+	for _, re := range results {
+		if re == nil || !re.MatchString("concurrent-abc") {
+			t.Fatalf("concurrent getCompiledRegex returned %v", re)
+		}
+	}
+	if cached == nil {
+		t.Error("pattern is not cached after concurrent use")
+	}
+}
+
+// benchmarkPatterns are 50 matcher-like patterns
+var benchmarkPatterns = func() []string {
+	patterns := make([]string, 50)
+	for i := range patterns {
+		patterns[i] = `(?i)version[-_ ]` + strconv.Itoa(i) + `\.(\d+)\.(\d+)|[a-z0-9._%+-]+@example` + strconv.Itoa(i) + `\.com`
+	}
+	return patterns
+}()
+
+// regexSink keeps the compiled patterns of the benchmarks alive
+var regexSink *regexp.Regexp
+
+func BenchmarkRegexCached(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		for _, p := range benchmarkPatterns {
+			re, err := getCompiledRegex(p)
+			if err != nil {
+				b.Fatal(err)
+			}
+			regexSink = re
+		}
+	}
+}
+
+func BenchmarkRegexUncached(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		for _, p := range benchmarkPatterns {
+			re, err := regexp.Compile(p)
+			if err != nil {
+				b.Fatal(err)
+			}
+			regexSink = re
+		}
+	}
+}
+
+// xpathPage and xpathExpr are a login page and an expression selecting its password field
+const (
+	xpathPage = `<html><body><div id="main"><form id="login" action="/session">
+<input type="text" name="user"><input type="password" name="pass" autocomplete="off">
+</form></div></body></html>`
+	xpathExpr = `//div[@id='main']//form[contains(@action,'session') and not(@method='get')]/input[@type='password' and starts-with(@name,'pa')]`
+)
+
+func TestXPathCacheConcurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if !matchXPathByPart([]byte(xpathPage), xpathExpr) {
+				t.Error("xpath matcher missed the password field")
+			}
+		}()
+	}
+	wg.Wait()
+
+	first, err := getCompiledXPath(xpathExpr)
+	if err != nil {
+		t.Fatalf("getCompiledXPath: %v", err)
+	}
+	if second, _ := getCompiledXPath(xpathExpr); first != second {
+		t.Error("the same expression was compiled twice")
+	}
+	if _, err := getCompiledXPath(`//div[`); err == nil {
+		t.Error("invalid expression compiled without an error")
+	}
+}
+
+func BenchmarkXPathCached(b *testing.B) {
+	doc, err := htmlquery.Parse(strings.NewReader(xpathPage))
+	if err != nil {
+		b.Fatal(err)
+	}
+	for i := 0; i < b.N; i++ {
+		expr, err := getCompiledXPath(xpathExpr)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if htmlquery.QuerySelector(doc, expr) == nil {
+			b.Fatal("no match")
+		}
+	}
+}
+
+func BenchmarkXPathUncached(b *testing.B) {
+	doc, err := htmlquery.Parse(strings.NewReader(xpathPage))
+	if err != nil {
+		b.Fatal(err)
+	}
+	for i := 0; i < b.N; i++ {
+		expr, err := xpath.Compile(xpathExpr)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if htmlquery.QuerySelector(doc, expr) == nil {
+			b.Fatal("no match")
+		}
+	}
+}
@@ -0,0 +1,30 @@
+// package templates - tool version compatibility checks
+package templates
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+// ToolVersion is the version of the scanner, templates may require a minimum version with min-tool-version
+const ToolVersion = "v3.0.0"
+
+// checkToolVersion returns an error if the template requires a newer tool version than ToolVersion
+func checkToolVersion(tmpl *Template) error {
+	required := tmpl.Info.MinToolVersion
+	if required == "" {
+		return nil
+	}
+	if !strings.HasPrefix(required, "v") {
+		required = "v" + required
+	}
+	if !semver.IsValid(required) {
+		return fmt.Errorf("template %s has invalid min-tool-version: %s", tmpl.ID, tmpl.Info.MinToolVersion)
+	}
+	if semver.Compare(ToolVersion, required) < 0 {
+		return fmt.Errorf("template %s requires tool version %s, current version is %s", tmpl.ID, required, ToolVersion)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package templates
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestCheckToolVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		required string
+		wantErr  bool
+	}{
+		{name: "no requirement", required: ""},
+		{name: "equal version", required: ToolVersion},
+		{name: "equal version without prefix", required: ToolVersion[1:]},
+		{name: "tool newer", required: "v2.9.1"},
+		{name: "tool older", required: "v3.1.0", wantErr: true},
+		{name: "tool older major", required: "4.0.0", wantErr: true},
+		{name: "invalid version", required: "latest", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl := &Template{ID: "versioned", Info: Info{MinToolVersion: tt.required}}
+			if err := checkToolVersion(tmpl); (err != nil) != tt.wantErr {
+				t.Fatalf("checkToolVersion(%q) error = %v, wantErr %v", tt.required, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadTemplateMinToolVersion(t *testing.T) {
+	const template = `id: needs-newer-tool
+version: 1.2.0
+info:
+  name: Needs newer tool
+  author: test
+  severity: info
+  min-tool-version: %s
+http:
+  - path:
+      - "{{BaseURL}}/"
+    matchers:
+      - type: status
+        status: [200]
+`
+	for version, wantErr := range map[string]bool{"v99.0.0": true, "v1.0.0": false} {
+		tmpl, err := LoadTemplate(writeTestFile(t, t.TempDir(), "template.yaml", fmt.Sprintf(template, version)))
+		if (err != nil) != wantErr {
+			t.Fatalf("LoadTemplate with min-tool-version %s error = %v, wantErr %v", version, err, wantErr)
+		}
+		if err == nil && (tmpl.Version != "1.2.0" || tmpl.Info.MinToolVersion != version) {
+			t.Errorf("versions = %q, %q", tmpl.Version, tmpl.Info.MinToolVersion)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+// package templates - WebSocket upgrade requests
+package templates
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/artnikel/nuclei/internal/constants"
+	"github.com/artnikel/nuclei/internal/logging"
+	"github.com/gorilla/websocket"
+)
+
+// matchWebSocketRequest upgrades a connection to the ws or wss URL of the request path, sends the default payload
+// if any and matches the first received frame. The frame is the body of the 101 handshake response for word and
+// regex matchers and the network data for network matchers
+func matchWebSocketRequest(ctx context.Context, baseURL string, req *Request, tmpl *Template, advanced *AdvancedSettingsChecker, logger *logging.Logger) (bool, error) {
+	if req.Type != "websocket" {
+		return false, fmt.Errorf("request type is not websocket: %s", req.Type)
+	}
+
+	target := baseURL
+	if len(req.Path) > 0 {
+		target = baseURL + req.Path[0]
+	}
+	target = webSocketURL(target)
+
+	if advanced.DryRun {
+		logDryRun(advanced, logger, "Would connect: "+target)
+		return false, nil
+	}
+
+	header := http.Header{}
+	for k, v := range req.Headers {
+		header.Set(k, v)
+	}
+	dialer := &websocket.Dialer{
+		HandshakeTimeout: constants.TenSecTimeout,
+		TLSClientConfig:  &tls.Config{InsecureSkipVerify: true},
+	}
+	conn, resp, err := dialer.DialContext(ctx, target, header)
+	if err != nil {
+		if resp != nil {
+			return false, fmt.Errorf("websocket upgrade failed with status %d: %w", resp.StatusCode, err)
+		}
+		return false, err
+	}
+	defer conn.Close()
+
+	if payload := defaultPayload(req); len(payload) > 0 {
+		if err := conn.WriteMessage(websocket.TextMessage, payload); err != nil {
+			return false, err
+		}
+	}
+	conn.SetReadDeadline(time.Now().Add(constants.FiveSecTimeout))
+	_, frame, err := conn.ReadMessage()
+	if err != nil {
+		return false, err
+	}
+
+	matchCtx := MatchContext{
+		Resp: resp,
+		Body: frame,
+		Network: &NetworkResponse{
+			Data: frame,
+		},
+	}
+	matched := checkMatchers(req.Matchers, req.MatchersCondition, matchCtx)
+
+	logger.Info("WebSocket request matched",
+		slog.String("template_id", tmpl.ID),
+		slog.String("url", target),
+		slog.Bool("matched", matched),
+	)
+	return matched, nil
+}
+
+// webSocketURL replaces the http and https schemes of rawURL with ws and wss
+func webSocketURL(rawURL string) string {
+	switch {
+	case strings.HasPrefix(rawURL, "https://"):
+		return "wss://" + strings.TrimPrefix(rawURL, "https://")
+	case strings.HasPrefix(rawURL, "http://"):
+		return "ws://" + strings.TrimPrefix(rawURL, "http://")
+	default:
+		return rawURL
+	}
+}
@@ -0,0 +1,92 @@
+package templates
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+// newEchoServer upgrades /ws to WebSocket and echoes the first received message, /plain is a regular page
+func newEchoServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	upgrader := websocket.Upgrader{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		kind, msg, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		conn.WriteMessage(kind, append([]byte("echo: "), msg...))
+	})
+	mux.HandleFunc("/plain", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "echo: not a websocket")
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+const webSocketTemplate = `id: websocket-echo-%d
+info:
+  name: Unauthenticated WebSocket
+  author: test
+  severity: medium
+http:
+  - type: websocket
+    path:
+      - "%s"
+    payloads:
+      default:
+        - '{"action":"subscribe","channel":"admin"}'
+    matchers:
+      - type: word
+        words:
+          - '%s'
+`
+
+func TestWebSocketRequest(t *testing.T) {
+	srv := newEchoServer(t)
+	tests := []struct {
+		name string
+		path string
+		word string
+		want bool
+	}{
+		{name: "echoed message", path: "/ws", word: `echo: {"action":"subscribe","channel":"admin"}`, want: true},
+		{name: "other frame", path: "/ws", word: "unauthorized", want: false},
+		{name: "no upgrade", path: "/plain", word: "echo:", want: false},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl := loadTestTemplate(t, fmt.Sprintf(webSocketTemplate, i, tt.path, tt.word))
+			matched, _, err := matchTemplate(context.Background(), srv.URL, "", tmpl, nil, testSettings(), testLogger())
+			if err != nil {
+				t.Fatalf("matchTemplate: %v", err)
+			}
+			if matched != tt.want {
+				t.Errorf("websocket matched = %v, want %v", matched, tt.want)
+			}
+		})
+	}
+}
+
+func TestWebSocketURL(t *testing.T) {
+	for in, want := range map[string]string{
+		"http://example.com/ws":      "ws://example.com/ws",
+		"https://example.com:8443/x": "wss://example.com:8443/x",
+		"ws://example.com/ws":        "ws://example.com/ws",
+	} {
+		if got := webSocketURL(in); got != want {
+			t.Errorf("webSocketURL(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
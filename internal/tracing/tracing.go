@@ -0,0 +1,41 @@
+// Package tracing configures OpenTelemetry distributed tracing
+package tracing
+
+import (
+	"context"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// endpointEnv is the standard OpenTelemetry variable holding the collector endpoint
+const endpointEnv = "OTEL_EXPORTER_OTLP_ENDPOINT"
+
+// Init installs a global tracer provider exporting spans over OTLP/HTTP.
+// The endpoint is read from OTEL_EXPORTER_OTLP_ENDPOINT, falling back to cfgEndpoint;
+// when neither is set tracing stays disabled. The returned function flushes and stops the exporter
+func Init(ctx context.Context, cfgEndpoint string) (func(context.Context) error, error) {
+	var opts []otlptracehttp.Option
+	if os.Getenv(endpointEnv) == "" {
+		if cfgEndpoint == "" {
+			return func(context.Context) error { return nil }, nil
+		}
+		opts = append(opts, otlptracehttp.WithEndpointURL(cfgEndpoint))
+	}
+
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", "nuclei"))),
+	)
+	otel.SetTracerProvider(tp)
+	return tp.Shutdown, nil
+}
@@ -0,0 +1,28 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+)
+
+func TestInitDisabledWithoutEndpoint(t *testing.T) {
+	t.Setenv(endpointEnv, "")
+	shutdown, err := Init(context.Background(), "")
+	if err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("shutdown: %v", err)
+	}
+}
+
+func TestInitWithConfigEndpoint(t *testing.T) {
+	t.Setenv(endpointEnv, "")
+	shutdown, err := Init(context.Background(), "http://127.0.0.1:4318")
+	if err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("shutdown: %v", err)
+	}
+}
@@ -0,0 +1,134 @@
+// Package waf detects web application firewalls in front of a target
+package waf
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// probeQuery is appended to the target to provoke a WAF response
+var probeQuery = url.Values{
+	"id":   {"1' OR '1'='1"},
+	"q":    {"<script>alert(1)</script>"},
+	"file": {"../../../../etc/passwd"},
+}
+
+// maxProbeBody limits the amount of the probe response body inspected
+const maxProbeBody = 64 * 1024
+
+// WAFInfo describes a detected firewall
+type WAFInfo struct {
+	Name       string
+	Confidence float64
+}
+
+// signature describes how a firewall reveals itself in a response
+type signature struct {
+	name       string
+	headers    []string
+	cookies    []*regexp.Regexp
+	body       []*regexp.Regexp
+	confidence float64
+}
+
+// signatures are the known WAF fingerprints checked in order
+var signatures = []signature{
+	{
+		name:       "Cloudflare",
+		headers:    []string{"CF-RAY", "cf-mitigated"},
+		body:       []*regexp.Regexp{regexp.MustCompile(`(?i)attention required! \| cloudflare`)},
+		confidence: 0.9,
+	},
+	{
+		name:       "AWS WAF",
+		headers:    []string{"x-amzn-RequestId", "x-amz-cf-id"},
+		body:       []*regexp.Regexp{regexp.MustCompile(`(?i)request blocked.*aws`)},
+		confidence: 0.7,
+	},
+	{
+		name:       "ModSecurity",
+		body:       []*regexp.Regexp{regexp.MustCompile(`(?i)mod_security|modsecurity|this error was generated by mod_security`)},
+		confidence: 0.8,
+	},
+	{
+		name:       "F5 BIG-IP ASM",
+		cookies:    []*regexp.Regexp{regexp.MustCompile(`^TS[0-9a-f]{6,}$`), regexp.MustCompile(`^BIGipServer`)},
+		body:       []*regexp.Regexp{regexp.MustCompile(`(?i)the requested url was rejected`)},
+		confidence: 0.8,
+	},
+}
+
+// Detector probes targets for known WAF signatures
+type Detector struct{}
+
+// NewDetector creates a WAF detector
+func NewDetector() *Detector {
+	return &Detector{}
+}
+
+// Detect sends a single malicious looking probe and returns the matched WAF, or nil when none is detected
+func (d *Detector) Detect(ctx context.Context, target string, client *http.Client) (*WAFInfo, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, err
+	}
+	u.RawQuery = probeQuery.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxProbeBody))
+	if err != nil {
+		return nil, err
+	}
+	return match(resp, body), nil
+}
+
+// match returns the first signature found in the response
+func match(resp *http.Response, body []byte) *WAFInfo {
+	blocked := resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusNotAcceptable
+	for _, sig := range signatures {
+		confidence := 0.0
+		for _, h := range sig.headers {
+			if resp.Header.Get(h) != "" {
+				confidence = sig.confidence
+				break
+			}
+		}
+		for _, c := range resp.Cookies() {
+			for _, re := range sig.cookies {
+				if re.MatchString(c.Name) {
+					confidence = sig.confidence
+				}
+			}
+		}
+		for _, re := range sig.body {
+			if re.Match(body) {
+				confidence = sig.confidence
+			}
+		}
+		if confidence == 0 {
+			continue
+		}
+		// a fingerprint without a blocking status may just be a CDN passing the request through
+		if !blocked {
+			confidence /= 2
+		}
+		return &WAFInfo{Name: sig.name, Confidence: confidence}
+	}
+	if blocked && strings.Contains(strings.ToLower(string(body)), "firewall") {
+		return &WAFInfo{Name: "Generic", Confidence: 0.3}
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package waf
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name           string
+		handler        http.HandlerFunc
+		wantName       string
+		wantConfidence float64
+	}{
+		{
+			name: "cloudflare block",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("CF-RAY", "8a1b2c3d4e5f-AMS")
+				w.WriteHeader(http.StatusForbidden)
+				io.WriteString(w, "<title>Attention Required! | Cloudflare</title>")
+			},
+			wantName:       "Cloudflare",
+			wantConfidence: 0.9,
+		},
+		{
+			name: "cloudflare pass through",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("CF-RAY", "8a1b2c3d4e5f-AMS")
+				io.WriteString(w, "<html>home</html>")
+			},
+			wantName:       "Cloudflare",
+			wantConfidence: 0.45,
+		},
+		{
+			name: "modsecurity",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotAcceptable)
+				io.WriteString(w, "<p>This error was generated by Mod_Security.</p>")
+			},
+			wantName:       "ModSecurity",
+			wantConfidence: 0.8,
+		},
+		{
+			name: "f5 cookie",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.SetCookie(w, &http.Cookie{Name: "TS01a2b3c4", Value: "x"})
+				w.WriteHeader(http.StatusForbidden)
+			},
+			wantName:       "F5 BIG-IP ASM",
+			wantConfidence: 0.8,
+		},
+		{
+			name: "generic firewall",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusForbidden)
+				io.WriteString(w, "Blocked by the web application firewall")
+			},
+			wantName:       "Generic",
+			wantConfidence: 0.3,
+		},
+		{
+			name: "no waf",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				io.WriteString(w, "<html>home</html>")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(tt.handler)
+			defer srv.Close()
+			info, err := NewDetector().Detect(context.Background(), srv.URL, srv.Client())
+			if err != nil {
+				t.Fatalf("Detect: %v", err)
+			}
+			if tt.wantName == "" {
+				if info != nil {
+					t.Fatalf("Detect() = %+v, want no WAF", info)
+				}
+				return
+			}
+			if info == nil || info.Name != tt.wantName || info.Confidence != tt.wantConfidence {
+				t.Fatalf("Detect() = %+v, want %s with confidence %v", info, tt.wantName, tt.wantConfidence)
+			}
+		})
+	}
+}
+
+func TestDetectProbeIsEncoded(t *testing.T) {
+	var rawQuery string
+	var query map[string][]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rawQuery, query = r.URL.RawQuery, r.URL.Query()
+	}))
+	defer srv.Close()
+
+	if _, err := NewDetector().Detect(context.Background(), srv.URL+"/app", srv.Client()); err != nil {
+		t.Fatalf("Detect: %v", err)
+	}
+	for key, want := range map[string]string{"id": "1' OR '1'='1", "q": "<script>alert(1)</script>", "file": "../../../../etc/passwd"} {
+		if got := query[key]; len(got) != 1 || got[0] != want {
+			t.Errorf("probe parameter %s = %q, want %q", key, got, want)
+		}
+	}
+	for _, c := range []string{" ", "<", ">", "'"} {
+		if strings.Contains(rawQuery, c) {
+			t.Errorf("probe query %q contains unescaped %q", rawQuery, c)
+		}
+	}
+}
@@ -2,39 +2,52 @@
 package main
 
 import (
+	"context"
 	"log"
-	"os"
+	"log/slog"
 	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
 	"fyne.io/fyne/v2/container"
-	"fyne.io/fyne/v2/theme"
 
 	"github.com/artnikel/nuclei/internal/config"
 	"github.com/artnikel/nuclei/internal/constants"
 	"github.com/artnikel/nuclei/internal/gui"
 	"github.com/artnikel/nuclei/internal/logging"
+	"github.com/artnikel/nuclei/internal/metrics"
+	"github.com/artnikel/nuclei/internal/scanner"
 	"github.com/artnikel/nuclei/internal/security"
 	"github.com/artnikel/nuclei/internal/license"
+	"github.com/artnikel/nuclei/internal/storage"
+	"github.com/artnikel/nuclei/internal/templates"
+	"github.com/artnikel/nuclei/internal/tracing"
 )
 
 func main() {
-	cfg, err := config.LoadConfig("config.yaml")
+	cfg, err := config.LoadConfig(config.DefaultPath)
 	if err != nil {
 		log.Fatalf("failed to load config: %v", err)
 	}
 
-	logger, err := logging.NewLogger(cfg.Logging.Path)
+	guiWriter := logging.NewGUIWriter()
+	logger, err := logging.NewLogger(cfg.Logging.Path, cfg.Logging.Format, guiWriter)
 	if err != nil {
 		log.Fatalf("failed to init logger: %v", err)
 	}
 
+	if cfg.App.MetricsAddr != "" {
+		go func() {
+			if err := metrics.Serve(cfg.App.MetricsAddr); err != nil {
+				logger.Error("Metrics server failed", slog.Any("error", err))
+			}
+		}()
+	}
+
 	go func() {
 		for {
 			if security.IsBeingDebugged() {
-				logger.Error.Fatalf("Debug detected. Exiting.")
-				os.Exit(1)
+				logger.Fatal("Debug detected. Exiting.")
 			}
 			time.Sleep(constants.FiveSecTimeout)
 		}
@@ -42,38 +55,75 @@ func main() {
 
 	go func() {
 		for {
-			cfg, err := config.LoadConfig("config.yaml")
+			cfg, err := config.LoadConfig(config.DefaultPath)
 			if err != nil {
-				logger.Error.Fatalf("Failed to load config: %v", err)
+				logger.Fatal("Failed to load config", slog.Any("error", err))
 			}
 			lc := license.NewLicenseClient(cfg.License.ServerURL, cfg.License.Key)
 			time.Sleep(constants.DayTimeout)
 
 			if err := lc.CheckLicense(); err != nil {
-				logger.Error.Fatalf("Failed to verify the license: %v", err)
-				os.Exit(1)
+				logger.Fatal("Failed to verify the license", slog.Any("error", err))
 			}
 		}
 	}()
+	shutdownTracing, err := tracing.Init(context.Background(), cfg.Tracing.Endpoint)
+	if err != nil {
+		logger.Error("Failed to init tracing", slog.Any("error", err))
+	} else {
+		defer shutdownTracing(context.Background())
+	}
+
+	if cfg.Templates.UpdateURL != "" && cfg.Templates.Dir != "" {
+		go func() {
+			updater := templates.NewUpdater(cfg.Templates.UpdateURL, cfg.Templates.Dir, cfg.Templates.HMACKey)
+			result, err := updater.CheckForUpdates(context.Background())
+			if err != nil {
+				logger.Error("Template update failed", slog.Any("error", err))
+				return
+			}
+			logger.Info("Templates updated", slog.Int("changed", len(result.Changed)))
+		}()
+	}
+
+	store, err := storage.NewSQLiteStore(constants.DatabaseFile)
+	if err != nil {
+		logger.Fatal("Failed to open scan history storage", slog.Any("error", err))
+	}
+	defer store.Close()
+
 	a := app.NewWithID(cfg.App.ID)
-	a.Settings().SetTheme(theme.DarkTheme())
+	gui.ApplySavedTheme(a)
 	w := a.NewWindow("Nuclei 3.0 GUI Scanner")
 
-	scannerSection, _, _ := gui.BuildScannerSection(a, w, logger)
-	templateCheckerSection := gui.BuildTemplateCheckerSection(a, w, logger)
+	drop := gui.NewFileDrop(w)
+	scannerSection, scanControl := gui.BuildScannerSection(a, w, store, drop, logger)
+	templateCheckerSection, templateBrowserSection := gui.BuildTemplateCheckerSection(a, w, drop, logger)
+	templateEditorSection := gui.BuildTemplateEditorSection(a, w, logger)
 	licenseSection := gui.BuildLicenseSection(a, w)
+	historySection := gui.BuildHistorySection(a, w, store, logger)
+	settingsSection := gui.BuildSettingsSection(a, w, logger)
+	logsSection := gui.BuildLogsSection(w, guiWriter)
 
 	tabs := container.NewAppTabs(
 		container.NewTabItem("Scanner", scannerSection),
 		container.NewTabItem("Template Checker", templateCheckerSection),
+		container.NewTabItem("Templates", templateBrowserSection),
+		container.NewTabItem("Template Editor", templateEditorSection),
+		container.NewTabItem("Scan History", historySection),
 		container.NewTabItem("License", licenseSection),
+		container.NewTabItem("Settings", settingsSection),
+		container.NewTabItem("Logs", logsSection),
 	)
 	const (
 		width  = 800
 		heigth = 750
 	)
+	w.SetMainMenu(gui.BuildMainMenu(a, w))
+	gui.BuildTrayIcon(a, w, scanControl)
 	w.SetContent(tabs)
 	w.Resize(fyne.NewSize(width, heigth))
 	w.CenterOnScreen()
 	w.ShowAndRun()
+	scanner.ShutdownWorkerPools()
 }